@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/neox5/otelbox/internal/backfill"
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/urfave/cli/v3"
+)
+
+// backfillCmd generates historical samples for a time range and writes
+// them to a file or a remote write endpoint.
+// Logs go to stderr so file output may go to stdout.
+func backfillCmd(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	out, remote := cmd.String("out"), cmd.String("remote-write")
+	if (out == "") == (remote == "") {
+		return fmt.Errorf("exactly one of --out or --remote-write required")
+	}
+
+	end := time.Now()
+	if s := cmd.String("end"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return fmt.Errorf("invalid --end: %w", err)
+		}
+		end = t
+	}
+	start := end.Add(-cmd.Duration("range"))
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	var constLabels map[string]string
+	if cfg.Export.Prometheus != nil {
+		constLabels = cfg.Export.Prometheus.ConstLabels
+	}
+
+	var w backfill.Writer
+	if remote != "" {
+		w = backfill.NewRemoteWriter(remote, constLabels)
+	} else {
+		f := os.Stdout
+		if out != "-" {
+			if f, err = os.Create(out); err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer f.Close()
+		}
+
+		switch format := cmd.String("format"); format {
+		case "openmetrics":
+			w = backfill.NewOpenMetricsWriter(f, constLabels)
+		case "otlp":
+			resource := map[string]string{"service.name": config.DefaultServiceName}
+			if cfg.Export.OTEL != nil {
+				resource = cfg.Export.OTEL.Resource
+			}
+			w = backfill.NewOTLPWriter(f, start, resource)
+		default:
+			return fmt.Errorf("invalid --format: %s (must be openmetrics or otlp)", format)
+		}
+	}
+
+	finishRecording, err := startRecordReplay(cmd)
+	if err != nil {
+		return err
+	}
+	defer finishRecording()
+
+	ticks, err := backfill.Run(cfg, start, end, w)
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := finishRecording(); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	slog.Info("backfill written", "start", start.Format(time.RFC3339), "end", end.Format(time.RFC3339), "ticks", ticks)
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/neox5/otelbox/internal/bench"
+	"github.com/urfave/cli/v3"
+)
+
+// benchCmd runs the built-in workload at increasing series counts and
+// reports the cost per series and the sustainable series count.
+// Logs go to stderr so the report stays readable.
+func benchCmd(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	opts := bench.Options{
+		StartSeries: cmd.Int("start-series"),
+		MaxSeries:   cmd.Int("max-series"),
+		Ticks:       cmd.Int("ticks"),
+		Seed:        cmd.Uint64("seed"),
+	}
+
+	fmt.Printf("Benchmarking %s interval updates on %d CPUs (GOMAXPROCS %d)\n\n",
+		bench.Interval, runtime.NumCPU(), runtime.GOMAXPROCS(0))
+
+	// Fixed columns, since rows are printed as they are measured
+	const row = "%-10v %-12v %-8v %-12v %v\n"
+	fmt.Printf(row, "SERIES", "TICK", "LOAD", "CPU/SERIES", "MEM/SERIES")
+	results, err := bench.Run(opts, func(r bench.Result) {
+		fmt.Printf(row, r.Series, r.TickTime.Round(time.Microsecond),
+			fmt.Sprintf("%.1f%%", 100*r.Load()), r.CPUPerSeries, fmt.Sprintf("%dB", r.MemPerSeries))
+	})
+	if err != nil {
+		return err
+	}
+
+	last := results[len(results)-1]
+	fmt.Printf("\nMax sustainable series: ~%d (extrapolated from %d series)\n", bench.MaxSustainable(results), last.Series)
+	return nil
+}
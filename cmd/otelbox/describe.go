@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/urfave/cli/v3"
+)
+
+// componentKind groups supported component types for describe output.
+type componentKind struct {
+	name  string
+	types func() []simulation.TypeInfo
+}
+
+// componentKinds lists the kinds shown by describe in output order.
+var componentKinds = []componentKind{
+	{name: "clocks", types: simulation.ClockTypes},
+	{name: "sources", types: simulation.SourceTypes},
+	{name: "transforms", types: simulation.TransformTypes},
+	{name: "resets", types: simulation.ResetTypes},
+}
+
+// describeCommands returns one describe subcommand per component kind.
+func describeCommands() []*cli.Command {
+	commands := make([]*cli.Command, len(componentKinds))
+	for i, kind := range componentKinds {
+		commands[i] = &cli.Command{
+			Name:  kind.name,
+			Usage: fmt.Sprintf("List supported %s types and their options", strings.TrimSuffix(kind.name, "s")),
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return writeTypes(os.Stdout, kind.types())
+			},
+		}
+	}
+	return commands
+}
+
+// describeAll prints all supported component types grouped by kind.
+func describeAll(ctx context.Context, cmd *cli.Command) error {
+	for i, kind := range componentKinds {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n\n", strings.ToUpper(kind.name[:1])+kind.name[1:])
+		if err := writeTypes(os.Stdout, kind.types()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTypes prints type descriptions with an aligned option table.
+func writeTypes(w io.Writer, types []simulation.TypeInfo) error {
+	for i, t := range types {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\n  %s\n", t.Name, t.Description)
+		if len(t.Options) == 0 {
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, opt := range t.Options {
+			required := "optional"
+			if opt.Required {
+				required = "required"
+			}
+			fmt.Fprintf(tw, "    %s\t%s\t%s\t%s\n", opt.Name, opt.Type, required, opt.Description)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
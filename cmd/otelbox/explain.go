@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/urfave/cli/v3"
+)
+
+// explain prints the fully expanded and resolved configuration as YAML.
+// Logs go to stderr so the output can be redirected to a file.
+func explain(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	raw, err := loadRawConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	applySettingsOverrides(cmd, raw)
+
+	// Count series per definition before expansion replaces definitions
+	counts, err := config.SeriesCounts(raw)
+	if err != nil {
+		return fmt.Errorf("failed to expand config: %w", err)
+	}
+
+	cfg, err := resolveConfig(cmd, raw)
+	if err != nil {
+		return err
+	}
+
+	out, err := config.Explain(cfg, counts)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
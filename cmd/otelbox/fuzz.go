@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/version"
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// fuzz generates a random workload within budgets and runs it.
+// The seed determines both the generated configuration and its values;
+// without --seed a random seed is drawn and logged for reproduction.
+func fuzz(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Bool("print") {
+		setupLogging(cmd, os.Stderr)
+	} else {
+		setupLogging(cmd, os.Stdout)
+		slog.Info("starting otelbox", "version", version.String())
+	}
+
+	seed := rand.Uint64()
+	if cmd.IsSet("seed") {
+		seed = cmd.Uint64("seed")
+	}
+
+	opts := config.FuzzOptions{
+		Series:              cmd.Int("series"),
+		Families:            cmd.Int("families"),
+		LabelCardinalityMax: cmd.Int("label-cardinality-max"),
+		Seed:                seed,
+	}
+	slog.Info("generating fuzz workload",
+		"series", opts.Series,
+		"families", opts.Families,
+		"label_cardinality_max", opts.LabelCardinalityMax,
+		"seed", opts.Seed)
+
+	raw, err := config.FuzzProfile(opts)
+	if err != nil {
+		return err
+	}
+
+	// Apply export target override
+	if target := cmd.String("target"); target != "" {
+		if err := config.ApplyTarget(raw, target); err != nil {
+			return err
+		}
+	}
+
+	if err := config.Validate(raw); err != nil {
+		return fmt.Errorf("generated config is invalid: %w", err)
+	}
+
+	// Print the workload as a config file instead of running it
+	if cmd.Bool("print") {
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(raw); err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		return encoder.Close()
+	}
+
+	cfg, err := resolveConfig(cmd, raw)
+	if err != nil {
+		return err
+	}
+
+	return run(ctx, cmd, cfg, false)
+}
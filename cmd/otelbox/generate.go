@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/urfave/cli/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+// generateCommands returns the subcommands of generate.
+func generateCommands() []*cli.Command {
+	scenario := &cli.StringFlag{
+		Name:  "scenario",
+		Value: config.ScaffoldScenarios[0],
+		Usage: "scenario to model: " + strings.Join(config.ScaffoldScenarios, ", "),
+	}
+
+	return []*cli.Command{
+		{
+			Name:   "config",
+			Usage:  "Print a starter config for a common scenario",
+			Flags:  []cli.Flag{scenario},
+			Action: generateConfig,
+		},
+		{
+			Name:  "k8s",
+			Usage: "Print Kubernetes manifests running a starter config",
+			Flags: []cli.Flag{
+				scenario,
+				&cli.StringFlag{
+					Name:  "name",
+					Value: "otelbox",
+					Usage: "name of the generated resources",
+				},
+				&cli.StringFlag{
+					Name:  "image",
+					Value: "ghcr.io/neox5/otelbox:latest",
+					Usage: "container image to run",
+				},
+			},
+			Action: generateK8s,
+		},
+	}
+}
+
+// generateConfig prints the starter config of a scenario.
+func generateConfig(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	data, err := scaffoldConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// generateK8s prints a ConfigMap holding the starter config of a scenario,
+// a Deployment running it, and a Service for the Prometheus endpoint.
+func generateK8s(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	data, err := scaffoldConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	raw, err := config.ParseBytes(data)
+	if err != nil {
+		return err
+	}
+
+	params := manifestParams{
+		Name:   cmd.String("name"),
+		Image:  cmd.String("image"),
+		Config: string(data),
+	}
+	if prom := raw.Export.Prometheus; prom != nil && prom.Enabled {
+		params.Port = cmp.Or(prom.Port, config.DefaultPrometheusPort)
+		params.Path = cmp.Or(prom.Path, config.DefaultPrometheusPath)
+	}
+
+	var out bytes.Buffer
+	if err := manifestTemplate.Execute(&out, params); err != nil {
+		return fmt.Errorf("failed to render manifests: %w", err)
+	}
+
+	_, err = os.Stdout.Write(out.Bytes())
+	return err
+}
+
+// scaffoldConfig returns the starter config of the selected scenario as
+// YAML, with the --target export override applied.
+func scaffoldConfig(cmd *cli.Command) ([]byte, error) {
+	raw, err := config.ScaffoldProfile(cmd.String("scenario"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply export target override
+	if target := cmd.String("target"); target != "" {
+		if err := config.ApplyTarget(raw, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(raw); err != nil {
+		return nil, fmt.Errorf("generated config is invalid: %w", err)
+	}
+
+	var node yaml.Node
+	if err := node.Encode(raw); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	// Scaffolds leave instances and settings unset: omit their zero values
+	for i := 0; i+1 < len(node.Content); {
+		if key := node.Content[i].Value; key == "instances" || key == "settings" {
+			node.Content = slices.Delete(node.Content, i, i+2)
+			continue
+		}
+		i += 2
+	}
+	node.HeadComment = fmt.Sprintf("otelbox starter config: %s scenario\nGenerated by otelbox generate config", cmd.String("scenario"))
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// manifestParams fills manifestTemplate. Port is 0 without a Prometheus
+// endpoint, in which case no Service and probes are generated.
+type manifestParams struct {
+	Name   string
+	Image  string
+	Config string
+	Port   int
+	Path   string
+}
+
+var manifestTemplate = template.Must(template.New("manifests").Funcs(template.FuncMap{
+	"indent": func(n int, s string) string {
+		pad := strings.Repeat(" ", n)
+		return pad + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+pad)
+	},
+}).Parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{.Name}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+data:
+  config.yaml: |
+{{indent 4 .Config}}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{.Name}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{.Name}}
+{{- if .Port}}
+      annotations:
+        prometheus.io/scrape: "true"
+        prometheus.io/port: "{{.Port}}"
+        prometheus.io/path: "{{.Path}}"
+{{- end}}
+    spec:
+      containers:
+        - name: otelbox
+          image: {{.Image}}
+          args: ["-config", "/config/config.yaml"]
+          env:
+            - name: POD_NAME
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.name
+            - name: POD_NAMESPACE
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.namespace
+            - name: NODE_NAME
+              valueFrom:
+                fieldRef:
+                  fieldPath: spec.nodeName
+{{- if .Port}}
+          ports:
+            - name: metrics
+              containerPort: {{.Port}}
+          readinessProbe:
+            httpGet:
+              path: {{.Path}}
+              port: metrics
+            periodSeconds: 10
+          livenessProbe:
+            httpGet:
+              path: {{.Path}}
+              port: metrics
+            periodSeconds: 30
+            failureThreshold: 3
+{{- end}}
+          resources:
+            requests:
+              cpu: 50m
+              memory: 64Mi
+            limits:
+              memory: 256Mi
+          volumeMounts:
+            - name: config
+              mountPath: /config
+              readOnly: true
+      volumes:
+        - name: config
+          configMap:
+            name: {{.Name}}
+{{- if .Port}}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Name}}
+  labels:
+    app.kubernetes.io/name: {{.Name}}
+spec:
+  selector:
+    app.kubernetes.io/name: {{.Name}}
+  ports:
+    - name: metrics
+      port: {{.Port}}
+      targetPort: metrics
+{{- end}}
+`))
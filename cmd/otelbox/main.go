@@ -3,16 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
-	"log/slog"
 	"os"
-	"os/signal"
-	"sync"
-	"syscall"
 	"time"
 
-	"github.com/neox5/otelbox/internal/app"
+	"github.com/neox5/otelbox/internal/bench"
 	"github.com/neox5/otelbox/internal/config"
-	"github.com/neox5/otelbox/internal/monitor"
 	"github.com/neox5/otelbox/internal/version"
 	"github.com/urfave/cli/v3"
 )
@@ -22,19 +17,262 @@ func main() {
 		Name:    "otelbox",
 		Usage:   "Telemetry signal generator for testing observability components",
 		Version: version.String(),
+		// Repeat flags instead: --set values may contain commas
+		DisableSliceFlagSeparator: true,
 		Flags: []cli.Flag{
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name:    "config",
 				Aliases: []string{"c"},
-				Value:   "config.yaml",
-				Usage:   "path to configuration file",
+				Value:   []string{"config.yaml"},
+				Usage:   "path to configuration file or directory (repeatable, merged in order)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "profile",
+				Usage: "config profile name or overlay file applied over the configuration (repeatable, applied in order)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "set",
+				Usage: "override a config value as path=value, e.g. export.prometheus.port=9191 (repeatable, applied after profiles)",
 			},
 			&cli.BoolFlag{
 				Name:  "debug",
 				Usage: "enable debug logging",
 			},
+			&cli.StringFlag{
+				Name:  "log-format",
+				Usage: "log record format: text or json (overrides settings.logging.format)",
+				Validator: func(format string) error {
+					_, err := config.ResolveLogging(config.RawLoggingConfig{Format: format})
+					return err
+				},
+			},
+			&cli.StringFlag{
+				Name:  "log-output",
+				Usage: "log destination: stdout, stderr, or a file path (overrides settings.logging.output)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "log-level",
+				Usage: "log level, or module=level for one module (repeatable, overrides settings.logging)",
+				Validator: func(levels []string) error {
+					var raw config.RawLoggingConfig
+					if err := parseLogLevels(levels, &raw); err != nil {
+						return err
+					}
+					_, err := config.ResolveLogging(raw)
+					return err
+				},
+			},
+			&cli.BoolFlag{
+				Name:  "watch",
+				Usage: "reload configuration when config files change (SIGHUP always reloads)",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "disable the settings.max_series expansion limit",
+			},
+			&cli.Uint64Flag{
+				Name:  "seed",
+				Usage: "master seed for reproducible generation (overrides settings.seed)",
+			},
+			&cli.StringFlag{
+				Name:  "shard",
+				Usage: "generate only shard N of M of the expanded metrics, as N/M (overrides settings.shard)",
+				Validator: func(shard string) error {
+					_, err := config.ParseShard(shard)
+					return err
+				},
+			},
+			&cli.DurationFlag{
+				Name:  "duration",
+				Usage: "exit cleanly after running this long (overrides settings.run_duration)",
+			},
+			&cli.StringFlag{
+				Name:  "record",
+				Usage: "record all source updates with timestamps to a file",
+			},
+			&cli.StringFlag{
+				Name:  "replay",
+				Usage: "replay source updates from a recording instead of generating them",
+			},
+			&cli.BoolFlag{
+				Name:     "metrics",
+				Usage:    "generate metrics from the built-in profile (no config file)",
+				Category: "built-in profile",
+			},
+			&cli.IntFlag{
+				Name:     "scale",
+				Value:    config.DefaultBuiltinScale,
+				Usage:    "number of series per built-in metric",
+				Category: "built-in profile",
+			},
+			&cli.StringFlag{
+				Name:     "target",
+				Usage:    "export target: otlp://host:port, otlp+http://host:port, or prometheus://:port/path (overrides export)",
+				Category: "built-in profile",
+			},
 		},
 		Action: serve,
+		Commands: []*cli.Command{
+			{
+				Name:   "serve",
+				Usage:  "Generate and export telemetry (default)",
+				Action: serve,
+			},
+			{
+				Name:   "explain",
+				Usage:  "Print the resolved configuration with series counts and exit",
+				Action: explain,
+			},
+			{
+				Name:     "describe",
+				Usage:    "List supported clock, source, transform, and reset types with their options",
+				Commands: describeCommands(),
+				Action:   describeAll,
+			},
+			{
+				Name:     "generate",
+				Usage:    "Print starter configs and Kubernetes manifests for common scenarios",
+				Commands: generateCommands(),
+			},
+			{
+				Name:      "migrate-config",
+				Usage:     "Convert a config file to the current schema version",
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "write",
+						Usage: "overwrite the file instead of printing the converted config",
+					},
+				},
+				Action: migrateConfig,
+			},
+			{
+				Name:  "fuzz",
+				Usage: "Generate a random workload within series and label budgets and run it",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "series",
+						Value: config.DefaultFuzzSeries,
+						Usage: "upper bound on total series",
+					},
+					&cli.IntFlag{
+						Name:  "families",
+						Value: config.DefaultFuzzFamilies,
+						Usage: "number of metric families",
+					},
+					&cli.IntFlag{
+						Name:  "label-cardinality-max",
+						Value: config.DefaultFuzzLabelCardinalityMax,
+						Usage: "upper bound on distinct values per label",
+					},
+					&cli.BoolFlag{
+						Name:  "print",
+						Usage: "print the generated config instead of running it",
+					},
+				},
+				Action: fuzz,
+			},
+			{
+				Name:  "snapshot",
+				Usage: "Record the exported output of a deterministic run for regression tests",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "ticks",
+						Value: 100,
+						Usage: "number of ticks to record",
+					},
+					&cli.StringFlag{
+						Name:     "out",
+						Required: true,
+						Usage:    "directory to write the snapshot to",
+					},
+				},
+				Action: snapshotCmd,
+			},
+			{
+				Name:  "verify-snapshot",
+				Usage: "Replay a recorded snapshot and compare the output byte for byte",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "snapshot",
+						Required: true,
+						Usage:    "directory containing the recorded snapshot",
+					},
+				},
+				Action: verifySnapshot,
+			},
+			{
+				Name:  "bench",
+				Usage: "Measure per-series CPU and memory cost and the sustainable series count on this host",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "start-series",
+						Value: bench.DefaultStartSeries,
+						Usage: "series count of the first step (doubled each step)",
+					},
+					&cli.IntFlag{
+						Name:  "max-series",
+						Value: bench.DefaultMaxSeries,
+						Usage: "upper bound on the series count",
+					},
+					&cli.IntFlag{
+						Name:  "ticks",
+						Value: bench.DefaultTicks,
+						Usage: "ticks measured per step",
+					},
+				},
+				Action: benchCmd,
+			},
+			{
+				Name:  "scrape",
+				Usage: "Scrape a running instance and verify that all configured series are exposed with the configured types",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "url",
+						Usage: "endpoint to scrape (default: the configured Prometheus endpoint on localhost)",
+					},
+					&cli.StringFlag{
+						Name:  "job",
+						Usage: "verify the metrics of a job instead of the top-level metrics",
+					},
+					&cli.DurationFlag{
+						Name:  "timeout",
+						Value: 10 * time.Second,
+						Usage: "scrape timeout",
+					},
+				},
+				Action: scrapeCmd,
+			},
+			{
+				Name:  "backfill",
+				Usage: "Generate historical samples for a time range and write them to a file or remote write endpoint",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "range",
+						Value: 24 * time.Hour,
+						Usage: "length of the generated history",
+					},
+					&cli.StringFlag{
+						Name:  "end",
+						Usage: "end of the generated history (RFC 3339, default: now)",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "file to write samples to (- for stdout)",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "openmetrics",
+						Usage: "file format: openmetrics or otlp (JSON lines)",
+					},
+					&cli.StringFlag{
+						Name:  "remote-write",
+						Usage: "Prometheus remote write URL to send samples to",
+					},
+				},
+				Action: backfillCmd,
+			},
+		},
 	}
 
 	if err := cmd.Run(context.Background(), os.Args); err != nil {
@@ -42,112 +280,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func serve(ctx context.Context, cmd *cli.Command) error {
-	configPath := cmd.String("config")
-	debug := cmd.Bool("debug")
-
-	// Configure logging level
-	logLevel := slog.LevelInfo
-	if debug {
-		logLevel = slog.LevelDebug
-	}
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
-	slog.SetDefault(logger)
-
-	slog.Info("starting otelbox", "version", version.String(), "config", configPath)
-
-	// Load configuration
-	raw, err := config.Parse(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to parse config: %w", err)
-	}
-
-	// Log pre-expansion counts
-	slog.Info("configuration parsed",
-		"iterators", len(raw.Iterators),
-		"templates.clocks", len(raw.Templates.Clocks),
-		"templates.sources", len(raw.Templates.Sources),
-		"templates.values", len(raw.Templates.Values),
-		"instances.clocks", len(raw.Instances.Clocks),
-		"instances.sources", len(raw.Instances.Sources),
-		"instances.values", len(raw.Instances.Values),
-		"metrics", len(raw.Metrics))
-
-	// Expand configuration
-	if err = config.Expand(raw); err != nil {
-		return fmt.Errorf("failed to expand config: %w", err)
-	}
-
-	// Resolve configuration
-	cfg, err := config.Resolve(raw)
-	if err != nil {
-		return fmt.Errorf("failed to resolve config: %w", err)
-	}
-
-	// Log post-expansion counts
-	slog.Info("configuration expanded",
-		"clocks", len(cfg.Instances.Clocks),
-		"sources", len(cfg.Instances.Sources),
-		"values", len(cfg.Instances.Values),
-		"metrics", len(cfg.Metrics))
-
-	// Initialize application (handles seed initialization internally)
-	application, err := app.New(cfg)
-	if err != nil {
-		return fmt.Errorf("initialization failed: %w", err)
-	}
-
-	// Setup graceful shutdown
-	shutdownCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
-	// Start generator
-	application.Generator.Start()
-	defer application.Generator.Stop()
-
-	// Start resource monitor
-	mon := monitor.New(5*time.Second, logger)
-	mon.Run(shutdownCtx)
-	defer mon.Wait()
-
-	// Start exporters
-	var wg sync.WaitGroup
-	errChan := make(chan error, 2)
-
-	if application.PrometheusExporter != nil {
-		wg.Go(func() {
-			if err := application.PrometheusExporter.Start(shutdownCtx); err != nil {
-				errChan <- fmt.Errorf("prometheus exporter: %w", err)
-			}
-		})
-	}
-
-	if application.OTELExporter != nil {
-		wg.Go(func() {
-			if err := application.OTELExporter.Start(shutdownCtx); err != nil {
-				errChan <- fmt.Errorf("otel exporter: %w", err)
-			}
-		})
-	}
-
-	// Wait for shutdown or error
-	select {
-	case err := <-errChan:
-		slog.Error("exporter error", "error", err)
-		stop() // Cancel context to trigger shutdown
-	case <-shutdownCtx.Done():
-		// Graceful shutdown triggered
-	}
-
-	slog.Info("shutting down")
-
-	// Wait for all goroutines to complete
-	// The exporters' Start methods will return when shutdownCtx is cancelled
-	wg.Wait()
-
-	slog.Info("shutdown complete")
-	return nil
-}
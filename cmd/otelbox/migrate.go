@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/urfave/cli/v3"
+)
+
+// migrateConfig converts a config file to the current schema version.
+// The converted config is written to stdout unless --write is set.
+func migrateConfig(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	path := cmd.Args().First()
+	if path == "" {
+		return fmt.Errorf("config file argument required")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	out, changes, err := config.Migrate(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(changes) == 0 {
+		slog.Info("config already uses current schema", "file", path, "version", config.SchemaVersion)
+	}
+	for _, change := range changes {
+		slog.Info("converted legacy construct", "file", path, "construct", change)
+	}
+
+	if cmd.Bool("write") {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write config file: %w", err)
+		}
+		slog.Info("config migrated", "file", path, "version", config.SchemaVersion)
+		return nil
+	}
+
+	_, err = os.Stdout.Write(out)
+	return err
+}
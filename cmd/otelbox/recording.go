@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/urfave/cli/v3"
+)
+
+// startRecordReplay applies --record and --replay before sources are
+// created. The returned function finishes the recording; calls after the
+// first return the same result.
+func startRecordReplay(cmd *cli.Command) (func() error, error) {
+	record, replay := cmd.String("record"), cmd.String("replay")
+	if record != "" && replay != "" {
+		return nil, fmt.Errorf("--record and --replay are mutually exclusive")
+	}
+
+	if replay != "" {
+		f, err := os.Open(replay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+		defer f.Close()
+		if err := simulation.LoadReplay(f); err != nil {
+			return nil, fmt.Errorf("%s: %w", replay, err)
+		}
+		slog.Info("replaying recording", "file", replay)
+	}
+
+	if record == "" {
+		return func() error { return nil }, nil
+	}
+
+	f, err := os.Create(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	simulation.StartRecording(f)
+	slog.Info("recording source updates", "file", record)
+
+	return sync.OnceValue(func() error {
+		err := simulation.StopRecording()
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}), nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/neox5/otelbox/internal/app"
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/urfave/cli/v3"
+)
+
+// watchInterval is the polling interval for config file changes.
+const watchInterval = 2 * time.Second
+
+// fileStamp identifies a file version by modification time and size.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watchSignals runs the actions of user signals and, for reloadable
+// configurations, reloads on SIGHUP and, with --watch, when config files
+// change. Blocks until context is cancelled.
+func watchSignals(ctx context.Context, cmd *cli.Command, application *app.App, reloadable bool) {
+	actions := userSignalActions(application.Config.Settings.Signals)
+	usr := make(chan os.Signal, 1)
+	for sig := range actions {
+		signal.Notify(usr, sig)
+	}
+	defer signal.Stop(usr)
+
+	hup := make(chan os.Signal, 1)
+	if reloadable {
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+	}
+
+	// Poll config files (fsnotify-free, works on all filesystems)
+	var poll <-chan time.Time
+	var stamps map[string]fileStamp
+	if reloadable && cmd.Bool("watch") {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+		stamps = statFiles(watchedFiles(cmd, application))
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-usr:
+			action := actions[sig]
+			if action != config.SignalActionReload {
+				runSignalAction(application, sig, action)
+				continue
+			}
+			if !reloadable {
+				slog.Warn("reload not supported for this run, ignoring signal", "signal", sig)
+				continue
+			}
+			slog.Info("reloading configuration", "trigger", sig.String())
+		case <-hup:
+			slog.Info("reloading configuration", "trigger", "sighup")
+		case <-poll:
+			current := statFiles(watchedFiles(cmd, application))
+			if maps.Equal(current, stamps) {
+				continue
+			}
+			slog.Info("reloading configuration", "trigger", "file change")
+		}
+
+		reload(cmd, application)
+
+		// Track files of the applied configuration (includes may change)
+		if poll != nil {
+			stamps = statFiles(watchedFiles(cmd, application))
+		}
+	}
+}
+
+// reload loads configuration and applies it to the running application.
+// Keeps the current configuration on failure.
+func reload(cmd *cli.Command, application *app.App) {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		slog.Error("reload failed, keeping current configuration", "error", err)
+		return
+	}
+
+	if err := application.Reload(cfg); err != nil {
+		slog.Error("reload failed, keeping current configuration", "error", err)
+		return
+	}
+
+	slog.Info("configuration reloaded", "metrics", len(cfg.Metrics))
+}
+
+// watchedFiles returns the config paths and all files they loaded.
+// Directories are included so added files are detected.
+func watchedFiles(cmd *cli.Command, application *app.App) []string {
+	if !cmd.IsSet("config") && len(application.Config.Files) == 0 {
+		return nil // Built-in profile
+	}
+	files := append([]string{}, cmd.StringSlice("config")...)
+	return append(files, application.Config.Files...)
+}
+
+// statFiles returns the current stamps of files.
+// Missing files are recorded with a zero stamp.
+func statFiles(files []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			stamps[file] = fileStamp{}
+			continue
+		}
+		stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/neox5/otelbox/internal/scrape"
+	"github.com/urfave/cli/v3"
+)
+
+// scrapeCmd scrapes a running endpoint and verifies it against the
+// configuration. Fails if any configured series is missing or mistyped,
+// so it can gate smoke tests.
+func scrapeCmd(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	target, err := scrape.NewTarget(cfg, cmd.String("job"))
+	if err != nil {
+		return err
+	}
+	if url := cmd.String("url"); url != "" {
+		target.URL = url
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cmd.Duration("timeout"))
+	defer cancel()
+
+	families, err := scrape.Fetch(ctx, target.URL, target.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to scrape %s: %w", target.URL, err)
+	}
+
+	report := scrape.Verify(families, target)
+	for _, problem := range report.Problems {
+		fmt.Println(problem)
+	}
+	fmt.Printf("%s: %d of %d series present", target.URL, report.Found, report.Expected)
+	if report.Absent > 0 {
+		fmt.Printf(", %d sparse series absent", report.Absent)
+	}
+	fmt.Println()
+
+	if !report.OK() {
+		return fmt.Errorf("verification failed with %d problems", len(report.Problems))
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/neox5/otelbox/internal/app"
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/logging"
+	"github.com/neox5/otelbox/internal/version"
+	"github.com/urfave/cli/v3"
+)
+
+func serve(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stdout)
+
+	slog.Info("starting otelbox", "version", version.String())
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	return run(ctx, cmd, cfg, true)
+}
+
+// run starts the application and blocks until shutdown.
+// Reloadable configurations are reloaded on SIGHUP and with --watch; user
+// signals run their configured actions.
+func run(ctx context.Context, cmd *cli.Command, cfg *config.Config, reloadable bool) error {
+	// Record or replay source updates
+	finishRecording, err := startRecordReplay(cmd)
+	if err != nil {
+		return err
+	}
+	defer finishRecording()
+
+	// Apply logging settings before components start logging
+	if err := configureLogging(cfg.Settings.Logging); err != nil {
+		return err
+	}
+	defer func() {
+		if logDestination.file != nil {
+			logDestination.file.Close()
+		}
+	}()
+
+	// Initialize application (handles seed initialization internally)
+	application, err := app.New(cfg, slog.Default())
+	if err != nil {
+		return fmt.Errorf("initialization failed: %w", err)
+	}
+
+	// Setup graceful shutdown
+	shutdownCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Exit after the run duration; stopping components flushes exporters
+	if d := cfg.Settings.RunDuration; d > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, d)
+		defer cancel()
+		context.AfterFunc(shutdownCtx, func() {
+			if errors.Is(context.Cause(shutdownCtx), context.DeadlineExceeded) {
+				slog.Info("run duration elapsed, shutting down", "duration", d)
+			}
+		})
+	}
+
+	// Run components until shutdown or failure
+	lifecycle := application.Lifecycle()
+	lifecycle.Add(app.Component{
+		Name:      "signals",
+		DependsOn: []string{app.ComponentGenerator},
+		Run: func(ctx context.Context) error {
+			watchSignals(ctx, cmd, application, reloadable)
+			return nil
+		},
+	})
+
+	if err := lifecycle.Run(shutdownCtx); err != nil {
+		return err
+	}
+	if err := finishRecording(); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	slog.Info("shutdown complete")
+	return nil
+}
+
+// logDestination holds the default log output of the command and the log
+// file opened for the current logger, if any.
+var logDestination struct {
+	writer io.Writer
+	file   io.Closer
+}
+
+// setupLogging configures the default logger from command flags, writing
+// to w unless --log-output is set. Logging settings of the configuration
+// apply once it is loaded (see configureLogging).
+func setupLogging(cmd *cli.Command, w io.Writer) {
+	logDestination.writer = w
+
+	var raw config.RawLoggingConfig
+	applyLoggingFlags(cmd, &raw)
+	cfg, err := config.ResolveLogging(raw) // Flag values are validated on parsing
+	if err != nil {
+		cfg, _ = config.ResolveLogging(config.RawLoggingConfig{})
+	}
+	if err := configureLogging(cfg); err != nil {
+		slog.Warn("failed to configure logging", "error", err)
+	}
+}
+
+// configureLogging replaces the default logger with one configured by cfg
+// and closes the log file of the previous logger.
+func configureLogging(cfg config.LoggingConfig) error {
+	handler, file, err := logging.New(cfg, logDestination.writer)
+	if err != nil {
+		return err
+	}
+
+	// Sample high-frequency debug logs
+	if cfg.Sampled() {
+		handler = logging.NewSamplingHandler(handler, cfg)
+	}
+	slog.SetDefault(slog.New(handler))
+
+	if logDestination.file != nil {
+		logDestination.file.Close()
+	}
+	logDestination.file = file
+	return nil
+}
+
+// applyLoggingFlags applies the logging flags to raw logging settings.
+// --debug sets the default level before --log-level applies.
+func applyLoggingFlags(cmd *cli.Command, raw *config.RawLoggingConfig) {
+	if cmd.Bool("debug") {
+		raw.Level = string(config.LogLevelDebug)
+	}
+	if cmd.IsSet("log-format") {
+		raw.Format = cmd.String("log-format")
+	}
+	if cmd.IsSet("log-output") {
+		raw.Output = cmd.String("log-output")
+	}
+	parseLogLevels(cmd.StringSlice("log-level"), raw) // Validated on parsing
+}
+
+// parseLogLevels applies --log-level values: a level sets the default
+// level, module=level the level of one module.
+func parseLogLevels(levels []string, raw *config.RawLoggingConfig) error {
+	for _, value := range levels {
+		module, level, found := strings.Cut(value, "=")
+		if !found {
+			raw.Level = value
+			continue
+		}
+		if module == "" || level == "" {
+			return fmt.Errorf("invalid log level %q (must be level or module=level)", value)
+		}
+		if raw.Modules == nil {
+			raw.Modules = make(map[string]string)
+		}
+		raw.Modules[module] = level
+	}
+	return nil
+}
+
+// loadConfig parses, expands, and resolves configuration from command flags.
+func loadConfig(cmd *cli.Command) (*config.Config, error) {
+	raw, err := loadRawConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return resolveConfig(cmd, raw)
+}
+
+// loadRawConfig parses configuration from command flags.
+// Uses the built-in profile when profile flags are given without --config.
+func loadRawConfig(cmd *cli.Command) (*config.RawConfig, error) {
+	var raw *config.RawConfig
+	var err error
+
+	builtin := !cmd.IsSet("config") &&
+		(cmd.Bool("metrics") || cmd.IsSet("scale") || cmd.IsSet("target"))
+
+	if builtin {
+		if cmd.IsSet("profile") || cmd.IsSet("set") {
+			return nil, fmt.Errorf("--profile and --set require a config file")
+		}
+		slog.Info("loading configuration", "profile", "builtin", "scale", cmd.Int("scale"))
+
+		raw, err = config.BuiltinMetricsProfile(cmd.Int("scale"))
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		configPaths := cmd.StringSlice("config")
+		opts := config.ParseOptions{
+			Profiles:  cmd.StringSlice("profile"),
+			Overrides: cmd.StringSlice("set"),
+		}
+		slog.Info("loading configuration", "config", configPaths, "profiles", opts.Profiles, "overrides", opts.Overrides)
+
+		// Load configuration
+		raw, err = config.ParseFiles(configPaths, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+	}
+
+	// Apply export target override
+	if target := cmd.String("target"); target != "" {
+		if err := config.ApplyTarget(raw, target); err != nil {
+			return nil, err
+		}
+	}
+
+	if builtin {
+		if err := config.Validate(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	// Log pre-expansion counts
+	slog.Info("configuration parsed",
+		"iterators", len(raw.Iterators),
+		"templates.clocks", len(raw.Templates.Clocks),
+		"templates.sources", len(raw.Templates.Sources),
+		"templates.values", len(raw.Templates.Values),
+		"instances.clocks", len(raw.Instances.Clocks),
+		"instances.sources", len(raw.Instances.Sources),
+		"instances.values", len(raw.Instances.Values),
+		"metrics", len(raw.Metrics))
+
+	return raw, nil
+}
+
+// resolveConfig expands and resolves parsed configuration and applies
+// command flag overrides.
+func resolveConfig(cmd *cli.Command, raw *config.RawConfig) (*config.Config, error) {
+	applySettingsOverrides(cmd, raw)
+
+	// Expand configuration
+	if err := config.Expand(raw); err != nil {
+		return nil, fmt.Errorf("failed to expand config: %w", err)
+	}
+
+	// Resolve configuration
+	cfg, err := config.Resolve(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config: %w", err)
+	}
+
+	// Log post-expansion counts
+	slog.Info("configuration expanded",
+		"clocks", len(cfg.Instances.Clocks),
+		"sources", len(cfg.Instances.Sources),
+		"values", len(cfg.Instances.Values),
+		"metrics", len(cfg.Metrics))
+
+	return cfg, nil
+}
+
+// applySettingsOverrides applies settings given as command flags.
+// Must run before expansion, which depends on the seed and series limit.
+func applySettingsOverrides(cmd *cli.Command, raw *config.RawConfig) {
+	applyLoggingFlags(cmd, &raw.Settings.Logging)
+
+	if cmd.IsSet("seed") {
+		seed := cmd.Uint64("seed")
+		raw.Settings.Seed = &seed
+	}
+
+	if cmd.IsSet("duration") {
+		raw.Settings.RunDuration = cmd.Duration("duration")
+	}
+
+	if cmd.IsSet("shard") {
+		shard, _ := config.ParseShard(cmd.String("shard")) // Validated on parsing
+		raw.Settings.Shard = &shard
+	}
+
+	// Lift the series limit on request
+	if cmd.Bool("force") {
+		unlimited := 0
+		raw.Settings.MaxSeries = &unlimited
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"cmp"
+	"log/slog"
+	"os"
+
+	"github.com/neox5/otelbox/internal/app"
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/logging"
+)
+
+// runSignalAction performs the dump or debug action of a user signal.
+// Reload actions are handled by watchSignals.
+func runSignalAction(application *app.App, sig os.Signal, action config.SignalAction) {
+	switch action {
+	case config.SignalActionDump:
+		if err := dumpValues(application, application.Config.Settings.Signals.DumpFile); err != nil {
+			slog.Error("failed to dump values", "signal", sig, "error", err)
+		}
+	case config.SignalActionDebug:
+		// Logged at warn so the switch back is visible at any level
+		slog.Warn("debug logging toggled", "signal", sig, "enabled", logging.ToggleDebug())
+	case config.SignalActionNone:
+		slog.Debug("ignoring signal", "signal", sig)
+	}
+}
+
+// dumpValues writes the current values of all series to path, replacing a
+// previous dump, or to stderr without a path.
+func dumpValues(application *app.App, path string) error {
+	if path == "" {
+		if err := application.Dump(os.Stderr); err != nil {
+			return err
+		}
+	} else {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := application.Dump(file); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("dumped values", "series", len(application.Metrics.Metrics()), "output", cmp.Or(path, "stderr"))
+	return nil
+}
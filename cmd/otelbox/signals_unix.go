@@ -0,0 +1,18 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// userSignalActions maps SIGUSR1 and SIGUSR2 to their configured actions.
+func userSignalActions(cfg config.SignalsConfig) map[os.Signal]config.SignalAction {
+	return map[os.Signal]config.SignalAction{
+		syscall.SIGUSR1: cfg.USR1,
+		syscall.SIGUSR2: cfg.USR2,
+	}
+}
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// userSignalActions returns no actions: Windows has no user signals.
+func userSignalActions(config.SignalsConfig) map[os.Signal]config.SignalAction {
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/neox5/otelbox/internal/snapshot"
+	"github.com/urfave/cli/v3"
+)
+
+// snapshotCmd records the exported output of a deterministic run.
+// Logs go to stderr so the command output stays clean.
+func snapshotCmd(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	finishRecording, err := startRecordReplay(cmd)
+	if err != nil {
+		return err
+	}
+	defer finishRecording()
+
+	dir := cmd.String("out")
+	if err := snapshot.Write(dir, cfg, cmd.Int("ticks")); err != nil {
+		return err
+	}
+	if err := finishRecording(); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	slog.Info("snapshot written", "dir", dir, "ticks", cmd.Int("ticks"))
+	return nil
+}
+
+// verifySnapshot replays a recorded snapshot and compares the output.
+func verifySnapshot(ctx context.Context, cmd *cli.Command) error {
+	setupLogging(cmd, os.Stderr)
+
+	dir := cmd.String("snapshot")
+	manifest, err := snapshot.ReadManifest(dir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
+
+	// Expand with the recorded seed so random iterators match
+	raw, err := loadRawConfig(cmd)
+	if err != nil {
+		return err
+	}
+	raw.Settings.Seed = &manifest.Seed
+
+	cfg, err := resolveConfig(cmd, raw)
+	if err != nil {
+		return err
+	}
+
+	if err := snapshot.Verify(dir, cfg); err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
+
+	slog.Info("snapshot verified", "dir", dir)
+	return nil
+}
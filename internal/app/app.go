@@ -1,13 +1,32 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"reflect"
+	"sync/atomic"
+	"time"
 
 	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/exporter"
 	"github.com/neox5/otelbox/internal/generator"
 	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/monitor"
+	"github.com/neox5/otelbox/internal/receiver"
 	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/neox5/otelbox/internal/sizing"
+)
+
+// Component names for lifecycle dependencies
+const (
+	ComponentMonitor            = "monitor"
+	ComponentGenerator          = "generator"
+	ComponentPrometheusExporter = "prometheus-exporter"
+	ComponentOTELExporter       = "otel-exporter"
+	ComponentCustomExporter     = "custom-exporter"
+	ComponentDebugServer        = "debug-server"
+	ComponentReceiver           = "otlp-receiver"
 )
 
 // App holds initialized application components.
@@ -17,55 +36,401 @@ type App struct {
 	Metrics            *metric.Registry
 	PrometheusExporter *exporter.PrometheusExporter
 	OTELExporter       *exporter.OTELExporter
+	CustomExporter     exporter.CustomExporter
+	JobExporters       []JobExporters // Exporters of jobs with dedicated export
+	Monitor            *monitor.Monitor
+
+	logger    *slog.Logger
+	sim       *simulation.Context             // Seed and rng of all generated values
+	shared    atomic.Pointer[metric.Registry] // Metrics of the top-level exporters
+	lifecycle atomic.Pointer[Lifecycle]       // Latest lifecycle, replacing exporters on reload
 }
 
-// New initializes the application from configuration.
-// Seed must be initialized before calling this function.
-func New(cfg *config.Config) (*App, error) {
-	// Initialize seed before creating any simv objects
-	simulation.InitializeSeed(&cfg.Settings)
+// JobExporters holds the dedicated exporters of a job.
+type JobExporters struct {
+	Job        string
+	Export     config.ExportConfig
+	Prometheus *exporter.PrometheusExporter
+	OTEL       *exporter.OTELExporter
+}
+
+// New initializes the application from configuration. Application events
+// are logged to logger.
+func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
+	// Seed, rng, and generation mode belong to this app alone
+	sim, err := simulation.NewContext(&cfg.Settings, logger)
+	if err != nil {
+		return nil, err
+	}
+	simulation.ConfigureRecovery(cfg.Settings.Panics)
+	simulation.ConfigureRamp(cfg.Settings.Ramp)
+	simulation.ConfigureThrottle(cfg.Settings.Throttle)
+	simulation.ConfigureShedding(cfg.Settings.LoadShedding)
+
+	// Estimate resources before allocating series
+	if err := checkResources(cfg.Settings.ResourceCheck, sizing.EstimateConfig(cfg), sizing.Detect(), logger); err != nil {
+		return nil, err
+	}
 
 	// Create generator from metrics
-	gen, err := generator.New(cfg.Metrics)
+	gen, err := generator.New(sim, cfg.Metrics)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create generator: %w", err)
 	}
 
 	// Create metrics
-	metrics, err := metric.New(cfg, gen)
+	metrics, err := metric.New(cfg, sim, gen)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metrics: %w", err)
 	}
 
+	a := &App{
+		Config:    cfg,
+		Generator: gen,
+		Metrics:   metrics,
+		Monitor:   monitor.New(cfg.Settings.Monitor.Interval, logger),
+		logger:    logger,
+		sim:       sim,
+	}
+
+	// Shed generation load while the monitor reports saturation
+	if cfg.Settings.LoadShedding.Enabled {
+		a.Monitor.OnSample(func(s monitor.Snapshot) {
+			simulation.AdjustShedding(s.Utilization, s.Saturation == monitor.SaturationSaturated)
+		})
+	}
+
+	// Metrics of jobs with dedicated export are served only there
+	a.shared.Store(sharedMetrics(metrics, cfg.Jobs))
+	a.PrometheusExporter, a.OTELExporter, err = newExporters(cfg.Export, a.shared.Load(), sim, cfg.Settings, cfg.Chaos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Custom exporters read the current metrics across reloads
+	if cfg.Export.Custom != nil {
+		a.CustomExporter, err = exporter.NewCustomExporter(cfg.Export.Custom, a.shared.Load)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, job := range cfg.Jobs {
+		if !job.Dedicated() {
+			continue
+		}
+		prom, otel, err := newExporters(*job.Export, jobMetrics(metrics, job.Name), sim, cfg.Settings, cfg.Chaos)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		a.JobExporters = append(a.JobExporters, JobExporters{Job: job.Name, Export: *job.Export, Prometheus: prom, OTEL: otel})
+	}
+
+	return a, nil
+}
+
+// Lifecycle returns a lifecycle managing all application components.
+// Stop order: exporters, generator, monitor. With settings.drain, shutdown
+// first stops generation, then pushes and serves the final values.
+func (a *App) Lifecycle() *Lifecycle {
+	l := NewLifecycle(a.logger)
+	a.lifecycle.Store(l)
+	drain := a.Config.Settings.Drain
+
+	l.Add(Component{
+		Name: ComponentMonitor,
+		Run: func(ctx context.Context) error {
+			a.Monitor.Run(ctx)
+			a.Monitor.Wait()
+			return nil
+		},
+	})
+
+	l.Add(Component{
+		Name:      ComponentGenerator,
+		DependsOn: []string{ComponentMonitor},
+		Run: func(ctx context.Context) error {
+			a.Generator.Start()
+			<-ctx.Done()
+			a.Generator.Stop()
+			return nil
+		},
+		Drain: drainFunc(drain, func(context.Context) {
+			a.Generator.Stop()
+			a.logger.Info("stopped generation for drain", "series", len(a.Metrics.Metrics()))
+		}),
+	})
+
+	if a.PrometheusExporter != nil {
+		l.Add(prometheusComponent(ComponentPrometheusExporter, a.PrometheusExporter, a.Config.Export.Prometheus.Restart, drain))
+	}
+
+	if a.OTELExporter != nil {
+		l.Add(otelComponent(ComponentOTELExporter, a.OTELExporter, a.Config.Export.OTEL.Restart, drain))
+	}
+
+	if a.CustomExporter != nil {
+		l.Add(Component{
+			Name:      ComponentCustomExporter,
+			DependsOn: []string{ComponentGenerator},
+			Run:       exporter.WithRestart(ComponentCustomExporter, a.Config.Export.Custom.Restart, a.runCustomExporter),
+		})
+	}
+
+	if a.Config.Settings.Debug.Enabled {
+		l.Add(Component{
+			Name:      ComponentDebugServer,
+			DependsOn: []string{ComponentMonitor},
+			Run:       newDebugServer(a.Config.Settings.Debug.Port, a.logger).run,
+		})
+	}
+
+	// Without dependencies the receiver starts first and stops last, so it
+	// receives the final pushes of all exporters
+	if a.Config.Settings.Receiver.Enabled {
+		l.Add(Component{
+			Name: ComponentReceiver,
+			Run:  receiver.New(a.Config.Settings.Receiver).Run,
+		})
+	}
+
+	for _, job := range a.JobExporters {
+		if job.Prometheus != nil {
+			l.Add(prometheusComponent(ComponentPrometheusExporter+"/"+job.Job, job.Prometheus, job.Export.Prometheus.Restart, drain))
+		}
+		if job.OTEL != nil {
+			l.Add(otelComponent(ComponentOTELExporter+"/"+job.Job, job.OTEL, job.Export.OTEL.Restart, drain))
+		}
+	}
+
+	return l
+}
+
+// prometheusComponent runs a Prometheus exporter, serving the final values
+// until scraped when draining.
+func prometheusComponent(name string, e *exporter.PrometheusExporter, restart *config.RestartConfig, drain config.DrainConfig) Component {
+	return Component{
+		Name:      name,
+		DependsOn: []string{ComponentGenerator},
+		Run:       exporter.WithRestart(name, restart, e.Start),
+		Drain: drainFunc(drain, func(context.Context) {
+			e.Drain(drain.ScrapeWindow)
+		}),
+	}
+}
+
+// otelComponent runs an OTEL exporter, pushing the final values when
+// draining.
+func otelComponent(name string, e *exporter.OTELExporter, restart *config.RestartConfig, drain config.DrainConfig) Component {
+	return Component{
+		Name:      name,
+		DependsOn: []string{ComponentGenerator},
+		Run:       exporter.WithRestart(name, restart, e.Start),
+		Drain: drainFunc(drain, func(ctx context.Context) {
+			e.Drain(ctx, drain.Timeout)
+		}),
+	}
+}
+
+// drainFunc returns fn if draining is enabled, and nil otherwise.
+func drainFunc(drain config.DrainConfig, fn func(context.Context)) func(context.Context) {
+	if !drain.Enabled {
+		return nil
+	}
+	return fn
+}
+
+// Reload applies a new configuration to the running application.
+// Metrics, sources, and clocks are updated in place: unchanged metrics keep
+// their current values. Exporters with unchanged configuration keep
+// serving; changed, added, and removed exporters are replaced. Settings,
+// chaos, and custom exporter changes are not applied and require a
+// restart. On failure, the running configuration is kept.
+func (a *App) Reload(cfg *config.Config) error {
+	if !reflect.DeepEqual(a.Config.Settings, cfg.Settings) {
+		a.logger.Warn("settings changed, restart required to apply")
+	}
+	if !reflect.DeepEqual(a.Config.Chaos, cfg.Chaos) {
+		a.logger.Warn("chaos configuration changed, restart required to apply")
+	}
+	if !reflect.DeepEqual(a.Config.Export.Custom, cfg.Export.Custom) {
+		a.logger.Warn("custom exporter configuration changed, restart required to apply")
+	}
+
+	// Keep running settings, chaos, and custom exporter configuration
+	next := *cfg
+	next.Settings = a.Config.Settings
+	next.Chaos = a.Config.Chaos
+	next.Export.Custom = a.Config.Export.Custom
+
+	// Prepare generator components; the running generation is kept until
+	// the exported metrics are swapped
+	reload, err := a.Generator.PrepareReload(next.Metrics)
+	if err != nil {
+		return fmt.Errorf("failed to reload generator: %w", err)
+	}
+
+	// Build metrics from the prepared generation
+	metrics, err := metric.New(&next, a.sim, reload)
+	if err != nil {
+		reload.Abort()
+		return fmt.Errorf("failed to create metrics: %w", err)
+	}
+
+	// Create changed exporters, then swap the metrics of kept ones,
+	// restoring the current metrics on failure
+	shared := sharedMetrics(metrics, next.Jobs)
+	exporters, err := a.prepareExporters(&next, shared, metrics)
+	if err != nil {
+		reload.Abort()
+		return err
+	}
+	if err := exporters.update(); err != nil {
+		if restoreErr := a.updateExporters(a.shared.Load(), a.Metrics); restoreErr != nil {
+			a.logger.Error("failed to restore exported metrics", "error", restoreErr)
+		}
+		exporters.abort()
+		reload.Abort()
+		return err
+	}
+
+	reload.Commit()
+	exporters.apply(a.lifecycle.Load())
+	a.shared.Store(shared)
+	a.Config = &next
+	a.Metrics = metrics
+
+	return nil
+}
+
+// updateExporters swaps the metrics of all running exporters: shared
+// metrics for the top-level exporters, job metrics for dedicated ones.
+func (a *App) updateExporters(shared, metrics *metric.Registry) error {
+	if err := updateExporters(a.PrometheusExporter, a.OTELExporter, shared); err != nil {
+		return err
+	}
+	for _, job := range a.JobExporters {
+		if err := updateExporters(job.Prometheus, job.OTEL, jobMetrics(metrics, job.Job)); err != nil {
+			return fmt.Errorf("job %q: %w", job.Job, err)
+		}
+	}
+	return nil
+}
+
+// runCustomExporter runs the custom exporter until ctx is cancelled.
+func (a *App) runCustomExporter(ctx context.Context) error {
+	a.logger.Info("starting custom exporter",
+		"name", a.Config.Export.Custom.Name,
+		"exporter", a.CustomExporter.Describe())
+	if err := a.CustomExporter.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start custom exporter: %w", err)
+	}
+
+	<-ctx.Done()
+
+	a.logger.Info("shutting down custom exporter")
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return a.CustomExporter.Stop(stopCtx)
+}
+
+// newExporters creates the exporters enabled in export.
+func newExporters(
+	export config.ExportConfig,
+	metrics *metric.Registry,
+	sim *simulation.Context,
+	settings config.SettingsConfig,
+	chaos config.ChaosConfig,
+) (*exporter.PrometheusExporter, *exporter.OTELExporter, error) {
 	var promExporter *exporter.PrometheusExporter
 	var otelExporter *exporter.OTELExporter
 
 	// Create Prometheus exporter if enabled
-	if cfg.Export.Prometheus != nil && cfg.Export.Prometheus.Enabled {
+	if export.Prometheus != nil && export.Prometheus.Enabled {
 		promExporter = exporter.NewPrometheusExporter(
-			cfg.Export.Prometheus.Port,
-			cfg.Export.Prometheus.Path,
+			export.Prometheus,
 			metrics,
-			cfg.Settings.InternalMetrics.Enabled,
+			sim,
+			settings.InternalMetrics,
+			chaos,
 		)
 	}
 
 	// Create OTEL exporter if enabled
-	if cfg.Export.OTEL != nil && cfg.Export.OTEL.Enabled {
+	if export.OTEL != nil && export.OTEL.Enabled {
+		var err error
 		otelExporter, err = exporter.NewOTELExporter(
-			cfg.Export.OTEL,
+			export.OTEL,
 			metrics,
+			sim,
+			settings.InternalMetrics,
+			chaos,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create OTEL exporter: %w", err)
+			return nil, nil, fmt.Errorf("failed to create OTEL exporter: %w", err)
 		}
 	}
 
-	return &App{
-		Config:             cfg,
-		Generator:          gen,
-		Metrics:            metrics,
-		PrometheusExporter: promExporter,
-		OTELExporter:       otelExporter,
-	}, nil
+	return promExporter, otelExporter, nil
+}
+
+// updateExporters swaps the metrics of running exporters.
+func updateExporters(prom *exporter.PrometheusExporter, otel *exporter.OTELExporter, metrics *metric.Registry) error {
+	if prom != nil {
+		prom.Update(metrics)
+	}
+	if otel != nil {
+		if err := otel.Update(metrics); err != nil {
+			return fmt.Errorf("failed to update OTEL exporter: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkResources logs the estimated cost per component and compares the
+// total with the detected resources according to mode.
+func checkResources(mode config.ResourceCheckMode, estimate sizing.Estimate, resources sizing.Resources, logger *slog.Logger) error {
+	if mode == config.ResourceCheckOff {
+		return nil
+	}
+
+	for _, c := range estimate.Components {
+		logger.Info("estimated resource usage",
+			"component", c.Name,
+			"series", c.Series,
+			"memory", sizing.FormatBytes(c.Memory),
+			"cpu", fmt.Sprintf("%.3f", c.CPU))
+	}
+	logger.Info("estimated total resource usage",
+		"memory", sizing.FormatBytes(estimate.Memory()),
+		"cpu", fmt.Sprintf("%.3f", estimate.CPU()),
+		"available_memory", sizing.FormatBytes(resources.Memory),
+		"available_cpus", resources.CPUs)
+
+	err := sizing.Check(estimate, resources)
+	if err == nil {
+		return nil
+	}
+	if mode == config.ResourceCheckRefuse {
+		return fmt.Errorf("%w (settings.resource_check: refuse)", err)
+	}
+	logger.Warn("configuration may exceed available resources", "error", err)
+	return nil
+}
+
+// sharedMetrics returns the metrics served by the top-level exporters:
+// top-level metrics and those of jobs without dedicated export.
+func sharedMetrics(metrics *metric.Registry, jobs []config.JobConfig) *metric.Registry {
+	dedicated := make(map[string]bool)
+	for _, job := range jobs {
+		if job.Dedicated() {
+			dedicated[job.Name] = true
+		}
+	}
+	return metrics.Filter(func(d metric.Descriptor) bool { return !dedicated[d.Job] })
+}
+
+// jobMetrics returns the metrics of a job.
+func jobMetrics(metrics *metric.Registry, job string) *metric.Registry {
+	return metrics.Filter(func(d metric.Descriptor) bool { return d.Job == job })
 }
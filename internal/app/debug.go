@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/neox5/otelbox/internal/version"
+)
+
+// startTime is reported by the runtime dump.
+var startTime = time.Now()
+
+func init() {
+	expvar.Publish("runtime", expvar.Func(runtimeInfo))
+}
+
+// debugServer serves pprof profiles and expvar variables for profiling
+// otelbox itself.
+type debugServer struct {
+	addr   string
+	server *http.Server
+	logger *slog.Logger
+}
+
+// newDebugServer creates a debug server listening on port.
+func newDebugServer(port int, logger *slog.Logger) *debugServer {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	addr := fmt.Sprintf(":%d", port)
+	return &debugServer{
+		addr:   addr,
+		server: &http.Server{Addr: addr, Handler: mux},
+		logger: logger,
+	}
+}
+
+// run serves until ctx is cancelled, then shuts down gracefully.
+func (s *debugServer) run(ctx context.Context) error {
+	errChan := make(chan error, 1)
+
+	go func() {
+		s.logger.Info("starting debug server", "addr", s.addr)
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+		s.logger.Info("shutting down debug server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return s.server.Shutdown(shutdownCtx)
+	}
+}
+
+// runtimeInfo returns the runtime state published under "runtime".
+// Memory statistics are published by expvar itself under "memstats".
+func runtimeInfo() any {
+	return map[string]any{
+		"version":    version.String(),
+		"go_version": runtime.Version(),
+		"uptime":     time.Since(startTime).Round(time.Second).String(),
+		"goroutines": runtime.NumGoroutine(),
+		"gomaxprocs": runtime.GOMAXPROCS(0),
+		"num_cpu":    runtime.NumCPU(),
+		"cgo_calls":  runtime.NumCgoCall(),
+	}
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// labelEscaper escapes label values as in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Dump writes the current value of every series in the Prometheus text
+// format. Values are read without consuming reset_on_read deltas, so dumps
+// do not change what exporters observe.
+func (a *App) Dump(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	metrics := a.Metrics.Metrics()
+	fmt.Fprintf(buf, "# otelbox dump at %s: %d series\n", time.Now().Format(time.RFC3339), len(metrics))
+
+	for _, m := range metrics {
+		var value int
+		if !m.Guard.Do("dump", func() { value = m.Value.State() }) {
+			continue
+		}
+
+		buf.WriteString(m.PrometheusName)
+		if len(m.Attributes) > 0 {
+			buf.WriteByte('{')
+			for i, key := range slices.Sorted(maps.Keys(m.Attributes)) {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				fmt.Fprintf(buf, `%s="%s"`, key, labelEscaper.Replace(m.Attributes[key]))
+			}
+			buf.WriteByte('}')
+		}
+		fmt.Fprintf(buf, " %d\n", value)
+	}
+
+	return buf.Flush()
+}
@@ -0,0 +1,319 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultStopTimeout bounds how long a component may take to stop.
+const DefaultStopTimeout = 10 * time.Second
+
+// Component is a long-running part of the application.
+type Component struct {
+	Name        string
+	DependsOn   []string                        // Components that must start before and stop after this one
+	Run         func(ctx context.Context) error // Blocks until ctx is cancelled
+	Drain       func(ctx context.Context)       // Runs on graceful shutdown before any component stops (nil: none)
+	StopTimeout time.Duration                   // Default: DefaultStopTimeout
+}
+
+// Lifecycle starts components in dependency order and stops them in
+// reverse order, stage by stage. While running, components can be started
+// and stopped individually, e.g. to replace an exporter on reload.
+type Lifecycle struct {
+	components []Component
+	logger     *slog.Logger
+
+	mu       sync.Mutex
+	ctx      context.Context // Parent of component contexts (nil: not running)
+	failures chan error
+	stage    map[string]int // Stage of each running component
+	started  [][]*running
+}
+
+// errNotRunning is returned when starting or stopping a component while
+// the lifecycle does not run.
+var errNotRunning = errors.New("lifecycle not running")
+
+// NewLifecycle creates an empty lifecycle logging to logger.
+func NewLifecycle(logger *slog.Logger) *Lifecycle {
+	return &Lifecycle{logger: logger}
+}
+
+// Add registers a component.
+func (l *Lifecycle) Add(c Component) {
+	l.components = append(l.components, c)
+}
+
+// running tracks a started component.
+type running struct {
+	component Component
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// Run starts all components and blocks until ctx is cancelled or a
+// component fails, then stops all components. On cancellation, components
+// are drained in start order first, stage by stage.
+// Components in the same stage (dependency depth) stop concurrently; each
+// stage waits for its slowest component up to its stop timeout.
+// Returns the first component failure.
+func (l *Lifecycle) Run(ctx context.Context) error {
+	stages, err := l.stages()
+	if err != nil {
+		return err
+	}
+
+	// Start stages in dependency order
+	l.mu.Lock()
+	l.ctx = ctx
+	l.failures = make(chan error, 1)
+	l.stage = make(map[string]int, len(l.components))
+	l.started = make([][]*running, len(stages))
+	for i, stage := range stages {
+		for _, c := range stage {
+			l.start(i, c)
+		}
+	}
+	failures := l.failures
+	l.mu.Unlock()
+
+	// Wait for shutdown or failure
+	var failure error
+	select {
+	case <-ctx.Done():
+	case failure = <-failures:
+		l.logger.Error("component failed", "error", failure)
+	}
+
+	l.logger.Info("shutting down")
+
+	// Stop accepting component changes
+	l.mu.Lock()
+	started := l.started
+	l.ctx = nil
+	l.mu.Unlock()
+
+	// Drain while all components still run; a failed component may not drain
+	if failure == nil {
+		l.drainStages(context.WithoutCancel(ctx), started)
+	}
+
+	// Stop stages in reverse dependency order
+	for i := len(started) - 1; i >= 0; i-- {
+		l.stopStage(started[i])
+	}
+
+	return failure
+}
+
+// Start starts a component while the lifecycle runs. It joins the stage
+// after its dependencies, which must be running, and stops with that
+// stage on shutdown. Fails on a nil or stopped lifecycle.
+func (l *Lifecycle) Start(c Component) error {
+	if c.Name == "" {
+		return fmt.Errorf("component name cannot be empty")
+	}
+	if c.Run == nil {
+		return fmt.Errorf("component %q: run function required", c.Name)
+	}
+	if c.StopTimeout == 0 {
+		c.StopTimeout = DefaultStopTimeout
+	}
+
+	if l == nil {
+		return errNotRunning
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ctx == nil {
+		return errNotRunning
+	}
+	if _, exists := l.stage[c.Name]; exists {
+		return fmt.Errorf("component %q already running", c.Name)
+	}
+	stage := 0
+	for _, dep := range c.DependsOn {
+		depStage, exists := l.stage[dep]
+		if !exists {
+			return fmt.Errorf("component %q: dependency %q not running", c.Name, dep)
+		}
+		stage = max(stage, depStage+1)
+	}
+	for len(l.started) <= stage {
+		l.started = append(l.started, nil)
+	}
+
+	l.start(stage, c)
+	return nil
+}
+
+// Stop stops a running component without draining it and waits for it to
+// return up to its stop timeout.
+func (l *Lifecycle) Stop(name string) error {
+	if l == nil {
+		return errNotRunning
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.ctx == nil {
+		return errNotRunning
+	}
+	stage, exists := l.stage[name]
+	if !exists {
+		return fmt.Errorf("component %q not running", name)
+	}
+
+	i := slices.IndexFunc(l.started[stage], func(r *running) bool { return r.component.Name == name })
+	r := l.started[stage][i]
+	l.started[stage] = slices.Delete(l.started[stage], i, i+1)
+	delete(l.stage, name)
+
+	l.stopStage([]*running{r})
+	return nil
+}
+
+// start runs c in stage. Must be called with l.mu held while running.
+func (l *Lifecycle) start(stage int, c Component) {
+	// Detach from parent cancellation so stop order stays controlled
+	componentCtx, cancel := context.WithCancel(context.WithoutCancel(l.ctx))
+	r := &running{component: c, cancel: cancel, done: make(chan struct{})}
+
+	failures := l.failures
+	go func() {
+		defer close(r.done)
+		err := c.Run(componentCtx)
+		switch {
+		case err == nil:
+		case componentCtx.Err() == nil:
+			// Only the first failure is reported
+			select {
+			case failures <- fmt.Errorf("%s: %w", c.Name, err):
+			default:
+			}
+		default:
+			l.logger.Warn("component stopped with error", "component", c.Name, "error", err)
+		}
+	}()
+
+	l.started[stage] = append(l.started[stage], r)
+	l.stage[c.Name] = stage
+	l.logger.Debug("started component", "component", c.Name, "stage", stage)
+}
+
+// drainStages runs the drain functions of all stages in start order.
+// Components of a stage drain concurrently.
+func (l *Lifecycle) drainStages(ctx context.Context, started [][]*running) {
+	start := time.Now()
+	drained := false
+	for _, stage := range started {
+		var wg sync.WaitGroup
+		for _, r := range stage {
+			if r.component.Drain == nil {
+				continue
+			}
+			drained = true
+			wg.Go(func() { r.component.Drain(ctx) })
+		}
+		wg.Wait()
+	}
+	if drained {
+		l.logger.Info("drain complete", "duration", time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// stopStage cancels all components of a stage and waits for them to return.
+func (l *Lifecycle) stopStage(stage []*running) {
+	timeout := time.Duration(0)
+	for _, r := range stage {
+		r.cancel()
+		timeout = max(timeout, r.component.StopTimeout)
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for _, r := range stage {
+		select {
+		case <-r.done:
+			l.logger.Debug("stopped component", "component", r.component.Name)
+		case <-deadline.C:
+			l.logger.Warn("component stop timed out", "component", r.component.Name, "timeout", timeout)
+		}
+	}
+}
+
+// stages groups components by dependency depth.
+// Stage 0 has no dependencies; stage n depends on components in earlier stages.
+func (l *Lifecycle) stages() ([][]Component, error) {
+	byName := make(map[string]Component, len(l.components))
+	for i, c := range l.components {
+		if c.Name == "" {
+			return nil, fmt.Errorf("component %d: name cannot be empty", i)
+		}
+		if _, exists := byName[c.Name]; exists {
+			return nil, fmt.Errorf("duplicate component %q", c.Name)
+		}
+		if c.Run == nil {
+			return nil, fmt.Errorf("component %q: run function required", c.Name)
+		}
+		if c.StopTimeout == 0 {
+			c.StopTimeout = DefaultStopTimeout
+			l.components[i] = c
+		}
+		byName[c.Name] = c
+	}
+
+	depth := make(map[string]int, len(l.components))
+	visiting := make(map[string]bool)
+
+	var visit func(name string) (int, error)
+	visit = func(name string) (int, error) {
+		if d, ok := depth[name]; ok {
+			return d, nil
+		}
+		if visiting[name] {
+			return 0, fmt.Errorf("component dependency cycle at %q", name)
+		}
+		visiting[name] = true
+		defer delete(visiting, name)
+
+		d := 0
+		for _, dep := range byName[name].DependsOn {
+			if _, exists := byName[dep]; !exists {
+				return 0, fmt.Errorf("component %q: unknown dependency %q", name, dep)
+			}
+			depDepth, err := visit(dep)
+			if err != nil {
+				return 0, err
+			}
+			d = max(d, depDepth+1)
+		}
+		depth[name] = d
+		return d, nil
+	}
+
+	var stages [][]Component
+	for _, c := range l.components {
+		d, err := visit(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		for len(stages) <= d {
+			stages = append(stages, nil)
+		}
+	}
+	// Keep registration order within each stage
+	for _, c := range l.components {
+		stages[depth[c.Name]] = append(stages[depth[c.Name]], c)
+	}
+
+	return stages, nil
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder records component starts and stops in order.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.events)
+}
+
+// component returns a component recording its start and stop.
+func (r *recorder) component(name string, started chan<- struct{}, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Run: func(ctx context.Context) error {
+			r.add("start " + name)
+			if started != nil {
+				started <- struct{}{}
+			}
+			<-ctx.Done()
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycleStartStopWhileRunning(t *testing.T) {
+	rec := &recorder{}
+	l := NewLifecycle(slog.New(slog.DiscardHandler))
+	started := make(chan struct{}, 3)
+	l.Add(rec.component("generator", started))
+	l.Add(rec.component("exporter", started, "generator"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- l.Run(ctx) }()
+	<-started
+	<-started
+
+	// Replace the exporter: the previous one stops before the next starts
+	if err := l.Stop("exporter"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Start(rec.component("exporter", started, "generator")); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	if err := l.Start(rec.component("exporter", nil)); err == nil {
+		t.Error("started a component twice")
+	}
+	if err := l.Start(rec.component("other", nil, "missing")); err == nil {
+		t.Error("started a component with a missing dependency")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Initial components start concurrently; the started exporter stops
+	// with its stage, before the generator
+	got := rec.get()
+	if len(got) > 2 {
+		slices.Sort(got[:2])
+	}
+	want := []string{"start exporter", "start generator", "stop exporter", "start exporter", "stop exporter", "stop generator"}
+	if !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+
+	if err := l.Start(rec.component("late", nil)); !errors.Is(err, errNotRunning) {
+		t.Errorf("start after shutdown = %v, want %v", err, errNotRunning)
+	}
+}
+
+func TestLifecycleStartedComponentFailure(t *testing.T) {
+	l := NewLifecycle(slog.New(slog.DiscardHandler))
+	started := make(chan struct{}, 1)
+	l.Add((&recorder{}).component("generator", started))
+
+	done := make(chan error, 1)
+	go func() { done <- l.Run(context.Background()) }()
+	<-started
+
+	failure := errors.New("bind failed")
+	if err := l.Start(Component{
+		Name: "exporter",
+		Run:  func(context.Context) error { return failure },
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, failure) {
+			t.Errorf("run = %v, want %v", err, failure)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failure of a started component did not stop the lifecycle")
+	}
+}
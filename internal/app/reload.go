@@ -0,0 +1,212 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/exporter"
+	"github.com/neox5/otelbox/internal/metric"
+)
+
+// exporterReload is a prepared update of the exporters to a reloaded
+// configuration. Exporters with unchanged configuration keep running and
+// receive the new metrics. Changed, added, and removed exporters are
+// replaced through the lifecycle: the previous exporter stops before the
+// new one starts, so it can bind the same endpoint.
+type exporterReload struct {
+	app        *App
+	prometheus *exporter.PrometheusExporter
+	otel       *exporter.OTELExporter
+	jobs       []JobExporters
+
+	kept    []func() error           // Metric updates of kept exporters
+	created []*exporter.OTELExporter // New OTEL exporters, closed on abort
+	stop    []replacedExporter       // Replaced and removed exporters
+	inherit []func()                 // Run after stopping, before starting
+	start   []Component              // Components of new exporters
+}
+
+// replacedExporter is a running exporter that is replaced or removed.
+type replacedExporter struct {
+	component string
+	otel      *exporter.OTELExporter // Closed if it never started (nil: Prometheus)
+}
+
+// prepareExporters prepares the exporters of next, serving shared metrics
+// from the top-level exporters and job metrics from dedicated ones.
+// Running exporters are not changed.
+func (a *App) prepareExporters(next *config.Config, shared, metrics *metric.Registry) (*exporterReload, error) {
+	r := &exporterReload{app: a}
+
+	var err error
+	r.prometheus = r.preparePrometheus(ComponentPrometheusExporter, a.PrometheusExporter, a.Config.Export.Prometheus, next.Export.Prometheus, shared)
+	r.otel, err = r.prepareOTEL(ComponentOTELExporter, a.OTELExporter, a.Config.Export.OTEL, next.Export.OTEL, shared)
+	if err != nil {
+		r.abort()
+		return nil, err
+	}
+
+	previous := make(map[string]JobExporters, len(a.JobExporters))
+	for _, job := range a.JobExporters {
+		previous[job.Job] = job
+	}
+	for _, job := range next.Jobs {
+		if !job.Dedicated() {
+			continue
+		}
+		prev := previous[job.Name] // Zero value for newly dedicated jobs
+		delete(previous, job.Name)
+
+		j := JobExporters{Job: job.Name, Export: *job.Export}
+		jm := jobMetrics(metrics, job.Name)
+		j.Prometheus = r.preparePrometheus(ComponentPrometheusExporter+"/"+job.Name, prev.Prometheus, prev.Export.Prometheus, job.Export.Prometheus, jm)
+		j.OTEL, err = r.prepareOTEL(ComponentOTELExporter+"/"+job.Name, prev.OTEL, prev.Export.OTEL, job.Export.OTEL, jm)
+		if err != nil {
+			r.abort()
+			return nil, fmt.Errorf("job %q: %w", job.Name, err)
+		}
+		r.jobs = append(r.jobs, j)
+	}
+
+	// Jobs removed or no longer exported on their own
+	for _, job := range a.JobExporters {
+		if _, removed := previous[job.Job]; !removed {
+			continue
+		}
+		if job.Prometheus != nil {
+			r.stop = append(r.stop, replacedExporter{component: ComponentPrometheusExporter + "/" + job.Job})
+		}
+		if job.OTEL != nil {
+			r.stop = append(r.stop, replacedExporter{component: ComponentOTELExporter + "/" + job.Job, otel: job.OTEL})
+		}
+	}
+
+	return r, nil
+}
+
+// preparePrometheus keeps the running exporter prev if its configuration
+// is unchanged, and creates its replacement otherwise.
+func (r *exporterReload) preparePrometheus(
+	name string,
+	prev *exporter.PrometheusExporter,
+	prevCfg, cfg *config.PrometheusExportConfig,
+	metrics *metric.Registry,
+) *exporter.PrometheusExporter {
+	if prev != nil && reflect.DeepEqual(prevCfg, cfg) {
+		r.kept = append(r.kept, func() error {
+			prev.Update(metrics)
+			return nil
+		})
+		return prev
+	}
+	if prev != nil {
+		r.stop = append(r.stop, replacedExporter{component: name})
+	}
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+
+	settings := r.app.Config.Settings
+	e := exporter.NewPrometheusExporter(cfg, metrics, r.app.sim, settings.InternalMetrics, r.app.Config.Chaos)
+	if prev != nil {
+		r.inherit = append(r.inherit, func() { e.Inherit(prev) })
+	}
+	r.start = append(r.start, prometheusComponent(name, e, cfg.Restart, settings.Drain))
+	return e
+}
+
+// prepareOTEL keeps the running exporter prev if its configuration is
+// unchanged, and creates its replacement otherwise.
+func (r *exporterReload) prepareOTEL(
+	name string,
+	prev *exporter.OTELExporter,
+	prevCfg, cfg *config.OTELExportConfig,
+	metrics *metric.Registry,
+) (*exporter.OTELExporter, error) {
+	if prev != nil && reflect.DeepEqual(prevCfg, cfg) {
+		r.kept = append(r.kept, func() error {
+			if err := prev.Update(metrics); err != nil {
+				return fmt.Errorf("failed to update OTEL exporter: %w", err)
+			}
+			return nil
+		})
+		return prev, nil
+	}
+	if prev != nil {
+		r.stop = append(r.stop, replacedExporter{component: name, otel: prev})
+	}
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	settings := r.app.Config.Settings
+	e, err := exporter.NewOTELExporter(cfg, metrics, r.app.sim, settings.InternalMetrics, r.app.Config.Chaos)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTEL exporter: %w", err)
+	}
+	r.created = append(r.created, e)
+	r.start = append(r.start, otelComponent(name, e, cfg.Restart, settings.Drain))
+	return e, nil
+}
+
+// update passes the new metrics to the kept exporters.
+func (r *exporterReload) update() error {
+	for _, update := range r.kept {
+		if err := update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// abort closes the exporters created for the reload.
+func (r *exporterReload) abort() {
+	closeExporters(r.created, r.app)
+}
+
+// apply stops replaced and removed exporters and starts new ones. Without
+// a running lifecycle, the new exporters start with the next one.
+func (r *exporterReload) apply(l *Lifecycle) {
+	var unstarted []*exporter.OTELExporter
+	for _, replaced := range r.stop {
+		err := l.Stop(replaced.component)
+		switch {
+		case err == nil:
+		case errors.Is(err, errNotRunning):
+			if replaced.otel != nil {
+				unstarted = append(unstarted, replaced.otel)
+			}
+		default:
+			r.app.logger.Error("failed to stop exporter", "component", replaced.component, "error", err)
+		}
+	}
+	closeExporters(unstarted, r.app)
+
+	for _, inherit := range r.inherit {
+		inherit()
+	}
+	for _, c := range r.start {
+		if err := l.Start(c); err != nil && !errors.Is(err, errNotRunning) {
+			r.app.logger.Error("failed to start exporter", "component", c.Name, "error", err)
+		}
+	}
+
+	r.app.PrometheusExporter = r.prometheus
+	r.app.OTELExporter = r.otel
+	r.app.JobExporters = r.jobs
+}
+
+// closeExporters shuts down OTEL exporters that were not started.
+func closeExporters(exporters []*exporter.OTELExporter, a *App) {
+	for _, e := range exporters {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := e.Close(ctx); err != nil {
+			a.logger.Warn("failed to close otel exporter", "error", err)
+		}
+		cancel()
+	}
+}
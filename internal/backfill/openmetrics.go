@@ -0,0 +1,156 @@
+package backfill
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/metric"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// sample is a timestamped series value.
+type sample struct {
+	ts    int64 // Unix milliseconds
+	value float64
+}
+
+// omSeries holds the samples of one series.
+type omSeries struct {
+	labels  []*dto.LabelPair
+	samples []sample
+}
+
+// omFamily holds the series of one metric family in first-seen order.
+type omFamily struct {
+	name   string
+	help   string
+	typ    dto.MetricType
+	series map[string]*omSeries
+	order  []string
+}
+
+// OpenMetricsWriter writes samples as an OpenMetrics text file, e.g. for
+// `promtool tsdb create-blocks-from openmetrics`. Families must not
+// interleave in OpenMetrics, so samples are held in memory and written on
+// Close.
+type OpenMetricsWriter struct {
+	out         io.Writer
+	constLabels map[string]string
+
+	families map[string]*omFamily
+	order    []string
+}
+
+// NewOpenMetricsWriter creates a writer to out. constLabels are added to
+// every series, like the Prometheus exporter does.
+func NewOpenMetricsWriter(out io.Writer, constLabels map[string]string) *OpenMetricsWriter {
+	return &OpenMetricsWriter{
+		out:         out,
+		constLabels: constLabels,
+		families:    make(map[string]*omFamily),
+	}
+}
+
+// Write records the points of one tick.
+func (w *OpenMetricsWriter) Write(ts time.Time, points []Point) error {
+	for _, p := range points {
+		if !p.Metric.ExportedTo(config.ExportProtocolPrometheus) {
+			continue
+		}
+		f := w.family(p.Metric)
+		key := seriesKey(p.Metric.Attributes)
+		s, exists := f.series[key]
+		if !exists {
+			s = &omSeries{labels: w.labelPairs(p.Metric.Attributes)}
+			f.series[key] = s
+			f.order = append(f.order, key)
+		}
+		s.samples = append(s.samples, sample{ts: ts.UnixMilli(), value: p.Value})
+	}
+	return nil
+}
+
+// Close writes all families and the terminating EOF marker.
+func (w *OpenMetricsWriter) Close() error {
+	for _, name := range w.order {
+		f := w.families[name]
+		family := &dto.MetricFamily{
+			Name: proto.String(f.name),
+			Help: proto.String(f.help),
+			Type: f.typ.Enum(),
+		}
+		for _, key := range f.order {
+			s := f.series[key]
+			for _, smp := range s.samples {
+				m := &dto.Metric{Label: s.labels, TimestampMs: proto.Int64(smp.ts)}
+				if f.typ == dto.MetricType_COUNTER {
+					m.Counter = &dto.Counter{Value: proto.Float64(smp.value)}
+				} else {
+					m.Gauge = &dto.Gauge{Value: proto.Float64(smp.value)}
+				}
+				family.Metric = append(family.Metric, m)
+			}
+		}
+		if _, err := expfmt.MetricFamilyToOpenMetrics(w.out, family); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", f.name, err)
+		}
+	}
+	if _, err := expfmt.FinalizeOpenMetrics(w.out); err != nil {
+		return fmt.Errorf("failed to finalize openmetrics: %w", err)
+	}
+	return nil
+}
+
+// family returns the family of d, creating it on first use.
+func (w *OpenMetricsWriter) family(d metric.Descriptor) *omFamily {
+	f, exists := w.families[d.PrometheusName]
+	if !exists {
+		typ := dto.MetricType_GAUGE
+		if d.Type == metric.MetricTypeCounter {
+			typ = dto.MetricType_COUNTER
+		}
+		f = &omFamily{
+			name:   d.PrometheusName,
+			help:   d.Description,
+			typ:    typ,
+			series: make(map[string]*omSeries),
+		}
+		w.families[d.PrometheusName] = f
+		w.order = append(w.order, d.PrometheusName)
+	}
+	return f
+}
+
+// labelPairs returns the sorted labels of a series with const labels.
+func (w *OpenMetricsWriter) labelPairs(attributes map[string]string) []*dto.LabelPair {
+	labels := maps.Clone(attributes)
+	if labels == nil {
+		labels = make(map[string]string, len(w.constLabels))
+	}
+	maps.Copy(labels, w.constLabels)
+
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, &dto.LabelPair{Name: proto.String(name), Value: proto.String(labels[name])})
+	}
+	return pairs
+}
+
+// seriesKey identifies a series of a family by its attributes.
+func seriesKey(attributes map[string]string) string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		b.WriteString(name)
+		b.WriteByte(0)
+		b.WriteString(attributes[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package backfill
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/metric"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// OTLPWriter writes samples as OTLP JSON lines, one export request per
+// tick, as read by the collector's otlpjsonfile receiver. Counters are
+// cumulative sums starting at the start of the range.
+type OTLPWriter struct {
+	out      *bufio.Writer
+	start    time.Time
+	resource *resourcepb.Resource
+}
+
+// NewOTLPWriter creates a writer to out with the given resource attributes.
+func NewOTLPWriter(out io.Writer, start time.Time, resource map[string]string) *OTLPWriter {
+	return &OTLPWriter{
+		out:      bufio.NewWriter(out),
+		start:    start,
+		resource: &resourcepb.Resource{Attributes: keyValues(resource)},
+	}
+}
+
+// Write writes the points of one tick as one export request line.
+func (w *OTLPWriter) Write(ts time.Time, points []Point) error {
+	var metrics []*metricspb.Metric
+	byName := make(map[string]*metricspb.Metric)
+	for _, p := range points {
+		if !p.Metric.ExportedTo(config.ExportProtocolOTEL) {
+			continue
+		}
+		m, exists := byName[p.Metric.OTELName]
+		if !exists {
+			m = newOTLPMetric(p.Metric)
+			byName[p.Metric.OTELName] = m
+			metrics = append(metrics, m)
+		}
+
+		dp := &metricspb.NumberDataPoint{
+			Attributes:   keyValues(p.Metric.Attributes),
+			TimeUnixNano: uint64(ts.UnixNano()),
+			Value:        &metricspb.NumberDataPoint_AsInt{AsInt: int64(p.Value)},
+		}
+		if math.IsNaN(p.Value) || math.IsInf(p.Value, 0) {
+			dp.Value = &metricspb.NumberDataPoint_AsDouble{AsDouble: p.Value}
+		}
+		switch data := m.Data.(type) {
+		case *metricspb.Metric_Sum:
+			dp.StartTimeUnixNano = uint64(w.start.UnixNano())
+			data.Sum.DataPoints = append(data.Sum.DataPoints, dp)
+		case *metricspb.Metric_Gauge:
+			data.Gauge.DataPoints = append(data.Gauge.DataPoints, dp)
+		}
+	}
+
+	request := &collectorpb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: w.resource,
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: "otelbox"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+
+	// OTLP JSON encodes enums as integers
+	line, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode otlp: %w", err)
+	}
+	if _, err := w.out.Write(line); err != nil {
+		return err
+	}
+	return w.out.WriteByte('\n')
+}
+
+// Close flushes buffered output.
+func (w *OTLPWriter) Close() error {
+	return w.out.Flush()
+}
+
+// newOTLPMetric creates an empty metric for descriptor d.
+func newOTLPMetric(d metric.Descriptor) *metricspb.Metric {
+	m := &metricspb.Metric{Name: d.OTELName, Description: d.Description, Unit: d.Unit}
+	if d.Type == metric.MetricTypeCounter {
+		m.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	} else {
+		m.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+	}
+	return m
+}
+
+// keyValues converts attributes to sorted OTLP key values.
+func keyValues(attributes map[string]string) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   key,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: attributes[key]}},
+		})
+	}
+	return kvs
+}
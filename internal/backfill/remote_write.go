@@ -0,0 +1,168 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/neox5/otelbox/internal/config"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// remoteWriteBatch is the number of samples sent per request
+	remoteWriteBatch = 10000
+
+	// remoteWriteTimeout bounds a single remote write request
+	remoteWriteTimeout = 30 * time.Second
+)
+
+// rwSeries holds the pending samples of one series.
+type rwSeries struct {
+	labels  [][2]string // Sorted name/value pairs including __name__
+	samples []sample
+}
+
+// RemoteWriter sends samples to a Prometheus remote write 1.0 endpoint.
+// Samples are batched; each request carries the pending samples of every
+// series in time order.
+type RemoteWriter struct {
+	url         string
+	client      *http.Client
+	constLabels map[string]string
+
+	series  map[string]*rwSeries
+	order   []string
+	pending int
+}
+
+// NewRemoteWriter creates a writer sending to url. constLabels are added to
+// every series, like the Prometheus exporter does.
+func NewRemoteWriter(url string, constLabels map[string]string) *RemoteWriter {
+	return &RemoteWriter{
+		url:         url,
+		client:      &http.Client{Timeout: remoteWriteTimeout},
+		constLabels: constLabels,
+		series:      make(map[string]*rwSeries),
+	}
+}
+
+// Write queues the points of one tick and sends full batches.
+func (w *RemoteWriter) Write(ts time.Time, points []Point) error {
+	for _, p := range points {
+		if !p.Metric.ExportedTo(config.ExportProtocolPrometheus) {
+			continue
+		}
+		key := p.Metric.PrometheusName + "\x00" + seriesKey(p.Metric.Attributes)
+		s, exists := w.series[key]
+		if !exists {
+			s = &rwSeries{labels: w.labels(p.Metric.PrometheusName, p.Metric.Attributes)}
+			w.series[key] = s
+			w.order = append(w.order, key)
+		}
+		s.samples = append(s.samples, sample{ts: ts.UnixMilli(), value: p.Value})
+		w.pending++
+	}
+
+	if w.pending >= remoteWriteBatch {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close sends the remaining samples.
+func (w *RemoteWriter) Close() error {
+	return w.flush()
+}
+
+// flush sends all pending samples in one request.
+func (w *RemoteWriter) flush() error {
+	if w.pending == 0 {
+		return nil
+	}
+
+	var body []byte
+	for _, key := range w.order {
+		s := w.series[key]
+		if len(s.samples) == 0 {
+			continue
+		}
+		body = protowire.AppendTag(body, 1, protowire.BytesType) // WriteRequest.timeseries
+		body = protowire.AppendBytes(body, encodeTimeSeries(s))
+		s.samples = s.samples[:0]
+	}
+	w.pending = 0
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteWriteTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(s2.EncodeSnappy(nil, body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote write failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote write failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// labels returns the sorted labels of a series with metric name and const
+// labels.
+func (w *RemoteWriter) labels(name string, attributes map[string]string) [][2]string {
+	all := maps.Clone(attributes)
+	if all == nil {
+		all = make(map[string]string, len(w.constLabels)+1)
+	}
+	maps.Copy(all, w.constLabels)
+	all["__name__"] = name
+
+	labels := make([][2]string, 0, len(all))
+	for _, key := range slices.Sorted(maps.Keys(all)) {
+		labels = append(labels, [2]string{key, all[key]})
+	}
+	return labels
+}
+
+// encodeTimeSeries encodes a prometheus.TimeSeries message.
+func encodeTimeSeries(s *rwSeries) []byte {
+	var b []byte
+	for _, l := range s.labels {
+		var label []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType) // Label.name
+		label = protowire.AppendString(label, l[0])
+		label = protowire.AppendTag(label, 2, protowire.BytesType) // Label.value
+		label = protowire.AppendString(label, l[1])
+
+		b = protowire.AppendTag(b, 1, protowire.BytesType) // TimeSeries.labels
+		b = protowire.AppendBytes(b, label)
+	}
+	for _, smp := range s.samples {
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type) // Sample.value
+		sample = protowire.AppendFixed64(sample, math.Float64bits(smp.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType) // Sample.timestamp
+		sample = protowire.AppendVarint(sample, uint64(smp.ts))
+
+		b = protowire.AppendTag(b, 2, protowire.BytesType) // TimeSeries.samples
+		b = protowire.AppendBytes(b, sample)
+	}
+	return b
+}
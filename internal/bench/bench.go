@@ -0,0 +1,200 @@
+// Package bench measures the cost of generating series on the host. The
+// built-in workload runs in virtual time at increasing series counts, read
+// by a null exporter that discards every sample.
+package bench
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/neox5/otelbox/internal/virtual"
+)
+
+// consumer isolates benchmark reads of reset_on_read values
+const consumer = "bench"
+
+// Interval is the update interval of the benchmark workload. A series
+// count is sustainable while one tick takes less than an interval.
+const Interval = config.DefaultBuiltinInterval
+
+const (
+	DefaultStartSeries = 1000
+	DefaultMaxSeries   = 1_000_000
+	DefaultTicks       = 10
+)
+
+// Options controls a benchmark.
+type Options struct {
+	StartSeries int // Series count of the first step
+	MaxSeries   int // Series count of the last step
+	Ticks       int // Ticks measured per step
+	Seed        uint64
+}
+
+// Result is the cost measured at one series count.
+type Result struct {
+	Series       int
+	TickTime     time.Duration // Wall time to generate and read one tick
+	CPUPerSeries time.Duration // CPU time per series and tick
+	MemPerSeries uint64        // Heap bytes per series
+}
+
+// Load returns the fraction of the update interval spent per tick.
+func (r Result) Load() float64 {
+	return float64(r.TickTime) / float64(Interval)
+}
+
+// Sustainable reports whether ticks complete within the update interval.
+func (r Result) Sustainable() bool {
+	return r.TickTime < Interval
+}
+
+// Run measures series counts doubling from opts.StartSeries until
+// opts.MaxSeries or until ticks no longer complete within the interval.
+// Each result is passed to report as soon as it is measured.
+func Run(opts Options, report func(Result)) ([]Result, error) {
+	if opts.StartSeries < 2 || opts.MaxSeries < opts.StartSeries {
+		return nil, fmt.Errorf("invalid series range %d to %d", opts.StartSeries, opts.MaxSeries)
+	}
+	if opts.Ticks <= 0 {
+		return nil, fmt.Errorf("ticks must be positive, got %d", opts.Ticks)
+	}
+
+	var results []Result
+	var sim *simulation.Context
+	for series := opts.StartSeries; series <= opts.MaxSeries; series *= 2 {
+		cfg, err := workload(series, opts.Seed)
+		if err != nil {
+			return results, fmt.Errorf("%d series: %w", series, err)
+		}
+		if sim == nil {
+			if sim, err = virtual.Initialize(cfg.Settings); err != nil {
+				return nil, err
+			}
+		}
+
+		result, err := measure(sim, cfg, series, opts.Ticks)
+		if err != nil {
+			return results, fmt.Errorf("%d series: %w", series, err)
+		}
+		results = append(results, result)
+		report(result)
+
+		if !result.Sustainable() {
+			break
+		}
+	}
+	return results, nil
+}
+
+// MaxSustainable estimates the largest sustainable series count from the
+// largest measured one, assuming cost grows linearly with series.
+func MaxSustainable(results []Result) int {
+	if len(results) == 0 {
+		return 0
+	}
+	last := results[len(results)-1]
+	if last.TickTime <= 0 {
+		return last.Series
+	}
+	return int(float64(last.Series) * float64(Interval) / float64(last.TickTime))
+}
+
+// measure runs cfg with the given series count and returns its cost per
+// tick.
+func measure(sim *simulation.Context, cfg *config.Config, series, ticks int) (Result, error) {
+	heapBefore := heapInUse()
+
+	run, err := virtual.New(sim, cfg)
+	if err != nil {
+		return Result{}, err
+	}
+	defer run.Stop()
+
+	// Warm up once so lazily initialized state is not measured as tick cost
+	if _, err := run.Tick(); err != nil {
+		return Result{}, err
+	}
+	read(run.Metrics)
+
+	var heap uint64
+	if heapAfter := heapInUse(); heapAfter > heapBefore {
+		heap = heapAfter - heapBefore
+	}
+
+	cpuBefore := cpuTime()
+	start := time.Now()
+	for range ticks {
+		if _, err := run.Tick(); err != nil {
+			return Result{}, err
+		}
+		read(run.Metrics)
+	}
+	elapsed := time.Since(start)
+	cpu := cpuTime() - cpuBefore
+
+	return Result{
+		Series:       series,
+		TickTime:     elapsed / time.Duration(ticks),
+		CPUPerSeries: cpu / time.Duration(ticks*series),
+		MemPerSeries: heap / uint64(series),
+	}, nil
+}
+
+// workload resolves the built-in profile with the given series count.
+func workload(series int, seed uint64) (*config.Config, error) {
+	// The built-in profile has two metrics with scale series each
+	raw, err := config.BuiltinMetricsProfile(series / 2)
+	if err != nil {
+		return nil, err
+	}
+	unlimited := 0
+	raw.Settings.MaxSeries = &unlimited
+	raw.Settings.Seed = &seed
+
+	if err := config.Validate(raw); err != nil {
+		return nil, err
+	}
+	if err := config.Expand(raw); err != nil {
+		return nil, err
+	}
+	return config.Resolve(raw)
+}
+
+// read samples every emitted series and discards the values, like an
+// exporter writing to nowhere.
+func read(registry *metric.Registry) {
+	for _, d := range registry.Metrics() {
+		if !d.Sampler.Emit(consumer) {
+			continue
+		}
+		d.Guard.Do("bench read", func() { d.Value.Sample(consumer) })
+	}
+}
+
+// heapInUse returns the live heap after a garbage collection.
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// cpuTime returns the CPU time the process spent running Go code and the
+// runtime, as estimated by the runtime. Collects garbage first, since the
+// estimate is updated by collections.
+func cpuTime() time.Duration {
+	runtime.GC()
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+	}
+	metrics.Read(samples)
+	busy := samples[0].Value.Float64() - samples[1].Value.Float64()
+	return time.Duration(busy * float64(time.Second))
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// number is an operand of an arithmetic expression: an integer, a float, or
+// a duration.
+type number struct {
+	kind  numberKind
+	int   int64
+	float float64
+	dur   time.Duration
+}
+
+type numberKind int
+
+const (
+	kindInt numberKind = iota
+	kindFloat
+	kindDuration
+)
+
+// String formats the number as a YAML scalar.
+func (n number) String() string {
+	switch n.kind {
+	case kindInt:
+		return strconv.FormatInt(n.int, 10)
+	case kindFloat:
+		return strconv.FormatFloat(n.float, 'g', -1, 64)
+	default:
+		return n.dur.String()
+	}
+}
+
+// toFloat returns an integer or float operand as float.
+func (n number) toFloat() float64 {
+	if n.kind == kindInt {
+		return float64(n.int)
+	}
+	return n.float
+}
+
+// evalArithmetic evaluates an arithmetic expression of numbers and
+// durations with +, -, *, /, %, and parentheses, like "2*100" or
+// "1m + 3*10s". Integer division truncates. Returns false if s is not an
+// expression with at least one operator.
+func evalArithmetic(s string) (string, bool) {
+	p := &arithmeticParser{input: strings.TrimSpace(s)}
+	result, err := p.expression()
+	if err != nil || p.pos < len(p.input) || !p.operators {
+		return "", false
+	}
+	return result.String(), true
+}
+
+// arithmeticParser evaluates an expression while parsing it.
+type arithmeticParser struct {
+	input     string
+	pos       int
+	operators bool // Whether any operator was applied
+}
+
+// expression parses terms joined by + and -.
+func (p *arithmeticParser) expression() (number, error) {
+	left, err := p.term()
+	if err != nil {
+		return number{}, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.term()
+		if err != nil {
+			return number{}, err
+		}
+		if left, err = p.apply(op, left, right); err != nil {
+			return number{}, err
+		}
+	}
+}
+
+// term parses factors joined by *, /, and %.
+func (p *arithmeticParser) term() (number, error) {
+	left, err := p.factor()
+	if err != nil {
+		return number{}, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.factor()
+		if err != nil {
+			return number{}, err
+		}
+		if left, err = p.apply(op, left, right); err != nil {
+			return number{}, err
+		}
+	}
+}
+
+// factor parses a signed number, duration, or parenthesized expression.
+func (p *arithmeticParser) factor() (number, error) {
+	switch p.peek() {
+	case '-', '+':
+		sign := p.input[p.pos]
+		p.pos++
+		n, err := p.factor()
+		if err != nil || sign == '+' {
+			return n, err
+		}
+		p.operators = true
+		return p.apply('*', n, number{kind: kindInt, int: -1})
+	case '(':
+		p.pos++
+		n, err := p.expression()
+		if err != nil {
+			return number{}, err
+		}
+		if p.peek() != ')' {
+			return number{}, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return n, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && strings.ContainsRune("0123456789.", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	digits := p.pos
+	for p.pos < len(p.input) && (isUnitChar(p.input[p.pos]) || (p.pos > digits && strings.ContainsRune("0123456789.", rune(p.input[p.pos])))) {
+		p.pos++
+	}
+	literal := p.input[start:p.pos]
+
+	switch {
+	case literal == "":
+		return number{}, fmt.Errorf("expected number at %d", start)
+	case p.pos > digits:
+		d, err := time.ParseDuration(literal)
+		return number{kind: kindDuration, dur: d}, err
+	case strings.Contains(literal, "."):
+		f, err := strconv.ParseFloat(literal, 64)
+		return number{kind: kindFloat, float: f}, err
+	default:
+		i, err := strconv.ParseInt(literal, 10, 64)
+		return number{kind: kindInt, int: i}, err
+	}
+}
+
+// peek returns the next non-space character, or 0 at the end.
+func (p *arithmeticParser) peek() byte {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+	if p.pos == len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+// apply combines two operands. Durations can be added and subtracted, and
+// scaled by numbers.
+func (p *arithmeticParser) apply(op byte, x, y number) (number, error) {
+	p.operators = true
+
+	if (op == '/' || op == '%') && y.kind != kindDuration && y.toFloat() == 0 {
+		return number{}, fmt.Errorf("division by zero")
+	}
+
+	switch {
+	case x.kind == kindDuration && y.kind == kindDuration:
+		switch op {
+		case '+':
+			return number{kind: kindDuration, dur: x.dur + y.dur}, nil
+		case '-':
+			return number{kind: kindDuration, dur: x.dur - y.dur}, nil
+		}
+	case x.kind == kindDuration || y.kind == kindDuration:
+		d, f := x.dur, y.toFloat()
+		if y.kind == kindDuration {
+			d, f = y.dur, x.toFloat()
+		}
+		switch {
+		case op == '*':
+			return number{kind: kindDuration, dur: time.Duration(math.Round(float64(d) * f))}, nil
+		case op == '/' && x.kind == kindDuration:
+			return number{kind: kindDuration, dur: time.Duration(math.Round(float64(d) / f))}, nil
+		}
+	case x.kind == kindInt && y.kind == kindInt:
+		switch op {
+		case '+':
+			return number{kind: kindInt, int: x.int + y.int}, nil
+		case '-':
+			return number{kind: kindInt, int: x.int - y.int}, nil
+		case '*':
+			return number{kind: kindInt, int: x.int * y.int}, nil
+		case '/':
+			return number{kind: kindInt, int: x.int / y.int}, nil
+		case '%':
+			return number{kind: kindInt, int: x.int % y.int}, nil
+		}
+	default:
+		a, b := x.toFloat(), y.toFloat()
+		switch op {
+		case '+':
+			return number{kind: kindFloat, float: a + b}, nil
+		case '-':
+			return number{kind: kindFloat, float: a - b}, nil
+		case '*':
+			return number{kind: kindFloat, float: a * b}, nil
+		case '/':
+			return number{kind: kindFloat, float: a / b}, nil
+		}
+	}
+	return number{}, fmt.Errorf("unsupported operation %c on %s and %s", op, x, y)
+}
+
+// isUnitChar reports whether c can appear in a duration unit.
+func isUnitChar(c byte) bool {
+	return strings.IndexByte("hmsuµn", c) >= 0
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultBuiltinScale    = 10
+	DefaultBuiltinInterval = 1 * time.Second
+)
+
+// BuiltinMetricsProfile returns a raw configuration generating a small
+// request/queue metric family for ad-hoc testing without a config file.
+// Scale controls the number of series per metric.
+func BuiltinMetricsProfile(scale int) (*RawConfig, error) {
+	if scale <= 0 {
+		return nil, fmt.Errorf("invalid scale: %d (must be positive)", scale)
+	}
+
+	start, end := 1, scale
+	periodic := "periodic"
+	randomInt := "random_int"
+	zero, requestMax, queueMax := 0, 100, 1000
+
+	return &RawConfig{
+		Iterators: []RawIterator{
+			{Name: "series", Type: "range", Start: &start, End: &end},
+		},
+		Metrics: []RawMetricConfig{
+			{
+				Name:        RawMetricNameConfig{Prometheus: "otelbox_requests_total", OTEL: "otelbox.requests"},
+				Type:        string(MetricTypeCounter),
+				Description: "Simulated requests",
+				Value: RawValueReference{
+					Source: &RawSourceReference{
+						Type:  &randomInt,
+						Clock: &RawClockReference{Type: &periodic, Interval: DefaultBuiltinInterval},
+						Min:   &zero,
+						Max:   &requestMax,
+					},
+					Transforms: []TransformConfig{{Type: "accumulate"}},
+				},
+				Attributes: map[string]string{"series": "{series}"},
+			},
+			{
+				Name:        RawMetricNameConfig{Prometheus: "otelbox_queue_depth", OTEL: "otelbox.queue.depth"},
+				Type:        string(MetricTypeGauge),
+				Description: "Simulated queue depth",
+				Value: RawValueReference{
+					Source: &RawSourceReference{
+						Type:  &randomInt,
+						Clock: &RawClockReference{Type: &periodic, Interval: DefaultBuiltinInterval},
+						Min:   &zero,
+						Max:   &queueMax,
+					},
+				},
+				Attributes: map[string]string{"series": "{series}"},
+			},
+		},
+	}, nil
+}
+
+// ApplyTarget replaces the export configuration with a single exporter
+// derived from a target URL:
+//
+//	otlp://host:port         OTLP gRPC push
+//	otlp+http://host:port    OTLP HTTP push
+//	prometheus://:port/path  Prometheus pull endpoint
+func ApplyTarget(raw *RawConfig, target string) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return fmt.Errorf("invalid target %q: %w", target, err)
+	}
+
+	host, portStr, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host, portStr = u.Host, ""
+	}
+	port := 0
+	if portStr != "" {
+		port, err = strconv.Atoi(portStr)
+		if err != nil {
+			return fmt.Errorf("invalid target port %q", portStr)
+		}
+	}
+
+	switch u.Scheme {
+	case "otlp", "otlp+grpc":
+		raw.Export = RawExportConfig{OTEL: &RawOTELExportConfig{
+			Enabled: true, Transport: "grpc", Host: host, Port: port,
+		}}
+	case "otlp+http":
+		raw.Export = RawExportConfig{OTEL: &RawOTELExportConfig{
+			Enabled: true, Transport: "http", Host: host, Port: port,
+		}}
+	case "prometheus":
+		raw.Export = RawExportConfig{Prometheus: &RawPrometheusExportConfig{
+			Enabled: true, Port: port, Path: u.Path,
+		}}
+	default:
+		return fmt.Errorf("invalid target scheme %q (must be otlp, otlp+http, or prometheus)", u.Scheme)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// ByteSize is a size in bytes parsed from either an integer or a
+// human-readable string with binary unit suffix (e.g. 512, 1KB, 64KB, 4MB).
+type ByteSize int
+
+// byteSizeUnits maps unit suffixes to multipliers (longest suffix first)
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"KB", 1 << 10},
+	{"MB", 1 << 20},
+	{"GB", 1 << 30},
+	{"K", 1 << 10},
+	{"M", 1 << 20},
+	{"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseByteSize parses a human-readable size string.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	input := s
+	multiplier := 1
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(strings.ToUpper(s), strings.ToUpper(unit.suffix)) {
+			s = strings.TrimSpace(s[:len(s)-len(unit.suffix)])
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("size cannot be negative: %d", n)
+	}
+	if n > math.MaxInt/multiplier {
+		return 0, fmt.Errorf("size too large: %q", input)
+	}
+	return ByteSize(n * multiplier), nil
+}
+
+// UnmarshalYAML handles both integer and string forms for sizes
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	// Try integer form first (plain bytes)
+	var n int
+	if err := value.Decode(&n); err == nil {
+		if n < 0 {
+			return fmt.Errorf("size cannot be negative: %d", n)
+		}
+		*b = ByteSize(n)
+		return nil
+	}
+
+	// Fall back to string form with unit suffix
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// MarshalYAML emits the human-readable form
+func (b ByteSize) MarshalYAML() (any, error) {
+	return b.String(), nil
+}
+
+// String returns the size in the largest exact binary unit.
+func (b ByteSize) String() string {
+	switch {
+	case b >= 1<<30 && b%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", b/(1<<30))
+	case b >= 1<<20 && b%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", b/(1<<20))
+	case b >= 1<<10 && b%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", b/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", int(b))
+	}
+}
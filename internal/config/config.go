@@ -4,8 +4,11 @@ package config
 type Config struct {
 	Instances InstanceRegistry
 	Metrics   []MetricConfig
+	Jobs      []JobConfig
 	Export    ExportConfig
 	Settings  SettingsConfig
+	Chaos     ChaosConfig
+	Files     []string // Files the configuration was loaded from
 }
 
 // InstanceRegistry holds resolved instance configurations
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// AuthConfig defines client authentication for push exporters.
+// Secrets given as files are re-read on use, so rotated credentials
+// take effect without a restart.
+type AuthConfig struct {
+	Basic  *BasicAuthConfig
+	Bearer *BearerAuthConfig
+	OAuth2 *OAuth2Config
+}
+
+// BasicAuthConfig defines HTTP basic authentication.
+type BasicAuthConfig struct {
+	Username     string
+	Password     string
+	PasswordFile string
+}
+
+// BearerAuthConfig defines bearer token authentication.
+type BearerAuthConfig struct {
+	Token     string
+	TokenFile string
+}
+
+// OAuth2Config defines the OAuth2 client credentials flow.
+type OAuth2Config struct {
+	ClientID         string
+	ClientSecret     string
+	ClientSecretFile string
+	TokenURL         string
+	Scopes           []string
+	EndpointParams   map[string]string
+}
+
+// Validate validates authentication configuration.
+func (c *AuthConfig) Validate() error {
+	methods := 0
+	if c.Basic != nil {
+		methods++
+		if c.Basic.Username == "" {
+			return fmt.Errorf("auth.basic.username required")
+		}
+		if err := exactlyOne("auth.basic", "password", c.Basic.Password, "password_file", c.Basic.PasswordFile); err != nil {
+			return err
+		}
+	}
+	if c.Bearer != nil {
+		methods++
+		if err := exactlyOne("auth.bearer", "token", c.Bearer.Token, "token_file", c.Bearer.TokenFile); err != nil {
+			return err
+		}
+	}
+	if c.OAuth2 != nil {
+		methods++
+		if c.OAuth2.ClientID == "" {
+			return fmt.Errorf("auth.oauth2.client_id required")
+		}
+		if err := exactlyOne("auth.oauth2", "client_secret", c.OAuth2.ClientSecret,
+			"client_secret_file", c.OAuth2.ClientSecretFile); err != nil {
+			return err
+		}
+		if c.OAuth2.TokenURL == "" {
+			return fmt.Errorf("auth.oauth2.token_url required")
+		}
+		if u, err := url.Parse(c.OAuth2.TokenURL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid auth.oauth2.token_url: %q", c.OAuth2.TokenURL)
+		}
+	}
+
+	if methods > 1 {
+		return fmt.Errorf("auth: only one of basic, bearer, or oauth2 allowed")
+	}
+
+	return nil
+}
+
+// exactlyOne requires exactly one of an inline value and a file reference.
+func exactlyOne(section, name, value, fileName, file string) error {
+	if value == "" && file == "" {
+		return fmt.Errorf("%s: %s or %s required", section, name, fileName)
+	}
+	if value != "" && file != "" {
+		return fmt.Errorf("%s: %s and %s are mutually exclusive", section, name, fileName)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChaosConfig defines deliberate misbehavior for testing downstream
+// timeout, retry, and alerting paths. The zero value disables all chaos.
+type ChaosConfig struct {
+	ScrapeDelay  *DelayConfig        // Delay before answering scrapes (nil: none)
+	ScrapeErrors []ScrapeErrorConfig // Failures injected into a fraction of scrapes
+	OTLPFaults   []OTLPFaultConfig   // Faults injected into a fraction of OTLP pushes
+
+	ProtocolViolations []ProtocolViolationConfig // Invalid data injected into a fraction of scrapes
+}
+
+// Validate applies defaults and validates chaos configuration.
+func (c *ChaosConfig) Validate() error {
+	if c.ScrapeDelay != nil {
+		if err := c.ScrapeDelay.Validate(); err != nil {
+			return fmt.Errorf("chaos.scrape_delay: %w", err)
+		}
+	}
+
+	// At most one error is injected per scrape
+	total := 0.0
+	for i := range c.ScrapeErrors {
+		if err := c.ScrapeErrors[i].Validate(); err != nil {
+			return fmt.Errorf("chaos.scrape_errors[%d]: %w", i, err)
+		}
+		total += c.ScrapeErrors[i].Probability
+	}
+	if total > 1 {
+		return fmt.Errorf("chaos.scrape_errors: probabilities sum to %g (must be at most 1)", total)
+	}
+
+	// At most one fault is injected per push
+	total = 0.0
+	for i := range c.OTLPFaults {
+		if err := c.OTLPFaults[i].Validate(); err != nil {
+			return fmt.Errorf("chaos.otlp_faults[%d]: %w", i, err)
+		}
+		total += c.OTLPFaults[i].Probability
+	}
+	if total > 1 {
+		return fmt.Errorf("chaos.otlp_faults: probabilities sum to %g (must be at most 1)", total)
+	}
+
+	// Violations are drawn independently, each type at most once
+	seen := make(map[ProtocolViolationType]bool, len(c.ProtocolViolations))
+	for i := range c.ProtocolViolations {
+		if err := c.ProtocolViolations[i].Validate(); err != nil {
+			return fmt.Errorf("chaos.protocol_violations[%d]: %w", i, err)
+		}
+		if seen[c.ProtocolViolations[i].Type] {
+			return fmt.Errorf("chaos.protocol_violations: duplicate type %s", c.ProtocolViolations[i].Type)
+		}
+		seen[c.ProtocolViolations[i].Type] = true
+	}
+
+	return nil
+}
+
+// ProtocolViolationType defines the invalid data a scrape carries.
+type ProtocolViolationType string
+
+const (
+	// ViolationDuplicateSeries exposes a series twice
+	ViolationDuplicateSeries ProtocolViolationType = "duplicate_series"
+
+	// ViolationLabelOrder exposes labels of a series in reverse order
+	ViolationLabelOrder ProtocolViolationType = "label_order"
+
+	// ViolationNaN replaces a sample value with NaN
+	ViolationNaN ProtocolViolationType = "nan"
+
+	// ViolationInf replaces a sample value with +Inf
+	ViolationInf ProtocolViolationType = "inf"
+
+	// ViolationInvalidUTF8 appends an invalid UTF-8 byte to a label value
+	ViolationInvalidUTF8 ProtocolViolationType = "invalid_utf8"
+
+	// ViolationCounterDecrease exposes a counter below its true value
+	ViolationCounterDecrease ProtocolViolationType = "counter_decrease"
+)
+
+// ProtocolViolationConfig defines a protocol violation injected with a
+// probability per scrape.
+type ProtocolViolationConfig struct {
+	Type        ProtocolViolationType
+	Probability float64
+}
+
+// Validate validates protocol violation configuration.
+func (c *ProtocolViolationConfig) Validate() error {
+	switch c.Type {
+	case ViolationDuplicateSeries, ViolationLabelOrder, ViolationNaN,
+		ViolationInf, ViolationInvalidUTF8, ViolationCounterDecrease:
+	default:
+		return fmt.Errorf("invalid type: %s (must be duplicate_series, label_order, nan, inf, invalid_utf8, or counter_decrease)", c.Type)
+	}
+
+	if c.Probability <= 0 || c.Probability > 1 {
+		return fmt.Errorf("probability must be in (0, 1], got %g", c.Probability)
+	}
+
+	return nil
+}
+
+// OTLPFaultType defines how a faulty OTLP push misbehaves.
+type OTLPFaultType string
+
+const (
+	// OTLPFaultSkip drops the batch without sending it
+	OTLPFaultSkip OTLPFaultType = "skip"
+
+	// OTLPFaultDuplicate sends the batch twice
+	OTLPFaultDuplicate OTLPFaultType = "duplicate"
+
+	// OTLPFaultDelay holds the batch back before sending it
+	OTLPFaultDelay OTLPFaultType = "delay"
+
+	// OTLPFaultCorrupt sends the batch with invalid timestamps and values
+	OTLPFaultCorrupt OTLPFaultType = "corrupt"
+)
+
+// OTLPFaultConfig defines an OTLP push fault injected with a probability.
+type OTLPFaultConfig struct {
+	Type        OTLPFaultType
+	Probability float64
+	Delay       *DelayConfig // Hold-back for type delay
+}
+
+// Validate applies defaults and validates OTLP fault configuration.
+func (c *OTLPFaultConfig) Validate() error {
+	switch c.Type {
+	case OTLPFaultDelay:
+		if c.Delay == nil {
+			return fmt.Errorf("delay required for type delay")
+		}
+		if err := c.Delay.Validate(); err != nil {
+			return fmt.Errorf("delay: %w", err)
+		}
+	case OTLPFaultSkip, OTLPFaultDuplicate, OTLPFaultCorrupt:
+		if c.Delay != nil {
+			return fmt.Errorf("delay only allowed with type delay")
+		}
+	default:
+		return fmt.Errorf("invalid type: %s (must be skip, duplicate, delay, or corrupt)", c.Type)
+	}
+
+	if c.Probability <= 0 || c.Probability > 1 {
+		return fmt.Errorf("probability must be in (0, 1], got %g", c.Probability)
+	}
+
+	return nil
+}
+
+// ScrapeErrorType defines how a failing scrape misbehaves.
+type ScrapeErrorType string
+
+const (
+	// ScrapeErrorStatus answers with a 5xx status
+	ScrapeErrorStatus ScrapeErrorType = "status"
+
+	// ScrapeErrorTruncate cuts the body short and aborts the response
+	ScrapeErrorTruncate ScrapeErrorType = "truncate"
+
+	// ScrapeErrorMalformed inserts an invalid exposition line
+	ScrapeErrorMalformed ScrapeErrorType = "malformed"
+
+	// ScrapeErrorReset resets the connection without a response
+	ScrapeErrorReset ScrapeErrorType = "reset"
+)
+
+// DefaultScrapeErrorStatus is returned by status errors without a code.
+const DefaultScrapeErrorStatus = 500
+
+// ScrapeErrorConfig defines a scrape failure injected with a probability.
+type ScrapeErrorConfig struct {
+	Type        ScrapeErrorType
+	Probability float64
+	Status      int // HTTP status for type status
+}
+
+// Validate applies defaults and validates scrape error configuration.
+func (c *ScrapeErrorConfig) Validate() error {
+	switch c.Type {
+	case ScrapeErrorStatus:
+		if c.Status == 0 {
+			c.Status = DefaultScrapeErrorStatus
+		}
+		if c.Status < 500 || c.Status > 599 {
+			return fmt.Errorf("invalid status: %d (must be 5xx)", c.Status)
+		}
+	case ScrapeErrorTruncate, ScrapeErrorMalformed, ScrapeErrorReset:
+		if c.Status != 0 {
+			return fmt.Errorf("status only allowed with type status")
+		}
+	default:
+		return fmt.Errorf("invalid type: %s (must be status, truncate, malformed, or reset)", c.Type)
+	}
+
+	if c.Probability <= 0 || c.Probability > 1 {
+		return fmt.Errorf("probability must be in (0, 1], got %g", c.Probability)
+	}
+
+	return nil
+}
+
+// DelayDistribution defines how delays are sampled.
+type DelayDistribution string
+
+const (
+	// DelayFixed always waits Delay
+	DelayFixed DelayDistribution = "fixed"
+
+	// DelayUniform waits uniformly between Min and Max
+	DelayUniform DelayDistribution = "uniform"
+
+	// DelayNormal waits normally distributed around Mean with StdDev
+	DelayNormal DelayDistribution = "normal"
+
+	// DelayExponential waits exponentially distributed with Mean
+	DelayExponential DelayDistribution = "exponential"
+)
+
+// DelayConfig defines a fixed or randomly distributed delay.
+// Sampled delays of the normal and exponential distributions are bounded
+// to [Min, Max]; a zero Max leaves them unbounded above.
+type DelayConfig struct {
+	Distribution DelayDistribution
+	Delay        time.Duration // Fixed delay
+	Min          time.Duration
+	Max          time.Duration
+	Mean         time.Duration
+	StdDev       time.Duration
+}
+
+// Validate applies defaults and validates delay configuration.
+func (c *DelayConfig) Validate() error {
+	// Detailed form without distribution is a jitter range
+	if c.Distribution == "" {
+		c.Distribution = DelayUniform
+	}
+
+	if c.Min < 0 || c.Max < 0 {
+		return fmt.Errorf("min and max must not be negative")
+	}
+	if c.Max > 0 && c.Min > c.Max {
+		return fmt.Errorf("min %s exceeds max %s", c.Min, c.Max)
+	}
+
+	switch c.Distribution {
+	case DelayFixed:
+		if c.Delay < 0 {
+			return fmt.Errorf("invalid delay: %s", c.Delay)
+		}
+	case DelayUniform:
+		if c.Max == 0 {
+			return fmt.Errorf("max required for distribution uniform")
+		}
+	case DelayNormal:
+		if c.Mean <= 0 {
+			return fmt.Errorf("mean required for distribution normal")
+		}
+		if c.StdDev < 0 {
+			return fmt.Errorf("invalid stddev: %s", c.StdDev)
+		}
+	case DelayExponential:
+		if c.Mean <= 0 {
+			return fmt.Errorf("mean required for distribution exponential")
+		}
+	default:
+		return fmt.Errorf("invalid distribution: %s (must be fixed, uniform, normal, or exponential)", c.Distribution)
+	}
+
+	return nil
+}
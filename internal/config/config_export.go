@@ -2,6 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -9,6 +13,12 @@ const (
 	// Prometheus defaults
 	DefaultPrometheusPort = 9090
 	DefaultPrometheusPath = "/metrics"
+	DefaultFederationPath = "/federate"
+
+	// Upstream defaults
+	DefaultUpstreamInterval     = 15 * time.Second
+	DefaultUpstreamTimeout      = 10 * time.Second
+	DefaultUpstreamReplicaLabel = "replica"
 
 	// OTEL defaults
 	DefaultOTELReadInterval = 1 * time.Second
@@ -17,24 +27,48 @@ const (
 	DefaultOTELHost         = "localhost"
 	DefaultOTELPortGRPC     = 4317
 	DefaultOTELPortHTTP     = 4318
+	DefaultOTELPath         = "/v1/metrics"
 	DefaultServiceName      = "otelbox"
 	DefaultServiceVersion   = "dev"
+	DefaultStartTimeMode    = StartTimeModeSDK
+	DefaultOTELCompression  = CompressionNone
+	DefaultOTELTimeout      = 10 * time.Second
+	DefaultOTELPayload      = OTELPayloadSDK
+
+	// OTLP retry defaults (match the OTEL SDK)
+	DefaultRetryInitialInterval = 5 * time.Second
+	DefaultRetryMaxInterval     = 30 * time.Second
+	DefaultRetryMaxElapsedTime  = 1 * time.Minute
+
+	// Exporter restart defaults
+	DefaultRestartInitialBackoff = 1 * time.Second
+	DefaultRestartMaxBackoff     = 1 * time.Minute
 )
 
 // ExportConfig defines how metrics are exposed.
 type ExportConfig struct {
 	Prometheus *PrometheusExportConfig
 	OTEL       *OTELExportConfig
+	Custom     *CustomExportConfig
+}
+
+// CustomExportConfig selects an exporter registered by external code.
+// Options are passed to the exporter as given.
+type CustomExportConfig struct {
+	Name    string
+	Options map[string]any
+	Restart *RestartConfig // Restart after failures (nil: fail)
 }
 
 // Validate applies defaults and validates export configuration.
 func (e *ExportConfig) Validate() error {
 	// Default to Prometheus enabled if no exporters configured
-	if e.Prometheus == nil && e.OTEL == nil {
+	if e.Prometheus == nil && e.OTEL == nil && e.Custom == nil {
 		e.Prometheus = &PrometheusExportConfig{
-			Enabled: true,
-			Port:    DefaultPrometheusPort,
-			Path:    DefaultPrometheusPath,
+			Enabled:     true,
+			Port:        DefaultPrometheusPort,
+			Path:        DefaultPrometheusPath,
+			LandingPage: true,
 		}
 		return nil
 	}
@@ -52,17 +86,36 @@ func (e *ExportConfig) Validate() error {
 		}
 	}
 
+	if e.Custom != nil {
+		if e.Custom.Name == "" {
+			return fmt.Errorf("custom exporter name cannot be empty")
+		}
+		if e.Custom.Restart != nil {
+			if err := e.Custom.Restart.Validate(); err != nil {
+				return fmt.Errorf("custom %w", err)
+			}
+		}
+	}
+
 	// Verify at least one exporter enabled
-	promEnabled := e.Prometheus != nil && e.Prometheus.Enabled
-	otelEnabled := e.OTEL != nil && e.OTEL.Enabled
+	enabled := 0
+	if e.Prometheus != nil && e.Prometheus.Enabled {
+		enabled++
+	}
+	if e.OTEL != nil && e.OTEL.Enabled {
+		enabled++
+	}
+	if e.Custom != nil {
+		enabled++
+	}
 
-	if !promEnabled && !otelEnabled {
+	if enabled == 0 {
 		return fmt.Errorf("at least one exporter must be enabled")
 	}
 
 	// Verify only one exporter enabled (prevent read conflicts)
-	if promEnabled && otelEnabled {
-		return fmt.Errorf("only one exporter can be enabled at a time (prometheus or otel)")
+	if enabled > 1 {
+		return fmt.Errorf("only one exporter can be enabled at a time (prometheus, otel, or custom)")
 	}
 
 	return nil
@@ -70,11 +123,260 @@ func (e *ExportConfig) Validate() error {
 
 // PrometheusExportConfig defines Prometheus pull endpoint settings.
 type PrometheusExportConfig struct {
+	Enabled          bool
+	Host             string // Listen address ("": all interfaces)
+	Port             int
+	Path             string
+	SocketActivation bool               // Serve on the listener passed by systemd when present
+	LandingPage      bool               // Serve the metric catalog at /
+	ConstLabels      map[string]string  // Labels added to every emitted series
+	LabelDetectors   []ResourceDetector // Detected const labels, overridden by ConstLabels
+	ProcessMetrics   ProcessMetricsConfig
+	Auth             *AuthConfig // Required scrape credentials (nil: none)
+	Exposition       ExpositionConfig
+	TargetParams     []string         // Query parameters copied to labels of every series
+	Timestamps       *TimestampConfig // Explicit sample timestamps (nil: none)
+	Restart          *RestartConfig   // Restart after failures (nil: fail)
+	Upstreams        []UpstreamConfig // Scraped endpoints re-exposed with replicas
+	Federation       FederationConfig
+	Paths            []ScrapePathConfig // Additional scrape paths
+	Metadata         MetadataConfig
+	Response         ResponseConfig
+}
+
+// ScrapePathConfig defines an additional scrape path on the Prometheus
+// server. The path serves the series matching any Match selector (all
+// series if empty), with Chaos replacing the top-level scrape chaos.
+type ScrapePathConfig struct {
+	Path  string
+	Match []string
+	Chaos *ChaosConfig // Scrape chaos of the path (nil: top-level chaos)
+}
+
+// Validate validates scrape path configuration.
+func (c *ScrapePathConfig) Validate() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("invalid path: %q (must start with /)", c.Path)
+	}
+	for _, s := range c.Match {
+		if _, err := ParseSelector(s); err != nil {
+			return err
+		}
+	}
+	if c.Chaos != nil && len(c.Chaos.OTLPFaults) > 0 {
+		return fmt.Errorf("chaos.otlp_faults not supported on scrape paths")
+	}
+	return nil
+}
+
+// FederationConfig defines a /federate-compatible endpoint serving the
+// series selected by match[] parameters.
+type FederationConfig struct {
 	Enabled bool
-	Port    int
 	Path    string
 }
 
+// UpstreamConfig defines a real metrics endpoint whose series are scraped
+// and re-exposed once per replica, turning one target into a fleet.
+type UpstreamConfig struct {
+	URL          string
+	Interval     time.Duration // Minimum time between upstream scrapes
+	Timeout      time.Duration
+	Replicas     int
+	ReplicaLabel string            // Label distinguishing replicas (replicas > 1 only)
+	Labels       map[string]string // Added to every re-exposed series
+}
+
+// ExpositionFormat names a Prometheus exposition format.
+type ExpositionFormat string
+
+const (
+	// ExpositionText is the Prometheus text format (text/plain; version=0.0.4)
+	ExpositionText ExpositionFormat = "text"
+
+	// ExpositionOpenMetrics is the OpenMetrics text format
+	ExpositionOpenMetrics ExpositionFormat = "openmetrics"
+
+	// ExpositionProtobuf is the delimited protobuf format
+	ExpositionProtobuf ExpositionFormat = "protobuf"
+)
+
+// DefaultExpositionFormats are negotiated unless configured otherwise.
+var DefaultExpositionFormats = []ExpositionFormat{ExpositionText, ExpositionOpenMetrics, ExpositionProtobuf}
+
+// ExpositionConfig controls content negotiation of the scrape endpoint.
+// Requests for formats outside Formats receive the first listed format;
+// a non-empty Force answers every request in that format.
+type ExpositionConfig struct {
+	Formats []ExpositionFormat
+	Force   ExpositionFormat
+}
+
+// Validate applies defaults and validates exposition configuration.
+func (c *ExpositionConfig) Validate() error {
+	if c.Force != "" {
+		if len(c.Formats) > 0 {
+			return fmt.Errorf("exposition.force and exposition.formats are mutually exclusive")
+		}
+		return validateExpositionFormat(c.Force)
+	}
+
+	// Apply formats default
+	if len(c.Formats) == 0 {
+		c.Formats = slices.Clone(DefaultExpositionFormats)
+	}
+
+	seen := make(map[ExpositionFormat]bool, len(c.Formats))
+	for _, f := range c.Formats {
+		if err := validateExpositionFormat(f); err != nil {
+			return err
+		}
+		if seen[f] {
+			return fmt.Errorf("duplicate exposition format: %s", f)
+		}
+		seen[f] = true
+	}
+
+	return nil
+}
+
+// MalformedMetadata names a deliberate metadata defect.
+type MalformedMetadata string
+
+const (
+	// MalformedDuplicateHelp repeats HELP with a different text
+	MalformedDuplicateHelp MalformedMetadata = "duplicate_help"
+
+	// MalformedConflictingType repeats TYPE with a different type
+	MalformedConflictingType MalformedMetadata = "conflicting_type"
+
+	// MalformedUnknownType declares a type that does not exist
+	MalformedUnknownType MalformedMetadata = "unknown_type"
+
+	// MalformedTypeAfterSamples moves TYPE behind the first sample
+	MalformedTypeAfterSamples MalformedMetadata = "type_after_samples"
+
+	// MalformedUnitMismatch declares a unit that is not a name suffix
+	MalformedUnitMismatch MalformedMetadata = "unit_mismatch"
+
+	// MalformedHelpEscape puts an invalid escape sequence into HELP
+	MalformedHelpEscape MalformedMetadata = "help_escape"
+)
+
+// MetadataConfig controls the metadata of text expositions. Created
+// attaches created timestamps to generated counters, exposed as _created
+// samples in OpenMetrics; Unit adds UNIT lines in OpenMetrics for names
+// ending in a base unit. Malformed defects apply to every metric family.
+type MetadataConfig struct {
+	Created   bool
+	OmitHelp  bool
+	OmitType  bool
+	Unit      bool
+	Malformed []MalformedMetadata
+}
+
+// Rewrites reports whether text expositions differ from the default.
+func (c *MetadataConfig) Rewrites() bool {
+	return c.OmitHelp || c.OmitType || c.Unit || len(c.Malformed) > 0
+}
+
+// Validate validates metadata configuration.
+func (c *MetadataConfig) Validate() error {
+	seen := make(map[MalformedMetadata]bool, len(c.Malformed))
+	for _, m := range c.Malformed {
+		switch m {
+		case MalformedDuplicateHelp, MalformedConflictingType, MalformedUnknownType,
+			MalformedTypeAfterSamples, MalformedUnitMismatch, MalformedHelpEscape:
+		default:
+			return fmt.Errorf("invalid malformed metadata: %s (must be duplicate_help, conflicting_type, unknown_type, type_after_samples, unit_mismatch, or help_escape)", m)
+		}
+		if seen[m] {
+			return fmt.Errorf("duplicate malformed metadata: %s", m)
+		}
+		seen[m] = true
+	}
+
+	if c.OmitHelp && (seen[MalformedDuplicateHelp] || seen[MalformedHelpEscape]) {
+		return fmt.Errorf("malformed help metadata conflicts with metadata.help: false")
+	}
+	if c.OmitType && (seen[MalformedConflictingType] || seen[MalformedUnknownType] || seen[MalformedTypeAfterSamples]) {
+		return fmt.Errorf("malformed type metadata conflicts with metadata.type: false")
+	}
+
+	return nil
+}
+
+// ResponseCompression selects how scrape responses are compressed.
+type ResponseCompression string
+
+const (
+	// ResponseCompressionAuto gzips responses if the request accepts gzip
+	ResponseCompressionAuto ResponseCompression = "auto"
+
+	// ResponseCompressionGzip gzips every response, regardless of Accept-Encoding
+	ResponseCompressionGzip ResponseCompression = "gzip"
+
+	// ResponseCompressionNone never compresses responses
+	ResponseCompressionNone ResponseCompression = "none"
+)
+
+// ResponseConfig controls compression and size of scrape responses.
+// Text expositions smaller than MinSize are padded with comment lines;
+// responses larger than MaxSize are truncated before compression.
+type ResponseConfig struct {
+	Compression ResponseCompression
+	MinSize     ByteSize // 0: no padding
+	MaxSize     ByteSize // 0: unlimited
+}
+
+// Modifies reports whether responses differ from the default.
+func (c *ResponseConfig) Modifies() bool {
+	return c.Compression != ResponseCompressionAuto || c.MinSize > 0 || c.MaxSize > 0
+}
+
+// Validate applies defaults and validates response configuration.
+func (c *ResponseConfig) Validate() error {
+	if c.Compression == "" {
+		c.Compression = ResponseCompressionAuto
+	}
+
+	switch c.Compression {
+	case ResponseCompressionAuto, ResponseCompressionGzip, ResponseCompressionNone:
+	default:
+		return fmt.Errorf("invalid response compression: %s (must be auto, gzip, or none)", c.Compression)
+	}
+	if c.MinSize < 0 {
+		return fmt.Errorf("invalid response min_size: %s", c.MinSize)
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("invalid response max_size: %s", c.MaxSize)
+	}
+	if c.MaxSize > 0 && c.MinSize > c.MaxSize {
+		return fmt.Errorf("invalid response min_size: %s (must be at most max_size: %s)", c.MinSize, c.MaxSize)
+	}
+
+	return nil
+}
+
+// validateExpositionFormat rejects unknown exposition formats.
+func validateExpositionFormat(f ExpositionFormat) error {
+	switch f {
+	case ExpositionText, ExpositionOpenMetrics, ExpositionProtobuf:
+		return nil
+	default:
+		return fmt.Errorf("invalid exposition format: %s (must be text, openmetrics, or protobuf)", f)
+	}
+}
+
+// ProcessMetricsConfig defines emulated process_ and go_ collector metrics.
+// One simulated process is exposed per distinct combination of target label
+// values across all metrics; without target labels a single process is
+// exposed.
+type ProcessMetricsConfig struct {
+	Enabled      bool
+	TargetLabels []string
+}
+
 // Validate applies defaults and validates Prometheus configuration.
 func (c *PrometheusExportConfig) Validate() error {
 	if !c.Enabled {
@@ -93,19 +395,339 @@ func (c *PrometheusExportConfig) Validate() error {
 	if c.Port <= 0 || c.Port > 65535 {
 		return fmt.Errorf("invalid prometheus port: %d", c.Port)
 	}
+	if strings.ContainsAny(c.Host, "[]/ ") {
+		return fmt.Errorf("invalid prometheus host: %s (must be a hostname or IP address without brackets)", c.Host)
+	}
+
+	// Detected labels fill in const labels that are not configured
+	detectors := make(map[ResourceDetector]bool, len(c.LabelDetectors))
+	for _, d := range c.LabelDetectors {
+		if d != ResourceDetectorK8s {
+			return fmt.Errorf("invalid prometheus label detector: %s (must be k8s)", d)
+		}
+		if detectors[d] {
+			return fmt.Errorf("duplicate prometheus label detector: %s", d)
+		}
+		detectors[d] = true
+		for name, value := range detectK8sLabels() {
+			if _, exists := c.ConstLabels[name]; !exists {
+				if c.ConstLabels == nil {
+					c.ConstLabels = make(map[string]string)
+				}
+				c.ConstLabels[name] = value
+			}
+		}
+	}
+
+	for name := range c.ConstLabels {
+		if !IsValidAttributeName(name) {
+			return fmt.Errorf("invalid prometheus const label name: %q", name)
+		}
+	}
+
+	if err := c.ProcessMetrics.Validate(); err != nil {
+		return err
+	}
+	for _, label := range c.ProcessMetrics.TargetLabels {
+		if _, exists := c.ConstLabels[label]; exists {
+			return fmt.Errorf("process_metrics target label %q conflicts with const label", label)
+		}
+	}
+
+	if err := c.Metadata.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Exposition.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Response.Validate(); err != nil {
+		return err
+	}
+
+	// Validate target parameters
+	seen := make(map[string]bool, len(c.TargetParams))
+	for _, param := range c.TargetParams {
+		if !IsValidAttributeName(param) {
+			return fmt.Errorf("invalid prometheus target param: %q", param)
+		}
+		if seen[param] {
+			return fmt.Errorf("duplicate prometheus target param: %q", param)
+		}
+		seen[param] = true
+		if _, exists := c.ConstLabels[param]; exists {
+			return fmt.Errorf("target param %q conflicts with const label", param)
+		}
+		if slices.Contains(c.ProcessMetrics.TargetLabels, param) {
+			return fmt.Errorf("target param %q conflicts with process_metrics target label", param)
+		}
+	}
+
+	if c.Timestamps != nil {
+		if err := c.Timestamps.Validate(); err != nil {
+			return fmt.Errorf("prometheus %w", err)
+		}
+	}
+
+	if c.Restart != nil {
+		if err := c.Restart.Validate(); err != nil {
+			return fmt.Errorf("prometheus %w", err)
+		}
+	}
+
+	if err := c.Federation.Validate(); err != nil {
+		return err
+	}
+	if c.Federation.Enabled && c.Federation.Path == c.Path {
+		return fmt.Errorf("federation path %q conflicts with prometheus path", c.Path)
+	}
+
+	paths := map[string]string{c.Path: "prometheus path"}
+	if c.Federation.Enabled {
+		paths[c.Federation.Path] = "federation path"
+	}
+	for i := range c.Paths {
+		if err := c.Paths[i].Validate(); err != nil {
+			return fmt.Errorf("prometheus paths[%d]: %w", i, err)
+		}
+		if existing, exists := paths[c.Paths[i].Path]; exists {
+			return fmt.Errorf("prometheus paths[%d]: path %q conflicts with %s", i, c.Paths[i].Path, existing)
+		}
+		paths[c.Paths[i].Path] = fmt.Sprintf("paths[%d]", i)
+	}
+
+	for i := range c.Upstreams {
+		if err := c.Upstreams[i].Validate(); err != nil {
+			return fmt.Errorf("prometheus upstream %d: %w", i, err)
+		}
+	}
+
+	// Validate scrape authentication (basic or bearer only)
+	if c.Auth != nil {
+		if c.Auth.OAuth2 != nil {
+			return fmt.Errorf("prometheus auth: oauth2 not supported (use basic or bearer)")
+		}
+		if err := c.Auth.Validate(); err != nil {
+			return fmt.Errorf("prometheus %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate applies defaults and validates upstream configuration.
+func (c *UpstreamConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url required")
+	}
+	if !strings.HasPrefix(c.URL, "http://") && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("invalid url: %s (must be http or https)", c.URL)
+	}
+
+	// Apply defaults
+	if c.Interval == 0 {
+		c.Interval = DefaultUpstreamInterval
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultUpstreamTimeout
+	}
+	if c.Replicas == 0 {
+		c.Replicas = 1
+	}
+	if c.ReplicaLabel == "" {
+		c.ReplicaLabel = DefaultUpstreamReplicaLabel
+	}
+
+	if c.Interval < 0 {
+		return fmt.Errorf("invalid interval: %s", c.Interval)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %s", c.Timeout)
+	}
+	if c.Replicas < 0 {
+		return fmt.Errorf("invalid replicas: %d", c.Replicas)
+	}
+	if !IsValidAttributeName(c.ReplicaLabel) {
+		return fmt.Errorf("invalid replica_label: %q", c.ReplicaLabel)
+	}
+	for name := range c.Labels {
+		if !IsValidAttributeName(name) {
+			return fmt.Errorf("invalid label name: %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Validate applies defaults and validates federation configuration.
+func (c *FederationConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Path == "" {
+		c.Path = DefaultFederationPath
+	}
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("invalid federation path: %q (must start with /)", c.Path)
+	}
+
+	return nil
+}
+
+// Validate validates process metrics configuration.
+func (c *ProcessMetricsConfig) Validate() error {
+	if !c.Enabled {
+		if len(c.TargetLabels) > 0 {
+			return fmt.Errorf("process_metrics.target_labels requires process_metrics.enabled")
+		}
+		return nil
+	}
+
+	seen := make(map[string]bool, len(c.TargetLabels))
+	for _, label := range c.TargetLabels {
+		if !IsValidAttributeName(label) {
+			return fmt.Errorf("invalid process_metrics target label: %q", label)
+		}
+		if seen[label] {
+			return fmt.Errorf("duplicate process_metrics target label: %q", label)
+		}
+		seen[label] = true
+	}
 
 	return nil
 }
 
 // OTELExportConfig defines OTEL push settings.
 type OTELExportConfig struct {
-	Enabled   bool
-	Transport string
-	Host      string
-	Port      int
-	Interval  IntervalConfig
-	Resource  map[string]string
-	Headers   map[string]string
+	Enabled    bool
+	Transport  string
+	Host       string
+	Port       int
+	TLS        bool   // Connect with TLS instead of plaintext
+	Path       string // HTTP request path (http transport only)
+	Interval   IntervalConfig
+	Resource   map[string]string
+	Detectors  []ResourceDetector // Detected attributes, overridden by Resource
+	Headers    map[string]string
+	StartTime  StartTimeConfig
+	DNSRefresh time.Duration // Re-resolve host periodically (0 disables)
+	Reconnect  ReconnectConfig
+
+	Temporality TemporalityConfig
+	Aggregation AggregationConfig
+
+	Compression Compression
+	Timeout     time.Duration // Per-export request timeout
+	Retry       RetryConfig
+
+	MaxDataPoints int // Data points per export request (0: unlimited)
+
+	Timestamps *TimestampConfig // Data point timestamp offset (nil: wall clock)
+	Restart    *RestartConfig   // Restart after failures (nil: fail)
+
+	Auth *AuthConfig // Client authentication (nil: none)
+
+	Payload OTELPayload // How export requests are built
+}
+
+// OTELPayload selects how OTLP export requests are built.
+type OTELPayload string
+
+const (
+	// OTELPayloadSDK observes instruments through the OTEL SDK
+	OTELPayloadSDK OTELPayload = "sdk"
+
+	// OTELPayloadDirect builds OTLP protobuf requests from the registry,
+	// bypassing SDK callbacks and aggregation
+	OTELPayloadDirect OTELPayload = "direct"
+)
+
+// Compression selects the OTLP payload compression.
+type Compression string
+
+const (
+	// CompressionNone sends uncompressed payloads
+	CompressionNone Compression = "none"
+
+	// CompressionGzip gzip-compresses payloads
+	CompressionGzip Compression = "gzip"
+)
+
+// RetryConfig defines the OTLP retry policy for failed exports.
+// Retries back off exponentially from InitialInterval up to MaxInterval
+// and give up once MaxElapsedTime has passed.
+type RetryConfig struct {
+	Enabled         bool
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	MaxElapsedTime  time.Duration
+}
+
+// ResourceDetector names a source of detected OTEL resource attributes.
+type ResourceDetector string
+
+const (
+	// ResourceDetectorHost detects host.name, host.id, and os.* attributes
+	ResourceDetectorHost ResourceDetector = "host"
+
+	// ResourceDetectorProcess detects process.* attributes of otelbox itself
+	ResourceDetectorProcess ResourceDetector = "process"
+
+	// ResourceDetectorContainer detects container.id from cgroups
+	ResourceDetectorContainer ResourceDetector = "container"
+
+	// ResourceDetectorK8s reads k8s.* attributes from downward-API env vars
+	ResourceDetectorK8s ResourceDetector = "k8s"
+)
+
+// Temporality defines how sums and histograms accumulate between exports.
+type Temporality string
+
+const (
+	// TemporalityCumulative reports totals since the start time
+	TemporalityCumulative Temporality = "cumulative"
+
+	// TemporalityDelta reports changes since the previous export
+	TemporalityDelta Temporality = "delta"
+)
+
+// TemporalityConfig selects the temporality per metric type.
+// Gauges only carry a temporality when aggregated as histograms.
+type TemporalityConfig struct {
+	Counter Temporality
+	Gauge   Temporality
+}
+
+// Aggregation selects how the OTEL SDK aggregates a metric type.
+type Aggregation string
+
+const (
+	// AggregationDefault exports counters as sums and gauges as gauges
+	AggregationDefault Aggregation = "default"
+
+	// AggregationHistogram exports observations as explicit bucket histograms
+	AggregationHistogram Aggregation = "histogram"
+
+	// AggregationExponentialHistogram exports base-2 exponential histograms
+	AggregationExponentialHistogram Aggregation = "exponential_histogram"
+
+	// AggregationDrop drops the metric type from export
+	AggregationDrop Aggregation = "drop"
+)
+
+// AggregationConfig selects the aggregation per metric type.
+type AggregationConfig struct {
+	Counter Aggregation
+	Gauge   Aggregation
+}
+
+// ReconnectConfig defines scheduled teardown and re-establishment of the
+// OTEL push connection, emulating flaky clients.
+type ReconnectConfig struct {
+	Pushes   int           // Reconnect after every N pushes (0 disables)
+	Interval time.Duration // Reconnect periodically (0 disables)
 }
 
 // IntervalConfig defines read and push intervals for OTEL.
@@ -144,6 +766,17 @@ func (c *OTELExportConfig) Validate() error {
 		}
 	}
 
+	// Apply path default, only HTTP requests have a path
+	if c.Path != "" && c.Transport != "http" {
+		return fmt.Errorf("path requires transport: http")
+	}
+	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("invalid path: %s (must start with /)", c.Path)
+	}
+	if c.Path == "" && c.Transport == "http" {
+		c.Path = DefaultOTELPath
+	}
+
 	// Apply interval defaults
 	if c.Interval.Read == 0 {
 		c.Interval.Read = DefaultOTELReadInterval
@@ -163,10 +796,277 @@ func (c *OTELExportConfig) Validate() error {
 		c.Resource["service.version"] = DefaultServiceVersion
 	}
 
+	// Validate resource detectors
+	seen := make(map[ResourceDetector]bool, len(c.Detectors))
+	for _, d := range c.Detectors {
+		switch d {
+		case ResourceDetectorHost, ResourceDetectorProcess, ResourceDetectorContainer, ResourceDetectorK8s:
+		default:
+			return fmt.Errorf("invalid resource detector: %s (must be host, process, container, or k8s)", d)
+		}
+		if seen[d] {
+			return fmt.Errorf("duplicate resource detector: %s", d)
+		}
+		seen[d] = true
+	}
+
+	// Validate DNS refresh interval
+	if c.DNSRefresh < 0 {
+		return fmt.Errorf("invalid dns_refresh: %s", c.DNSRefresh)
+	}
+
+	// Validate reconnect schedule
+	if c.Reconnect.Pushes < 0 {
+		return fmt.Errorf("invalid reconnect pushes: %d", c.Reconnect.Pushes)
+	}
+	if c.Reconnect.Interval < 0 {
+		return fmt.Errorf("invalid reconnect interval: %s", c.Reconnect.Interval)
+	}
+
+	// Validate start time semantics
+	if err := c.StartTime.Validate(); err != nil {
+		return err
+	}
+
+	// Validate data point timestamps
+	if c.Timestamps != nil {
+		if err := c.Timestamps.Validate(); err != nil {
+			return fmt.Errorf("otel %w", err)
+		}
+	}
+
+	if c.Restart != nil {
+		if err := c.Restart.Validate(); err != nil {
+			return fmt.Errorf("otel %w", err)
+		}
+	}
+
+	// Validate temporality and aggregation per metric type
+	for _, t := range []*Temporality{&c.Temporality.Counter, &c.Temporality.Gauge} {
+		if *t == "" {
+			*t = TemporalityCumulative
+		}
+		if *t != TemporalityCumulative && *t != TemporalityDelta {
+			return fmt.Errorf("invalid temporality: %s (must be cumulative or delta)", *t)
+		}
+	}
+	for _, a := range []*Aggregation{&c.Aggregation.Counter, &c.Aggregation.Gauge} {
+		if *a == "" {
+			*a = AggregationDefault
+		}
+		switch *a {
+		case AggregationDefault, AggregationHistogram, AggregationExponentialHistogram, AggregationDrop:
+		default:
+			return fmt.Errorf("invalid aggregation: %s (must be default, histogram, exponential_histogram, or drop)", *a)
+		}
+	}
+
+	// Apply compression default
+	if c.Compression == "" {
+		c.Compression = DefaultOTELCompression
+	}
+	if c.Compression != CompressionNone && c.Compression != CompressionGzip {
+		return fmt.Errorf("invalid compression: %s (must be none or gzip)", c.Compression)
+	}
+
+	// Apply timeout default
+	if c.Timeout == 0 {
+		c.Timeout = DefaultOTELTimeout
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("invalid timeout: %s", c.Timeout)
+	}
+
+	if err := c.Retry.Validate(); err != nil {
+		return err
+	}
+
+	// Validate payload size cap
+	if c.MaxDataPoints < 0 {
+		return fmt.Errorf("invalid max_data_points: %d", c.MaxDataPoints)
+	}
+
+	// Validate authentication
+	if c.Auth != nil {
+		if err := c.Auth.Validate(); err != nil {
+			return err
+		}
+		for name := range c.Headers {
+			if strings.EqualFold(name, "authorization") {
+				return fmt.Errorf("headers: authorization header conflicts with auth")
+			}
+		}
+	}
+
+	// Apply payload default
+	if c.Payload == "" {
+		c.Payload = DefaultOTELPayload
+	}
+	switch c.Payload {
+	case OTELPayloadSDK:
+	case OTELPayloadDirect:
+		// Direct payloads carry cumulative sums and gauges only
+		if c.Temporality.Counter != TemporalityCumulative {
+			return fmt.Errorf("payload direct: counter temporality must be cumulative")
+		}
+		if c.Aggregation.Counter != AggregationDefault || c.Aggregation.Gauge != AggregationDefault {
+			return fmt.Errorf("payload direct: aggregation must be default")
+		}
+	default:
+		return fmt.Errorf("invalid payload: %s (must be sdk or direct)", c.Payload)
+	}
+
 	return nil
 }
 
+// Validate applies defaults and validates retry configuration.
+func (c *RetryConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	// Apply backoff defaults
+	if c.InitialInterval == 0 {
+		c.InitialInterval = DefaultRetryInitialInterval
+	}
+	if c.MaxInterval == 0 {
+		c.MaxInterval = DefaultRetryMaxInterval
+	}
+	if c.MaxElapsedTime == 0 {
+		c.MaxElapsedTime = DefaultRetryMaxElapsedTime
+	}
+
+	if c.InitialInterval < 0 {
+		return fmt.Errorf("invalid retry initial_interval: %s", c.InitialInterval)
+	}
+	if c.MaxInterval < c.InitialInterval {
+		return fmt.Errorf("invalid retry max_interval: %s (must be at least initial_interval: %s)",
+			c.MaxInterval, c.InitialInterval)
+	}
+	if c.MaxElapsedTime < 0 {
+		return fmt.Errorf("invalid retry max_elapsed_time: %s", c.MaxElapsedTime)
+	}
+
+	return nil
+}
+
+// Addr returns the listen address of the endpoint.
+func (c *PrometheusExportConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
 // GetEndpoint returns the full endpoint address.
 func (c *OTELExportConfig) GetEndpoint() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
+
+// URL returns the HTTP export URL.
+func (c *OTELExportConfig) URL() string {
+	scheme := "http"
+	if c.TLS {
+		scheme = "https"
+	}
+	return scheme + "://" + c.GetEndpoint() + c.Path
+}
+
+// StartTimeMode defines how counter start timestamps are reported.
+type StartTimeMode string
+
+const (
+	// StartTimeModeSDK keeps the start timestamps assigned by the OTEL SDK
+	StartTimeModeSDK StartTimeMode = "sdk"
+
+	// StartTimeModeProcess reports the otelbox process start time
+	StartTimeModeProcess StartTimeMode = "process"
+
+	// StartTimeModeSeries reports the time each series was first exported
+	StartTimeModeSeries StartTimeMode = "series"
+
+	// StartTimeModeFixed reports a configured historical point in time
+	StartTimeModeFixed StartTimeMode = "fixed"
+)
+
+// StartTimeConfig defines counter start timestamp semantics for OTEL.
+// A non-zero ResetInterval moves the start time forward periodically,
+// simulating counter resets for backend reset detection.
+type StartTimeConfig struct {
+	Mode          StartTimeMode
+	Time          time.Time
+	ResetInterval time.Duration
+}
+
+// Validate applies defaults and validates start time configuration.
+func (c *StartTimeConfig) Validate() error {
+	// Apply mode default
+	if c.Mode == "" {
+		c.Mode = DefaultStartTimeMode
+	}
+
+	switch c.Mode {
+	case StartTimeModeSDK, StartTimeModeProcess, StartTimeModeSeries:
+		if !c.Time.IsZero() {
+			return fmt.Errorf("start_time.time only allowed with mode fixed")
+		}
+	case StartTimeModeFixed:
+		if c.Time.IsZero() {
+			return fmt.Errorf("start_time.time required for mode fixed")
+		}
+	default:
+		return fmt.Errorf("invalid start_time mode: %s (must be sdk, process, series, or fixed)", c.Mode)
+	}
+
+	if c.ResetInterval < 0 {
+		return fmt.Errorf("invalid start_time reset_interval: %s", c.ResetInterval)
+	}
+
+	return nil
+}
+
+// TimestampConfig defines explicit sample timestamps. Each sample is
+// stamped Offset before the wall clock, plus a random lag up to Jitter.
+// A negative Offset stamps samples in the future.
+type TimestampConfig struct {
+	Offset time.Duration
+	Jitter time.Duration
+}
+
+// Validate validates timestamp configuration.
+func (c *TimestampConfig) Validate() error {
+	if c.Jitter < 0 {
+		return fmt.Errorf("invalid timestamps jitter: %s", c.Jitter)
+	}
+	return nil
+}
+
+// RestartConfig defines how an exporter restarts after it fails, for
+// example when its port is in use. Restarts back off exponentially from
+// InitialBackoff to MaxBackoff; a run that lasts longer than MaxBackoff
+// resets the backoff and the restart count.
+type RestartConfig struct {
+	MaxRestarts    int // Consecutive restarts before giving up (0: unlimited)
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Validate applies defaults and validates restart configuration.
+func (c *RestartConfig) Validate() error {
+	if c.InitialBackoff == 0 {
+		c.InitialBackoff = DefaultRestartInitialBackoff
+	}
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = max(DefaultRestartMaxBackoff, c.InitialBackoff)
+	}
+
+	if c.MaxRestarts < 0 {
+		return fmt.Errorf("invalid restart max_restarts: %d (must be >= 0)", c.MaxRestarts)
+	}
+	if c.InitialBackoff < 0 {
+		return fmt.Errorf("invalid restart initial_backoff: %s", c.InitialBackoff)
+	}
+	if c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("invalid restart max_backoff: %s (must be at least initial_backoff: %s)",
+			c.MaxBackoff, c.InitialBackoff)
+	}
+
+	return nil
+}
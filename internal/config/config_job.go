@@ -0,0 +1,14 @@
+package config
+
+// JobConfig defines a resolved job. Job metrics are part of Config.Metrics
+// with the job labels applied and MetricConfig.Job set to the job name.
+type JobConfig struct {
+	Name   string
+	Labels map[string]string
+	Export *ExportConfig // Dedicated export (nil: top-level export)
+}
+
+// Dedicated reports whether the job exports through its own endpoint.
+func (j JobConfig) Dedicated() bool {
+	return j.Export != nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -12,12 +13,82 @@ var attributeNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
 // MetricConfig defines a fully resolved metric
 type MetricConfig struct {
-	PrometheusName string
-	OTELName       string
-	Type           MetricType
-	Description    string
-	Value          ValueConfig
-	Attributes     map[string]string
+	PrometheusName  string
+	OTELName        string
+	Type            MetricType
+	Description     string
+	Unit            string // OTEL unit (UCUM), empty if unknown
+	Value           ValueConfig
+	Attributes      map[string]string
+	Payload         PayloadConfig
+	EmitProbability float64          // Fraction of reads in which the series is emitted
+	ExportTo        []ExportProtocol // Protocols exporting the metric (empty: all)
+	Job             string           // Owning job (empty for top-level metrics)
+}
+
+// DefaultEmitProbability emits a series on every read.
+const DefaultEmitProbability = 1.0
+
+// Sparse reports whether the series is omitted from some reads.
+func (m MetricConfig) Sparse() bool {
+	return m.EmitProbability < 1
+}
+
+// ExportProtocol identifies an export protocol a metric can be restricted to.
+type ExportProtocol string
+
+const (
+	ExportProtocolPrometheus ExportProtocol = "prometheus"
+	ExportProtocolOTEL       ExportProtocol = "otel"
+)
+
+// ExportsTo reports whether the metric is exported by protocol.
+func (m MetricConfig) ExportsTo(protocol ExportProtocol) bool {
+	return len(m.ExportTo) == 0 || slices.Contains(m.ExportTo, protocol)
+}
+
+// PayloadConfig defines generated attributes with large values.
+// Used to probe wire-size limits and backend rejection behavior.
+type PayloadConfig struct {
+	Labels int
+	Size   ByteSize
+	Prefix string
+}
+
+const DefaultPayloadPrefix = "payload"
+
+// Enabled reports whether payload attributes are generated.
+func (p PayloadConfig) Enabled() bool {
+	return p.Labels > 0
+}
+
+// Key returns the attribute key of the i-th generated attribute.
+func (p PayloadConfig) Key(i int) string {
+	return fmt.Sprintf("%s_%d", p.Prefix, i)
+}
+
+// Validate applies defaults and validates payload configuration.
+func (p *PayloadConfig) Validate() error {
+	if p.Labels < 0 {
+		return fmt.Errorf("payload labels cannot be negative: %d", p.Labels)
+	}
+	if p.Labels == 0 {
+		return nil
+	}
+
+	// Apply prefix default
+	if p.Prefix == "" {
+		p.Prefix = DefaultPayloadPrefix
+	}
+	if !IsValidAttributeName(p.Prefix) {
+		return fmt.Errorf("invalid payload prefix: %q", p.Prefix)
+	}
+
+	if p.Size <= 0 {
+		return fmt.Errorf("payload size required")
+	}
+
+	return nil
 }
 
 // MetricType defines the semantic type of a metric
@@ -69,5 +140,18 @@ func (m MetricConfig) LogValue() slog.Value {
 		attrs = append(attrs, slog.String("attributes", fmt.Sprintf("[%s]", strings.Join(attrPairs, " "))))
 	}
 
+	// Summarize payload instead of logging large values
+	if m.Payload.Enabled() {
+		attrs = append(attrs, slog.String("payload", fmt.Sprintf("%dx%s", m.Payload.Labels, m.Payload.Size)))
+	}
+
+	if m.Sparse() {
+		attrs = append(attrs, slog.Float64("emit_probability", m.EmitProbability))
+	}
+
+	if len(m.ExportTo) > 0 {
+		attrs = append(attrs, slog.Any("export_to", m.ExportTo))
+	}
+
 	return slog.GroupValue(attrs...)
 }
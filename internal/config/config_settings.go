@@ -1,11 +1,412 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// drawnSeed is the seed of runs without settings.seed. It is drawn once
+// per process, so iterators, sources, and reloads of a run share it.
+var drawnSeed = sync.OnceValue(func() uint64 {
+	return uint64(time.Now().UnixNano())
+})
+
+// EffectiveSeed returns the configured seed, or the seed drawn for this
+// process if none is configured.
+func EffectiveSeed(seed *uint64) uint64 {
+	if seed != nil {
+		return *seed
+	}
+	return drawnSeed()
+}
 
 // SettingsConfig holds general application settings.
 type SettingsConfig struct {
 	Seed            *uint64
+	MaxSeries       int           // Expansion limit on total series (0: unlimited)
+	RunDuration     time.Duration // Time after which otelbox exits (0: unlimited)
+	Generation      GenerationMode
+	ResourceCheck   ResourceCheckMode // Startup check of estimated against detected resources
+	Ramp            RampConfig
+	Throttle        ThrottleConfig
+	LoadShedding    LoadSheddingConfig
+	Drain           DrainConfig
 	InternalMetrics InternalMetricsConfig
+	Panics          PanicConfig
+	Logging         LoggingConfig
+	RNG             RNGConfig
+	Debug           DebugConfig
+	Naming          NamingConfig
+	Monitor         MonitorConfig
+	Shard           ShardConfig
+	Signals         SignalsConfig
+	Receiver        ReceiverConfig
+}
+
+// DefaultMaxSeries caps expansion to protect against runaway iterators.
+const DefaultMaxSeries = 100_000
+
+// DefaultDebugPort is the listener port of the debug endpoints.
+const DefaultDebugPort = 6060
+
+// DebugConfig controls the pprof and expvar endpoints used to profile
+// otelbox itself.
+type DebugConfig struct {
+	Enabled bool
+	Port    int
+}
+
+// ReceiverConfig controls the embedded OTLP receiver, which summarizes
+// the metrics pushed to it for closed-loop verification of exports.
+type ReceiverConfig struct {
+	Enabled  bool
+	GRPCPort int
+	HTTPPort int // Also serves the summary
+}
+
+// Receiver defaults (the standard OTLP ports, so a default OTEL export
+// pushes to the receiver).
+const (
+	DefaultReceiverGRPCPort = 4317
+	DefaultReceiverHTTPPort = 4318
+)
+
+// DefaultMonitorInterval is the sampling interval of the resource monitor.
+const DefaultMonitorInterval = 5 * time.Second
+
+// MonitorConfig controls the resource monitor.
+type MonitorConfig struct {
+	Interval time.Duration // Time between resource samples
+}
+
+// SignalsConfig maps user signals to actions, for operating otelbox where
+// no admin endpoint is reachable.
+type SignalsConfig struct {
+	USR1     SignalAction
+	USR2     SignalAction
+	DumpFile string // Destination of dump actions ("": stderr)
+}
+
+// SignalAction defines what a user signal triggers.
+type SignalAction string
+
+const (
+	// SignalActionDump writes the current value of every series
+	SignalActionDump SignalAction = "dump"
+
+	// SignalActionDebug toggles debug logging for all modules
+	SignalActionDebug SignalAction = "debug"
+
+	// SignalActionReload reloads configuration as SIGHUP does
+	SignalActionReload SignalAction = "reload"
+
+	// SignalActionNone ignores the signal
+	SignalActionNone SignalAction = "none"
+)
+
+// Default signal actions.
+const (
+	DefaultSignalUSR1 = SignalActionDump
+	DefaultSignalUSR2 = SignalActionDebug
+)
+
+// Validate applies defaults and validates signal actions.
+func (c *SignalsConfig) Validate() error {
+	if c.USR1 == "" {
+		c.USR1 = DefaultSignalUSR1
+	}
+	if c.USR2 == "" {
+		c.USR2 = DefaultSignalUSR2
+	}
+	if err := c.USR1.validate("usr1"); err != nil {
+		return err
+	}
+	return c.USR2.validate("usr2")
+}
+
+// validate rejects unknown actions of the named signal.
+func (a SignalAction) validate(signal string) error {
+	switch a {
+	case SignalActionDump, SignalActionDebug, SignalActionReload, SignalActionNone:
+		return nil
+	}
+	return fmt.Errorf("invalid signals %s action: %s (must be dump, debug, reload, or none)", signal, a)
+}
+
+// ShardConfig selects the part of the expanded metrics generated by this
+// instance, so a simulation can be spread across several instances.
+type ShardConfig struct {
+	Index int // 1-based shard of this instance
+	Count int // Total shards (0: not sharded)
+}
+
+// Enabled reports whether metrics are sharded.
+func (s ShardConfig) Enabled() bool {
+	return s.Count > 0
+}
+
+// String formats the shard as index/count.
+func (s ShardConfig) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// NamingConfig controls translation between Prometheus and OTEL metric
+// names. Translation is applied during metric resolution.
+type NamingConfig struct {
+	Translate bool // Derive the undeclared protocol name of each metric
+	UTF8      bool // Keep UTF-8 characters in translated Prometheus names
+}
+
+// RNGConfig selects the random number generator for sources.
+type RNGConfig struct {
+	Type RNGType
+	File string // Sequence values (sequence only)
+}
+
+// RNGType defines the random number generator algorithm.
+type RNGType string
+
+const (
+	// RNGTypePCG uses PCG streams derived from the seed (default)
+	RNGTypePCG RNGType = "pcg"
+
+	// RNGTypeChaCha8 uses ChaCha8 streams derived from the seed
+	RNGTypeChaCha8 RNGType = "chacha8"
+
+	// RNGTypeCrypto uses the operating system's secure source (not reproducible)
+	RNGTypeCrypto RNGType = "crypto"
+
+	// RNGTypeSequence replays values from a file
+	RNGTypeSequence RNGType = "sequence"
+)
+
+// LoggingConfig controls log format, destination, levels, and sampling of
+// high-frequency debug logs.
+type LoggingConfig struct {
+	Format       LogFormat
+	Output       string              // stdout, stderr, or a file path ("": command default)
+	Level        LogLevel            // Default level ("": info)
+	Modules      map[string]LogLevel // Levels of individual modules, overriding Level
+	MaxSize      ByteSize            // Rotate the output file at this size (0: never)
+	MaxFiles     int                 // Rotated files kept besides the output file
+	SampleEvery  int                 // Log every Nth occurrence of a debug message (0 disables)
+	MaxPerSecond int                 // Log at most N occurrences of a debug message per second (0 disables)
+}
+
+// DefaultLogMaxFiles is the number of rotated log files kept.
+const DefaultLogMaxFiles = 3
+
+// Log outputs besides file paths.
+const (
+	LogOutputStdout = "stdout"
+	LogOutputStderr = "stderr"
+)
+
+// LogModules lists the modules accepted for per-module log levels: the
+// packages of otelbox, with main for the command line.
+var LogModules = []string{
+	"app", "backfill", "bench", "config", "exporter", "generator", "logging", "main",
+	"metric", "monitor", "scrape", "simulation", "sizing", "snapshot", "virtual",
+}
+
+// LogFormat defines the encoding of log records.
+type LogFormat string
+
+const (
+	// LogFormatText writes logfmt-style key=value records (default)
+	LogFormatText LogFormat = "text"
+
+	// LogFormatJSON writes one JSON object per record
+	LogFormatJSON LogFormat = "json"
+)
+
+// LogLevel is the minimum severity of logged records.
+type LogLevel string
+
+const (
+	LogLevelDebug LogLevel = "debug"
+	LogLevelInfo  LogLevel = "info"
+	LogLevelWarn  LogLevel = "warn"
+	LogLevelError LogLevel = "error"
+)
+
+// Slog returns the slog level of l.
+func (l LogLevel) Slog() slog.Level {
+	switch l {
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// validate reports whether l is a known level.
+func (l LogLevel) validate() error {
+	switch l {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+		return nil
+	}
+	return fmt.Errorf("invalid log level: %s (must be debug, info, warn, or error)", l)
+}
+
+// Validate applies defaults and validates logging configuration.
+func (l *LoggingConfig) Validate() error {
+	if l.Format == "" {
+		l.Format = LogFormatText
+	}
+	switch l.Format {
+	case LogFormatText, LogFormatJSON:
+	default:
+		return fmt.Errorf("invalid logging format: %s (must be text or json)", l.Format)
+	}
+
+	if l.Level == "" {
+		l.Level = LogLevelInfo
+	}
+	if err := l.Level.validate(); err != nil {
+		return fmt.Errorf("logging level: %w", err)
+	}
+	for module, level := range l.Modules {
+		if !slices.Contains(LogModules, module) {
+			return fmt.Errorf("invalid logging module: %s (must be one of: %s)", module, strings.Join(LogModules, ", "))
+		}
+		if err := level.validate(); err != nil {
+			return fmt.Errorf("logging modules.%s: %w", module, err)
+		}
+	}
+
+	// Rotation applies to file outputs only
+	if l.MaxSize < 0 {
+		return fmt.Errorf("invalid logging max_size: %d (must be non-negative)", l.MaxSize)
+	}
+	if l.MaxFiles < 0 {
+		return fmt.Errorf("invalid logging max_files: %d (must be non-negative)", l.MaxFiles)
+	}
+	if l.MaxSize > 0 && l.MaxFiles == 0 {
+		l.MaxFiles = DefaultLogMaxFiles
+	}
+
+	if l.SampleEvery < 0 {
+		return fmt.Errorf("invalid logging sample_every: %d (must be non-negative)", l.SampleEvery)
+	}
+	if l.MaxPerSecond < 0 {
+		return fmt.Errorf("invalid logging max_per_second: %d (must be non-negative)", l.MaxPerSecond)
+	}
+	return nil
+}
+
+// Sampled reports whether debug log sampling is active.
+func (l LoggingConfig) Sampled() bool {
+	return l.SampleEvery > 1 || l.MaxPerSecond > 0
+}
+
+// GenerationMode defines when values are generated.
+type GenerationMode string
+
+const (
+	// GenerationContinuous updates values on every clock tick (default)
+	GenerationContinuous GenerationMode = "continuous"
+
+	// GenerationLazy generates the ticks due when a value is read
+	GenerationLazy GenerationMode = "lazy"
+)
+
+// ResourceCheckMode defines how the startup resource estimate is enforced.
+type ResourceCheckMode string
+
+const (
+	// ResourceCheckWarn logs a warning when the estimate exceeds detected resources (default)
+	ResourceCheckWarn ResourceCheckMode = "warn"
+
+	// ResourceCheckRefuse refuses to start when the estimate exceeds detected resources
+	ResourceCheckRefuse ResourceCheckMode = "refuse"
+
+	// ResourceCheckOff skips the estimate
+	ResourceCheckOff ResourceCheckMode = "off"
+)
+
+// RampConfig scales source rates from a fraction of their configured
+// values up to the full values, so backends under test are not hit with
+// the full load at startup.
+type RampConfig struct {
+	Start    float64       // Fraction of the configured rates at startup
+	Duration time.Duration // Time to reach the full rates (0: no ramp)
+}
+
+// Enabled reports whether rates are ramped up.
+func (r RampConfig) Enabled() bool {
+	return r.Duration > 0
+}
+
+// ThrottleConfig caps the rate of source observations and exported data
+// points across all series, so an aggressive configuration cannot
+// overload the backend under test.
+type ThrottleConfig struct {
+	MaxObservationsPerSecond int // Source updates per second (0: unlimited)
+	MaxPointsPerSecond       int // Data points exported per second (0: unlimited)
+	Policy                   ThrottlePolicy
+}
+
+// Enabled reports whether any limit is set.
+func (t ThrottleConfig) Enabled() bool {
+	return t.MaxObservationsPerSecond > 0 || t.MaxPointsPerSecond > 0
+}
+
+// ThrottlePolicy defines the handling of observations and data points
+// exceeding a limit.
+type ThrottlePolicy string
+
+const (
+	// ThrottlePolicyDrop skips them (default)
+	ThrottlePolicyDrop ThrottlePolicy = "drop"
+
+	// ThrottlePolicyBackpressure delays them until the rate allows
+	ThrottlePolicyBackpressure ThrottlePolicy = "backpressure"
+)
+
+// LoadSheddingConfig lowers generation rates while the resource monitor
+// reports CPU saturation and restores them once utilization has dropped,
+// so an overloaded otelbox degrades instead of falling behind.
+type LoadSheddingConfig struct {
+	Enabled      bool
+	Step         float64 // Fraction of the full rate shed or restored per monitor sample
+	MinFactor    float64 // Lowest fraction of the full rate kept
+	RecoverBelow float64 // CPU utilization below which rates are restored
+}
+
+// DrainConfig controls the drain phase on shutdown: generation stops, OTLP
+// exporters push a final time, and Prometheus endpoints keep serving until
+// the final values are scraped.
+type DrainConfig struct {
+	Enabled      bool
+	ScrapeWindow time.Duration // Longest wait for a final scrape
+	Timeout      time.Duration // Bound on final OTLP pushes
+}
+
+// Drain defaults.
+const (
+	DefaultDrainScrapeWindow = 15 * time.Second // One default Prometheus scrape interval
+	DefaultDrainTimeout      = 5 * time.Second
+)
+
+// Load shedding defaults.
+const (
+	DefaultLoadSheddingStep         = 0.1
+	DefaultLoadSheddingMinFactor    = 0.1
+	DefaultLoadSheddingRecoverBelow = 0.7
+)
+
+// PanicConfig controls handling of panics recovered in series generation.
+type PanicConfig struct {
+	DisableSeries bool // Stop a series after its first panic
 }
 
 // InternalMetricsConfig controls otelbox's self-monitoring metrics.
@@ -36,11 +437,183 @@ func (s *SettingsConfig) Validate() error {
 		s.InternalMetrics.Format = NamingFormatNative
 	}
 
+	if s.MaxSeries < 0 {
+		return fmt.Errorf("max_series cannot be negative: %d", s.MaxSeries)
+	}
+	if s.RunDuration < 0 {
+		return fmt.Errorf("run_duration cannot be negative: %s", s.RunDuration)
+	}
+
+	// Validate generation mode
+	if s.Generation == "" {
+		s.Generation = GenerationContinuous
+	}
+	switch s.Generation {
+	case GenerationContinuous, GenerationLazy:
+	default:
+		return fmt.Errorf("invalid generation: %s (must be continuous or lazy)", s.Generation)
+	}
+
+	// Validate resource check
+	if s.ResourceCheck == "" {
+		s.ResourceCheck = ResourceCheckWarn
+	}
+	switch s.ResourceCheck {
+	case ResourceCheckWarn, ResourceCheckRefuse, ResourceCheckOff:
+	default:
+		return fmt.Errorf("invalid resource_check: %s (must be warn, refuse, or off)", s.ResourceCheck)
+	}
+
+	// Validate rate ramp
+	if s.Ramp.Start < 0 || s.Ramp.Start > 1 {
+		return fmt.Errorf("invalid ramp start: %g (must be between 0 and 1)", s.Ramp.Start)
+	}
+	if s.Ramp.Duration < 0 {
+		return fmt.Errorf("ramp duration cannot be negative: %s", s.Ramp.Duration)
+	}
+	if s.Ramp.Start > 0 && s.Ramp.Duration == 0 {
+		return fmt.Errorf("ramp duration required")
+	}
+
+	// Validate throttle
+	if s.Throttle.MaxObservationsPerSecond < 0 {
+		return fmt.Errorf("invalid throttle max_observations_per_second: %d (must be non-negative)", s.Throttle.MaxObservationsPerSecond)
+	}
+	if s.Throttle.MaxPointsPerSecond < 0 {
+		return fmt.Errorf("invalid throttle max_points_per_second: %d (must be non-negative)", s.Throttle.MaxPointsPerSecond)
+	}
+	if s.Throttle.Enabled() && s.Throttle.Policy == "" {
+		s.Throttle.Policy = ThrottlePolicyDrop
+	}
+	switch s.Throttle.Policy {
+	case "", ThrottlePolicyDrop, ThrottlePolicyBackpressure:
+	default:
+		return fmt.Errorf("invalid throttle policy: %s (must be drop or backpressure)", s.Throttle.Policy)
+	}
+
+	// Validate load shedding
+	if s.LoadShedding.Enabled {
+		if s.LoadShedding.Step == 0 {
+			s.LoadShedding.Step = DefaultLoadSheddingStep
+		}
+		if s.LoadShedding.MinFactor == 0 {
+			s.LoadShedding.MinFactor = DefaultLoadSheddingMinFactor
+		}
+		if s.LoadShedding.RecoverBelow == 0 {
+			s.LoadShedding.RecoverBelow = DefaultLoadSheddingRecoverBelow
+		}
+		if s.LoadShedding.Step < 0 || s.LoadShedding.Step > 1 {
+			return fmt.Errorf("invalid load_shedding step: %g (must be between 0 and 1)", s.LoadShedding.Step)
+		}
+		if s.LoadShedding.MinFactor < 0 || s.LoadShedding.MinFactor > 1 {
+			return fmt.Errorf("invalid load_shedding min_factor: %g (must be between 0 and 1)", s.LoadShedding.MinFactor)
+		}
+		// Recovery below the saturation threshold leaves a band that holds the rate
+		if s.LoadShedding.RecoverBelow < 0 || s.LoadShedding.RecoverBelow >= 0.95 {
+			return fmt.Errorf("invalid load_shedding recover_below: %g (must be between 0 and 0.95)", s.LoadShedding.RecoverBelow)
+		}
+	}
+
+	// Validate receiver
+	if s.Receiver.Enabled {
+		if s.Receiver.GRPCPort == 0 {
+			s.Receiver.GRPCPort = DefaultReceiverGRPCPort
+		}
+		if s.Receiver.HTTPPort == 0 {
+			s.Receiver.HTTPPort = DefaultReceiverHTTPPort
+		}
+		for _, port := range []int{s.Receiver.GRPCPort, s.Receiver.HTTPPort} {
+			if port < 0 || port > 65535 {
+				return fmt.Errorf("invalid receiver port: %d", port)
+			}
+		}
+		if s.Receiver.GRPCPort == s.Receiver.HTTPPort {
+			return fmt.Errorf("receiver grpc_port and http_port must differ: %d", s.Receiver.GRPCPort)
+		}
+	}
+
+	// Validate drain
+	if s.Drain.Enabled {
+		if s.Drain.ScrapeWindow == 0 {
+			s.Drain.ScrapeWindow = DefaultDrainScrapeWindow
+		}
+		if s.Drain.Timeout == 0 {
+			s.Drain.Timeout = DefaultDrainTimeout
+		}
+		if s.Drain.ScrapeWindow < 0 {
+			return fmt.Errorf("drain scrape_window cannot be negative: %s", s.Drain.ScrapeWindow)
+		}
+		if s.Drain.Timeout < 0 {
+			return fmt.Errorf("drain timeout cannot be negative: %s", s.Drain.Timeout)
+		}
+	}
+
 	// Validate format value
 	switch s.InternalMetrics.Format {
 	case NamingFormatNative, NamingFormatUnderscore, NamingFormatDot:
-		return nil
 	default:
 		return fmt.Errorf("invalid naming format: %s (must be native, underscore, or dot)", s.InternalMetrics.Format)
 	}
+
+	// Validate random number generator
+	if s.RNG.Type == "" {
+		s.RNG.Type = RNGTypePCG
+	}
+	switch s.RNG.Type {
+	case RNGTypePCG, RNGTypeChaCha8, RNGTypeCrypto:
+		if s.RNG.File != "" {
+			return fmt.Errorf("rng file only valid for sequence type")
+		}
+	case RNGTypeSequence:
+		if s.RNG.File == "" {
+			return fmt.Errorf("rng file required for sequence type")
+		}
+	default:
+		return fmt.Errorf("invalid rng type: %s (must be pcg, chacha8, crypto, or sequence)", s.RNG.Type)
+	}
+
+	// Validate logging
+	if err := s.Logging.Validate(); err != nil {
+		return err
+	}
+
+	// Validate name translation
+	if s.Naming.UTF8 && !s.Naming.Translate {
+		return fmt.Errorf("naming utf8 requires translate")
+	}
+
+	// Validate debug endpoints
+	if s.Debug.Enabled {
+		if s.Debug.Port == 0 {
+			s.Debug.Port = DefaultDebugPort
+		}
+		if s.Debug.Port < 0 || s.Debug.Port > 65535 {
+			return fmt.Errorf("invalid debug port: %d", s.Debug.Port)
+		}
+	}
+
+	// Validate shard; all instances must expand the same series
+	if s.Shard.Enabled() {
+		if s.Shard.Index < 1 || s.Shard.Index > s.Shard.Count {
+			return fmt.Errorf("invalid shard: %s (index must be between 1 and count)", s.Shard)
+		}
+		if s.Seed == nil {
+			return fmt.Errorf("shard requires a seed (settings.seed or --seed) so all instances expand the same series")
+		}
+	}
+
+	// Validate resource monitor
+	if s.Monitor.Interval == 0 {
+		s.Monitor.Interval = DefaultMonitorInterval
+	}
+	if s.Monitor.Interval < 0 {
+		return fmt.Errorf("monitor interval cannot be negative: %s", s.Monitor.Interval)
+	}
+
+	// Validate signal actions
+	if err := s.Signals.Validate(); err != nil {
+		return err
+	}
+
+	return nil
 }
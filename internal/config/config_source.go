@@ -4,11 +4,26 @@ import "log/slog"
 
 // SourceConfig defines a fully resolved source with embedded clock
 type SourceConfig struct {
-	Type     string
-	Clock    ClockConfig
-	ClockRef *string // Instance name if clock is shared
-	Min      int
-	Max      int
+	Type        string
+	Clock       ClockConfig
+	ClockRef    *string // Instance name if clock is shared
+	Min         int
+	Max         int
+	Group       string         // Latent signal shared by correlated sources
+	Correlation float64        // Correlation with other sources of the group, in [0, 1]
+	Options     map[string]any // Custom source types only
+	Replay      *ReplayConfig  // Replay sources only
+}
+
+// ReplayConfig selects the recorded series a replay source feeds.
+// The first series in File named Metric whose labels include Labels is
+// replayed, one sample per tick, multiplied by Scale.
+type ReplayConfig struct {
+	File   string
+	Metric string
+	Labels map[string]string
+	Scale  float64
+	Loop   bool // Restart at the first sample instead of holding the last
 }
 
 // LogValue implements slog.LogValuer for structured logging
@@ -24,5 +39,11 @@ func (s SourceConfig) LogValue() slog.Value {
 		slog.Int("min", s.Min),
 		slog.Int("max", s.Max),
 	}
+	if s.Group != "" {
+		attrs = append(attrs, slog.String("group", s.Group), slog.Float64("correlation", s.Correlation))
+	}
+	if s.Replay != nil {
+		attrs = append(attrs, slog.String("file", s.Replay.File), slog.String("metric", s.Replay.Metric))
+	}
 	return slog.GroupValue(attrs...)
 }
@@ -8,10 +8,65 @@ import (
 
 // ValueConfig defines a fully resolved value with embedded components.
 type ValueConfig struct {
-	Source     SourceConfig
-	SourceRef  *string // Instance name if source is shared
-	Transforms []TransformConfig
-	Reset      ResetConfig
+	Source        SourceConfig
+	SourceRef     *string // Instance name if source is shared
+	Transforms    []TransformConfig
+	Reset         ResetConfig
+	Initial       int                  // Offset added to the value from the start
+	Split         *SplitConfig         // Share of the source instance (nil: every update in full)
+	SpecialValues []SpecialValueConfig // Updates producing non-numeric samples
+}
+
+// SpecialValueType names a sample that is not a regular number.
+type SpecialValueType string
+
+const (
+	// SpecialNaN samples NaN
+	SpecialNaN SpecialValueType = "nan"
+
+	// SpecialInf samples +Inf
+	SpecialInf SpecialValueType = "inf"
+
+	// SpecialNegInf samples -Inf
+	SpecialNegInf SpecialValueType = "neg_inf"
+
+	// SpecialMissing omits the sample
+	SpecialMissing SpecialValueType = "missing"
+)
+
+// SpecialValueConfig replaces the samples of some updates of a value, drawn
+// with Probability or on every Every-th update. The value itself keeps
+// counting underneath.
+type SpecialValueConfig struct {
+	Type        SpecialValueType
+	Probability float64
+	Every       int
+}
+
+// Validate validates special value configuration.
+func (c SpecialValueConfig) Validate() error {
+	switch c.Type {
+	case SpecialNaN, SpecialInf, SpecialNegInf, SpecialMissing:
+	default:
+		return fmt.Errorf("invalid special value type: %s (must be nan, inf, neg_inf, or missing)", c.Type)
+	}
+
+	switch {
+	case c.Probability != 0 && c.Every != 0:
+		return fmt.Errorf("special value %s: probability and every are mutually exclusive", c.Type)
+	case c.Every < 0:
+		return fmt.Errorf("special value %s: every must be positive, got %d", c.Type, c.Every)
+	case c.Every == 0 && (c.Probability <= 0 || c.Probability > 1):
+		return fmt.Errorf("special value %s: probability must be in (0, 1], got %g", c.Type, c.Probability)
+	}
+
+	return nil
+}
+
+// SplitConfig distributes each update of a source instance across the
+// values splitting it, in proportion to their weights.
+type SplitConfig struct {
+	Weight int
 }
 
 // LogValue implements slog.LogValuer for structured logging
@@ -41,5 +96,21 @@ func (v ValueConfig) LogValue() slog.Value {
 		attrs = append(attrs, slog.String("reset", resetDesc))
 	}
 
+	if v.Initial != 0 {
+		attrs = append(attrs, slog.Int("initial", v.Initial))
+	}
+
+	if v.Split != nil {
+		attrs = append(attrs, slog.Int("split_weight", v.Split.Weight))
+	}
+
+	if len(v.SpecialValues) > 0 {
+		types := make([]string, len(v.SpecialValues))
+		for i, s := range v.SpecialValues {
+			types[i] = string(s.Type)
+		}
+		attrs = append(attrs, slog.String("special_values", fmt.Sprintf("[%s]", strings.Join(types, " "))))
+	}
+
 	return slog.GroupValue(attrs...)
 }
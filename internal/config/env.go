@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envPattern matches $${...} escapes and ${VAR} / ${VAR:-fallback} references
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([a-zA-Z_][a-zA-Z0-9_]*)(?::-([^}]*))?\}`)
+
+// blockScalarPattern matches a line ending in a literal or folded block
+// scalar indicator, such as "description: |" or "- >-"
+var blockScalarPattern = regexp.MustCompile(`(?:^\s*|[:-]\s+)[|>][1-9+-]*\s*$`)
+
+// expandEnv substitutes environment variable references in raw config data.
+// Supports ${VAR} and ${VAR:-fallback}; $${ produces a literal ${.
+// References in comments are left as they are.
+// Returns error listing all referenced variables that are unset without fallback.
+func expandEnv(data []byte) ([]byte, error) {
+	var missing []string
+
+	expand := func(text []byte) []byte {
+		return envPattern.ReplaceAllFunc(text, func(match []byte) []byte {
+			// Escaped reference
+			if string(match) == "$${" {
+				return []byte("${")
+			}
+
+			groups := envPattern.FindSubmatch(match)
+			name := string(groups[1])
+			hasFallback := strings.Contains(string(match), ":-")
+
+			if value, exists := os.LookupEnv(name); exists && (value != "" || !hasFallback) {
+				return []byte(value)
+			}
+			if hasFallback {
+				return groups[2]
+			}
+
+			missing = append(missing, name)
+			return match
+		})
+	}
+
+	result := make([]byte, 0, len(data))
+	var scanner commentScanner
+	for line := range bytes.Lines(data) {
+		content, comment := scanner.split(line)
+		result = append(result, expand(content)...)
+		result = append(result, comment...)
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+
+	return result, nil
+}
+
+// commentScanner splits raw YAML lines into content and comment. It tracks
+// quoted and block scalars across lines, since # inside them does not
+// start a comment.
+type commentScanner struct {
+	quote       byte // Quote of an open multi-line quoted scalar (0: none)
+	block       bool // Inside a block scalar
+	blockParent int  // Indentation of the line introducing the block scalar
+}
+
+// split returns the content of line and its comment, if any.
+func (s *commentScanner) split(line []byte) (content, comment []byte) {
+	indent := len(line) - len(bytes.TrimLeft(line, " "))
+
+	// Block scalar lines are indented below their key and hold no comments
+	if s.block {
+		if len(bytes.TrimSpace(line)) == 0 || indent > s.blockParent {
+			return line, nil
+		}
+		s.block = false
+	}
+
+	content = line
+scan:
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case s.quote == '\'':
+			// '' escapes a single quote
+			if c == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+				} else {
+					s.quote = 0
+				}
+			}
+		case s.quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				s.quote = 0
+			}
+		case c == '\'' || c == '"':
+			// Quotes only open a scalar where a value starts
+			if startsValue(line[:i]) {
+				s.quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				content, comment = line[:i], line[i:]
+				break scan
+			}
+		}
+	}
+
+	if s.quote == 0 && blockScalarPattern.Match(content) {
+		s.block = true
+		s.blockParent = indent
+	}
+	return content, comment
+}
+
+// startsValue reports whether a scalar can start after prefix.
+func startsValue(prefix []byte) bool {
+	prefix = bytes.TrimRight(prefix, " \t")
+	if len(prefix) == 0 {
+		return true
+	}
+	return strings.IndexByte(":-[{,?", prefix[len(prefix)-1]) >= 0
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyOTLPEnv fills OTEL export settings left unset in YAML from the
+// standard OTEL_EXPORTER_OTLP_* environment variables. Signal-specific
+// OTEL_EXPORTER_OTLP_METRICS_* variables take precedence over the generic
+// ones; explicit YAML takes precedence over both.
+func applyOTLPEnv(c *OTELExportConfig) error {
+	if value, name, ok := lookupOTLPEnv("PROTOCOL"); ok && c.Transport == "" {
+		switch value {
+		case "grpc":
+			c.Transport = "grpc"
+		case "http/protobuf":
+			c.Transport = "http"
+		default:
+			return fmt.Errorf("%s: unsupported protocol: %s (must be grpc or http/protobuf)", name, value)
+		}
+	}
+
+	// The endpoint is taken as a unit, only when neither host, port, nor path
+	// is set
+	if value, name, ok := lookupOTLPEnv("ENDPOINT"); ok && c.Host == "" && c.Port == 0 && c.Path == "" {
+		if err := applyOTLPEndpoint(c, value, name); err != nil {
+			return err
+		}
+	}
+
+	if value, name, ok := lookupOTLPEnv("HEADERS"); ok {
+		headers, err := parseOTLPHeaders(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		// YAML headers win per key
+		maps.Copy(headers, c.Headers)
+		c.Headers = headers
+	}
+
+	if value, name, ok := lookupOTLPEnv("TIMEOUT"); ok && c.Timeout == 0 {
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms <= 0 {
+			return fmt.Errorf("%s: invalid timeout: %q (must be positive milliseconds)", name, value)
+		}
+		c.Timeout = time.Duration(ms) * time.Millisecond
+	}
+
+	if value, _, ok := lookupOTLPEnv("COMPRESSION"); ok && c.Compression == "" {
+		c.Compression = Compression(value)
+	}
+
+	return nil
+}
+
+// applyOTLPEndpoint sets host, port, TLS, and HTTP path from an endpoint URL.
+// As in the OTEL SDK, https selects TLS, and the path of the generic
+// variable is a base the signal path is appended to, while the path of
+// OTEL_EXPORTER_OTLP_METRICS_ENDPOINT is used as given.
+func applyOTLPEndpoint(c *OTELExportConfig, value, name string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf("%s: invalid endpoint: %q", name, value)
+	}
+
+	switch u.Scheme {
+	case "http":
+	case "https":
+		c.TLS = true
+	default:
+		return fmt.Errorf("%s: unsupported endpoint scheme: %q (must be http or https)", name, u.Scheme)
+	}
+
+	c.Host = u.Hostname()
+	if port := u.Port(); port != "" {
+		if c.Port, err = strconv.Atoi(port); err != nil {
+			return fmt.Errorf("%s: invalid endpoint port: %q", name, port)
+		}
+	}
+
+	// gRPC has no request path
+	if c.Transport == "http" {
+		if name == "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT" {
+			c.Path = u.Path
+		} else {
+			c.Path = strings.TrimSuffix(u.Path, "/") + DefaultOTELPath
+		}
+	}
+	return nil
+}
+
+// lookupOTLPEnv returns the value of OTEL_EXPORTER_OTLP_METRICS_<key>,
+// falling back to OTEL_EXPORTER_OTLP_<key>. Empty values count as unset.
+func lookupOTLPEnv(key string) (value, name string, ok bool) {
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_METRICS_" + key, "OTEL_EXPORTER_OTLP_" + key} {
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value, name, true
+		}
+	}
+	return "", "", false
+}
+
+// parseOTLPHeaders parses the W3C baggage style "k1=v1,k2=v2" header list.
+// Values are URL-decoded.
+func parseOTLPHeaders(value string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for entry := range strings.SplitSeq(value, ",") {
+		key, v, found := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid header: %q (must be key=value)", entry)
+		}
+		decoded, err := url.PathUnescape(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header value for %q: %w", key, err)
+		}
+		headers[key] = decoded
+	}
+	return headers, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("OTELBOX_TEST_HOST", "collector")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{name: "reference", input: "host: ${OTELBOX_TEST_HOST}\n", want: "host: collector\n"},
+		{name: "fallback", input: "port: ${OTELBOX_TEST_UNSET:-4317}\n", want: "port: 4317\n"},
+		{name: "escape", input: "text: $${OTELBOX_TEST_HOST}\n", want: "text: ${OTELBOX_TEST_HOST}\n"},
+		{name: "unset", input: "host: ${OTELBOX_TEST_UNSET}\n", wantErr: "OTELBOX_TEST_UNSET"},
+		{
+			name:  "comment lines",
+			input: "# Set ${OTELBOX_TEST_UNSET} to override\nhost: ${OTELBOX_TEST_HOST}\n",
+			want:  "# Set ${OTELBOX_TEST_UNSET} to override\nhost: collector\n",
+		},
+		{
+			name:  "trailing comment",
+			input: "host: ${OTELBOX_TEST_HOST} # or ${OTELBOX_TEST_UNSET}\n",
+			want:  "host: collector # or ${OTELBOX_TEST_UNSET}\n",
+		},
+		{
+			name:  "hash in quoted scalar",
+			input: "description: \"a # ${OTELBOX_TEST_HOST}\"\n",
+			want:  "description: \"a # collector\"\n",
+		},
+		{
+			name:  "hash in block scalar",
+			input: "description: |\n  a # ${OTELBOX_TEST_HOST}\n# ${OTELBOX_TEST_UNSET}\nhost: x\n",
+			want:  "description: |\n  a # collector\n# ${OTELBOX_TEST_UNSET}\nhost: x\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandEnv([]byte(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("expanded = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
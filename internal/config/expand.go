@@ -1,10 +1,15 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // iteratorPattern matches {iterator_name} placeholders in strings
@@ -12,105 +17,279 @@ var iteratorPattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
 
 // Expander orchestrates iterator expansion across all configuration types.
 type Expander struct {
-	registry *IteratorRegistry
+	registry  *IteratorRegistry
+	maxSeries int          // Limit on expanded metrics (0: unlimited)
+	series    atomic.Int64 // Metrics expanded so far
 }
 
 // NewExpander creates an expander from iterator definitions.
-func NewExpander(iterators []RawIterator) (*Expander, error) {
-	if len(iterators) == 0 {
-		return &Expander{registry: nil}, nil
-	}
-
-	registry, err := buildIteratorRegistry(iterators)
+// The settings seed makes random iterators reproducible (nil draws a
+// random seed); max_series bounds iterator sizes and expanded metrics.
+// The built-in pod_ordinal iterator is available unless redefined.
+func NewExpander(iterators []RawIterator, settings RawSettingsConfig) (*Expander, error) {
+	maxSeries := settings.maxSeries()
+	registry, err := buildIteratorRegistry(iterators, settings.Seed, maxSeries)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build iterator registry: %w", err)
 	}
+	registerPodOrdinal(registry)
 
 	for _, it := range registry.iterators {
 		slog.Debug("registered iterator", "name", it.Name(), "count", it.Len())
 	}
 
-	return &Expander{registry: registry}, nil
+	return &Expander{registry: registry, maxSeries: maxSeries}, nil
 }
 
-// expandable defines operations needed for generic expansion with pointer receiver support
-type expandable[T any, PT interface {
+// expandShardSize is the minimum number of combinations expanded per worker.
+const expandShardSize = 4096
+
+// expansionTarget defines the pointer operations used during expansion
+type expansionTarget[T any] interface {
 	*T
 	FindPlaceholders() []string
 	SubstitutePlaceholders(map[string]string)
-}] interface {
+	ExpansionError() error
+	ExpansionMode() string
+	ExpansionFilters() []string
+}
+
+// expandable defines operations needed for generic expansion with pointer receiver support
+type expandable[T any, PT expansionTarget[T]] interface {
 	DeepCopy() T
 }
 
-// expand is the generic expansion implementation using two-type-parameter pattern
-func expand[T expandable[T, PT], PT interface {
-	*T
-	FindPlaceholders() []string
-	SubstitutePlaceholders(map[string]string)
-}](items []T, registry *IteratorRegistry, entityType string) ([]T, error) {
-	if registry == nil {
-		return items, nil
-	}
+// convertError carries an error returned by the convert function of
+// expandItem, as opposed to an expansion error.
+type convertError struct {
+	err error
+}
 
+func (e convertError) Error() string { return e.err.Error() }
+func (e convertError) Unwrap() error { return e.err }
+
+// expand is the generic expansion implementation using two-type-parameter pattern
+func expand[T expandable[T, PT], PT expansionTarget[T]](items []T, registry *IteratorRegistry, entityType string, admit func() error) ([]T, error) {
 	expanded := make([]T, 0)
 
 	for i, item := range items {
-		placeholders := PT(&item).FindPlaceholders()
-
-		if len(placeholders) == 0 {
-			expanded = append(expanded, item)
-			continue
-		}
-
-		iterators, err := registry.GetIterators(placeholders)
+		results, err := expandItem[T, PT](item, registry, admit, func(clone T) (T, error) { return clone, nil })
 		if err != nil {
 			return nil, fmt.Errorf("%s at index %d: %w", entityType, i, err)
 		}
+		expanded = append(expanded, results...)
+	}
 
-		gen := NewCombinationGenerator(iterators)
+	return expanded, nil
+}
 
-		if gen.Total() == 0 {
-			return nil, fmt.Errorf("%s at index %d: iterator combination produces zero results", entityType, i)
+// expandItem expands a single item and passes every expansion through
+// convert, returning the converted results in combination order. Expansions
+// are not retained, so only converted results occupy memory. Large
+// combination sets are split into shards expanded concurrently. Errors
+// returned by convert are wrapped in convertError.
+func expandItem[T expandable[T, PT], PT expansionTarget[T], R any](
+	item T,
+	registry *IteratorRegistry,
+	admit func() error,
+	convert func(T) (R, error),
+) ([]R, error) {
+	if admit == nil {
+		admit = func() error { return nil }
+	}
+
+	var placeholders []string
+	if registry != nil {
+		placeholders = PT(&item).FindPlaceholders()
+	}
+
+	if len(placeholders) == 0 {
+		if err := PT(&item).ExpansionError(); err != nil {
+			return nil, err
+		}
+		if err := admit(); err != nil {
+			return nil, err
+		}
+		result, err := convert(item)
+		if err != nil {
+			return nil, convertError{err}
 		}
+		return []R{result}, nil
+	}
+
+	// Stable iterator order keeps expansion order reproducible
+	slices.Sort(placeholders)
+
+	iterators, err := registry.GetIterators(placeholders)
+	if err != nil {
+		return nil, err
+	}
 
-		err = gen.ForEach(func(iteratorValues map[string]string) error {
-			clone := item.DeepCopy()
-			PT(&clone).SubstitutePlaceholders(iteratorValues)
-			expanded = append(expanded, clone)
-			return nil
+	gen, err := newExpansionGenerator(PT(&item).ExpansionMode(), iterators)
+	if err != nil {
+		return nil, err
+	}
+
+	total := gen.Total()
+	if total == 0 {
+		return nil, fmt.Errorf("iterator combination produces zero results")
+	}
+
+	// Validate filters once before starting workers
+	if _, err := parseFilters(PT(&item).ExpansionFilters()); err != nil {
+		return nil, err
+	}
+
+	// Contiguous index ranges keep results in combination order
+	shards := max(1, min(runtime.GOMAXPROCS(0), total/expandShardSize))
+	results := make([][]R, shards)
+	errs := make([]error, shards)
+	var failed atomic.Bool
+
+	var wg sync.WaitGroup
+	for shard := range shards {
+		wg.Go(func() {
+			// Filters cache compiled patterns, so each worker parses its own
+			filters, _ := parseFilters(PT(&item).ExpansionFilters())
+
+			for index := total * shard / shards; index < total*(shard+1)/shards; index++ {
+				// Stop early once any shard failed
+				if failed.Load() {
+					return
+				}
+				if err := expandCombination[T, PT](item, gen.Generate(index), filters, admit, convert, &results[shard]); err != nil {
+					errs[shard] = err
+					failed.Store(true)
+					return
+				}
+			}
 		})
+	}
+	wg.Wait()
+
+	// Report the first failing shard only
+	for _, err := range errs {
 		if err != nil {
-			return nil, fmt.Errorf("%s at index %d: %w", entityType, i, err)
+			return nil, err
 		}
 	}
 
+	expanded := make([]R, 0, total)
+	for _, r := range results {
+		expanded = append(expanded, r...)
+	}
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("all iterator combinations excluded by filters")
+	}
 	return expanded, nil
 }
 
+// expandCombination expands item for one iterator combination and appends
+// the converted result to results. Combinations rejected by filters are
+// skipped.
+func expandCombination[T expandable[T, PT], PT expansionTarget[T], R any](
+	item T,
+	iteratorValues map[string]string,
+	filters []*Filter,
+	admit func() error,
+	convert func(T) (R, error),
+	results *[]R,
+) error {
+	// Skip combinations rejected by filters
+	if ok, err := matchAll(filters, iteratorValues); err != nil || !ok {
+		return err
+	}
+	// Fail before materializing more than allowed
+	if err := admit(); err != nil {
+		return err
+	}
+	clone := item.DeepCopy()
+	PT(&clone).SubstitutePlaceholders(iteratorValues)
+	if err := PT(&clone).ExpansionError(); err != nil {
+		return err
+	}
+	result, err := convert(clone)
+	if err != nil {
+		return convertError{err}
+	}
+	*results = append(*results, result)
+	return nil
+}
+
 // ExpandClocks expands clock references containing iterator placeholders.
+// The expanded clocks are limited by max_series like metrics.
 func (e *Expander) ExpandClocks(clocks []RawClockReference) ([]RawClockReference, error) {
-	return expand(clocks, e.registry, "clock")
+	return expand(clocks, e.registry, "clock", e.admitLimit("clocks"))
 }
 
 // ExpandSources expands source references containing iterator placeholders.
+// The expanded sources are limited by max_series like metrics.
 func (e *Expander) ExpandSources(sources []RawSourceReference) ([]RawSourceReference, error) {
-	return expand(sources, e.registry, "source")
+	return expand(sources, e.registry, "source", e.admitLimit("sources"))
 }
 
 // ExpandValues expands value references containing iterator placeholders.
+// The expanded values are limited by max_series like metrics.
 func (e *Expander) ExpandValues(values []RawValueReference) ([]RawValueReference, error) {
-	return expand(values, e.registry, "value")
+	return expand(values, e.registry, "value", e.admitLimit("values"))
 }
 
 // ExpandMetrics expands metric configs containing iterator placeholders.
 func (e *Expander) ExpandMetrics(metrics []RawMetricConfig) ([]RawMetricConfig, error) {
-	return expand(metrics, e.registry, "metric")
+	return expand(metrics, e.registry, "metric", e.admitSeries)
+}
+
+// expandMetric expands a single metric config and converts every
+// expansion, so expanded configs are released as soon as they are converted.
+// Errors returned by convert stay wrapped in convertError.
+func expandMetric[R any](e *Expander, metric RawMetricConfig, index int, convert func(RawMetricConfig) (R, error)) ([]R, error) {
+	results, err := expandItem(metric, e.registry, e.admitSeries, convert)
+	if err != nil && !errors.As(err, new(convertError)) {
+		return nil, fmt.Errorf("metric at index %d: %w", index, err)
+	}
+	return results, err
+}
+
+// expansionFailure passes errors returned by convert through and prefixes
+// expansion errors with msg.
+func expansionFailure(err error, msg string) error {
+	var converted convertError
+	if errors.As(err, &converted) {
+		return converted.err
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// admitSeries counts an expanded metric against the series limit.
+// Safe for concurrent use.
+func (e *Expander) admitSeries() error {
+	if n := e.series.Add(1); e.maxSeries > 0 && n > int64(e.maxSeries) {
+		return fmt.Errorf("expansion exceeds %d series (settings.max_series): check iterator ranges, raise the limit, or pass --force",
+			e.maxSeries)
+	}
+	return nil
+}
+
+// admitLimit returns an admit function counting expanded definitions of
+// kind against max_series. Series use at most one clock, source, and value
+// each, so more definitions of a kind than series are never needed.
+func (e *Expander) admitLimit(kind string) func() error {
+	var count atomic.Int64
+	return func() error {
+		if n := count.Add(1); e.maxSeries > 0 && n > int64(e.maxSeries) {
+			return fmt.Errorf("expansion exceeds %d %s (settings.max_series): check iterator ranges, raise the limit, or pass --force",
+				e.maxSeries, kind)
+		}
+		return nil
+	}
 }
 
 // Expand performs iterator expansion on raw configuration.
 // Mutates raw config in place by replacing arrays with expanded versions.
+// Metrics are expanded lazily during Resolve, so expanded definitions are
+// converted one by one instead of being materialized together.
 func Expand(raw *RawConfig) error {
-	expander, err := NewExpander(raw.Iterators)
+	expander, err := NewExpander(raw.Iterators, raw.Settings)
 	if err != nil {
 		return err
 	}
@@ -151,11 +330,8 @@ func Expand(raw *RawConfig) error {
 		return fmt.Errorf("failed to expand instance values: %w", err)
 	}
 
-	// Expand metrics
-	raw.Metrics, err = expander.ExpandMetrics(raw.Metrics)
-	if err != nil {
-		return fmt.Errorf("failed to expand metrics: %w", err)
-	}
+	// Defer metric and job metric expansion to resolution
+	raw.expander = expander
 
 	// Clear consumed iterators
 	raw.Iterators = nil
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"errors"
+	"slices"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// deferredNode holds the YAML of a block that cannot be decoded before
+// expansion because placeholders stand in for non-string values, like
+// max: "{shard}*10". The block is decoded once placeholders are substituted.
+type deferredNode struct {
+	node *yaml.Node
+	err  error // Decode error, reported if the block is never decoded
+}
+
+// decodeExpandable decodes a block with unmarshal into out. Blocks failing
+// to decode only in values with placeholders are returned for decoding
+// after substitution instead of failing.
+func decodeExpandable(unmarshal func(any) error, out any) (*deferredNode, error) {
+	err := unmarshal(out)
+	if err == nil {
+		return nil, nil
+	}
+
+	var typeErr *yaml.TypeError
+	var capture nodeCapture
+	if !errors.As(err, &typeErr) || unmarshal(&capture) != nil {
+		return nil, err
+	}
+
+	lines := make(map[int]bool)
+	for _, n := range placeholderScalars(capture.node) {
+		lines[n.Line] = true
+	}
+	for _, e := range typeErr.Errors {
+		if !lines[e.Line] {
+			return nil, err
+		}
+	}
+	return &deferredNode{node: capture.node, err: err}, nil
+}
+
+// nodeCapture keeps the node it is decoded from.
+type nodeCapture struct {
+	node *yaml.Node
+}
+
+// UnmarshalYAML keeps node instead of decoding it.
+func (c *nodeCapture) UnmarshalYAML(node *yaml.Node) error {
+	c.node = node
+	return nil
+}
+
+// placeholders returns the distinct placeholders in the block.
+func (d *deferredNode) placeholders() []string {
+	var names []string
+	for _, n := range placeholderScalars(d.node) {
+		for _, name := range extractPlaceholderNames(n.Value) {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// decode substitutes placeholders and decodes the block into out. Values
+// that fail to decode are evaluated as arithmetic, so "{shard}*10" becomes
+// a number. On failure, the substituted block is returned for reporting.
+func (d *deferredNode) decode(iteratorValues map[string]string, out any) *deferredNode {
+	var substituted []*yaml.Node
+	node := substituteNode(d.node, iteratorValues, true, &substituted)
+	if err := node.Decode(out); err == nil {
+		return nil
+	}
+
+	// Retry with substituted values retyped and evaluated
+	for _, n := range substituted {
+		n.Tag = ""
+		n.Style = 0
+		if value, ok := evalArithmetic(n.Value); ok {
+			n.Value = value
+		}
+	}
+	// Unknown fields were rejected before substitution, and substituted
+	// nodes keep their lines for errors
+	if err := node.Decode(out); err != nil {
+		return &deferredNode{node: node, err: err}
+	}
+	return nil
+}
+
+// error reports a block that was not decoded.
+func (d *deferredNode) error() error {
+	if d == nil {
+		return nil
+	}
+	return d.err
+}
+
+// placeholderScalars returns the keys and values of a block containing
+// placeholders. Expansion settings of the block itself are skipped.
+func placeholderScalars(node *yaml.Node) []*yaml.Node {
+	var scalars []*yaml.Node
+	var walk func(n *yaml.Node, top bool)
+	walk = func(n *yaml.Node, top bool) {
+		switch n.Kind {
+		case yaml.ScalarNode:
+			if iteratorPattern.MatchString(n.Value) {
+				scalars = append(scalars, n)
+			}
+		case yaml.AliasNode:
+			walk(n.Alias, false)
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if top && isExpansionKey(n.Content[i].Value) {
+					continue
+				}
+				walk(n.Content[i], false)
+				walk(n.Content[i+1], false)
+			}
+		default:
+			for _, child := range n.Content {
+				walk(child, false)
+			}
+		}
+	}
+	walk(node, true)
+	return scalars
+}
+
+// substituteNode returns a copy of node with placeholders substituted and
+// aliases resolved. Changed scalars are appended to substituted.
+func substituteNode(node *yaml.Node, iteratorValues map[string]string, top bool, substituted *[]*yaml.Node) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		return substituteNode(node.Alias, iteratorValues, top, substituted)
+	}
+
+	clone := *node
+	clone.Anchor = ""
+	clone.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		// Expansion settings keep their placeholders for filtering
+		if top && node.Kind == yaml.MappingNode && i%2 == 1 && isExpansionKey(node.Content[i-1].Value) {
+			clone.Content[i] = child
+			continue
+		}
+		clone.Content[i] = substituteNode(child, iteratorValues, false, substituted)
+	}
+
+	if clone.Kind == yaml.ScalarNode {
+		if value := substitutePlaceholders(clone.Value, iteratorValues); value != clone.Value {
+			clone.Value = value
+			*substituted = append(*substituted, &clone)
+		}
+	}
+	return &clone
+}
+
+// isExpansionKey reports whether key configures expansion of its block.
+func isExpansionKey(key string) bool {
+	return key == "expand" || key == "filter"
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func rangeIterator(name string, start, end int) RawIterator {
+	return RawIterator{Name: name, Type: "range", Start: &start, End: &end}
+}
+
+func maxSeriesSettings(limit int) RawSettingsConfig {
+	return RawSettingsConfig{MaxSeries: &limit}
+}
+
+func TestExpandSourcesMaxSeries(t *testing.T) {
+	sourceType := "random_int"
+	sources := []RawSourceReference{{Name: "src_{i}_{j}", Type: &sourceType}}
+
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr bool
+	}{
+		{name: "within limit", limit: 9},
+		{name: "over limit", limit: 8, wantErr: true},
+		{name: "unlimited", limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each iterator fits, only the 3x3 expanded sources can exceed the limit
+			iterators := []RawIterator{rangeIterator("i", 1, 3), rangeIterator("j", 1, 3)}
+			e, err := NewExpander(iterators, maxSeriesSettings(tt.limit))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expanded, err := e.ExpandSources(sources)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "exceeds 8 sources") {
+					t.Fatalf("error = %v, want source limit error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(expanded) != 9 {
+				t.Errorf("expanded = %d sources, want 9", len(expanded))
+			}
+		})
+	}
+}
+
+func TestExpandKindsLimitedSeparately(t *testing.T) {
+	e, err := NewExpander([]RawIterator{rangeIterator("i", 1, 3)}, maxSeriesSettings(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourceType := "random_int"
+	clockType := "periodic"
+	if _, err := e.ExpandClocks([]RawClockReference{{Name: "clk_{i}", Type: &clockType}}); err != nil {
+		t.Fatalf("clocks: %v", err)
+	}
+	if _, err := e.ExpandSources([]RawSourceReference{{Name: "src_{i}", Type: &sourceType}}); err != nil {
+		t.Fatalf("sources: %v", err)
+	}
+}
+
+func TestIteratorMaxSeries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts.txt")
+	if err := os.WriteFile(path, []byte("a\nb\n# comment\n\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		iterator RawIterator
+		limit    int
+		wantErr  string
+	}{
+		{name: "range over limit", iterator: rangeIterator("i", 1, 10), limit: 9, wantErr: "generates 10 values"},
+		{name: "file within limit", iterator: RawIterator{Name: "host", Type: "file", Path: path}, limit: 3},
+		{name: "file over limit", iterator: RawIterator{Name: "host", Type: "file", Path: path}, limit: 2, wantErr: "more than 2 values"},
+		{name: "random over limit", iterator: RawIterator{Name: "id", Type: "random", Generate: "uuid", Count: 1000000}, limit: 10, wantErr: "generates 1000000 values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildIteratorRegistry([]RawIterator{tt.iterator}, nil, tt.limit)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpandMetricsMaxSeries(t *testing.T) {
+	e, err := NewExpander([]RawIterator{rangeIterator("i", 1, 3), rangeIterator("j", 1, 3)}, maxSeriesSettings(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metrics := []RawMetricConfig{{Attributes: map[string]string{"i": "{i}", "j": "{j}"}}}
+	if _, err := e.ExpandMetrics(metrics); err == nil || !strings.Contains(err.Error(), "exceeds 8 series") {
+		t.Fatalf("error = %v, want series limit error", err)
+	}
+}
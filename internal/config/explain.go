@@ -0,0 +1,658 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// SeriesCounts returns the number of series each raw metric definition
+// expands to. Must be called before Expand.
+func SeriesCounts(raw *RawConfig) ([]int, error) {
+	expander, err := NewExpander(raw.Iterators, raw.Settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Top-level definitions first, then job definitions in order
+	definitions := slices.Clone(raw.Metrics)
+	for _, job := range raw.Jobs {
+		definitions = append(definitions, job.Metrics...)
+	}
+
+	counts := make([]int, len(definitions))
+	for i, metric := range definitions {
+		expanded, err := expander.ExpandMetrics([]RawMetricConfig{metric})
+		if err != nil {
+			return nil, fmt.Errorf("metric %d: %w", i, err)
+		}
+		counts[i] = len(expanded)
+	}
+
+	return counts, nil
+}
+
+// Explain renders a resolved configuration as a YAML config document.
+// Templates are inlined, iterators expanded, and defaults applied; shared
+// clock and source instances stay referenced by name. Series counts per
+// metric definition are emitted as comments.
+func Explain(cfg *Config, seriesCounts []int) ([]byte, error) {
+	raw := RawConfig{
+		Version: SchemaVersion,
+		Instances: RawInstances{
+			Clocks:  explainClockInstances(cfg.Instances.Clocks),
+			Sources: explainSourceInstances(cfg.Instances.Sources),
+		},
+		Export:   explainExport(cfg.Export),
+		Settings: explainSettings(cfg.Settings),
+		Chaos:    explainChaos(cfg.Chaos),
+	}
+	for _, job := range cfg.Jobs {
+		rawJob := RawJobConfig{Name: job.Name, Labels: job.Labels}
+		if job.Dedicated() {
+			export := explainExport(*job.Export)
+			rawJob.Export = &export
+		}
+		raw.Jobs = append(raw.Jobs, rawJob)
+	}
+	for _, metric := range cfg.Metrics {
+		if metric.Job == "" {
+			raw.Metrics = append(raw.Metrics, explainMetric(metric))
+			continue
+		}
+		for i := range raw.Jobs {
+			if raw.Jobs[i].Name == metric.Job {
+				raw.Jobs[i].Metrics = append(raw.Jobs[i].Metrics, explainMetric(metric))
+			}
+		}
+	}
+
+	var root yaml.Node
+	if err := root.Encode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	// Drop sections that are empty after resolution
+	removeEmptyKeys(&root, "templates", "instances")
+
+	// Annotate metric definitions with series counts
+	total := 0
+	for _, count := range seriesCounts {
+		total += count
+	}
+	// Metric nodes in document order match the resolved metric order
+	var metricNodes []*yaml.Node
+	if metrics := mappingValue(&root, "metrics"); metrics != nil {
+		metricNodes = append(metricNodes, metrics.Content...)
+	}
+	if jobs := mappingValue(&root, "jobs"); jobs != nil {
+		for _, job := range jobs.Content {
+			if metrics := mappingValue(job, "metrics"); metrics != nil {
+				metricNodes = append(metricNodes, metrics.Content...)
+			}
+		}
+	}
+	if cfg.Settings.Shard.Enabled() {
+		// Definitions lose series to other shards; only totals are meaningful
+		root.HeadComment = fmt.Sprintf("Resolved configuration: %d metric definitions, shard %s owns %d of %d series",
+			len(seriesCounts), cfg.Settings.Shard, len(cfg.Metrics), total)
+	} else {
+		offset := 0
+		for _, count := range seriesCounts {
+			if count > 0 && offset < len(metricNodes) {
+				name := cfg.Metrics[offset].PrometheusName
+				metricNodes[offset].HeadComment = fmt.Sprintf("%s: %d series", name, count)
+			}
+			offset += count
+		}
+		root.HeadComment = fmt.Sprintf("Resolved configuration: %d metric definitions, %d series",
+			len(seriesCounts), total)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// explainClockInstances converts resolved clock instances to raw form.
+func explainClockInstances(clocks map[string]ClockConfig) []RawClockReference {
+	var result []RawClockReference
+	for _, name := range slices.Sorted(maps.Keys(clocks)) {
+		clock := explainClock(clocks[name])
+		clock.Name = name
+		result = append(result, *clock)
+	}
+	return result
+}
+
+// explainSourceInstances converts resolved source instances to raw form.
+func explainSourceInstances(sources map[string]SourceConfig) []RawSourceReference {
+	var result []RawSourceReference
+	for _, name := range slices.Sorted(maps.Keys(sources)) {
+		source := explainSource(sources[name], nil)
+		source.Name = name
+		result = append(result, *source)
+	}
+	return result
+}
+
+// explainClock converts a resolved clock to inline raw form.
+func explainClock(c ClockConfig) *RawClockReference {
+	clockType := c.Type
+	return &RawClockReference{Type: &clockType, Interval: c.Interval}
+}
+
+// explainSource converts a resolved source to raw form.
+// Returns an instance reference if ref is set.
+func explainSource(s SourceConfig, ref *string) *RawSourceReference {
+	if ref != nil {
+		return &RawSourceReference{Instance: *ref}
+	}
+
+	sourceType, min, max := s.Type, s.Min, s.Max
+	result := &RawSourceReference{Type: &sourceType, Min: &min, Max: &max, Group: s.Group, Options: s.Options}
+	if s.Group != "" {
+		correlation := s.Correlation
+		result.Correlation = &correlation
+	}
+	if s.Replay != nil {
+		scale := s.Replay.Scale
+		result.Replay = &RawReplayConfig{
+			File:   s.Replay.File,
+			Metric: s.Replay.Metric,
+			Labels: s.Replay.Labels,
+			Scale:  &scale,
+			Loop:   s.Replay.Loop,
+		}
+	}
+	if s.ClockRef != nil {
+		result.Clock = &RawClockReference{Instance: *s.ClockRef}
+	} else {
+		result.Clock = explainClock(s.Clock)
+	}
+	return result
+}
+
+// explainMetric converts a resolved metric to raw form with inline value.
+func explainMetric(m MetricConfig) RawMetricConfig {
+	result := RawMetricConfig{
+		Type:        string(m.Type),
+		Description: m.Description,
+		Unit:        m.Unit,
+		Value: RawValueReference{
+			Source:     explainSource(m.Value.Source, m.Value.SourceRef),
+			Transforms: m.Value.Transforms,
+			Reset:      m.Value.Reset,
+		},
+		Attributes: m.Attributes,
+	}
+
+	if m.PrometheusName == m.OTELName {
+		result.Name = RawMetricNameConfig{Simple: m.PrometheusName}
+	} else {
+		result.Name = RawMetricNameConfig{Prometheus: m.PrometheusName, OTEL: m.OTELName}
+	}
+
+	if m.Value.Initial != 0 {
+		initial := m.Value.Initial
+		result.Value.Initial = &initial
+	}
+
+	for _, s := range m.Value.SpecialValues {
+		result.Value.SpecialValues = append(result.Value.SpecialValues, RawSpecialValueConfig{
+			Type:        string(s.Type),
+			Probability: s.Probability,
+			Every:       s.Every,
+		})
+	}
+
+	if m.Value.Split != nil {
+		result.Value.Split = &RawSplitConfig{Weight: m.Value.Split.Weight}
+	}
+
+	if m.Sparse() {
+		p := m.EmitProbability
+		result.EmitProbability = &p
+	}
+
+	for _, protocol := range m.ExportTo {
+		result.ExportTo = append(result.ExportTo, string(protocol))
+	}
+
+	if m.Payload.Enabled() {
+		result.Payload = &RawPayloadConfig{
+			Labels: m.Payload.Labels,
+			Size:   m.Payload.Size,
+			Prefix: m.Payload.Prefix,
+		}
+	}
+
+	return result
+}
+
+// explainExport converts resolved export configuration to raw form.
+func explainExport(e ExportConfig) RawExportConfig {
+	var result RawExportConfig
+
+	if e.Prometheus != nil {
+		result.Prometheus = &RawPrometheusExportConfig{
+			Enabled:          e.Prometheus.Enabled,
+			Host:             e.Prometheus.Host,
+			Port:             e.Prometheus.Port,
+			Path:             e.Prometheus.Path,
+			SocketActivation: e.Prometheus.SocketActivation,
+			ConstLabels:      e.Prometheus.ConstLabels,
+			LabelDetectors:   explainDetectors(e.Prometheus.LabelDetectors),
+			Auth:             explainAuth(e.Prometheus.Auth),
+			TargetParams:     e.Prometheus.TargetParams,
+			Exposition: RawExpositionConfig{
+				Force: string(e.Prometheus.Exposition.Force),
+			},
+		}
+		for _, f := range e.Prometheus.Exposition.Formats {
+			result.Prometheus.Exposition.Formats = append(result.Prometheus.Exposition.Formats, string(f))
+		}
+		result.Prometheus.Timestamps = explainTimestamps(e.Prometheus.Timestamps)
+		result.Prometheus.Metadata = explainMetadata(e.Prometheus.Metadata)
+		if !e.Prometheus.LandingPage {
+			result.Prometheus.LandingPage = new(bool)
+		}
+		if r := e.Prometheus.Response; r.Modifies() {
+			result.Prometheus.Response = &RawResponseConfig{
+				MinSize: r.MinSize,
+				MaxSize: r.MaxSize,
+			}
+			if r.Compression != ResponseCompressionAuto {
+				result.Prometheus.Response.Compression = string(r.Compression)
+			}
+		}
+		result.Prometheus.Restart = explainRestart(e.Prometheus.Restart)
+		for _, u := range e.Prometheus.Upstreams {
+			result.Prometheus.Upstreams = append(result.Prometheus.Upstreams, RawUpstreamConfig{
+				URL:          u.URL,
+				Interval:     u.Interval,
+				Timeout:      u.Timeout,
+				Replicas:     u.Replicas,
+				ReplicaLabel: u.ReplicaLabel,
+				Labels:       u.Labels,
+			})
+		}
+		if f := e.Prometheus.Federation; f.Enabled {
+			result.Prometheus.Federation = &RawFederationConfig{
+				Enabled: true,
+				Path:    f.Path,
+			}
+		}
+		for _, p := range e.Prometheus.Paths {
+			path := RawScrapePathConfig{Path: p.Path, Match: p.Match}
+			if p.Chaos != nil {
+				chaos := explainChaos(*p.Chaos)
+				path.Chaos = &chaos
+			}
+			result.Prometheus.Paths = append(result.Prometheus.Paths, path)
+		}
+		if pm := e.Prometheus.ProcessMetrics; pm.Enabled {
+			result.Prometheus.ProcessMetrics = &RawProcessMetricsConfig{
+				Enabled:      true,
+				TargetLabels: pm.TargetLabels,
+			}
+		}
+	}
+
+	if e.OTEL != nil {
+		retryEnabled := e.OTEL.Retry.Enabled
+		result.OTEL = &RawOTELExportConfig{
+			Enabled:   e.OTEL.Enabled,
+			Transport: e.OTEL.Transport,
+			Host:      e.OTEL.Host,
+			Port:      e.OTEL.Port,
+			TLS:       e.OTEL.TLS,
+			Path:      e.OTEL.Path,
+			Interval: RawIntervalConfig{
+				Read: e.OTEL.Interval.Read,
+				Push: e.OTEL.Interval.Push,
+			},
+			Resource:  e.OTEL.Resource,
+			Detectors: explainDetectors(e.OTEL.Detectors),
+			Headers:   e.OTEL.Headers,
+			StartTime: RawStartTimeConfig{
+				Mode:          string(e.OTEL.StartTime.Mode),
+				Time:          e.OTEL.StartTime.Time,
+				ResetInterval: e.OTEL.StartTime.ResetInterval,
+			},
+			DNSRefresh: e.OTEL.DNSRefresh,
+			Reconnect: RawReconnectConfig{
+				Pushes:   e.OTEL.Reconnect.Pushes,
+				Interval: e.OTEL.Reconnect.Interval,
+			},
+			Temporality: RawTemporalityConfig{
+				Counter: string(e.OTEL.Temporality.Counter),
+				Gauge:   string(e.OTEL.Temporality.Gauge),
+			},
+			Aggregation: RawAggregationConfig{
+				Counter: string(e.OTEL.Aggregation.Counter),
+				Gauge:   string(e.OTEL.Aggregation.Gauge),
+			},
+			Compression: string(e.OTEL.Compression),
+			Timeout:     e.OTEL.Timeout,
+			Retry: RawRetryConfig{
+				Enabled:         &retryEnabled,
+				InitialInterval: e.OTEL.Retry.InitialInterval,
+				MaxInterval:     e.OTEL.Retry.MaxInterval,
+				MaxElapsedTime:  e.OTEL.Retry.MaxElapsedTime,
+			},
+			MaxDataPoints: e.OTEL.MaxDataPoints,
+			Timestamps:    explainTimestamps(e.OTEL.Timestamps),
+			Auth:          explainAuth(e.OTEL.Auth),
+			Payload:       string(e.OTEL.Payload),
+			Restart:       explainRestart(e.OTEL.Restart),
+		}
+	}
+
+	if e.Custom != nil {
+		result.Custom = &RawCustomExportConfig{
+			Name:    e.Custom.Name,
+			Options: e.Custom.Options,
+			Restart: explainRestart(e.Custom.Restart),
+		}
+	}
+
+	return result
+}
+
+// explainDetectors converts resolved resource detectors to raw names.
+func explainDetectors(detectors []ResourceDetector) []string {
+	if detectors == nil {
+		return nil
+	}
+	names := make([]string, len(detectors))
+	for i, d := range detectors {
+		names[i] = string(d)
+	}
+	return names
+}
+
+// explainTimestamps converts resolved timestamp config to raw form (handles nil).
+func explainTimestamps(t *TimestampConfig) *RawTimestampConfig {
+	if t == nil {
+		return nil
+	}
+	return &RawTimestampConfig{Offset: t.Offset, Jitter: t.Jitter}
+}
+
+// explainRestart converts resolved restart config to raw form (handles nil).
+func explainRestart(r *RestartConfig) *RawRestartConfig {
+	if r == nil {
+		return nil
+	}
+	return &RawRestartConfig{
+		MaxRestarts:    r.MaxRestarts,
+		InitialBackoff: r.InitialBackoff,
+		MaxBackoff:     r.MaxBackoff,
+	}
+}
+
+// explainMetadata converts resolved metadata config to raw form (nil if default).
+func explainMetadata(m MetadataConfig) *RawMetadataConfig {
+	if !m.Created && !m.Rewrites() {
+		return nil
+	}
+	result := &RawMetadataConfig{
+		Created: m.Created,
+		Unit:    m.Unit,
+	}
+	if m.OmitHelp {
+		result.Help = new(bool)
+	}
+	if m.OmitType {
+		result.Type = new(bool)
+	}
+	for _, d := range m.Malformed {
+		result.Malformed = append(result.Malformed, string(d))
+	}
+	return result
+}
+
+// explainAuth converts resolved auth config to raw form (handles nil).
+// Inline secrets are redacted.
+func explainAuth(a *AuthConfig) *RawAuthConfig {
+	if a == nil {
+		return nil
+	}
+
+	result := &RawAuthConfig{}
+	if a.Basic != nil {
+		result.Basic = &RawBasicAuthConfig{
+			Username:     a.Basic.Username,
+			Password:     redact(a.Basic.Password),
+			PasswordFile: a.Basic.PasswordFile,
+		}
+	}
+	if a.Bearer != nil {
+		result.Bearer = &RawBearerAuthConfig{
+			Token:     redact(a.Bearer.Token),
+			TokenFile: a.Bearer.TokenFile,
+		}
+	}
+	if a.OAuth2 != nil {
+		result.OAuth2 = &RawOAuth2Config{
+			ClientID:         a.OAuth2.ClientID,
+			ClientSecret:     redact(a.OAuth2.ClientSecret),
+			ClientSecretFile: a.OAuth2.ClientSecretFile,
+			TokenURL:         a.OAuth2.TokenURL,
+			Scopes:           a.OAuth2.Scopes,
+			EndpointParams:   a.OAuth2.EndpointParams,
+		}
+	}
+	return result
+}
+
+// redact hides a configured secret in explain output.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "<redacted>"
+}
+
+// explainChaos converts resolved chaos config to raw form.
+func explainChaos(c ChaosConfig) RawChaosConfig {
+	result := RawChaosConfig{
+		ScrapeDelay: explainDelay(c.ScrapeDelay),
+	}
+	for _, e := range c.ScrapeErrors {
+		result.ScrapeErrors = append(result.ScrapeErrors, RawScrapeErrorConfig{
+			Type:        string(e.Type),
+			Probability: e.Probability,
+			Status:      e.Status,
+		})
+	}
+	for _, f := range c.OTLPFaults {
+		result.OTLPFaults = append(result.OTLPFaults, RawOTLPFaultConfig{
+			Type:        string(f.Type),
+			Probability: f.Probability,
+			Delay:       explainDelay(f.Delay),
+		})
+	}
+	for _, v := range c.ProtocolViolations {
+		result.ProtocolViolations = append(result.ProtocolViolations, RawProtocolViolationConfig{
+			Type:        string(v.Type),
+			Probability: v.Probability,
+		})
+	}
+	return result
+}
+
+// explainDelay converts resolved delay config to raw form (handles nil).
+func explainDelay(d *DelayConfig) *RawDelayConfig {
+	if d == nil {
+		return nil
+	}
+	return &RawDelayConfig{
+		Distribution: string(d.Distribution),
+		Delay:        d.Delay,
+		Min:          d.Min,
+		Max:          d.Max,
+		Mean:         d.Mean,
+		StdDev:       d.StdDev,
+	}
+}
+
+// explainSettings converts resolved settings to raw form.
+func explainSettings(s SettingsConfig) RawSettingsConfig {
+	maxSeries := s.MaxSeries
+	var generation string
+	if s.Generation != GenerationContinuous {
+		generation = string(s.Generation)
+	}
+	var monitor RawMonitorConfig
+	if s.Monitor.Interval != DefaultMonitorInterval {
+		monitor.Interval = s.Monitor.Interval
+	}
+	signals := RawSignalsConfig{DumpFile: s.Signals.DumpFile}
+	if s.Signals.USR1 != DefaultSignalUSR1 {
+		signals.USR1 = string(s.Signals.USR1)
+	}
+	if s.Signals.USR2 != DefaultSignalUSR2 {
+		signals.USR2 = string(s.Signals.USR2)
+	}
+	var resourceCheck string
+	if s.ResourceCheck != ResourceCheckWarn {
+		resourceCheck = string(s.ResourceCheck)
+	}
+	var ramp *RawRampConfig
+	if s.Ramp.Enabled() {
+		ramp = &RawRampConfig{
+			Start:    s.Ramp.Start,
+			Duration: s.Ramp.Duration,
+		}
+	}
+	var throttle *RawThrottleConfig
+	if s.Throttle.Enabled() {
+		throttle = &RawThrottleConfig{
+			MaxObservationsPerSecond: s.Throttle.MaxObservationsPerSecond,
+			MaxPointsPerSecond:       s.Throttle.MaxPointsPerSecond,
+			Policy:                   string(s.Throttle.Policy),
+		}
+	}
+	var loadShedding *RawLoadSheddingConfig
+	if s.LoadShedding.Enabled {
+		loadShedding = &RawLoadSheddingConfig{
+			Step:         s.LoadShedding.Step,
+			MinFactor:    s.LoadShedding.MinFactor,
+			RecoverBelow: s.LoadShedding.RecoverBelow,
+		}
+	}
+	var drain *RawDrainConfig
+	if s.Drain.Enabled {
+		drain = &RawDrainConfig{
+			ScrapeWindow: s.Drain.ScrapeWindow,
+			Timeout:      s.Drain.Timeout,
+		}
+	}
+	var receiver *RawReceiverConfig
+	if s.Receiver.Enabled {
+		receiver = &RawReceiverConfig{
+			GRPCPort: s.Receiver.GRPCPort,
+			HTTPPort: s.Receiver.HTTPPort,
+		}
+	}
+	var shard *RawShardConfig
+	if s.Shard.Enabled() {
+		shard = &RawShardConfig{Index: s.Shard.Index, Count: s.Shard.Count}
+	}
+	return RawSettingsConfig{
+		Seed:          s.Seed,
+		MaxSeries:     &maxSeries,
+		RunDuration:   s.RunDuration,
+		Generation:    generation,
+		ResourceCheck: resourceCheck,
+		Ramp:          ramp,
+		Throttle:      throttle,
+		LoadShedding:  loadShedding,
+		Drain:         drain,
+		InternalMetrics: RawInternalMetricsConfig{
+			Enabled: s.InternalMetrics.Enabled,
+			Format:  string(s.InternalMetrics.Format),
+		},
+		Panics: RawPanicConfig{
+			DisableSeries: s.Panics.DisableSeries,
+		},
+		Logging: explainLogging(s.Logging),
+		RNG: RawRNGConfig{
+			Type: string(s.RNG.Type),
+			File: s.RNG.File,
+		},
+		Debug: RawDebugConfig{
+			Enabled: s.Debug.Enabled,
+			Port:    s.Debug.Port,
+		},
+		Naming: RawNamingConfig{
+			Translate: s.Naming.Translate,
+			UTF8:      s.Naming.UTF8,
+		},
+		Monitor:  monitor,
+		Shard:    shard,
+		Signals:  signals,
+		Receiver: receiver,
+	}
+}
+
+// explainLogging converts resolved logging config back to raw form.
+// Default format and level are omitted.
+func explainLogging(l LoggingConfig) RawLoggingConfig {
+	result := RawLoggingConfig{
+		Output:       l.Output,
+		MaxSize:      l.MaxSize,
+		MaxFiles:     l.MaxFiles,
+		SampleEvery:  l.SampleEvery,
+		MaxPerSecond: l.MaxPerSecond,
+	}
+	if l.Format != LogFormatText {
+		result.Format = string(l.Format)
+	}
+	if l.Level != LogLevelInfo {
+		result.Level = string(l.Level)
+	}
+	if l.Modules != nil {
+		result.Modules = make(map[string]string, len(l.Modules))
+		for module, level := range l.Modules {
+			result.Modules[module] = string(level)
+		}
+	}
+	return result
+}
+
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(root *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeEmptyKeys drops keys with empty mapping values from a mapping node.
+func removeEmptyKeys(root *yaml.Node, keys ...string) {
+	for i := 0; i+1 < len(root.Content); {
+		value := root.Content[i+1]
+		if slices.Contains(keys, root.Content[i].Value) &&
+			value.Kind == yaml.MappingNode && len(value.Content) == 0 {
+			root.Content = slices.Delete(root.Content, i, i+2)
+			continue
+		}
+		i += 2
+	}
+}
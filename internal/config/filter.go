@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// filterOperators are the supported comparison operators
+var filterOperators = []string{"==", "!=", "=~", "!~"}
+
+// Filter is a condition on iterator values deciding whether a combination
+// is kept. Operands may contain {iterator} placeholders.
+type Filter struct {
+	expr     string
+	left     string
+	operator string
+	right    string
+	patterns map[string]*regexp.Regexp // Compiled patterns by substituted operand
+}
+
+// ParseFilter parses a filter expression of the form
+// "<left> <op> <right>" with op one of ==, !=, =~ (regex match), !~.
+func ParseFilter(expr string) (*Filter, error) {
+	for _, op := range filterOperators {
+		left, right, found := strings.Cut(expr, " "+op+" ")
+		if !found {
+			continue
+		}
+		return &Filter{
+			expr:     expr,
+			left:     strings.TrimSpace(left),
+			operator: op,
+			right:    strings.TrimSpace(right),
+			patterns: make(map[string]*regexp.Regexp),
+		}, nil
+	}
+	return nil, fmt.Errorf("invalid filter %q: expected <left> <op> <right> with op one of %s",
+		expr, strings.Join(filterOperators, ", "))
+}
+
+// Match evaluates the filter for one iterator combination.
+// Regex patterns are anchored and must match the whole left operand.
+func (f *Filter) Match(iteratorValues map[string]string) (bool, error) {
+	left := substitutePlaceholders(f.left, iteratorValues)
+	right := substitutePlaceholders(f.right, iteratorValues)
+
+	for _, operand := range []string{left, right} {
+		if names := extractPlaceholderNames(operand); len(names) > 0 {
+			return false, fmt.Errorf("filter %q: iterator %q not used by definition", f.expr, names[0])
+		}
+	}
+
+	switch f.operator {
+	case "==":
+		return left == right, nil
+	case "!=":
+		return left != right, nil
+	default:
+		re, exists := f.patterns[right]
+		if !exists {
+			var err error
+			re, err = regexp.Compile("^(?:" + right + ")$")
+			if err != nil {
+				return false, fmt.Errorf("filter %q: invalid pattern: %w", f.expr, err)
+			}
+			f.patterns[right] = re
+		}
+		return re.MatchString(left) == (f.operator == "=~"), nil
+	}
+}
+
+// parseFilters parses all filter expressions of a definition.
+func parseFilters(exprs []string) ([]*Filter, error) {
+	filters := make([]*Filter, len(exprs))
+	for i, expr := range exprs {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		filters[i] = f
+	}
+	return filters, nil
+}
+
+// matchAll reports whether a combination passes all filters.
+func matchAll(filters []*Filter, iteratorValues map[string]string) (bool, error) {
+	for _, f := range filters {
+		ok, err := f.Match(iteratorValues)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
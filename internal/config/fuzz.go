@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	DefaultFuzzSeries              = 10000
+	DefaultFuzzFamilies            = 100
+	DefaultFuzzLabelCardinalityMax = 100
+
+	// fuzzMaxLabels bounds the number of labels per family
+	fuzzMaxLabels = 4
+
+	// fuzzSparseFamilies is the share of families emitted sparsely
+	fuzzSparseFamilies = 0.1
+)
+
+// fuzzIntervals are the clock intervals families are spread across
+var fuzzIntervals = []time.Duration{
+	1 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+}
+
+// fuzzWords name generated families and labels
+var fuzzWords = []string{
+	"api", "batch", "cache", "cluster", "container", "db", "disk", "endpoint",
+	"gateway", "host", "ingest", "job", "method", "node", "pod", "queue",
+	"region", "route", "shard", "status", "storage", "tenant", "worker", "zone",
+}
+
+// FuzzOptions bounds a generated workload.
+type FuzzOptions struct {
+	Series              int // Upper bound on total series
+	Families            int // Number of metric families
+	LabelCardinalityMax int // Upper bound on distinct values per label
+	Seed                uint64
+}
+
+// FuzzProfile returns a random but valid raw configuration within the
+// budgets of opts. The same options always produce the same configuration.
+// Series are spread unevenly across families, each family gets up to four
+// labels whose cardinalities multiply to at most its share, and families
+// vary in type, update interval, value range, and sparseness.
+func FuzzProfile(opts FuzzOptions) (*RawConfig, error) {
+	if opts.Families <= 0 {
+		return nil, fmt.Errorf("invalid families: %d (must be positive)", opts.Families)
+	}
+	if opts.Series < opts.Families {
+		return nil, fmt.Errorf("invalid series: %d (must be at least families: %d)", opts.Series, opts.Families)
+	}
+	if opts.LabelCardinalityMax <= 0 {
+		return nil, fmt.Errorf("invalid label cardinality max: %d (must be positive)", opts.LabelCardinalityMax)
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, 0))
+
+	seed := opts.Seed
+	maxSeries := opts.Series
+	raw := &RawConfig{
+		Settings: RawSettingsConfig{Seed: &seed, MaxSeries: &maxSeries},
+	}
+
+	iterators := make(map[string]bool)
+	for i, share := range fuzzShares(rng, opts.Series, opts.Families) {
+		metric, labels := fuzzFamily(rng, i, share, opts.LabelCardinalityMax)
+		raw.Metrics = append(raw.Metrics, metric)
+
+		// Iterators are shared by label position and cardinality
+		for _, it := range labels {
+			if iterators[it.Name] {
+				continue
+			}
+			iterators[it.Name] = true
+			raw.Iterators = append(raw.Iterators, it)
+		}
+	}
+
+	return raw, nil
+}
+
+// fuzzShares splits series across families with a heavy-tailed
+// distribution. Every family gets at least one series.
+func fuzzShares(rng *rand.Rand, series, families int) []int {
+	weights := make([]float64, families)
+	total := 0.0
+	for i := range weights {
+		weights[i] = math.Pow(rng.Float64(), 3)
+		total += weights[i]
+	}
+
+	shares := make([]int, families)
+	spare := series - families
+	for i, w := range weights {
+		shares[i] = 1
+		if total > 0 {
+			shares[i] += int(float64(spare) * w / total)
+		}
+	}
+	return shares
+}
+
+// fuzzFamily generates a metric family with at most share series.
+// Returns the metric and the iterators its labels draw from.
+func fuzzFamily(rng *rand.Rand, index, share, cardinalityMax int) (RawMetricConfig, []RawIterator) {
+	word := fuzzWords[rng.IntN(len(fuzzWords))]
+	name := fmt.Sprintf("fuzz_%s_%d", word, index)
+
+	metricType := MetricTypeGauge
+	if rng.IntN(2) == 0 {
+		metricType = MetricTypeCounter
+		name += "_total"
+	}
+
+	periodic := "periodic"
+	randomInt := "random_int"
+	zero := 0
+	maxValue := 1 + rng.IntN(1000)
+
+	metric := RawMetricConfig{
+		Name:        RawMetricNameConfig{Simple: name},
+		Type:        string(metricType),
+		Description: fmt.Sprintf("Fuzz family %d", index),
+		Value: RawValueReference{
+			Source: &RawSourceReference{
+				Type: &randomInt,
+				Clock: &RawClockReference{
+					Type:     &periodic,
+					Interval: fuzzIntervals[rng.IntN(len(fuzzIntervals))],
+				},
+				Min: &zero,
+				Max: &maxValue,
+			},
+		},
+	}
+	if metricType == MetricTypeCounter {
+		metric.Value.Transforms = []TransformConfig{{Type: "accumulate"}}
+	}
+	if rng.Float64() < fuzzSparseFamilies {
+		p := 0.5 + 0.45*rng.Float64()
+		metric.EmitProbability = &p
+	}
+
+	// Pick label cardinalities whose product stays within the share
+	var iterators []RawIterator
+	remaining := share
+	used := make(map[string]bool)
+	labelCount := 1 + rng.IntN(fuzzMaxLabels)
+	for position := 0; position < labelCount && remaining > 1; position++ {
+		// Aim for an even split of the remaining share, with jitter
+		cardinality := min(cardinalityMax, remaining)
+		if left := labelCount - position; left > 1 {
+			even := math.Pow(float64(remaining), 1/float64(left))
+			cardinality = min(cardinality, max(1, int(even*(0.5+rng.Float64()))))
+		}
+		remaining /= cardinality
+
+		label := fuzzWords[rng.IntN(len(fuzzWords))]
+		for used[label] {
+			label = fuzzWords[rng.IntN(len(fuzzWords))]
+		}
+		used[label] = true
+
+		start, end := 1, cardinality
+		iterator := RawIterator{
+			Name:   fmt.Sprintf("l%d_%d", position, cardinality),
+			Type:   "range",
+			Start:  &start,
+			End:    &end,
+			Prefix: "v",
+		}
+		iterators = append(iterators, iterator)
+
+		if metric.Attributes == nil {
+			metric.Attributes = make(map[string]string)
+		}
+		metric.Attributes[label] = "{" + iterator.Name + "}"
+	}
+
+	return metric, iterators
+}
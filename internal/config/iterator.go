@@ -1,8 +1,14 @@
 package config
 
 import (
+	"bufio"
 	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 // Iterator provides lazy value generation for configuration expansion.
@@ -11,11 +17,41 @@ type Iterator struct {
 	name      string
 	generator func(index int) string // Generate value at index
 	count     int                    // Total number of values
+	weighted  bool                   // Repeats values, so needs zipping with a distinct iterator
 }
 
 // NewRangeIterator creates an iterator that generates sequential integers.
 // Values are generated as strings: start, start+1, ..., end (inclusive).
 func NewRangeIterator(name string, start, end int) *Iterator {
+	return NewFormattedRangeIterator(name, start, end, 1, RangeFormat{})
+}
+
+// RangeFormat controls how range iterator values are rendered.
+type RangeFormat struct {
+	Format string // printf-style format with one integer verb (e.g. "%03d")
+	Pad    int    // Zero-pad to width (ignored if Format is set)
+	Prefix string
+	Suffix string
+}
+
+// render formats a single range value.
+func (f RangeFormat) render(n int) string {
+	var s string
+	switch {
+	case f.Format != "":
+		s = fmt.Sprintf(f.Format, n)
+	case f.Pad > 0:
+		s = fmt.Sprintf("%0*d", f.Pad, n)
+	default:
+		s = strconv.Itoa(n)
+	}
+	return f.Prefix + s + f.Suffix
+}
+
+// NewFormattedRangeIterator creates an iterator that generates integers
+// from start to end (inclusive) in increments of step, rendered by format.
+// Step must be positive.
+func NewFormattedRangeIterator(name string, start, end, step int, format RangeFormat) *Iterator {
 	if end < start {
 		return &Iterator{
 			name:  name,
@@ -28,9 +64,9 @@ func NewRangeIterator(name string, start, end int) *Iterator {
 
 	return &Iterator{
 		name:  name,
-		count: end - start + 1,
+		count: (end-start)/step + 1,
 		generator: func(index int) string {
-			return strconv.Itoa(start + index)
+			return format.render(start + index*step)
 		},
 	}
 }
@@ -50,6 +86,100 @@ func NewListIterator(name string, values []string) *Iterator {
 	}
 }
 
+// NewWeightedListIterator creates an iterator that repeats each value
+// weight times in order. Combined with zip expansion, weights distribute
+// series across values proportionally.
+func NewWeightedListIterator(name string, values []string, weights []int) *Iterator {
+	valuesCopy := make([]string, len(values))
+	copy(valuesCopy, values)
+
+	// Cumulative weights: value i covers indexes [bounds[i-1], bounds[i])
+	bounds := make([]int, len(weights))
+	total := 0
+	for i, w := range weights {
+		total += w
+		bounds[i] = total
+	}
+
+	return &Iterator{
+		name:  name,
+		count: total,
+		generator: func(index int) string {
+			return valuesCopy[sort.SearchInts(bounds, index+1)]
+		},
+		weighted: true,
+	}
+}
+
+// NewFileIterator creates an iterator over the lines of a file.
+// Blank lines and lines starting with # are skipped.
+func NewFileIterator(name, path string) (*Iterator, error) {
+	return newFileIterator(name, path, 0)
+}
+
+// newFileIterator creates an iterator over the lines of a file, failing
+// as soon as the file has more than limit values (0: unlimited).
+func newFileIterator(name, path string, limit int) (*Iterator, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if limit > 0 && len(values) == limit {
+			return nil, fmt.Errorf("file %q has more than %d values (settings.max_series)", path, limit)
+		}
+		values = append(values, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewListIterator(name, values), nil
+}
+
+// excludeValues returns an iterator without the excluded values.
+// Returns error if an excluded value is not generated by it.
+func excludeValues(it *Iterator, exclude []string) (*Iterator, error) {
+	excluded := make(map[string]bool, len(exclude))
+	for _, v := range exclude {
+		excluded[v] = true
+	}
+
+	// Keep indexes of remaining values
+	var kept []int
+	found := make(map[string]bool, len(exclude))
+	for i := range it.Len() {
+		v := it.ValueAt(i)
+		if excluded[v] {
+			found[v] = true
+			continue
+		}
+		kept = append(kept, i)
+	}
+	for _, v := range exclude {
+		if !found[v] {
+			return nil, fmt.Errorf("excluded value %q not generated", v)
+		}
+	}
+
+	return &Iterator{
+		name:  it.name,
+		count: len(kept),
+		generator: func(index int) string {
+			return it.ValueAt(kept[index])
+		},
+		weighted: it.weighted,
+	}, nil
+}
+
 // Name returns the iterator name (used in {name} placeholders).
 func (it *Iterator) Name() string {
 	return it.name
@@ -83,7 +213,8 @@ func (it *Iterator) AllValues() []string {
 
 // IteratorRegistry manages all defined iterators.
 type IteratorRegistry struct {
-	iterators map[string]*Iterator
+	iterators   map[string]*Iterator
+	unavailable map[string]error // Built-in iterators that could not be determined
 }
 
 // NewIteratorRegistry creates an empty iterator registry.
@@ -117,6 +248,9 @@ func (r *IteratorRegistry) GetIterators(names []string) ([]*Iterator, error) {
 	for i, name := range names {
 		it, exists := r.Get(name)
 		if !exists {
+			if reason, ok := r.unavailable[name]; ok {
+				return nil, fmt.Errorf("iterator %q not available: %w", name, reason)
+			}
 			return nil, fmt.Errorf("iterator %q not defined", name)
 		}
 		iterators[i] = it
@@ -124,11 +258,23 @@ func (r *IteratorRegistry) GetIterators(names []string) ([]*Iterator, error) {
 	return iterators, nil
 }
 
+// ExpansionMode defines how values of multiple iterators are combined.
+type ExpansionMode string
+
+const (
+	// ExpansionModeProduct generates all combinations (default)
+	ExpansionModeProduct ExpansionMode = "product"
+
+	// ExpansionModeZip pairs values at the same index of equal-length iterators
+	ExpansionModeZip ExpansionMode = "zip"
+)
+
 // CombinationGenerator generates Cartesian product combinations lazily.
 // Memory usage is O(1) regardless of combination count.
 type CombinationGenerator struct {
 	iterators []*Iterator
 	total     int
+	zip       bool // Pair values by index instead of crossing them
 }
 
 // NewCombinationGenerator creates a lazy combination generator.
@@ -141,10 +287,14 @@ func NewCombinationGenerator(iterators []*Iterator) *CombinationGenerator {
 		}
 	}
 
-	// Calculate total combinations (Cartesian product size)
+	// Calculate total combinations (Cartesian product size), saturating on overflow
 	total := 1
 	for _, it := range iterators {
-		total *= it.Len()
+		if n := it.Len(); n > 0 && total > math.MaxInt/n {
+			total = math.MaxInt
+		} else {
+			total *= n
+		}
 	}
 
 	return &CombinationGenerator{
@@ -153,6 +303,51 @@ func NewCombinationGenerator(iterators []*Iterator) *CombinationGenerator {
 	}
 }
 
+// NewZipGenerator creates a generator pairing the values at the same index
+// of all iterators. Returns error if iterator lengths differ.
+func NewZipGenerator(iterators []*Iterator) (*CombinationGenerator, error) {
+	total := 0
+	for i, it := range iterators {
+		if i > 0 && it.Len() != total {
+			return nil, fmt.Errorf("zip requires equal-length iterators: %q has %d values, %q has %d",
+				iterators[0].Name(), total, it.Name(), it.Len())
+		}
+		total = it.Len()
+	}
+
+	return &CombinationGenerator{
+		iterators: iterators,
+		total:     total,
+		zip:       true,
+	}, nil
+}
+
+// newExpansionGenerator creates a combination generator for the mode.
+// An empty mode selects the Cartesian product. Weighted lists repeat their
+// values, so they are only valid zipped with an iterator that is not
+// weighted; otherwise expansion would yield duplicate series.
+func newExpansionGenerator(mode string, iterators []*Iterator) (*CombinationGenerator, error) {
+	weighted := slices.IndexFunc(iterators, func(it *Iterator) bool { return it.weighted })
+	distinct := slices.ContainsFunc(iterators, func(it *Iterator) bool { return !it.weighted })
+
+	switch ExpansionMode(mode) {
+	case "", ExpansionModeProduct:
+		if weighted >= 0 {
+			return nil, fmt.Errorf("weighted_list iterator %q requires expand: zip with an iterator that is not weighted",
+				iterators[weighted].Name())
+		}
+		return NewCombinationGenerator(iterators), nil
+	case ExpansionModeZip:
+		if weighted >= 0 && !distinct {
+			return nil, fmt.Errorf("weighted_list iterator %q must be zipped with an iterator that is not weighted",
+				iterators[weighted].Name())
+		}
+		return NewZipGenerator(iterators)
+	default:
+		return nil, fmt.Errorf("unknown expand mode %q (must be product or zip)", mode)
+	}
+}
+
 // Total returns the number of combinations this generator will produce.
 func (g *CombinationGenerator) Total() int {
 	return g.total
@@ -169,6 +364,14 @@ func (g *CombinationGenerator) Generate(index int) map[string]string {
 
 	result := make(map[string]string, len(g.iterators))
 
+	// Zip: every iterator contributes its value at index
+	if g.zip {
+		for _, it := range g.iterators {
+			result[it.Name()] = it.ValueAt(index)
+		}
+		return result
+	}
+
 	// Calculate which value from each iterator to use
 	// Uses positional encoding: rightmost iterator cycles fastest
 	repeat := 1
@@ -195,9 +398,13 @@ func (g *CombinationGenerator) ForEach(fn func(map[string]string) error) error {
 }
 
 // buildIteratorRegistry creates a registry from raw iterator definitions.
-func buildIteratorRegistry(rawIterators []RawIterator) (*IteratorRegistry, error) {
+// Iterators generating more than maxSeries values are rejected (0: unlimited).
+func buildIteratorRegistry(rawIterators []RawIterator, seed *uint64, maxSeries int) (*IteratorRegistry, error) {
 	registry := NewIteratorRegistry()
 
+	// Random iterators derive their values from the master seed
+	masterSeed := EffectiveSeed(seed)
+
 	for _, raw := range rawIterators {
 		var it *Iterator
 
@@ -212,7 +419,32 @@ func buildIteratorRegistry(rawIterators []RawIterator) (*IteratorRegistry, error
 				return nil, fmt.Errorf("iterator %q: end required for range type",
 					raw.Name)
 			}
-			it = NewRangeIterator(raw.Name, *raw.Start, *raw.End)
+			step := 1
+			if raw.Step != nil {
+				step = *raw.Step
+			}
+			if step <= 0 {
+				return nil, fmt.Errorf("iterator %q: step must be positive, got %d",
+					raw.Name, step)
+			}
+			if raw.Pad < 0 {
+				return nil, fmt.Errorf("iterator %q: pad must not be negative, got %d",
+					raw.Name, raw.Pad)
+			}
+			if raw.Format != "" && raw.Pad > 0 {
+				return nil, fmt.Errorf("iterator %q: format and pad are mutually exclusive",
+					raw.Name)
+			}
+			if raw.Format != "" && strings.Contains(fmt.Sprintf(raw.Format, 0), "%!") {
+				return nil, fmt.Errorf("iterator %q: format %q must contain exactly one integer verb",
+					raw.Name, raw.Format)
+			}
+			it = NewFormattedRangeIterator(raw.Name, *raw.Start, *raw.End, step, RangeFormat{
+				Format: raw.Format,
+				Pad:    raw.Pad,
+				Prefix: raw.Prefix,
+				Suffix: raw.Suffix,
+			})
 
 		case "list":
 			// Validate list parameters
@@ -222,11 +454,70 @@ func buildIteratorRegistry(rawIterators []RawIterator) (*IteratorRegistry, error
 			}
 			it = NewListIterator(raw.Name, raw.Values)
 
+		case "weighted_list":
+			// Validate weighted list parameters
+			if len(raw.WeightedValues) == 0 {
+				return nil, fmt.Errorf("iterator %q: weighted_values required for weighted_list type",
+					raw.Name)
+			}
+			values := make([]string, len(raw.WeightedValues))
+			weights := make([]int, len(raw.WeightedValues))
+			for i, wv := range raw.WeightedValues {
+				if wv.Weight <= 0 {
+					return nil, fmt.Errorf("iterator %q: weight for %q must be positive, got %d",
+						raw.Name, wv.Value, wv.Weight)
+				}
+				values[i] = wv.Value
+				weights[i] = wv.Weight
+			}
+			it = NewWeightedListIterator(raw.Name, values, weights)
+
+		case "file":
+			// Validate file parameters
+			if raw.Path == "" {
+				return nil, fmt.Errorf("iterator %q: path required for file type",
+					raw.Name)
+			}
+			var err error
+			it, err = newFileIterator(raw.Name, raw.Path, maxSeries)
+			if err != nil {
+				return nil, fmt.Errorf("iterator %q: %w", raw.Name, err)
+			}
+			if it.Len() == 0 {
+				return nil, fmt.Errorf("iterator %q: file %q contains no values",
+					raw.Name, raw.Path)
+			}
+
+		case "random":
+			// Check the count before generating values
+			if maxSeries > 0 && raw.Count > maxSeries {
+				return nil, fmt.Errorf("iterator %q generates %d values, more than %d (settings.max_series)",
+					raw.Name, raw.Count, maxSeries)
+			}
+			var err error
+			it, err = newRandomIterator(raw, masterSeed)
+			if err != nil {
+				return nil, fmt.Errorf("iterator %q: %w", raw.Name, err)
+			}
+
 		default:
-			return nil, fmt.Errorf("iterator %q: unknown type %q (must be range or list)",
+			return nil, fmt.Errorf("iterator %q: unknown type %q (must be range, list, weighted_list, file, or random)",
 				raw.Name, raw.Type)
 		}
 
+		if maxSeries > 0 && it.Len() > maxSeries {
+			return nil, fmt.Errorf("iterator %q generates %d values, more than %d (settings.max_series)",
+				raw.Name, it.Len(), maxSeries)
+		}
+
+		if len(raw.Exclude) > 0 {
+			var err error
+			it, err = excludeValues(it, raw.Exclude)
+			if err != nil {
+				return nil, fmt.Errorf("iterator %q: %w", raw.Name, err)
+			}
+		}
+
 		if err := registry.Register(it); err != nil {
 			return nil, err
 		}
@@ -234,3 +525,18 @@ func buildIteratorRegistry(rawIterators []RawIterator) (*IteratorRegistry, error
 
 	return registry, nil
 }
+
+// registerPodOrdinal adds the built-in pod_ordinal iterator unless an
+// iterator of that name is defined. Outside a StatefulSet the iterator is
+// recorded as unavailable and fails only when referenced.
+func registerPodOrdinal(registry *IteratorRegistry) {
+	if _, exists := registry.Get(PodOrdinalIterator); exists {
+		return
+	}
+	ordinal, err := PodOrdinal()
+	if err != nil {
+		registry.unavailable = map[string]error{PodOrdinalIterator: err}
+		return
+	}
+	registry.iterators[PodOrdinalIterator] = NewRangeIterator(PodOrdinalIterator, ordinal, ordinal)
+}
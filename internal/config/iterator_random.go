@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+)
+
+// DefaultRandomHexLength is the default number of digits of random hex values.
+const DefaultRandomHexLength = 16
+
+// newRandomIterator creates an iterator of pseudo-random values.
+// Values are derived from the master seed and the iterator name, and do
+// not consume streams from the simulation seed registry.
+func newRandomIterator(raw RawIterator, masterSeed uint64) (*Iterator, error) {
+	if raw.Count <= 0 {
+		return nil, fmt.Errorf("count must be positive for random type, got %d", raw.Count)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(raw.Name))
+	rng := rand.New(rand.NewPCG(masterSeed, h.Sum64()))
+
+	var values []string
+	switch raw.Generate {
+	case "uuid":
+		values = make([]string, raw.Count)
+		for i := range values {
+			values[i] = randomUUID(rng)
+		}
+
+	case "hex":
+		length := raw.Length
+		if length == 0 {
+			length = DefaultRandomHexLength
+		}
+		if length < 0 {
+			return nil, fmt.Errorf("length must be positive, got %d", length)
+		}
+		values = make([]string, raw.Count)
+		for i := range values {
+			values[i] = randomHex(rng, length)
+		}
+
+	case "word":
+		dictionary := raw.Values
+		if raw.Path != "" {
+			it, err := NewFileIterator(raw.Name, raw.Path)
+			if err != nil {
+				return nil, err
+			}
+			dictionary = it.AllValues()
+		}
+		if len(dictionary) == 0 {
+			return nil, fmt.Errorf("values or path required for word generation")
+		}
+		if raw.Count > len(dictionary) {
+			return nil, fmt.Errorf("count %d exceeds dictionary size %d", raw.Count, len(dictionary))
+		}
+		// Sample without replacement to keep values distinct
+		values = make([]string, raw.Count)
+		for i, j := range rng.Perm(len(dictionary))[:raw.Count] {
+			values[i] = dictionary[j]
+		}
+
+	case "":
+		return nil, fmt.Errorf("generate required for random type")
+
+	default:
+		return nil, fmt.Errorf("unknown generate %q (must be uuid, hex, or word)", raw.Generate)
+	}
+
+	return NewListIterator(raw.Name, values), nil
+}
+
+// randomUUID generates a version 4 UUID from rng.
+func randomUUID(rng *rand.Rand) string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(rng.UintN(256))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// randomHex generates a lowercase hex string with length digits.
+func randomHex(rng *rand.Rand, length int) string {
+	b := make([]byte, (length+1)/2)
+	for i := range b {
+		b[i] = byte(rng.UintN(256))
+	}
+	return hex.EncodeToString(b)[:length]
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWeightedListIteratorRepeatsValuesByWeight(t *testing.T) {
+	it := NewWeightedListIterator("tier", []string{"gold", "free"}, []int{3, 1})
+
+	got := it.AllValues()
+	want := []string{"gold", "gold", "gold", "free"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("values = %v, want %v", got, want)
+	}
+}
+
+func TestExpansionGeneratorWeightedList(t *testing.T) {
+	weighted := func() *Iterator {
+		return NewWeightedListIterator("tier", []string{"gold", "free"}, []int{2, 1})
+	}
+	id := NewRangeIterator("id", 1, 3)
+
+	tests := []struct {
+		name      string
+		mode      string
+		iterators []*Iterator
+		wantErr   string
+	}{
+		{name: "product alone", mode: "", iterators: []*Iterator{weighted()}, wantErr: "requires expand: zip"},
+		{name: "product with range", mode: "product", iterators: []*Iterator{weighted(), id}, wantErr: "requires expand: zip"},
+		{name: "zip alone", mode: "zip", iterators: []*Iterator{weighted()}, wantErr: "must be zipped"},
+		{name: "zip with range", mode: "zip", iterators: []*Iterator{weighted(), id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := newExpansionGenerator(tt.mode, tt.iterators)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Zipped series must be distinct
+			seen := make(map[string]bool)
+			err = gen.ForEach(func(combo map[string]string) error {
+				key := combo["tier"] + "/" + combo["id"]
+				if seen[key] {
+					t.Errorf("duplicate combination %s", key)
+				}
+				seen[key] = true
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(seen) != 3 {
+				t.Errorf("combinations = %d, want 3", len(seen))
+			}
+		})
+	}
+}
+
+func TestExcludeKeepsWeighted(t *testing.T) {
+	it, err := excludeValues(NewWeightedListIterator("tier", []string{"gold", "free"}, []int{2, 1}), []string{"free"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newExpansionGenerator("", []*Iterator{it}); err == nil {
+		t.Fatal("expected weighted list with exclusions to be rejected in product mode")
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PodOrdinalIterator is the built-in iterator holding the StatefulSet
+// ordinal of the pod otelbox runs in.
+const PodOrdinalIterator = "pod_ordinal"
+
+// K8sEnvAttribute maps downward-API environment variables to a k8s
+// resource attribute and, optionally, a Prometheus label.
+type K8sEnvAttribute struct {
+	Attribute string
+	Label     string   // Prometheus label ("": not labeled)
+	Vars      []string // Checked in order; the first set variable wins
+}
+
+// K8sEnvAttributes lists the attributes read from the downward API.
+var K8sEnvAttributes = []K8sEnvAttribute{
+	{Attribute: "k8s.pod.name", Label: "pod", Vars: []string{"K8S_POD_NAME", "POD_NAME"}},
+	{Attribute: "k8s.pod.uid", Vars: []string{"K8S_POD_UID", "POD_UID"}},
+	{Attribute: "k8s.namespace.name", Label: "namespace", Vars: []string{"K8S_NAMESPACE_NAME", "POD_NAMESPACE"}},
+	{Attribute: "k8s.node.name", Label: "node", Vars: []string{"K8S_NODE_NAME", "NODE_NAME"}},
+	{Attribute: "k8s.container.name", Vars: []string{"K8S_CONTAINER_NAME"}},
+}
+
+// Lookup returns the value of the first set variable.
+func (a K8sEnvAttribute) Lookup() (string, bool) {
+	for _, name := range a.Vars {
+		if value := os.Getenv(name); value != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// detectK8sLabels returns the Prometheus labels of all set downward-API
+// variables.
+func detectK8sLabels() map[string]string {
+	labels := make(map[string]string)
+	for _, attr := range K8sEnvAttributes {
+		if attr.Label == "" {
+			continue
+		}
+		if value, ok := attr.Lookup(); ok {
+			labels[attr.Label] = value
+		}
+	}
+	return labels
+}
+
+// PodOrdinal returns the StatefulSet ordinal of the pod, parsed from the
+// hostname, which StatefulSets set to <statefulset>-<ordinal>.
+func PodOrdinal() (int, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read hostname: %w", err)
+	}
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("hostname %q has no StatefulSet ordinal", hostname)
+	}
+	ordinal, err := strconv.Atoi(hostname[i+1:])
+	if err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("hostname %q has no StatefulSet ordinal", hostname)
+	}
+	return ordinal, nil
+}
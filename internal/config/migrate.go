@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// SchemaVersion is the configuration schema version this build reads.
+// Version 1 is the legacy format with a simulation section and named
+// entities given as mappings instead of lists.
+const SchemaVersion = 2
+
+// entitySections are the sections holding named clocks, sources, and values.
+var entitySections = []string{"templates", "instances", "simulation"}
+
+// checkSchema rejects configurations written for another schema version
+// with a hint on how to convert them.
+func checkSchema(root *yaml.Node, path string) error {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	if version := mappingValue(root, "version"); version != nil {
+		v, err := strconv.Atoi(version.Value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid version %q", path, version.Value)
+		}
+		switch {
+		case v > SchemaVersion:
+			return fmt.Errorf("%s: config version %d is newer than supported version %d, upgrade otelbox",
+				path, v, SchemaVersion)
+		case v < SchemaVersion:
+			return fmt.Errorf("%s: config version %d is no longer supported, convert it with 'otelbox migrate-config %s'",
+				path, v, path)
+		}
+	}
+
+	if legacy := legacyFeatures(root); len(legacy) > 0 {
+		return fmt.Errorf("%s: legacy config format (%s), convert it with 'otelbox migrate-config %s'",
+			path, legacy[0], path)
+	}
+
+	return nil
+}
+
+// legacyFeatures lists the legacy constructs used by a config document.
+func legacyFeatures(root *yaml.Node) []string {
+	var features []string
+	for _, section := range entitySections {
+		node := mappingValue(root, section)
+		if node == nil {
+			continue
+		}
+		if section == "simulation" {
+			features = append(features, "simulation section")
+		}
+		if node.Kind != yaml.MappingNode {
+			continue
+		}
+		for _, kind := range []string{"clocks", "sources", "values"} {
+			if entities := mappingValue(node, kind); entities != nil && entities.Kind == yaml.MappingNode {
+				features = append(features, fmt.Sprintf("%s.%s as mapping", section, kind))
+			}
+		}
+	}
+	return features
+}
+
+// Migrate converts a configuration document to the current schema.
+// Named entity mappings become lists with a name field, the simulation
+// section moves to instances, and the version field is set. Comments are
+// preserved. Returns the converted document and the applied changes.
+func Migrate(data []byte) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config must be a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	if version := mappingValue(root, "version"); version != nil {
+		if v, err := strconv.Atoi(version.Value); err == nil && v > SchemaVersion {
+			return nil, nil, fmt.Errorf("config version %d is newer than supported version %d", v, SchemaVersion)
+		}
+	}
+
+	changes := legacyFeatures(root)
+
+	// Convert named entity mappings to lists
+	for _, section := range entitySections {
+		node := mappingValue(root, section)
+		if node == nil || node.Kind != yaml.MappingNode {
+			continue
+		}
+		for _, kind := range []string{"clocks", "sources", "values"} {
+			if entities := mappingValue(node, kind); entities != nil && entities.Kind == yaml.MappingNode {
+				*entities = *namedList(entities)
+			}
+		}
+	}
+
+	// Keep the document head comment on the first key
+	var headComment string
+	if len(root.Content) > 0 {
+		headComment = root.Content[0].HeadComment
+		root.Content[0].HeadComment = ""
+	}
+
+	// Move simulation entities to instances
+	if simulation := mappingValue(root, "simulation"); simulation != nil {
+		if instances := mappingValue(root, "instances"); instances != nil {
+			*instances = *mergeNodes(instances, simulation)
+			removeKeys(root, "simulation")
+		} else {
+			for i := 0; i < len(root.Content); i += 2 {
+				if root.Content[i].Value == "simulation" {
+					root.Content[i].Value = "instances"
+				}
+			}
+		}
+	}
+
+	// Set version as first key
+	removeKeys(root, "version")
+	version := scalarNode("version")
+	version.HeadComment = headComment
+	root.Content = append([]*yaml.Node{version, intNode(SchemaVersion)}, root.Content...)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	// Reject anything the conversion does not cover
+	var raw RawConfig
+	if err := decodeStrict(buf.Bytes(), &raw); err != nil {
+		return nil, nil, fmt.Errorf("converted config is invalid: %w", err)
+	}
+
+	return buf.Bytes(), changes, nil
+}
+
+// namedList converts a mapping of name to entity into a list of entities
+// with a leading name field.
+func namedList(entities *yaml.Node) *yaml.Node {
+	list := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for i := 0; i+1 < len(entities.Content); i += 2 {
+		key, value := entities.Content[i], entities.Content[i+1]
+
+		item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: key.HeadComment}
+		item.Content = append(item.Content, scalarNode("name"), scalarNode(key.Value))
+		if value.Kind == yaml.MappingNode {
+			item.Content = append(item.Content, value.Content...)
+		}
+		list.Content = append(list.Content, item)
+	}
+	return list
+}
+
+// removeKeys drops keys from a mapping node.
+func removeKeys(root *yaml.Node, keys ...string) {
+	for i := 0; i+1 < len(root.Content); {
+		if slices.Contains(keys, root.Content[i].Value) {
+			root.Content = slices.Delete(root.Content, i, i+2)
+			continue
+		}
+		i += 2
+	}
+}
+
+// scalarNode creates a string scalar node.
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// intNode creates an integer scalar node.
+func intNode(value int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+)
+
+// unitSuffixes maps OTEL units (UCUM) to Prometheus name suffixes, as in the
+// OpenTelemetry Prometheus compatibility specification.
+var unitSuffixes = []struct{ unit, suffix string }{
+	{"d", "days"},
+	{"h", "hours"},
+	{"min", "minutes"},
+	{"s", "seconds"},
+	{"ms", "milliseconds"},
+	{"us", "microseconds"},
+	{"ns", "nanoseconds"},
+	{"By", "bytes"},
+	{"KiBy", "kibibytes"},
+	{"MiBy", "mebibytes"},
+	{"GiBy", "gibibytes"},
+	{"TiBy", "tibibytes"},
+	{"KBy", "kilobytes"},
+	{"MBy", "megabytes"},
+	{"GBy", "gigabytes"},
+	{"TBy", "terabytes"},
+	{"m", "meters"},
+	{"V", "volts"},
+	{"A", "amperes"},
+	{"J", "joules"},
+	{"W", "watts"},
+	{"g", "grams"},
+	{"Cel", "celsius"},
+	{"Hz", "hertz"},
+	{"%", "percent"},
+}
+
+// perUnitSuffixes maps the denominators of OTEL units like "By/s".
+var perUnitSuffixes = []struct{ unit, suffix string }{
+	{"s", "second"},
+	{"m", "minute"},
+	{"h", "hour"},
+	{"d", "day"},
+	{"w", "week"},
+	{"mo", "month"},
+	{"y", "year"},
+}
+
+var (
+	invalidNameChars    = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	repeatedUnderscores = regexp.MustCompile(`__+`)
+	unitAnnotations     = regexp.MustCompile(`\{[^}]*\}`)
+)
+
+// translateNames derives the undeclared protocol name of m from the declared
+// one. A simple name containing a dot is an OTEL name, any other simple name
+// a Prometheus name. Declared names are kept as is.
+func translateNames(m *MetricConfig, name RawMetricNameConfig, utf8 bool) {
+	switch {
+	case name.Simple != "" && strings.Contains(name.Simple, "."):
+		m.PrometheusName = prometheusName(name.Simple, m.Type, m.Unit, utf8)
+	case name.Simple != "":
+		m.OTELName = otelName(m, name.Simple)
+	case name.Prometheus == "" && name.OTEL != "":
+		m.PrometheusName = prometheusName(name.OTEL, m.Type, m.Unit, utf8)
+	case name.OTEL == "" && name.Prometheus != "":
+		m.OTELName = otelName(m, name.Prometheus)
+	}
+}
+
+// prometheusName translates an OTEL name: invalid characters become
+// underscores unless UTF-8 names are allowed, and unit and _total suffixes
+// are appended if missing.
+func prometheusName(name string, typ MetricType, unit string, utf8 bool) string {
+	if !utf8 {
+		name = invalidNameChars.ReplaceAllString(name, "_")
+		name = repeatedUnderscores.ReplaceAllString(name, "_")
+		if name != "" && name[0] >= '0' && name[0] <= '9' {
+			name = "_" + name
+		}
+	}
+
+	if suffix := unitSuffix(unit, typ); suffix != "" && !strings.HasSuffix(name, "_"+suffix) {
+		name += "_" + suffix
+	}
+	if typ == MetricTypeCounter && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+	return name
+}
+
+// unitSuffix returns the Prometheus name suffix of an OTEL unit. Annotations
+// like {request} are dropped; unknown units are used as is.
+func unitSuffix(unit string, typ MetricType) string {
+	unit = strings.TrimSpace(unitAnnotations.ReplaceAllString(unit, ""))
+	if unit == "1" {
+		// Dimensionless gauges are ratios; counters get no suffix
+		if typ == MetricTypeGauge {
+			return "ratio"
+		}
+		return ""
+	}
+
+	main, per, _ := strings.Cut(unit, "/")
+	parts := make([]string, 0, 3)
+	if main != "" {
+		parts = append(parts, lookupSuffix(unitSuffixes, main))
+	}
+	if per != "" {
+		parts = append(parts, "per", lookupSuffix(perUnitSuffixes, per))
+	}
+
+	suffix := strings.Join(parts, "_")
+	return strings.Trim(invalidNameChars.ReplaceAllString(suffix, "_"), "_")
+}
+
+// lookupSuffix returns the suffix of unit in table, or unit itself.
+func lookupSuffix(table []struct{ unit, suffix string }, unit string) string {
+	for _, entry := range table {
+		if entry.unit == unit {
+			return entry.suffix
+		}
+	}
+	return unit
+}
+
+// otelName translates a Prometheus name: the _total suffix of counters and
+// a known unit suffix are removed, and underscores become dots. The unit of
+// m is set from the suffix if not declared.
+func otelName(m *MetricConfig, name string) string {
+	if m.Type == MetricTypeCounter {
+		name = strings.TrimSuffix(name, "_total")
+	}
+
+	if unit, base, ok := nameUnitSuffix(name, m.Type); ok {
+		name = base
+		if m.Unit == "" {
+			m.Unit = unit
+		}
+	}
+
+	return strings.ReplaceAll(name, "_", ".")
+}
+
+// nameUnitSuffix splits a known unit suffix like _seconds or
+// _bytes_per_second off a Prometheus name.
+func nameUnitSuffix(name string, typ MetricType) (unit, base string, ok bool) {
+	if typ == MetricTypeGauge {
+		if base, found := strings.CutSuffix(name, "_ratio"); found && base != "" {
+			return "1", base, true
+		}
+	}
+
+	var per string
+	for _, entry := range perUnitSuffixes {
+		if base, found := strings.CutSuffix(name, "_per_"+entry.suffix); found {
+			name, per = base, "/"+entry.unit
+			break
+		}
+	}
+
+	for _, entry := range unitSuffixes {
+		if base, found := strings.CutSuffix(name, "_"+entry.suffix); found && base != "" {
+			return entry.unit + per, base, true
+		}
+	}
+	return "", "", false
+}
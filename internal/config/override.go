@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// applyOverrides assigns path=value overrides to the merged configuration in
+// order. Paths are dot-separated keys; list entries are selected by name or
+// by index, like iterators.shard.end or metrics.0.description. Values are
+// YAML, so lists and mappings can be given inline.
+func applyOverrides(merged *yaml.Node, overrides []string) (*yaml.Node, error) {
+	for _, override := range overrides {
+		path, value, ok := strings.Cut(override, "=")
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid override %q: expected path=value", override)
+		}
+
+		node, err := overrideValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override %q: %w", override, err)
+		}
+
+		if merged == nil {
+			merged = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		if err := setPath(merged, strings.Split(path, "."), node); err != nil {
+			return nil, fmt.Errorf("invalid override %q: %w", override, err)
+		}
+	}
+	return merged, nil
+}
+
+// overrideValue parses the value of an override. An empty value is an
+// empty string.
+func overrideValue(value string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}, nil
+	}
+	return doc.Content[0], nil
+}
+
+// setPath replaces the value at path below node, creating missing mapping
+// keys. Mapping keys containing dots, like resource attributes, match
+// several path segments.
+func setPath(node *yaml.Node, path []string, value *yaml.Node) error {
+	switch node.Kind {
+	case yaml.MappingNode:
+		// Prefer the longest existing key
+		for n := len(path); n > 0; n-- {
+			key := strings.Join(path[:n], ".")
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value != key {
+					continue
+				}
+				if n == len(path) {
+					node.Content[i+1] = value
+					return nil
+				}
+				return setPath(node.Content[i+1], path[n:], value)
+			}
+		}
+
+		child := value
+		if len(path) > 1 {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}, child)
+		if len(path) > 1 {
+			return setPath(child, path[1:], value)
+		}
+		return nil
+
+	case yaml.SequenceNode:
+		i := sequenceEntry(node, path[0])
+		if i < 0 {
+			return fmt.Errorf("no entry %q", path[0])
+		}
+		if len(path) == 1 {
+			node.Content[i] = value
+			return nil
+		}
+		return setPath(node.Content[i], path[1:], value)
+
+	case yaml.AliasNode:
+		return setPath(node.Alias, path, value)
+
+	default:
+		return fmt.Errorf("cannot set %q in a scalar value", strings.Join(path, "."))
+	}
+}
+
+// sequenceEntry returns the index of the entry named key, or of entry
+// number key, or -1.
+func sequenceEntry(seq *yaml.Node, key string) int {
+	for i, entry := range seq.Content {
+		if name := mappingValue(entry, "name"); entry.Kind == yaml.MappingNode && name != nil && name.Value == key {
+			return i
+		}
+	}
+	if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(seq.Content) {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOverridesSetValues(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+
+	raw, err := ParseFiles([]string{path}, ParseOptions{
+		Profiles: []string{"small"},
+		Overrides: []string{
+			"export.prometheus.port=9191",
+			"settings.seed=42",
+			"iterators.shard.end=50",
+			"iterators.region.values=[ap, sa]",
+			"metrics.0.description=Overridden",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if raw.Export.Prometheus.Port != 9191 {
+		t.Errorf("port = %d, want 9191", raw.Export.Prometheus.Port)
+	}
+
+	// Missing keys are created
+	if raw.Settings.Seed == nil || *raw.Settings.Seed != 42 {
+		t.Errorf("seed = %v, want 42", raw.Settings.Seed)
+	}
+
+	// Overrides apply after profiles and select list entries by name
+	if end := *iteratorNamed(raw, "shard").End; end != 50 {
+		t.Errorf("shard end = %d, want 50", end)
+	}
+	if values := iteratorNamed(raw, "region").Values; !slices.Equal(values, []string{"ap", "sa"}) {
+		t.Errorf("region values = %v, want [ap sa]", values)
+	}
+
+	// Or by index
+	if got := raw.Metrics[0].Description; got != "Overridden" {
+		t.Errorf("description = %q, want Overridden", got)
+	}
+}
+
+func TestOverridesMatchDottedKeys(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig+`
+  otel:
+    export:
+      otel:
+        enabled: true
+        resource:
+          service.name: base
+`)
+
+	raw, err := ParseFiles([]string{path}, ParseOptions{
+		Profiles:  []string{"otel"},
+		Overrides: []string{"export.otel.resource.service.name=sim"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The existing dotted key is replaced rather than nested
+	if got := raw.Export.OTEL.Resource; len(got) != 1 || got["service.name"] != "sim" {
+		t.Errorf("resource = %v, want only service.name=sim", got)
+	}
+}
+
+func TestOverridesErrors(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+
+	tests := []struct {
+		name     string
+		override string
+		wantErr  string
+	}{
+		{name: "missing value", override: "settings.seed", wantErr: "expected path=value"},
+		{name: "empty path", override: "=1", wantErr: "expected path=value"},
+		{name: "invalid yaml", override: "iterators.region.values=[us", wantErr: `invalid override "iterators.region.values=[us"`},
+		{name: "unknown entry", override: "iterators.zone.end=5", wantErr: `no entry "zone"`},
+		{name: "index out of range", override: "metrics.3.description=x", wantErr: `no entry "3"`},
+		{name: "scalar", override: "iterators.shard.end.max=5", wantErr: `cannot set "max" in a scalar value`},
+		{name: "wrong type", override: "export.prometheus.port=high", wantErr: "failed to parse merged config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFiles([]string{path}, ParseOptions{Overrides: []string{tt.override}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
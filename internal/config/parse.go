@@ -2,30 +2,267 @@ package config
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 
 	"go.yaml.in/yaml/v4"
 )
 
+// ParseOptions adjusts merged configuration files before decoding.
+type ParseOptions struct {
+	Profiles  []string // Profile names or overlay file paths, applied in order
+	Overrides []string // path=value assignments, applied after profiles
+}
+
 // Parse reads and parses a YAML configuration file
 func Parse(path string) (*RawConfig, error) {
+	return ParseFiles([]string{path}, ParseOptions{})
+}
+
+// ParseFiles reads, merges, and parses configuration files.
+// Paths may be files or directories (all *.yaml/*.yml files, sorted by name).
+// Files are merged in order, each file's includes before the file itself:
+// mappings merge recursively, sequences concatenate, scalars are overridden.
+// Profiles and overrides of opts are applied to the merged configuration.
+func ParseFiles(paths []string, opts ParseOptions) (*RawConfig, error) {
+	loader := &fileLoader{visiting: make(map[string]bool)}
+
+	var merged *yaml.Node
+	for _, path := range paths {
+		node, err := loader.loadPath(path)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, node)
+	}
+
+	merged, err := applyProfiles(merged, opts.Profiles, loader)
+	if err != nil {
+		return nil, err
+	}
+	if merged, err = applyOverrides(merged, opts.Overrides); err != nil {
+		return nil, err
+	}
+
+	var raw RawConfig
+	if merged != nil {
+		data, err := yaml.Marshal(merged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge config files: %w", err)
+		}
+		if err := decodeStrict(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse merged config: %w", err)
+		}
+	}
+	raw.Include = nil
+	raw.Profiles = nil
+	raw.Files = loader.files
+
+	if err := Validate(&raw); err != nil {
+		return nil, err
+	}
+
+	return &raw, nil
+}
+
+// ParseBytes parses configuration from YAML data.
+// Includes are rejected since there is no file to resolve them against.
+func ParseBytes(data []byte) (*RawConfig, error) {
+	var raw RawConfig
+	if err := decodeStrict(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if len(raw.Include) > 0 {
+		return nil, fmt.Errorf("include not supported in inline config")
+	}
+
+	if err := Validate(&raw); err != nil {
+		return nil, err
+	}
+
+	return &raw, nil
+}
+
+// fileLoader reads configuration files and resolves includes.
+type fileLoader struct {
+	visiting map[string]bool // Include cycle detection
+	files    []string        // Loaded files in load order
+}
+
+// loadPath loads a file or all YAML files in a directory.
+func (l *fileLoader) loadPath(path string) (*yaml.Node, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if !info.IsDir() {
+		return l.loadFile(path)
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(path, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("config directory %q contains no yaml files", path)
+	}
+
+	var merged *yaml.Node
+	for _, file := range files {
+		node, err := l.loadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeNodes(merged, node)
+	}
+	return merged, nil
+}
+
+// loadFile loads a single file and merges its includes beneath it.
+func (l *fileLoader) loadFile(path string) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if l.visiting[absPath] {
+		return nil, fmt.Errorf("include cycle detected at %q", path)
+	}
+	l.visiting[absPath] = true
+	defer delete(l.visiting, absPath)
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
+	l.files = append(l.files, path)
 
+	// Substitute environment variables before parsing
+	data, err = expandEnv(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil // Empty file
+	}
+	node := doc.Content[0]
+
+	// Point legacy configs to migrate-config before strict decoding fails
+	if err := checkSchema(node, path); err != nil {
+		return nil, err
+	}
+
+	// Validate structure per file for precise error messages
 	var raw RawConfig
+	if err := decodeStrict(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
+	}
+
+	resolveIteratorPaths(node, filepath.Dir(path))
+
+	// Merge includes first so the including file takes precedence
+	var merged *yaml.Node
+	for _, include := range raw.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		matches, err := filepath.Glob(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include pattern %q: %w", path, include, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s: include %q matched no files", path, include)
+		}
+
+		for _, match := range matches {
+			included, err := l.loadPath(match)
+			if err != nil {
+				return nil, err
+			}
+			merged = mergeNodes(merged, included)
+		}
+	}
+
+	return mergeNodes(merged, node), nil
+}
+
+// resolveIteratorPaths makes relative file iterator paths relative to the
+// directory of the defining config file.
+func resolveIteratorPaths(root *yaml.Node, dir string) {
+	if root.Kind != yaml.MappingNode {
+		return
+	}
+	iterators := mappingValue(root, "iterators")
+	if iterators == nil || iterators.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, it := range iterators.Content {
+		if path := mappingValue(it, "path"); path != nil && path.Value != "" && !filepath.IsAbs(path.Value) {
+			path.Value = filepath.Join(dir, path.Value)
+		}
+	}
+}
+
+// decodeStrict decodes YAML data rejecting unknown fields.
+func decodeStrict(data []byte, raw *RawConfig) error {
 	decoder := yaml.NewDecoder(bytes.NewReader(data))
 	decoder.KnownFields(true) // Reject unknown fields
 
-	if err := decoder.Decode(&raw); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := decoder.Decode(raw); err != nil && !errors.Is(err, io.EOF) {
+		return err
 	}
+	return nil
+}
 
-	if err := Validate(&raw); err != nil {
-		return nil, err
+// mergeNodes merges src into dst and returns the result.
+// Mappings merge recursively, sequences concatenate, anything else is replaced.
+func mergeNodes(dst, src *yaml.Node) *yaml.Node {
+	if dst == nil {
+		return src
+	}
+	if src == nil || src.ShortTag() == "!!null" {
+		return dst
 	}
 
-	return &raw, nil
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+
+			found := false
+			for j := 0; j < len(dst.Content); j += 2 {
+				if dst.Content[j].Value == key.Value {
+					dst.Content[j+1] = mergeNodes(dst.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+		return dst
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode:
+		dst.Content = append(dst.Content, src.Content...)
+		return dst
+
+	default:
+		return src
+	}
 }
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// applyProfiles removes the profiles section from the merged configuration
+// and overlays the selected profiles in order. A profile is a name from the
+// profiles section or the path of an overlay file.
+func applyProfiles(merged *yaml.Node, selected []string, loader *fileLoader) (*yaml.Node, error) {
+	profiles := removeMappingKey(merged, "profiles")
+
+	for _, name := range selected {
+		var overlay *yaml.Node
+		if profile := profileNode(profiles, name); profile != nil {
+			overlay = profile
+		} else if _, err := os.Stat(name); err == nil {
+			if overlay, err = loader.loadPath(name); err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, fmt.Errorf("unknown profile %q (available: %s)", name, profileNames(profiles))
+		}
+
+		if overlay.Kind == yaml.MappingNode && mappingValue(overlay, "profiles") != nil {
+			return nil, fmt.Errorf("profile %q: profiles cannot be nested", name)
+		}
+		merged = overlayNodes(merged, overlay)
+	}
+
+	return merged, nil
+}
+
+// profileNode returns the overlay of a named profile, or nil.
+func profileNode(profiles *yaml.Node, name string) *yaml.Node {
+	if profiles == nil || profiles.Kind != yaml.MappingNode {
+		return nil
+	}
+	return mappingValue(profiles, name)
+}
+
+// profileNames lists the names of the profiles section.
+func profileNames(profiles *yaml.Node) string {
+	if profiles == nil || profiles.Kind != yaml.MappingNode || len(profiles.Content) == 0 {
+		return "none"
+	}
+	var names []string
+	for i := 0; i < len(profiles.Content); i += 2 {
+		names = append(names, profiles.Content[i].Value)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// removeMappingKey removes key from a mapping node and returns its value,
+// or nil if absent.
+func removeMappingKey(root *yaml.Node, key string) *yaml.Node {
+	if root == nil || root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			value := root.Content[i+1]
+			root.Content = slices.Delete(root.Content, i, i+2)
+			return value
+		}
+	}
+	return nil
+}
+
+// overlayNodes patches dst with src and returns the result. Unlike
+// mergeNodes, lists of named entries merge entries by name and append new
+// ones, and other lists are replaced, so a profile can change an iterator
+// range or a single metric without repeating the rest.
+func overlayNodes(dst, src *yaml.Node) *yaml.Node {
+	if dst == nil {
+		return src
+	}
+	if src == nil || src.ShortTag() == "!!null" {
+		return dst
+	}
+
+	switch {
+	case dst.Kind == yaml.MappingNode && src.Kind == yaml.MappingNode:
+		for i := 0; i < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+
+			found := false
+			for j := 0; j < len(dst.Content); j += 2 {
+				if dst.Content[j].Value == key.Value {
+					dst.Content[j+1] = overlayNodes(dst.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+			if !found {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+		return dst
+
+	case dst.Kind == yaml.SequenceNode && src.Kind == yaml.SequenceNode && namedEntries(src):
+		for _, entry := range src.Content {
+			name := mappingValue(entry, "name")
+			j := slices.IndexFunc(dst.Content, func(n *yaml.Node) bool {
+				return n.Kind == yaml.MappingNode && equalNodes(mappingValue(n, "name"), name)
+			})
+			if j >= 0 {
+				dst.Content[j] = overlayNodes(dst.Content[j], entry)
+			} else {
+				dst.Content = append(dst.Content, entry)
+			}
+		}
+		return dst
+
+	default:
+		return src
+	}
+}
+
+// namedEntries reports whether all entries of a sequence are mappings with a
+// name field.
+func namedEntries(seq *yaml.Node) bool {
+	for _, entry := range seq.Content {
+		if entry.Kind != yaml.MappingNode || mappingValue(entry, "name") == nil {
+			return false
+		}
+	}
+	return len(seq.Content) > 0
+}
+
+// equalNodes reports whether two nodes hold the same value. Names may be
+// scalars or mappings, like the protocol-specific metric names.
+func equalNodes(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Kind != b.Kind || a.Value != b.Value || len(a.Content) != len(b.Content) {
+		return false
+	}
+	for i := range a.Content {
+		if !equalNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
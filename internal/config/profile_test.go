@@ -0,0 +1,160 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// profileConfig defines a shard iterator and two profiles changing it.
+const profileConfig = `
+iterators:
+  - name: shard
+    type: range
+    start: 1
+    end: 10
+  - name: region
+    type: list
+    values: [us, eu]
+metrics:
+  - name: requests_total
+    type: counter
+    description: "Requests"
+    value:
+      source: {type: random_int, clock: {type: periodic, interval: 1s}, min: 0, max: 10}
+export:
+  prometheus:
+    enabled: true
+    port: 9090
+    path: /metrics
+profiles:
+  small:
+    iterators:
+      - name: shard
+        end: 2
+  huge:
+    iterators:
+      - name: shard
+        end: 1000
+      - name: zone
+        type: list
+        values: [a]
+    settings:
+      max_series: 5000
+`
+
+// writeConfig writes data to a config file in a temporary directory and
+// returns its path.
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// iteratorNamed returns the iterator called name, or nil.
+func iteratorNamed(raw *RawConfig, name string) *RawIterator {
+	for i := range raw.Iterators {
+		if raw.Iterators[i].Name == name {
+			return &raw.Iterators[i]
+		}
+	}
+	return nil
+}
+
+func TestProfilesOverlayNamedEntries(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+
+	raw, err := ParseFiles([]string{path}, ParseOptions{Profiles: []string{"huge"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The named entry is patched, keeping the fields the profile omits
+	shard := iteratorNamed(raw, "shard")
+	if shard == nil || shard.Type != "range" || *shard.Start != 1 || *shard.End != 1000 {
+		t.Errorf("shard = %+v, want range 1..1000", shard)
+	}
+
+	// Untouched entries are kept and new names are appended
+	if len(raw.Iterators) != 3 || iteratorNamed(raw, "region") == nil || raw.Iterators[2].Name != "zone" {
+		t.Errorf("iterators = %+v, want shard, region, zone", raw.Iterators)
+	}
+	if raw.Settings.MaxSeries == nil || *raw.Settings.MaxSeries != 5000 {
+		t.Errorf("max_series = %v, want 5000", raw.Settings.MaxSeries)
+	}
+	if raw.Profiles != nil {
+		t.Errorf("profiles = %v, want removed", raw.Profiles)
+	}
+}
+
+func TestProfilesApplyInOrder(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+
+	raw, err := ParseFiles([]string{path}, ParseOptions{Profiles: []string{"huge", "small"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end := *iteratorNamed(raw, "shard").End; end != 2 {
+		t.Errorf("shard end = %d, want 2 from the later profile", end)
+	}
+}
+
+func TestProfilesReplaceUnnamedLists(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+	overlay := writeConfig(t, "overlay.yaml", `
+iterators:
+  - name: region
+    values: [ap]
+`)
+
+	raw, err := ParseFiles([]string{path}, ParseOptions{Profiles: []string{overlay}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	region := iteratorNamed(raw, "region")
+	if region == nil || strings.Join(region.Values, ",") != "ap" || region.Type != "list" {
+		t.Errorf("region = %+v, want list [ap]", region)
+	}
+}
+
+func TestProfilesIgnoredWithoutSelection(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+
+	raw, err := ParseFiles([]string{path}, ParseOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end := *iteratorNamed(raw, "shard").End; end != 10 {
+		t.Errorf("shard end = %d, want 10", end)
+	}
+	if raw.Settings.MaxSeries != nil {
+		t.Errorf("max_series = %d, want unset", *raw.Settings.MaxSeries)
+	}
+}
+
+func TestProfilesErrors(t *testing.T) {
+	path := writeConfig(t, "config.yaml", profileConfig)
+	nested := writeConfig(t, "nested.yaml", "profiles:\n  inner:\n    settings:\n      max_series: 1\n")
+
+	tests := []struct {
+		name    string
+		profile string
+		wantErr string
+	}{
+		{name: "unknown", profile: "medium", wantErr: `unknown profile "medium" (available: huge, small)`},
+		{name: "nested", profile: nested, wantErr: "profiles cannot be nested"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFiles([]string{path}, ParseOptions{Profiles: []string{tt.profile}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
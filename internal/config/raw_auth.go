@@ -0,0 +1,32 @@
+package config
+
+// RawAuthConfig defines client authentication for push exporters.
+// At most one method may be configured.
+type RawAuthConfig struct {
+	Basic  *RawBasicAuthConfig  `yaml:"basic,omitempty"`
+	Bearer *RawBearerAuthConfig `yaml:"bearer,omitempty"`
+	OAuth2 *RawOAuth2Config     `yaml:"oauth2,omitempty"`
+}
+
+// RawBasicAuthConfig defines HTTP basic authentication
+type RawBasicAuthConfig struct {
+	Username     string `yaml:"username"`
+	Password     string `yaml:"password,omitempty"`
+	PasswordFile string `yaml:"password_file,omitempty"`
+}
+
+// RawBearerAuthConfig defines bearer token authentication
+type RawBearerAuthConfig struct {
+	Token     string `yaml:"token,omitempty"`
+	TokenFile string `yaml:"token_file,omitempty"`
+}
+
+// RawOAuth2Config defines the OAuth2 client credentials flow
+type RawOAuth2Config struct {
+	ClientID         string            `yaml:"client_id"`
+	ClientSecret     string            `yaml:"client_secret,omitempty"`
+	ClientSecretFile string            `yaml:"client_secret_file,omitempty"`
+	TokenURL         string            `yaml:"token_url"`
+	Scopes           []string          `yaml:"scopes,omitempty"`
+	EndpointParams   map[string]string `yaml:"endpoint_params,omitempty"`
+}
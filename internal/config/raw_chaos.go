@@ -0,0 +1,74 @@
+package config
+
+import (
+	"time"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// RawChaosConfig defines deliberate misbehavior for downstream testing
+type RawChaosConfig struct {
+	ScrapeDelay  *RawDelayConfig        `yaml:"scrape_delay,omitempty"`
+	ScrapeErrors []RawScrapeErrorConfig `yaml:"scrape_errors,omitempty"`
+	OTLPFaults   []RawOTLPFaultConfig   `yaml:"otlp_faults,omitempty"`
+
+	ProtocolViolations []RawProtocolViolationConfig `yaml:"protocol_violations,omitempty"`
+}
+
+// RawProtocolViolationConfig defines invalid data injected with a probability
+type RawProtocolViolationConfig struct {
+	Type        string  `yaml:"type"`
+	Probability float64 `yaml:"probability"`
+}
+
+// RawOTLPFaultConfig defines an OTLP push fault injected with a probability
+type RawOTLPFaultConfig struct {
+	Type        string          `yaml:"type"`
+	Probability float64         `yaml:"probability"`
+	Delay       *RawDelayConfig `yaml:"delay,omitempty"`
+}
+
+// RawScrapeErrorConfig defines a scrape failure injected with a probability
+type RawScrapeErrorConfig struct {
+	Type        string  `yaml:"type"`
+	Probability float64 `yaml:"probability"`
+	Status      int     `yaml:"status,omitempty"`
+}
+
+// RawDelayConfig defines a fixed or randomly distributed delay
+type RawDelayConfig struct {
+	Distribution string        `yaml:"distribution,omitempty"`
+	Delay        time.Duration `yaml:"delay,omitempty"`
+	Min          time.Duration `yaml:"min,omitempty"`
+	Max          time.Duration `yaml:"max,omitempty"`
+	Mean         time.Duration `yaml:"mean,omitempty"`
+	StdDev       time.Duration `yaml:"stddev,omitempty"`
+}
+
+// UnmarshalYAML handles both simple (2s) and detailed forms
+func (d *RawDelayConfig) UnmarshalYAML(value *yaml.Node) error {
+	// Try simple duration form first
+	var simple time.Duration
+	if err := value.Decode(&simple); err == nil {
+		*d = RawDelayConfig{Distribution: string(DelayFixed), Delay: simple}
+		return nil
+	}
+
+	// Fall back to detailed form
+	type delayConfig RawDelayConfig
+	var detailed delayConfig
+	if err := value.Decode(&detailed); err != nil {
+		return err
+	}
+	*d = RawDelayConfig(detailed)
+	return nil
+}
+
+// MarshalYAML emits the simple form for fixed delays
+func (d RawDelayConfig) MarshalYAML() (any, error) {
+	if d.Distribution == string(DelayFixed) {
+		return d.Delay, nil
+	}
+	type delayConfig RawDelayConfig
+	return delayConfig(d), nil
+}
@@ -9,6 +9,18 @@ type RawClockReference struct {
 	Template string        `yaml:"template,omitempty"`
 	Type     *string       `yaml:"type,omitempty"`
 	Interval time.Duration `yaml:"interval,omitempty"`
+	Expand   string        `yaml:"expand,omitempty"` // Iterator combination: "product" (default) or "zip"
+	Filter   []string      `yaml:"filter,omitempty"` // Conditions on iterator values, all must hold
+
+	deferred *deferredNode // Decoded on expansion (nil if decoded)
+}
+
+// UnmarshalYAML defers decoding of blocks with placeholders in non-string fields
+func (c *RawClockReference) UnmarshalYAML(unmarshal func(any) error) error {
+	type rawClockReference RawClockReference
+	deferred, err := decodeExpandable(unmarshal, (*rawClockReference)(c))
+	c.deferred = deferred
+	return err
 }
 
 // DeepCopy creates an independent copy of the clock reference
@@ -26,6 +38,10 @@ func (c RawClockReference) DeepCopy() RawClockReference {
 
 // FindPlaceholders implements expandable for RawClockReference
 func (c *RawClockReference) FindPlaceholders() []string {
+	if c.deferred != nil {
+		return c.deferred.placeholders()
+	}
+
 	found := make(map[string]bool)
 
 	// Scan string fields for {placeholder} patterns
@@ -49,7 +65,32 @@ func (c *RawClockReference) FindPlaceholders() []string {
 
 // SubstitutePlaceholders implements expandable for RawClockReference
 func (c *RawClockReference) SubstitutePlaceholders(iteratorValues map[string]string) {
+	if c.deferred != nil {
+		var decoded RawClockReference
+		if failed := c.deferred.decode(iteratorValues, &decoded); failed != nil {
+			c.deferred = failed
+			return
+		}
+		*c = decoded
+		return
+	}
+
 	c.Name = substitutePlaceholders(c.Name, iteratorValues)
 	c.Instance = substitutePlaceholders(c.Instance, iteratorValues)
 	c.Template = substitutePlaceholders(c.Template, iteratorValues)
 }
+
+// ExpansionError implements expandable for RawClockReference
+func (c *RawClockReference) ExpansionError() error {
+	return c.deferred.error()
+}
+
+// ExpansionMode implements expandable for RawClockReference
+func (c *RawClockReference) ExpansionMode() string {
+	return c.Expand
+}
+
+// ExpansionFilters implements expandable for RawClockReference
+func (c *RawClockReference) ExpansionFilters() []string {
+	return c.Filter
+}
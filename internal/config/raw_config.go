@@ -2,12 +2,22 @@ package config
 
 // RawConfig represents unparsed YAML structure
 type RawConfig struct {
+	Version   int               `yaml:"version,omitempty"` // Schema version (default: SchemaVersion)
+	Include   []string          `yaml:"include,omitempty"`
 	Iterators []RawIterator     `yaml:"iterators,omitempty"`
 	Templates RawTemplates      `yaml:"templates"`
 	Instances RawInstances      `yaml:"instances"`
 	Metrics   []RawMetricConfig `yaml:"metrics"`
+	Jobs      []RawJobConfig    `yaml:"jobs,omitempty"`
 	Export    RawExportConfig   `yaml:"export"`
 	Settings  RawSettingsConfig `yaml:"settings"`
+	Chaos     RawChaosConfig    `yaml:"chaos,omitempty"`
+
+	Profiles map[string]RawConfig `yaml:"profiles,omitempty"` // Named overlays selected with --profile
+
+	Files []string `yaml:"-"` // Files the configuration was loaded from
+
+	expander *Expander // Expands metrics during resolution (nil: metrics already expanded)
 }
 
 // RawTemplates holds all template definitions
@@ -10,24 +10,168 @@ import (
 type RawExportConfig struct {
 	Prometheus *RawPrometheusExportConfig `yaml:"prometheus,omitempty"`
 	OTEL       *RawOTELExportConfig       `yaml:"otel,omitempty"`
+	Custom     *RawCustomExportConfig     `yaml:"custom,omitempty"`
+}
+
+// RawCustomExportConfig selects a registered custom exporter
+type RawCustomExportConfig struct {
+	Name    string            `yaml:"name"`
+	Options map[string]any    `yaml:"options,omitempty"`
+	Restart *RawRestartConfig `yaml:"restart,omitempty"`
 }
 
 // RawPrometheusExportConfig defines Prometheus pull endpoint settings
 type RawPrometheusExportConfig struct {
+	Enabled          bool                     `yaml:"enabled"`
+	Host             string                   `yaml:"host,omitempty"`
+	Port             int                      `yaml:"port"`
+	Path             string                   `yaml:"path"`
+	SocketActivation bool                     `yaml:"socket_activation,omitempty"`
+	LandingPage      *bool                    `yaml:"landing_page,omitempty"`
+	ConstLabels      map[string]string        `yaml:"const_labels,omitempty"`
+	LabelDetectors   []string                 `yaml:"label_detectors,omitempty"`
+	ProcessMetrics   *RawProcessMetricsConfig `yaml:"process_metrics,omitempty"`
+	Auth             *RawAuthConfig           `yaml:"auth,omitempty"`
+	Exposition       RawExpositionConfig      `yaml:"exposition,omitempty"`
+	TargetParams     []string                 `yaml:"target_params,omitempty"`
+	Timestamps       *RawTimestampConfig      `yaml:"timestamps,omitempty"`
+	Upstreams        []RawUpstreamConfig      `yaml:"upstreams,omitempty"`
+	Federation       *RawFederationConfig     `yaml:"federation,omitempty"`
+	Paths            []RawScrapePathConfig    `yaml:"paths,omitempty"`
+	Metadata         *RawMetadataConfig       `yaml:"metadata,omitempty"`
+	Response         *RawResponseConfig       `yaml:"response,omitempty"`
+	Restart          *RawRestartConfig        `yaml:"restart,omitempty"`
+}
+
+// RawResponseConfig defines scrape response compression and size
+type RawResponseConfig struct {
+	Compression string   `yaml:"compression,omitempty"`
+	MinSize     ByteSize `yaml:"min_size,omitempty"`
+	MaxSize     ByteSize `yaml:"max_size,omitempty"`
+}
+
+// RawMetadataConfig defines metadata and _created emission
+type RawMetadataConfig struct {
+	Created   bool     `yaml:"created,omitempty"`
+	Help      *bool    `yaml:"help,omitempty"`
+	Type      *bool    `yaml:"type,omitempty"`
+	Unit      bool     `yaml:"unit,omitempty"`
+	Malformed []string `yaml:"malformed,omitempty"`
+}
+
+// RawScrapePathConfig defines an additional scrape path
+type RawScrapePathConfig struct {
+	Path  string          `yaml:"path"`
+	Match []string        `yaml:"match,omitempty"`
+	Chaos *RawChaosConfig `yaml:"chaos,omitempty"`
+}
+
+// RawFederationConfig defines the federation endpoint
+type RawFederationConfig struct {
 	Enabled bool   `yaml:"enabled"`
-	Port    int    `yaml:"port"`
-	Path    string `yaml:"path"`
+	Path    string `yaml:"path,omitempty"`
+}
+
+// RawUpstreamConfig defines a scraped endpoint re-exposed with replicas
+type RawUpstreamConfig struct {
+	URL          string            `yaml:"url"`
+	Interval     time.Duration     `yaml:"interval,omitempty"`
+	Timeout      time.Duration     `yaml:"timeout,omitempty"`
+	Replicas     int               `yaml:"replicas,omitempty"`
+	ReplicaLabel string            `yaml:"replica_label,omitempty"`
+	Labels       map[string]string `yaml:"labels,omitempty"`
+}
+
+// RawExpositionConfig defines the formats the scrape endpoint negotiates
+type RawExpositionConfig struct {
+	Formats []string `yaml:"formats,omitempty"`
+	Force   string   `yaml:"force,omitempty"`
+}
+
+// RawProcessMetricsConfig defines emulated process and Go runtime metrics
+type RawProcessMetricsConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	TargetLabels []string `yaml:"target_labels,omitempty"`
 }
 
 // RawOTELExportConfig defines OTEL push settings
 type RawOTELExportConfig struct {
-	Enabled   bool              `yaml:"enabled"`
-	Transport string            `yaml:"transport"`
-	Host      string            `yaml:"host"`
-	Port      int               `yaml:"port"`
-	Interval  RawIntervalConfig `yaml:"interval"`
-	Resource  map[string]string `yaml:"resource,omitempty"`
-	Headers   map[string]string `yaml:"headers,omitempty"`
+	Enabled    bool               `yaml:"enabled"`
+	Transport  string             `yaml:"transport"`
+	Host       string             `yaml:"host"`
+	Port       int                `yaml:"port"`
+	TLS        bool               `yaml:"tls,omitempty"`
+	Path       string             `yaml:"path,omitempty"`
+	Interval   RawIntervalConfig  `yaml:"interval"`
+	Resource   map[string]string  `yaml:"resource,omitempty"`
+	Detectors  []string           `yaml:"resource_detectors,omitempty"`
+	Headers    map[string]string  `yaml:"headers,omitempty"`
+	StartTime  RawStartTimeConfig `yaml:"start_time,omitempty"`
+	DNSRefresh time.Duration      `yaml:"dns_refresh,omitempty"`
+	Reconnect  RawReconnectConfig `yaml:"reconnect,omitempty"`
+
+	Temporality RawTemporalityConfig `yaml:"temporality,omitempty"`
+	Aggregation RawAggregationConfig `yaml:"aggregation,omitempty"`
+
+	Compression string         `yaml:"compression,omitempty"`
+	Timeout     time.Duration  `yaml:"timeout,omitempty"`
+	Retry       RawRetryConfig `yaml:"retry,omitempty"`
+
+	MaxDataPoints int `yaml:"max_data_points,omitempty"`
+
+	Timestamps *RawTimestampConfig `yaml:"timestamps,omitempty"`
+
+	Auth *RawAuthConfig `yaml:"auth,omitempty"`
+
+	Payload string `yaml:"payload,omitempty"`
+
+	Restart *RawRestartConfig `yaml:"restart,omitempty"`
+}
+
+// RawRetryConfig defines the OTLP retry and backoff policy
+type RawRetryConfig struct {
+	Enabled         *bool         `yaml:"enabled,omitempty"`
+	InitialInterval time.Duration `yaml:"initial_interval,omitempty"`
+	MaxInterval     time.Duration `yaml:"max_interval,omitempty"`
+	MaxElapsedTime  time.Duration `yaml:"max_elapsed_time,omitempty"`
+}
+
+// RawTemporalityConfig defines OTEL temporality per metric type
+type RawTemporalityConfig struct {
+	Counter string `yaml:"counter,omitempty"`
+	Gauge   string `yaml:"gauge,omitempty"`
+}
+
+// RawAggregationConfig defines OTEL aggregation per metric type
+type RawAggregationConfig struct {
+	Counter string `yaml:"counter,omitempty"`
+	Gauge   string `yaml:"gauge,omitempty"`
+}
+
+// RawReconnectConfig defines scheduled OTEL connection teardown
+type RawReconnectConfig struct {
+	Pushes   int           `yaml:"pushes,omitempty"`
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// RawStartTimeConfig defines counter start timestamp semantics for OTEL
+type RawStartTimeConfig struct {
+	Mode          string        `yaml:"mode,omitempty"`
+	Time          time.Time     `yaml:"time,omitempty"`
+	ResetInterval time.Duration `yaml:"reset_interval,omitempty"`
+}
+
+// RawTimestampConfig defines explicit sample timestamps offset from wall clock
+type RawTimestampConfig struct {
+	Offset time.Duration `yaml:"offset,omitempty"`
+	Jitter time.Duration `yaml:"jitter,omitempty"`
+}
+
+// RawRestartConfig defines the exporter restart policy after failures
+type RawRestartConfig struct {
+	MaxRestarts    int           `yaml:"max_restarts,omitempty"`
+	InitialBackoff time.Duration `yaml:"initial_backoff,omitempty"`
+	MaxBackoff     time.Duration `yaml:"max_backoff,omitempty"`
 }
 
 // RawIntervalConfig defines read and push intervals for OTEL
@@ -59,3 +203,54 @@ func (i *RawIntervalConfig) UnmarshalYAML(value *yaml.Node) error {
 	i.Push = detailed.Push
 	return nil
 }
+
+// MarshalYAML emits the simple form when both intervals are equal
+func (i RawIntervalConfig) MarshalYAML() (any, error) {
+	if i.Read == i.Push {
+		return i.Read, nil
+	}
+	return struct {
+		Read time.Duration `yaml:"read"`
+		Push time.Duration `yaml:"push"`
+	}{i.Read, i.Push}, nil
+}
+
+// UnmarshalYAML handles both simple (delta) and per-type (counter/gauge) forms
+func (t *RawTemporalityConfig) UnmarshalYAML(value *yaml.Node) error {
+	// Try simple form first
+	var simple string
+	if err := value.Decode(&simple); err == nil {
+		t.Counter = simple
+		t.Gauge = simple
+		return nil
+	}
+
+	// Fall back to per-type form
+	type temporalityConfig struct {
+		Counter string `yaml:"counter"`
+		Gauge   string `yaml:"gauge"`
+	}
+	var detailed temporalityConfig
+	if err := value.Decode(&detailed); err != nil {
+		return err
+	}
+	t.Counter = detailed.Counter
+	t.Gauge = detailed.Gauge
+	return nil
+}
+
+// MarshalYAML emits the simple form when both types are equal
+func (t RawTemporalityConfig) MarshalYAML() (any, error) {
+	if t.Counter == t.Gauge {
+		return t.Counter, nil
+	}
+	return struct {
+		Counter string `yaml:"counter,omitempty"`
+		Gauge   string `yaml:"gauge,omitempty"`
+	}{t.Counter, t.Gauge}, nil
+}
+
+// IsZero reports whether no temporality is configured
+func (t RawTemporalityConfig) IsZero() bool {
+	return t.Counter == "" && t.Gauge == ""
+}
@@ -2,9 +2,26 @@ package config
 
 // RawIterator defines a single iterator for config expansion
 type RawIterator struct {
-	Name   string   `yaml:"name"`
-	Type   string   `yaml:"type"` // "range" or "list"
-	Start  *int     `yaml:"start,omitempty"`
-	End    *int     `yaml:"end,omitempty"`
-	Values []string `yaml:"values,omitempty"`
+	Name           string             `yaml:"name"`
+	Type           string             `yaml:"type"` // "range", "list", "weighted_list", "file", or "random"
+	Start          *int               `yaml:"start,omitempty"`
+	End            *int               `yaml:"end,omitempty"`
+	Step           *int               `yaml:"step,omitempty"`   // Range increment (default: 1)
+	Format         string             `yaml:"format,omitempty"` // Range printf format, e.g. "node-%03d"
+	Pad            int                `yaml:"pad,omitempty"`    // Range zero-padding width
+	Prefix         string             `yaml:"prefix,omitempty"`
+	Suffix         string             `yaml:"suffix,omitempty"`
+	Values         []string           `yaml:"values,omitempty"`
+	WeightedValues []RawWeightedValue `yaml:"weighted_values,omitempty"`
+	Path           string             `yaml:"path,omitempty"`     // Relative to the defining config file
+	Count          int                `yaml:"count,omitempty"`    // Random: number of values
+	Generate       string             `yaml:"generate,omitempty"` // Random: "uuid", "hex", or "word"
+	Length         int                `yaml:"length,omitempty"`   // Random hex: digits (default: 16)
+	Exclude        []string           `yaml:"exclude,omitempty"`  // Values removed from any iterator type
+}
+
+// RawWeightedValue defines a value repeated weight times by a weighted list
+type RawWeightedValue struct {
+	Value  string `yaml:"value"`
+	Weight int    `yaml:"weight"`
 }
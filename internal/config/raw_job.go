@@ -0,0 +1,10 @@
+package config
+
+// RawJobConfig groups metrics with constant labels and an optional
+// dedicated export endpoint
+type RawJobConfig struct {
+	Name    string            `yaml:"name"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+	Metrics []RawMetricConfig `yaml:"metrics"`
+	Export  *RawExportConfig  `yaml:"export,omitempty"` // Shares the top-level export if unset
+}
@@ -1,14 +1,43 @@
 package config
 
-import "go.yaml.in/yaml/v4"
+import (
+	"slices"
+
+	"go.yaml.in/yaml/v4"
+)
 
 // RawMetricConfig with polymorphic value field
 type RawMetricConfig struct {
 	Name        RawMetricNameConfig `yaml:"name"`
 	Type        string              `yaml:"type"`
 	Description string              `yaml:"description"`
+	Unit        string              `yaml:"unit,omitempty"` // OTEL unit (UCUM), e.g. "s" or "By"
 	Value       RawValueReference   `yaml:"value"`
 	Attributes  map[string]string   `yaml:"attributes,omitempty"`
+	Payload     *RawPayloadConfig   `yaml:"payload,omitempty"`
+	// Fraction of reads in which the series is emitted (default: 1)
+	EmitProbability *float64 `yaml:"emit_probability,omitempty"`
+	Expand          string   `yaml:"expand,omitempty"` // Iterator combination: "product" (default) or "zip"
+	Filter          []string `yaml:"filter,omitempty"` // Conditions on iterator values, all must hold
+	// Protocols exporting the metric: "prometheus", "otel" (default: all)
+	ExportTo []string `yaml:"export_to,omitempty"`
+
+	deferred *deferredNode // Decoded on expansion (nil if decoded)
+}
+
+// UnmarshalYAML defers decoding of metrics with placeholders in non-string fields
+func (m *RawMetricConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	type rawMetricConfig RawMetricConfig
+	deferred, err := decodeExpandable(unmarshal, (*rawMetricConfig)(m))
+	m.deferred = deferred
+	return err
+}
+
+// RawPayloadConfig defines large generated attribute values for stress testing
+type RawPayloadConfig struct {
+	Labels int      `yaml:"labels"`
+	Size   ByteSize `yaml:"size"`
+	Prefix string   `yaml:"prefix,omitempty"`
 }
 
 // DeepCopy creates an independent copy of the metric config
@@ -29,11 +58,32 @@ func (m RawMetricConfig) DeepCopy() RawMetricConfig {
 		}
 	}
 
+	// Deep copy payload config
+	if m.Payload != nil {
+		payloadCopy := *m.Payload
+		clone.Payload = &payloadCopy
+	}
+
+	// Deep copy emit probability
+	if m.EmitProbability != nil {
+		probabilityCopy := *m.EmitProbability
+		clone.EmitProbability = &probabilityCopy
+	}
+
+	// Deep copy export protocols
+	if len(m.ExportTo) > 0 {
+		clone.ExportTo = slices.Clone(m.ExportTo)
+	}
+
 	return clone
 }
 
 // FindPlaceholders implements expandable for RawMetricConfig
 func (m *RawMetricConfig) FindPlaceholders() []string {
+	if m.deferred != nil {
+		return m.deferred.placeholders()
+	}
+
 	found := make(map[string]bool)
 
 	// Scan name fields
@@ -41,6 +91,11 @@ func (m *RawMetricConfig) FindPlaceholders() []string {
 		found[name] = true
 	}
 
+	// Scan description
+	for _, name := range extractPlaceholderNames(m.Description) {
+		found[name] = true
+	}
+
 	// Scan attribute keys and values
 	for key, value := range m.Attributes {
 		for _, name := range extractPlaceholderNames(key) {
@@ -66,8 +121,19 @@ func (m *RawMetricConfig) FindPlaceholders() []string {
 
 // SubstitutePlaceholders implements expandable for RawMetricConfig
 func (m *RawMetricConfig) SubstitutePlaceholders(iteratorValues map[string]string) {
+	if m.deferred != nil {
+		var decoded RawMetricConfig
+		if failed := m.deferred.decode(iteratorValues, &decoded); failed != nil {
+			m.deferred = failed
+			return
+		}
+		*m = decoded
+		return
+	}
+
 	// Substitute in name
 	m.Name.SubstitutePlaceholders(iteratorValues)
+	m.Description = substitutePlaceholders(m.Description, iteratorValues)
 
 	// Substitute in attributes - both keys and values
 	if len(m.Attributes) > 0 {
@@ -150,6 +216,17 @@ func (m *RawMetricNameConfig) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
+// MarshalYAML emits the string form for short-form names
+func (m RawMetricNameConfig) MarshalYAML() (any, error) {
+	if m.Simple != "" {
+		return m.Simple, nil
+	}
+	return struct {
+		Prometheus string `yaml:"prometheus"`
+		OTEL       string `yaml:"otel"`
+	}{m.Prometheus, m.OTEL}, nil
+}
+
 // GetPrometheusName returns the Prometheus metric name
 func (m *RawMetricNameConfig) GetPrometheusName() string {
 	if m.Simple != "" {
@@ -165,3 +242,21 @@ func (m *RawMetricNameConfig) GetOTELName() string {
 	}
 	return m.OTEL
 }
+
+// ExpansionError implements expandable for RawMetricConfig
+func (m *RawMetricConfig) ExpansionError() error {
+	if err := m.deferred.error(); err != nil {
+		return err
+	}
+	return m.Value.ExpansionError()
+}
+
+// ExpansionMode implements expandable for RawMetricConfig
+func (m *RawMetricConfig) ExpansionMode() string {
+	return m.Expand
+}
+
+// ExpansionFilters implements expandable for RawMetricConfig
+func (m *RawMetricConfig) ExpansionFilters() []string {
+	return m.Filter
+}
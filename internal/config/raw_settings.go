@@ -1,9 +1,28 @@
 package config
 
+import "time"
+
 // RawSettingsConfig holds general application settings
 type RawSettingsConfig struct {
 	Seed            *uint64                  `yaml:"seed,omitempty"`
+	MaxSeries       *int                     `yaml:"max_series,omitempty"`   // 0 disables the limit
+	RunDuration     time.Duration            `yaml:"run_duration,omitempty"` // 0 runs until stopped
+	Generation      string                   `yaml:"generation,omitempty"`
+	ResourceCheck   string                   `yaml:"resource_check,omitempty"`
+	Ramp            *RawRampConfig           `yaml:"ramp,omitempty"`
+	Throttle        *RawThrottleConfig       `yaml:"throttle,omitempty"`
+	LoadShedding    *RawLoadSheddingConfig   `yaml:"load_shedding,omitempty"`
+	Drain           *RawDrainConfig          `yaml:"drain,omitempty"`
 	InternalMetrics RawInternalMetricsConfig `yaml:"internal_metrics"`
+	Panics          RawPanicConfig           `yaml:"panics"`
+	Logging         RawLoggingConfig         `yaml:"logging"`
+	RNG             RawRNGConfig             `yaml:"rng"`
+	Debug           RawDebugConfig           `yaml:"debug,omitempty"`
+	Naming          RawNamingConfig          `yaml:"naming,omitempty"`
+	Monitor         RawMonitorConfig         `yaml:"monitor,omitempty"`
+	Shard           *RawShardConfig          `yaml:"shard,omitempty"`
+	Signals         RawSignalsConfig         `yaml:"signals,omitempty"`
+	Receiver        *RawReceiverConfig       `yaml:"receiver,omitempty"`
 }
 
 // RawInternalMetricsConfig controls otelbox's self-monitoring metrics
@@ -11,3 +30,97 @@ type RawInternalMetricsConfig struct {
 	Enabled bool   `yaml:"enabled"`
 	Format  string `yaml:"format"`
 }
+
+// RawPanicConfig controls handling of recovered panics
+type RawPanicConfig struct {
+	DisableSeries bool `yaml:"disable_series"`
+}
+
+// RawRampConfig controls the warm-up of source rates
+type RawRampConfig struct {
+	Start    float64       `yaml:"start"` // Fraction of the configured rates at startup
+	Duration time.Duration `yaml:"duration"`
+}
+
+// RawThrottleConfig caps generated observations and exported data points
+type RawThrottleConfig struct {
+	MaxObservationsPerSecond int    `yaml:"max_observations_per_second,omitempty"` // 0 disables the limit
+	MaxPointsPerSecond       int    `yaml:"max_points_per_second,omitempty"`       // 0 disables the limit
+	Policy                   string `yaml:"policy,omitempty"`
+}
+
+// RawLoadSheddingConfig lowers generation rates under CPU saturation
+type RawLoadSheddingConfig struct {
+	Step         float64 `yaml:"step,omitempty"`
+	MinFactor    float64 `yaml:"min_factor,omitempty"`
+	RecoverBelow float64 `yaml:"recover_below,omitempty"`
+}
+
+// RawDrainConfig controls the drain phase on shutdown; present enables it
+type RawDrainConfig struct {
+	ScrapeWindow time.Duration `yaml:"scrape_window,omitempty"`
+	Timeout      time.Duration `yaml:"timeout,omitempty"`
+}
+
+// RawReceiverConfig controls the embedded OTLP receiver; present enables it
+type RawReceiverConfig struct {
+	GRPCPort int `yaml:"grpc_port,omitempty"`
+	HTTPPort int `yaml:"http_port,omitempty"`
+}
+
+// RawLoggingConfig controls log format, destination, levels, and sampling
+// of high-frequency debug logs
+type RawLoggingConfig struct {
+	Format       string            `yaml:"format,omitempty"`
+	Output       string            `yaml:"output,omitempty"`
+	Level        string            `yaml:"level,omitempty"`
+	Modules      map[string]string `yaml:"modules,omitempty"`
+	MaxSize      ByteSize          `yaml:"max_size,omitempty"`
+	MaxFiles     int               `yaml:"max_files,omitempty"`
+	SampleEvery  int               `yaml:"sample_every,omitempty"`
+	MaxPerSecond int               `yaml:"max_per_second,omitempty"`
+}
+
+// RawRNGConfig selects the random number generator
+type RawRNGConfig struct {
+	Type string `yaml:"type,omitempty"`
+	File string `yaml:"file,omitempty"`
+}
+
+// RawNamingConfig controls translation between Prometheus and OTEL names
+type RawNamingConfig struct {
+	Translate bool `yaml:"translate"`
+	UTF8      bool `yaml:"utf8,omitempty"` // Keep dots and other UTF-8 characters in Prometheus names
+}
+
+// RawDebugConfig controls the profiling and runtime debug endpoints
+type RawDebugConfig struct {
+	Enabled bool `yaml:"enabled"`
+	Port    int  `yaml:"port,omitempty"`
+}
+
+// RawMonitorConfig controls the resource monitor
+type RawMonitorConfig struct {
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// RawSignalsConfig maps user signals to actions
+type RawSignalsConfig struct {
+	USR1     string `yaml:"usr1,omitempty"`
+	USR2     string `yaml:"usr2,omitempty"`
+	DumpFile string `yaml:"dump_file,omitempty"`
+}
+
+// RawShardConfig selects the part of the metrics generated by this instance
+type RawShardConfig struct {
+	Index int `yaml:"index"` // 1-based
+	Count int `yaml:"count"`
+}
+
+// maxSeries returns the series limit with the default applied.
+func (s RawSettingsConfig) maxSeries() int {
+	if s.MaxSeries == nil {
+		return DefaultMaxSeries
+	}
+	return *s.MaxSeries
+}
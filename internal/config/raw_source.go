@@ -1,14 +1,32 @@
 package config
 
+import "maps"
+
 // RawSourceReference handles polymorphic source field (instance/template/inline)
 type RawSourceReference struct {
-	Name     string             `yaml:"name,omitempty"` // Only used in templates/instances arrays
-	Instance string             `yaml:"instance,omitempty"`
-	Template string             `yaml:"template,omitempty"`
-	Type     *string            `yaml:"type,omitempty"`
-	Clock    *RawClockReference `yaml:"clock,omitempty"`
-	Min      *int               `yaml:"min,omitempty"`
-	Max      *int               `yaml:"max,omitempty"`
+	Name        string             `yaml:"name,omitempty"` // Only used in templates/instances arrays
+	Instance    string             `yaml:"instance,omitempty"`
+	Template    string             `yaml:"template,omitempty"`
+	Type        *string            `yaml:"type,omitempty"`
+	Clock       *RawClockReference `yaml:"clock,omitempty"`
+	Min         *int               `yaml:"min,omitempty"`
+	Max         *int               `yaml:"max,omitempty"`
+	Group       string             `yaml:"group,omitempty"`       // Correlated sources only
+	Correlation *float64           `yaml:"correlation,omitempty"` // Correlated sources only
+	Options     map[string]any     `yaml:"options,omitempty"`     // Custom source types only
+	Replay      *RawReplayConfig   `yaml:"replay,omitempty"`      // Replay sources only
+	Expand      string             `yaml:"expand,omitempty"`      // Iterator combination: "product" (default) or "zip"
+	Filter      []string           `yaml:"filter,omitempty"`      // Conditions on iterator values, all must hold
+
+	deferred *deferredNode // Decoded on expansion (nil if decoded)
+}
+
+// UnmarshalYAML defers decoding of blocks with placeholders in non-string fields
+func (s *RawSourceReference) UnmarshalYAML(unmarshal func(any) error) error {
+	type rawSourceReference RawSourceReference
+	deferred, err := decodeExpandable(unmarshal, (*rawSourceReference)(s))
+	s.deferred = deferred
+	return err
 }
 
 // DeepCopy creates an independent copy of the source reference
@@ -31,6 +49,18 @@ func (s RawSourceReference) DeepCopy() RawSourceReference {
 		clone.Max = &maxCopy
 	}
 
+	if s.Correlation != nil {
+		correlationCopy := *s.Correlation
+		clone.Correlation = &correlationCopy
+	}
+
+	clone.Options = maps.Clone(s.Options)
+
+	if s.Replay != nil {
+		replayCopy := s.Replay.DeepCopy()
+		clone.Replay = &replayCopy
+	}
+
 	// Deep copy nested clock reference
 	if s.Clock != nil {
 		clockCopy := s.Clock.DeepCopy()
@@ -42,6 +72,10 @@ func (s RawSourceReference) DeepCopy() RawSourceReference {
 
 // FindPlaceholders implements expandable for RawSourceReference
 func (s *RawSourceReference) FindPlaceholders() []string {
+	if s.deferred != nil {
+		return s.deferred.placeholders()
+	}
+
 	found := make(map[string]bool)
 
 	// Scan own string fields
@@ -54,6 +88,14 @@ func (s *RawSourceReference) FindPlaceholders() []string {
 	for _, name := range extractPlaceholderNames(s.Template) {
 		found[name] = true
 	}
+	for _, name := range extractPlaceholderNames(s.Group) {
+		found[name] = true
+	}
+	if s.Replay != nil {
+		for _, name := range s.Replay.FindPlaceholders() {
+			found[name] = true
+		}
+	}
 
 	// Recursively scan nested clock
 	if s.Clock != nil {
@@ -72,12 +114,86 @@ func (s *RawSourceReference) FindPlaceholders() []string {
 
 // SubstitutePlaceholders implements expandable for RawSourceReference
 func (s *RawSourceReference) SubstitutePlaceholders(iteratorValues map[string]string) {
+	if s.deferred != nil {
+		var decoded RawSourceReference
+		if failed := s.deferred.decode(iteratorValues, &decoded); failed != nil {
+			s.deferred = failed
+			return
+		}
+		*s = decoded
+		return
+	}
+
 	s.Name = substitutePlaceholders(s.Name, iteratorValues)
 	s.Instance = substitutePlaceholders(s.Instance, iteratorValues)
 	s.Template = substitutePlaceholders(s.Template, iteratorValues)
+	s.Group = substitutePlaceholders(s.Group, iteratorValues)
+	if s.Replay != nil {
+		s.Replay.SubstitutePlaceholders(iteratorValues)
+	}
 
 	// Recursively substitute in nested clock
 	if s.Clock != nil {
 		s.Clock.SubstitutePlaceholders(iteratorValues)
 	}
 }
+
+// ExpansionError implements expandable for RawSourceReference
+func (s *RawSourceReference) ExpansionError() error {
+	if err := s.deferred.error(); err != nil {
+		return err
+	}
+	if s.Clock != nil {
+		return s.Clock.ExpansionError()
+	}
+	return nil
+}
+
+// ExpansionMode implements expandable for RawSourceReference
+func (s *RawSourceReference) ExpansionMode() string {
+	return s.Expand
+}
+
+// ExpansionFilters implements expandable for RawSourceReference
+func (s *RawSourceReference) ExpansionFilters() []string {
+	return s.Filter
+}
+
+// RawReplayConfig selects a recorded series to replay
+type RawReplayConfig struct {
+	File   string            `yaml:"file"`
+	Metric string            `yaml:"metric"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+	Scale  *float64          `yaml:"scale,omitempty"`
+	Loop   bool              `yaml:"loop,omitempty"`
+}
+
+// DeepCopy creates an independent copy of the replay config
+func (r RawReplayConfig) DeepCopy() RawReplayConfig {
+	clone := r
+	clone.Labels = maps.Clone(r.Labels)
+	if r.Scale != nil {
+		scaleCopy := *r.Scale
+		clone.Scale = &scaleCopy
+	}
+	return clone
+}
+
+// FindPlaceholders returns placeholders in the file, metric, and label values
+func (r *RawReplayConfig) FindPlaceholders() []string {
+	names := extractPlaceholderNames(r.File)
+	names = append(names, extractPlaceholderNames(r.Metric)...)
+	for _, value := range r.Labels {
+		names = append(names, extractPlaceholderNames(value)...)
+	}
+	return names
+}
+
+// SubstitutePlaceholders replaces placeholders in the file, metric, and label values
+func (r *RawReplayConfig) SubstitutePlaceholders(iteratorValues map[string]string) {
+	r.File = substitutePlaceholders(r.File, iteratorValues)
+	r.Metric = substitutePlaceholders(r.Metric, iteratorValues)
+	for name, value := range r.Labels {
+		r.Labels[name] = substitutePlaceholders(value, iteratorValues)
+	}
+}
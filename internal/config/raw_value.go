@@ -1,15 +1,35 @@
 package config
 
-import "go.yaml.in/yaml/v4"
+import (
+	"maps"
+	"slices"
+
+	"go.yaml.in/yaml/v4"
+)
 
 // RawValueReference handles polymorphic value field (instance/template/inline)
 type RawValueReference struct {
-	Name       string              `yaml:"name,omitempty"` // Only used in templates/instances arrays
-	Instance   string              `yaml:"instance,omitempty"`
-	Template   string              `yaml:"template,omitempty"`
-	Source     *RawSourceReference `yaml:"source,omitempty"`
-	Transforms []TransformConfig   `yaml:"transforms,omitempty"`
-	Reset      ResetConfig         `yaml:"reset,omitempty"`
+	Name          string                  `yaml:"name,omitempty"` // Only used in templates/instances arrays
+	Instance      string                  `yaml:"instance,omitempty"`
+	Template      string                  `yaml:"template,omitempty"`
+	Source        *RawSourceReference     `yaml:"source,omitempty"`
+	Transforms    []TransformConfig       `yaml:"transforms,omitempty"`
+	Reset         ResetConfig             `yaml:"reset,omitempty"`
+	Initial       *int                    `yaml:"initial,omitempty"`
+	Split         *RawSplitConfig         `yaml:"split,omitempty"`
+	SpecialValues []RawSpecialValueConfig `yaml:"special_values,omitempty"`
+	Expand        string                  `yaml:"expand,omitempty"` // Iterator combination: "product" (default) or "zip"
+	Filter        []string                `yaml:"filter,omitempty"` // Conditions on iterator values, all must hold
+
+	deferred *deferredNode // Decoded on expansion (nil if decoded)
+}
+
+// UnmarshalYAML defers decoding of blocks with placeholders in non-string fields
+func (v *RawValueReference) UnmarshalYAML(unmarshal func(any) error) error {
+	type rawValueReference RawValueReference
+	deferred, err := decodeExpandable(unmarshal, (*rawValueReference)(v))
+	v.deferred = deferred
+	return err
 }
 
 // DeepCopy creates an independent copy of the value reference
@@ -26,15 +46,38 @@ func (v RawValueReference) DeepCopy() RawValueReference {
 	if len(v.Transforms) > 0 {
 		clone.Transforms = make([]TransformConfig, len(v.Transforms))
 		copy(clone.Transforms, v.Transforms)
+		for i, t := range v.Transforms {
+			clone.Transforms[i].Levels = slices.Clone(t.Levels)
+			clone.Transforms[i].Options = maps.Clone(t.Options)
+		}
 	}
 
 	// Reset config is plain struct, no pointers to copy
 
+	// Deep copy initial value
+	if v.Initial != nil {
+		initialCopy := *v.Initial
+		clone.Initial = &initialCopy
+	}
+
+	// Deep copy split config
+	if v.Split != nil {
+		splitCopy := *v.Split
+		clone.Split = &splitCopy
+	}
+
+	// Special value rules are plain structs
+	clone.SpecialValues = slices.Clone(v.SpecialValues)
+
 	return clone
 }
 
 // FindPlaceholders implements expandable for RawValueReference
 func (v *RawValueReference) FindPlaceholders() []string {
+	if v.deferred != nil {
+		return v.deferred.placeholders()
+	}
+
 	found := make(map[string]bool)
 
 	// Scan own string fields
@@ -65,6 +108,16 @@ func (v *RawValueReference) FindPlaceholders() []string {
 
 // SubstitutePlaceholders implements expandable for RawValueReference
 func (v *RawValueReference) SubstitutePlaceholders(iteratorValues map[string]string) {
+	if v.deferred != nil {
+		var decoded RawValueReference
+		if failed := v.deferred.decode(iteratorValues, &decoded); failed != nil {
+			v.deferred = failed
+			return
+		}
+		*v = decoded
+		return
+	}
+
 	v.Name = substitutePlaceholders(v.Name, iteratorValues)
 	v.Instance = substitutePlaceholders(v.Instance, iteratorValues)
 	v.Template = substitutePlaceholders(v.Template, iteratorValues)
@@ -77,7 +130,10 @@ func (v *RawValueReference) SubstitutePlaceholders(iteratorValues map[string]str
 
 // TransformConfig defines a transform operation
 type TransformConfig struct {
-	Type string
+	Type    string
+	Levels  []int          // quantize: allowed output levels
+	Step    int            // quantize: snap to multiples of step
+	Options map[string]any // Custom transform types only
 }
 
 // UnmarshalYAML handles both string and object forms for transforms
@@ -91,16 +147,47 @@ func (t *TransformConfig) UnmarshalYAML(value *yaml.Node) error {
 
 	// Fall back to object form
 	type transformConfig struct {
-		Type string `yaml:"type"`
+		Type    string         `yaml:"type"`
+		Levels  []int          `yaml:"levels,omitempty"`
+		Step    int            `yaml:"step,omitempty"`
+		Options map[string]any `yaml:"options,omitempty"`
 	}
 	var full transformConfig
 	if err := value.Decode(&full); err != nil {
 		return err
 	}
 	t.Type = full.Type
+	t.Levels = full.Levels
+	t.Step = full.Step
+	t.Options = full.Options
 	return nil
 }
 
+// MarshalYAML emits the string form when no options are set
+func (t TransformConfig) MarshalYAML() (any, error) {
+	if len(t.Levels) == 0 && t.Step == 0 && len(t.Options) == 0 {
+		return t.Type, nil
+	}
+	return struct {
+		Type    string         `yaml:"type"`
+		Levels  []int          `yaml:"levels,omitempty"`
+		Step    int            `yaml:"step,omitempty"`
+		Options map[string]any `yaml:"options,omitempty"`
+	}{t.Type, t.Levels, t.Step, t.Options}, nil
+}
+
+// RawSpecialValueConfig defines updates producing NaN, infinite, or missing samples
+type RawSpecialValueConfig struct {
+	Type        string  `yaml:"type"`
+	Probability float64 `yaml:"probability,omitempty"`
+	Every       int     `yaml:"every,omitempty"`
+}
+
+// RawSplitConfig defines the share of a source instance a value receives
+type RawSplitConfig struct {
+	Weight int `yaml:"weight"`
+}
+
 // ResetConfig defines reset behavior
 type ResetConfig struct {
 	Type  string
@@ -130,3 +217,35 @@ func (r *ResetConfig) UnmarshalYAML(value *yaml.Node) error {
 	r.Value = full.Value
 	return nil
 }
+
+// MarshalYAML emits the string form when no value is set
+func (r ResetConfig) MarshalYAML() (any, error) {
+	if r.Value == 0 {
+		return r.Type, nil
+	}
+	return struct {
+		Type  string `yaml:"type"`
+		Value int    `yaml:"value"`
+	}{r.Type, r.Value}, nil
+}
+
+// ExpansionError implements expandable for RawValueReference
+func (v *RawValueReference) ExpansionError() error {
+	if err := v.deferred.error(); err != nil {
+		return err
+	}
+	if v.Source != nil {
+		return v.Source.ExpansionError()
+	}
+	return nil
+}
+
+// ExpansionMode implements expandable for RawValueReference
+func (v *RawValueReference) ExpansionMode() string {
+	return v.Expand
+}
+
+// ExpansionFilters implements expandable for RawValueReference
+func (v *RawValueReference) ExpansionFilters() []string {
+	return v.Filter
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"strings"
+
+	"github.com/neox5/otelbox/internal/intern"
 )
 
 // Resolver handles template and instance resolution
@@ -22,6 +24,9 @@ type Resolver struct {
 	instanceClocks  map[string]ClockConfig
 	instanceSources map[string]SourceConfig
 	instanceValues  map[string]ValueConfig
+
+	// Names and attributes shared by expanded metrics
+	interned *intern.Pool
 }
 
 // newResolver creates a new resolver
@@ -36,6 +41,7 @@ func newResolver(raw *RawConfig) *Resolver {
 		instanceClocks:  make(map[string]ClockConfig),
 		instanceSources: make(map[string]SourceConfig),
 		instanceValues:  make(map[string]ValueConfig),
+		interned:        intern.New(),
 	}
 }
 
@@ -87,14 +93,43 @@ func Resolve(raw *RawConfig) (*Config, error) {
 		return nil, err
 	}
 
-	// Phase 5: Settings resolution
+	// Phase 5: Job resolution
+	jobs, err := resolveJobs(raw.Jobs, export)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConstLabels(metrics, export, jobs); err != nil {
+		return nil, err
+	}
+	if err := validateFamilies(metrics, jobs); err != nil {
+		return nil, err
+	}
+
+	// Phase 6: Settings resolution
 	settings, err := resolveSettings(&raw.Settings)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateListenerPorts(settings, export, jobs); err != nil {
+		return nil, err
+	}
+	if settings.Shard.Enabled() {
+		metrics = shardMetrics(metrics, settings.Shard)
+	}
+
+	// Phase 7: Chaos resolution
+	chaos, err := resolveChaos(&raw.Chaos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Phase 8: Assemble final config
+	cfg := buildConfig(resolver, metrics, export, settings)
+	cfg.Jobs = jobs
+	cfg.Chaos = chaos
+	cfg.Files = raw.Files
 
-	// Phase 6: Assemble final config
-	return buildConfig(resolver, metrics, export, settings), nil
+	return cfg, nil
 }
 
 // buildConfig assembles the final configuration
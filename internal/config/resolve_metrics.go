@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log/slog"
 	"maps"
+	"math"
+	"slices"
 )
 
 // resolveTemplateMetrics resolves metric templates (may reference value templates)
@@ -12,26 +14,245 @@ func (r *Resolver) resolveTemplateMetrics() error {
 	return nil
 }
 
-// resolveMetrics resolves final metrics from raw config
+// resolveMetrics resolves final metrics from raw config.
+// Metric definitions are expanded here, and each expansion is resolved
+// right away on the expansion workers.
 func (r *Resolver) resolveMetrics() ([]MetricConfig, error) {
 	var metrics []MetricConfig
 
-	for _, raw := range r.raw.Metrics {
-		promName := raw.Name.GetPrometheusName()
-		ctx := resolveContext{}.push("metric", promName)
+	// Metrics without a pending expansion resolve as they are
+	expander := r.raw.expander
+	if expander == nil {
+		expander = &Expander{}
+	}
+
+	for i, raw := range r.raw.Metrics {
+		resolved, err := expandMetric(expander, raw, i, func(raw RawMetricConfig) (MetricConfig, error) {
+			ctx := resolveContext{}.push("metric", raw.Name.GetPrometheusName())
+
+			metric, err := r.resolveMetric(&raw, ctx)
+			if err != nil {
+				return MetricConfig{}, err
+			}
 
-		metric, err := r.resolveMetric(&raw, ctx)
+			slog.Debug("resolved metric", "metric", metric)
+			return metric, nil
+		})
 		if err != nil {
-			return nil, err
+			return nil, expansionFailure(err, "failed to expand metrics")
 		}
+		metrics = append(metrics, resolved...)
+	}
+
+	for _, job := range r.raw.Jobs {
+		jobCtx := resolveContext{}.push("job", job.Name)
+
+		for i, raw := range job.Metrics {
+			resolved, err := expandMetric(expander, raw, i, func(raw RawMetricConfig) (MetricConfig, error) {
+				ctx := jobCtx.push("metric", raw.Name.GetPrometheusName())
 
-		metrics = append(metrics, metric)
-		slog.Debug("resolved metric", "metric", metric)
+				metric, err := r.resolveMetric(&raw, ctx)
+				if err != nil {
+					return MetricConfig{}, err
+				}
+
+				// Job labels apply unless the metric sets the attribute itself
+				if len(job.Labels) > 0 {
+					attributes := maps.Clone(job.Labels)
+					for i := range metric.Payload.Labels {
+						delete(attributes, metric.Payload.Key(i))
+					}
+					maps.Copy(attributes, metric.Attributes)
+					metric.Attributes = attributes
+				}
+				metric.Job = job.Name
+
+				slog.Debug("resolved metric", "metric", metric)
+				return metric, nil
+			})
+			if err != nil {
+				return nil, expansionFailure(err, fmt.Sprintf("failed to expand metrics of job %q", job.Name))
+			}
+			metrics = append(metrics, resolved...)
+		}
 	}
 
 	return metrics, nil
 }
 
+// resolveJobs converts raw jobs to resolved jobs.
+// Jobs with their own export get a dedicated endpoint; OTEL exports default
+// service.name to the job name so each job reports its own resource.
+func resolveJobs(raw []RawJobConfig, export ExportConfig) ([]JobConfig, error) {
+	var jobs []JobConfig
+
+	// Track Prometheus listener ports to reject conflicts
+	ports := make(map[int]string)
+	if port, ok := prometheusPort(export); ok {
+		ports[port] = "export"
+	}
+
+	for _, rawJob := range raw {
+		job := JobConfig{
+			Name:   rawJob.Name,
+			Labels: copyStringMap(rawJob.Labels),
+		}
+
+		if rawJob.Export != nil {
+			if rawJob.Export.Custom != nil {
+				return nil, fmt.Errorf("job %q: custom export not supported in jobs", rawJob.Name)
+			}
+			if rawJob.Export.Prometheus != nil && rawJob.Export.Prometheus.SocketActivation {
+				return nil, fmt.Errorf("job %q: socket activation not supported in jobs", rawJob.Name)
+			}
+			if rawJob.Export.Prometheus == nil && rawJob.Export.OTEL == nil {
+				return nil, fmt.Errorf("job %q: export must configure prometheus or otel", rawJob.Name)
+			}
+
+			jobExport, err := resolveExport(rawJob.Export)
+			if err != nil {
+				return nil, fmt.Errorf("job %q: %w", rawJob.Name, err)
+			}
+			if jobExport.OTEL != nil && rawJob.Export.OTEL.Resource["service.name"] == "" {
+				jobExport.OTEL.Resource["service.name"] = rawJob.Name
+			}
+
+			owner := fmt.Sprintf("job %q", rawJob.Name)
+			if port, ok := prometheusPort(jobExport); ok {
+				if existing, exists := ports[port]; exists {
+					return nil, fmt.Errorf("%s: prometheus port %d already used by %s", owner, port, existing)
+				}
+				ports[port] = owner
+			}
+
+			job.Export = &jobExport
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// validateConstLabels rejects metric attributes that collide with the
+// Prometheus constant labels or target params of the export serving the
+// metric.
+func validateConstLabels(metrics []MetricConfig, export ExportConfig, jobs []JobConfig) error {
+	exports := make(map[string]ExportConfig)
+	for _, job := range jobs {
+		if job.Dedicated() {
+			exports[job.Name] = *job.Export
+		}
+	}
+
+	for _, metric := range metrics {
+		e, dedicated := exports[metric.Job]
+		if !dedicated {
+			e = export
+		}
+		if e.Prometheus == nil {
+			continue
+		}
+		for name := range e.Prometheus.ConstLabels {
+			if _, exists := metric.Attributes[name]; exists {
+				return fmt.Errorf("metric %q: attribute %q conflicts with prometheus const label",
+					metric.PrometheusName, name)
+			}
+		}
+		for _, name := range e.Prometheus.TargetParams {
+			if _, exists := metric.Attributes[name]; exists {
+				return fmt.Errorf("metric %q: attribute %q conflicts with prometheus target param",
+					metric.PrometheusName, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateFamilies rejects series of one Prometheus metric family with
+// different types or descriptions, which Prometheus cannot expose. Families
+// are scoped to the export serving them.
+func validateFamilies(metrics []MetricConfig, jobs []JobConfig) error {
+	dedicated := make(map[string]bool)
+	for _, job := range jobs {
+		dedicated[job.Name] = job.Dedicated()
+	}
+
+	type family struct{ scope, name string }
+	first := make(map[family]MetricConfig)
+	for _, metric := range metrics {
+		if !metric.ExportsTo(ExportProtocolPrometheus) {
+			continue
+		}
+		key := family{name: metric.PrometheusName}
+		if dedicated[metric.Job] {
+			key.scope = metric.Job
+		}
+
+		prev, exists := first[key]
+		if !exists {
+			first[key] = metric
+			continue
+		}
+		if prev.Type != metric.Type {
+			return fmt.Errorf("metric %q: series have different types: %s and %s", metric.PrometheusName, prev.Type, metric.Type)
+		}
+		if prev.Description != metric.Description {
+			return fmt.Errorf("metric %q: series have different descriptions: %q and %q",
+				metric.PrometheusName, prev.Description, metric.Description)
+		}
+	}
+
+	return nil
+}
+
+// validateListenerPorts rejects debug and receiver listeners sharing a
+// port with each other or with a Prometheus export.
+func validateListenerPorts(settings SettingsConfig, export ExportConfig, jobs []JobConfig) error {
+	type listener struct {
+		name string
+		port int
+	}
+	var listeners []listener
+	if settings.Debug.Enabled {
+		listeners = append(listeners, listener{"debug", settings.Debug.Port})
+	}
+	if settings.Receiver.Enabled {
+		listeners = append(listeners,
+			listener{"receiver grpc", settings.Receiver.GRPCPort},
+			listener{"receiver http", settings.Receiver.HTTPPort})
+	}
+
+	for i, l := range listeners {
+		for _, other := range listeners[:i] {
+			if other.port == l.port {
+				return fmt.Errorf("%s port %d already used by %s", l.name, l.port, other.name)
+			}
+		}
+		if port, ok := prometheusPort(export); ok && port == l.port {
+			return fmt.Errorf("%s port %d already used by export", l.name, port)
+		}
+		for _, job := range jobs {
+			if !job.Dedicated() {
+				continue
+			}
+			if port, ok := prometheusPort(*job.Export); ok && port == l.port {
+				return fmt.Errorf("%s port %d already used by job %q", l.name, port, job.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// prometheusPort returns the listener port of an enabled Prometheus export.
+func prometheusPort(export ExportConfig) (int, bool) {
+	if export.Prometheus == nil || !export.Prometheus.Enabled {
+		return 0, false
+	}
+	return export.Prometheus.Port, true
+}
+
 // resolveMetric resolves a single metric with template + overrides
 func (r *Resolver) resolveMetric(raw *RawMetricConfig, ctx resolveContext) (MetricConfig, error) {
 	result := MetricConfig{
@@ -39,6 +260,12 @@ func (r *Resolver) resolveMetric(raw *RawMetricConfig, ctx resolveContext) (Metr
 		OTELName:       raw.Name.GetOTELName(),
 		Type:           MetricType(raw.Type),
 		Description:    raw.Description,
+		Unit:           raw.Unit,
+	}
+
+	// Derive protocol names from a single declared name
+	if r.raw.Settings.Naming.Translate {
+		translateNames(&result, raw.Name, r.raw.Settings.Naming.UTF8)
 	}
 
 	// Always resolve to full ValueConfig
@@ -49,9 +276,45 @@ func (r *Resolver) resolveMetric(raw *RawMetricConfig, ctx resolveContext) (Metr
 	result.Value = value
 
 	// Apply attribute overrides (complete replacement if specified)
-	if raw.Attributes != nil {
-		result.Attributes = make(map[string]string, len(raw.Attributes))
-		maps.Copy(result.Attributes, raw.Attributes)
+	result.Attributes = r.interned.Attributes(raw.Attributes)
+
+	// Apply payload stress attributes
+	if raw.Payload != nil {
+		result.Payload = PayloadConfig{
+			Labels: raw.Payload.Labels,
+			Size:   raw.Payload.Size,
+			Prefix: raw.Payload.Prefix,
+		}
+		if err := result.Payload.Validate(); err != nil {
+			return MetricConfig{}, ctx.error(err.Error())
+		}
+		for i := range result.Payload.Labels {
+			if _, exists := result.Attributes[result.Payload.Key(i)]; exists {
+				return MetricConfig{}, ctx.error(fmt.Sprintf("attribute %q conflicts with payload attribute", result.Payload.Key(i)))
+			}
+		}
+	}
+
+	// Apply emit probability for sparse series
+	result.EmitProbability = DefaultEmitProbability
+	if raw.EmitProbability != nil {
+		p := *raw.EmitProbability
+		if math.IsNaN(p) || p <= 0 || p > 1 {
+			return MetricConfig{}, ctx.error(fmt.Sprintf("emit_probability must be in (0, 1], got %g", p))
+		}
+		result.EmitProbability = p
+	}
+
+	// Restrict export protocols
+	for _, name := range raw.ExportTo {
+		protocol := ExportProtocol(name)
+		if protocol != ExportProtocolPrometheus && protocol != ExportProtocolOTEL {
+			return MetricConfig{}, ctx.error(fmt.Sprintf("invalid export_to protocol: %q (must be prometheus or otel)", name))
+		}
+		if slices.Contains(result.ExportTo, protocol) {
+			return MetricConfig{}, ctx.error(fmt.Sprintf("duplicate export_to protocol: %q", name))
+		}
+		result.ExportTo = append(result.ExportTo, protocol)
 	}
 
 	// Validate final metric
@@ -59,6 +322,12 @@ func (r *Resolver) resolveMetric(raw *RawMetricConfig, ctx resolveContext) (Metr
 		return MetricConfig{}, err
 	}
 
+	// Series of one expanded definition share names and description
+	result.PrometheusName = r.interned.String(result.PrometheusName)
+	result.OTELName = r.interned.String(result.OTELName)
+	result.Description = r.interned.String(result.Description)
+	result.Unit = r.interned.String(result.Unit)
+
 	return result, nil
 }
 
@@ -86,6 +355,11 @@ func (r *Resolver) validateMetric(metric MetricConfig, ctx resolveContext) error
 		return ctx.error("value source required")
 	}
 
+	// Counters never go below zero
+	if metric.Type == MetricTypeCounter && metric.Value.Initial < 0 {
+		return ctx.error(fmt.Sprintf("counter initial value cannot be negative: %d", metric.Value.Initial))
+	}
+
 	return nil
 }
 
@@ -93,12 +367,78 @@ func (r *Resolver) validateMetric(metric MetricConfig, ctx resolveContext) error
 func resolveExport(raw *RawExportConfig) (ExportConfig, error) {
 	result := ExportConfig{}
 
+	// Without any exporter configured, an OTLP endpoint in the environment
+	// selects the OTEL exporter instead of the default Prometheus exporter
+	if raw.Prometheus == nil && raw.OTEL == nil && raw.Custom == nil {
+		if _, _, ok := lookupOTLPEnv("ENDPOINT"); ok {
+			withOTEL := *raw
+			withOTEL.OTEL = &RawOTELExportConfig{Enabled: true}
+			raw = &withOTEL
+		}
+	}
+
 	// Convert Prometheus config if present
 	if raw.Prometheus != nil {
 		result.Prometheus = &PrometheusExportConfig{
-			Enabled: raw.Prometheus.Enabled,
-			Port:    raw.Prometheus.Port,
-			Path:    raw.Prometheus.Path,
+			Enabled:          raw.Prometheus.Enabled,
+			Host:             raw.Prometheus.Host,
+			Port:             raw.Prometheus.Port,
+			Path:             raw.Prometheus.Path,
+			SocketActivation: raw.Prometheus.SocketActivation,
+			LandingPage:      raw.Prometheus.LandingPage == nil || *raw.Prometheus.LandingPage,
+			ConstLabels:      copyStringMap(raw.Prometheus.ConstLabels),
+			LabelDetectors:   resolveDetectors(raw.Prometheus.LabelDetectors),
+			Auth:             resolveAuth(raw.Prometheus.Auth),
+			TargetParams:     raw.Prometheus.TargetParams,
+			Exposition: ExpositionConfig{
+				Force: ExpositionFormat(raw.Prometheus.Exposition.Force),
+			},
+		}
+		for _, f := range raw.Prometheus.Exposition.Formats {
+			result.Prometheus.Exposition.Formats = append(result.Prometheus.Exposition.Formats, ExpositionFormat(f))
+		}
+		result.Prometheus.Timestamps = resolveTimestamps(raw.Prometheus.Timestamps)
+		result.Prometheus.Metadata = resolveMetadata(raw.Prometheus.Metadata)
+		if r := raw.Prometheus.Response; r != nil {
+			result.Prometheus.Response = ResponseConfig{
+				Compression: ResponseCompression(r.Compression),
+				MinSize:     r.MinSize,
+				MaxSize:     r.MaxSize,
+			}
+		}
+		result.Prometheus.Restart = resolveRestart(raw.Prometheus.Restart)
+		for _, u := range raw.Prometheus.Upstreams {
+			result.Prometheus.Upstreams = append(result.Prometheus.Upstreams, UpstreamConfig{
+				URL:          u.URL,
+				Interval:     u.Interval,
+				Timeout:      u.Timeout,
+				Replicas:     u.Replicas,
+				ReplicaLabel: u.ReplicaLabel,
+				Labels:       copyStringMap(u.Labels),
+			})
+		}
+		if f := raw.Prometheus.Federation; f != nil {
+			result.Prometheus.Federation = FederationConfig{
+				Enabled: f.Enabled,
+				Path:    f.Path,
+			}
+		}
+		for i, p := range raw.Prometheus.Paths {
+			path := ScrapePathConfig{Path: p.Path, Match: slices.Clone(p.Match)}
+			if p.Chaos != nil {
+				chaos, err := resolveChaos(p.Chaos)
+				if err != nil {
+					return ExportConfig{}, fmt.Errorf("prometheus paths[%d]: %w", i, err)
+				}
+				path.Chaos = &chaos
+			}
+			result.Prometheus.Paths = append(result.Prometheus.Paths, path)
+		}
+		if pm := raw.Prometheus.ProcessMetrics; pm != nil {
+			result.Prometheus.ProcessMetrics = ProcessMetricsConfig{
+				Enabled:      pm.Enabled,
+				TargetLabels: slices.Clone(pm.TargetLabels),
+			}
 		}
 	}
 
@@ -109,12 +449,61 @@ func resolveExport(raw *RawExportConfig) (ExportConfig, error) {
 			Transport: raw.OTEL.Transport,
 			Host:      raw.OTEL.Host,
 			Port:      raw.OTEL.Port,
+			TLS:       raw.OTEL.TLS,
+			Path:      raw.OTEL.Path,
 			Interval: IntervalConfig{
 				Read: raw.OTEL.Interval.Read,
 				Push: raw.OTEL.Interval.Push,
 			},
-			Resource: copyStringMap(raw.OTEL.Resource),
-			Headers:  copyStringMap(raw.OTEL.Headers),
+			Resource:  copyStringMap(raw.OTEL.Resource),
+			Detectors: resolveDetectors(raw.OTEL.Detectors),
+			Headers:   copyStringMap(raw.OTEL.Headers),
+			StartTime: StartTimeConfig{
+				Mode:          StartTimeMode(raw.OTEL.StartTime.Mode),
+				Time:          raw.OTEL.StartTime.Time,
+				ResetInterval: raw.OTEL.StartTime.ResetInterval,
+			},
+			DNSRefresh: raw.OTEL.DNSRefresh,
+			Reconnect: ReconnectConfig{
+				Pushes:   raw.OTEL.Reconnect.Pushes,
+				Interval: raw.OTEL.Reconnect.Interval,
+			},
+			Temporality: TemporalityConfig{
+				Counter: Temporality(raw.OTEL.Temporality.Counter),
+				Gauge:   Temporality(raw.OTEL.Temporality.Gauge),
+			},
+			Aggregation: AggregationConfig{
+				Counter: Aggregation(raw.OTEL.Aggregation.Counter),
+				Gauge:   Aggregation(raw.OTEL.Aggregation.Gauge),
+			},
+			Compression: Compression(raw.OTEL.Compression),
+			Timeout:     raw.OTEL.Timeout,
+			Retry: RetryConfig{
+				Enabled:         raw.OTEL.Retry.Enabled == nil || *raw.OTEL.Retry.Enabled,
+				InitialInterval: raw.OTEL.Retry.InitialInterval,
+				MaxInterval:     raw.OTEL.Retry.MaxInterval,
+				MaxElapsedTime:  raw.OTEL.Retry.MaxElapsedTime,
+			},
+			MaxDataPoints: raw.OTEL.MaxDataPoints,
+			Timestamps:    resolveTimestamps(raw.OTEL.Timestamps),
+			Auth:          resolveAuth(raw.OTEL.Auth),
+			Payload:       OTELPayload(raw.OTEL.Payload),
+			Restart:       resolveRestart(raw.OTEL.Restart),
+		}
+
+		// Fill settings left unset in YAML from OTEL_EXPORTER_OTLP_*
+		if raw.OTEL.Enabled {
+			if err := applyOTLPEnv(result.OTEL); err != nil {
+				return ExportConfig{}, err
+			}
+		}
+	}
+
+	if raw.Custom != nil {
+		result.Custom = &CustomExportConfig{
+			Name:    raw.Custom.Name,
+			Options: raw.Custom.Options,
+			Restart: resolveRestart(raw.Custom.Restart),
 		}
 	}
 
@@ -126,14 +515,174 @@ func resolveExport(raw *RawExportConfig) (ExportConfig, error) {
 	return result, nil
 }
 
+// resolveDetectors converts raw resource detector names (handles nil)
+func resolveDetectors(raw []string) []ResourceDetector {
+	if raw == nil {
+		return nil
+	}
+	detectors := make([]ResourceDetector, len(raw))
+	for i, name := range raw {
+		detectors[i] = ResourceDetector(name)
+	}
+	return detectors
+}
+
+// resolveTimestamps converts raw timestamp config to resolved config (handles nil)
+func resolveTimestamps(raw *RawTimestampConfig) *TimestampConfig {
+	if raw == nil {
+		return nil
+	}
+	return &TimestampConfig{Offset: raw.Offset, Jitter: raw.Jitter}
+}
+
+// resolveRestart converts raw restart config to resolved config (handles nil)
+func resolveRestart(raw *RawRestartConfig) *RestartConfig {
+	if raw == nil {
+		return nil
+	}
+	return &RestartConfig{
+		MaxRestarts:    raw.MaxRestarts,
+		InitialBackoff: raw.InitialBackoff,
+		MaxBackoff:     raw.MaxBackoff,
+	}
+}
+
+// resolveMetadata converts raw metadata config to resolved config (handles nil)
+func resolveMetadata(raw *RawMetadataConfig) MetadataConfig {
+	if raw == nil {
+		return MetadataConfig{}
+	}
+	result := MetadataConfig{
+		Created:  raw.Created,
+		OmitHelp: raw.Help != nil && !*raw.Help,
+		OmitType: raw.Type != nil && !*raw.Type,
+		Unit:     raw.Unit,
+	}
+	for _, m := range raw.Malformed {
+		result.Malformed = append(result.Malformed, MalformedMetadata(m))
+	}
+	return result
+}
+
+// resolveAuth converts raw auth config to resolved auth config (handles nil)
+func resolveAuth(raw *RawAuthConfig) *AuthConfig {
+	if raw == nil {
+		return nil
+	}
+
+	result := &AuthConfig{}
+	if raw.Basic != nil {
+		result.Basic = &BasicAuthConfig{
+			Username:     raw.Basic.Username,
+			Password:     raw.Basic.Password,
+			PasswordFile: raw.Basic.PasswordFile,
+		}
+	}
+	if raw.Bearer != nil {
+		result.Bearer = &BearerAuthConfig{
+			Token:     raw.Bearer.Token,
+			TokenFile: raw.Bearer.TokenFile,
+		}
+	}
+	if raw.OAuth2 != nil {
+		result.OAuth2 = &OAuth2Config{
+			ClientID:         raw.OAuth2.ClientID,
+			ClientSecret:     raw.OAuth2.ClientSecret,
+			ClientSecretFile: raw.OAuth2.ClientSecretFile,
+			TokenURL:         raw.OAuth2.TokenURL,
+			Scopes:           slices.Clone(raw.OAuth2.Scopes),
+			EndpointParams:   copyStringMap(raw.OAuth2.EndpointParams),
+		}
+	}
+	return result
+}
+
 // resolveSettings converts raw settings config to resolved settings config
 func resolveSettings(raw *RawSettingsConfig) (SettingsConfig, error) {
 	result := SettingsConfig{
-		Seed: raw.Seed,
+		Seed:          raw.Seed,
+		MaxSeries:     raw.maxSeries(),
+		RunDuration:   raw.RunDuration,
+		Generation:    GenerationMode(raw.Generation),
+		ResourceCheck: ResourceCheckMode(raw.ResourceCheck),
 		InternalMetrics: InternalMetricsConfig{
 			Enabled: raw.InternalMetrics.Enabled,
 			Format:  NamingFormat(raw.InternalMetrics.Format),
 		},
+		Panics: PanicConfig{
+			DisableSeries: raw.Panics.DisableSeries,
+		},
+		Logging: resolveLogging(raw.Logging),
+		RNG: RNGConfig{
+			Type: RNGType(raw.RNG.Type),
+			File: raw.RNG.File,
+		},
+		Debug: DebugConfig{
+			Enabled: raw.Debug.Enabled,
+			Port:    raw.Debug.Port,
+		},
+		Naming: NamingConfig{
+			Translate: raw.Naming.Translate,
+			UTF8:      raw.Naming.UTF8,
+		},
+		Monitor: MonitorConfig{
+			Interval: raw.Monitor.Interval,
+		},
+		Signals: SignalsConfig{
+			USR1:     SignalAction(raw.Signals.USR1),
+			USR2:     SignalAction(raw.Signals.USR2),
+			DumpFile: raw.Signals.DumpFile,
+		},
+	}
+
+	if raw.Ramp != nil {
+		result.Ramp = RampConfig{
+			Start:    raw.Ramp.Start,
+			Duration: raw.Ramp.Duration,
+		}
+	}
+
+	if raw.Throttle != nil {
+		result.Throttle = ThrottleConfig{
+			MaxObservationsPerSecond: raw.Throttle.MaxObservationsPerSecond,
+			MaxPointsPerSecond:       raw.Throttle.MaxPointsPerSecond,
+			Policy:                   ThrottlePolicy(raw.Throttle.Policy),
+		}
+	}
+
+	if raw.Shard != nil {
+		result.Shard = ShardConfig{
+			Index: raw.Shard.Index,
+			Count: raw.Shard.Count,
+		}
+		if result.Shard.Count < 1 {
+			return SettingsConfig{}, fmt.Errorf("invalid shard count: %d (must be at least 1)", result.Shard.Count)
+		}
+	}
+
+	if raw.Drain != nil {
+		result.Drain = DrainConfig{
+			Enabled:      true,
+			ScrapeWindow: raw.Drain.ScrapeWindow,
+			Timeout:      raw.Drain.Timeout,
+		}
+	}
+
+	if raw.Receiver != nil {
+		result.Receiver = ReceiverConfig{
+			Enabled:  true,
+			GRPCPort: raw.Receiver.GRPCPort,
+			HTTPPort: raw.Receiver.HTTPPort,
+		}
+	}
+
+	if raw.LoadShedding != nil {
+		result.LoadShedding = LoadSheddingConfig{
+			Enabled:      true,
+			Step:         raw.LoadShedding.Step,
+			MinFactor:    raw.LoadShedding.MinFactor,
+			RecoverBelow: raw.LoadShedding.RecoverBelow,
+		}
 	}
 
 	// Validate converted config
@@ -144,6 +693,85 @@ func resolveSettings(raw *RawSettingsConfig) (SettingsConfig, error) {
 	return result, nil
 }
 
+// resolveLogging converts raw logging config to resolved logging config
+func resolveLogging(raw RawLoggingConfig) LoggingConfig {
+	result := LoggingConfig{
+		Format:       LogFormat(raw.Format),
+		Output:       raw.Output,
+		Level:        LogLevel(raw.Level),
+		MaxSize:      raw.MaxSize,
+		MaxFiles:     raw.MaxFiles,
+		SampleEvery:  raw.SampleEvery,
+		MaxPerSecond: raw.MaxPerSecond,
+	}
+	if raw.Modules != nil {
+		result.Modules = make(map[string]LogLevel, len(raw.Modules))
+		for module, level := range raw.Modules {
+			result.Modules[module] = LogLevel(level)
+		}
+	}
+	return result
+}
+
+// ResolveLogging resolves and validates logging config on its own, for
+// logging set up before the rest of the configuration is loaded.
+func ResolveLogging(raw RawLoggingConfig) (LoggingConfig, error) {
+	result := resolveLogging(raw)
+	if err := result.Validate(); err != nil {
+		return LoggingConfig{}, err
+	}
+	return result, nil
+}
+
+// resolveChaos converts raw chaos config to resolved chaos config
+func resolveChaos(raw *RawChaosConfig) (ChaosConfig, error) {
+	result := ChaosConfig{
+		ScrapeDelay: resolveDelay(raw.ScrapeDelay),
+	}
+	for _, e := range raw.ScrapeErrors {
+		result.ScrapeErrors = append(result.ScrapeErrors, ScrapeErrorConfig{
+			Type:        ScrapeErrorType(e.Type),
+			Probability: e.Probability,
+			Status:      e.Status,
+		})
+	}
+	for _, f := range raw.OTLPFaults {
+		result.OTLPFaults = append(result.OTLPFaults, OTLPFaultConfig{
+			Type:        OTLPFaultType(f.Type),
+			Probability: f.Probability,
+			Delay:       resolveDelay(f.Delay),
+		})
+	}
+	for _, v := range raw.ProtocolViolations {
+		result.ProtocolViolations = append(result.ProtocolViolations, ProtocolViolationConfig{
+			Type:        ProtocolViolationType(v.Type),
+			Probability: v.Probability,
+		})
+	}
+
+	// Validate converted config
+	if err := result.Validate(); err != nil {
+		return ChaosConfig{}, err
+	}
+
+	return result, nil
+}
+
+// resolveDelay converts raw delay config to resolved delay config (handles nil)
+func resolveDelay(raw *RawDelayConfig) *DelayConfig {
+	if raw == nil {
+		return nil
+	}
+	return &DelayConfig{
+		Distribution: DelayDistribution(raw.Distribution),
+		Delay:        raw.Delay,
+		Min:          raw.Min,
+		Max:          raw.Max,
+		Mean:         raw.Mean,
+		StdDev:       raw.StdDev,
+	}
+}
+
 // copyStringMap creates a copy of a string map (handles nil)
 func copyStringMap(src map[string]string) map[string]string {
 	if src == nil {
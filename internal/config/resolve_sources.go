@@ -36,6 +36,12 @@ func (r *Resolver) resolveTemplateSources() error {
 		if raw.Max != nil {
 			resolved.Max = *raw.Max
 		}
+		resolved.Group = raw.Group
+		if raw.Correlation != nil {
+			resolved.Correlation = *raw.Correlation
+		}
+		resolved.Options = raw.Options
+		resolved.Replay = resolveReplay(raw.Replay)
 
 		// Validate
 		if resolved.Type == "" {
@@ -80,6 +86,12 @@ func (r *Resolver) resolveInstanceSources() error {
 		if raw.Max != nil {
 			resolved.Max = *raw.Max
 		}
+		resolved.Group = raw.Group
+		if raw.Correlation != nil {
+			resolved.Correlation = *raw.Correlation
+		}
+		resolved.Options = raw.Options
+		resolved.Replay = resolveReplay(raw.Replay)
 
 		// Validate
 		if resolved.Type == "" {
@@ -102,7 +114,7 @@ func (r *Resolver) resolveSourceReference(raw *RawSourceReference, ctx resolveCo
 			return SourceConfig{}, nil, ctx.error(fmt.Sprintf("source instance %q not found", raw.Instance))
 		}
 		// No overrides allowed for instances
-		if raw.Template != "" || raw.Type != nil || raw.Clock != nil || raw.Min != nil || raw.Max != nil {
+		if raw.Template != "" || raw.Type != nil || raw.Clock != nil || raw.Min != nil || raw.Max != nil || raw.Group != "" || raw.Correlation != nil || raw.Options != nil || raw.Replay != nil {
 			return SourceConfig{}, nil, ctx.error("cannot override instance source")
 		}
 		return instance, &raw.Instance, nil // Return instance ref
@@ -134,6 +146,18 @@ func (r *Resolver) resolveSourceReference(raw *RawSourceReference, ctx resolveCo
 		if raw.Max != nil {
 			result.Max = *raw.Max
 		}
+		if raw.Group != "" {
+			result.Group = raw.Group
+		}
+		if raw.Correlation != nil {
+			result.Correlation = *raw.Correlation
+		}
+		if raw.Options != nil {
+			result.Options = raw.Options
+		}
+		if raw.Replay != nil {
+			result.Replay = resolveReplay(raw.Replay)
+		}
 		return result, nil, nil // No instance ref for templates
 	}
 
@@ -159,6 +183,12 @@ func (r *Resolver) resolveSourceReference(raw *RawSourceReference, ctx resolveCo
 		if raw.Max != nil {
 			result.Max = *raw.Max
 		}
+		result.Group = raw.Group
+		if raw.Correlation != nil {
+			result.Correlation = *raw.Correlation
+		}
+		result.Options = raw.Options
+		result.Replay = resolveReplay(raw.Replay)
 
 		// Validate
 		if result.Type == "" {
@@ -170,3 +200,21 @@ func (r *Resolver) resolveSourceReference(raw *RawSourceReference, ctx resolveCo
 
 	return SourceConfig{}, nil, ctx.error("source must reference instance, template, or provide inline definition")
 }
+
+// resolveReplay converts raw replay config to resolved config (handles nil)
+func resolveReplay(raw *RawReplayConfig) *ReplayConfig {
+	if raw == nil {
+		return nil
+	}
+	result := &ReplayConfig{
+		File:   raw.File,
+		Metric: raw.Metric,
+		Labels: raw.Labels,
+		Scale:  1,
+		Loop:   raw.Loop,
+	}
+	if raw.Scale != nil {
+		result.Scale = *raw.Scale
+	}
+	return result
+}
@@ -27,9 +27,14 @@ func (r *Resolver) resolveTemplateValues() error {
 			resolved.SourceRef = sourceRef
 		}
 
-		// Copy transforms and reset
+		// Copy transforms, reset, split, and initial value
 		resolved.Transforms = raw.Transforms
 		resolved.Reset = raw.Reset
+		resolved.Split = resolveSplit(raw.Split)
+		if raw.Initial != nil {
+			resolved.Initial = *raw.Initial
+		}
+		resolved.SpecialValues = resolveSpecialValues(raw.SpecialValues)
 
 		// Validate
 		if err := r.validateValue(resolved, ctx); err != nil {
@@ -65,9 +70,14 @@ func (r *Resolver) resolveInstanceValues() error {
 			resolved.SourceRef = sourceRef
 		}
 
-		// Copy transforms and reset
+		// Copy transforms, reset, split, and initial value
 		resolved.Transforms = raw.Transforms
 		resolved.Reset = raw.Reset
+		resolved.Split = resolveSplit(raw.Split)
+		if raw.Initial != nil {
+			resolved.Initial = *raw.Initial
+		}
+		resolved.SpecialValues = resolveSpecialValues(raw.SpecialValues)
 
 		// Validate
 		if err := r.validateValue(resolved, ctx); err != nil {
@@ -93,7 +103,7 @@ func (r *Resolver) resolveValue(raw *RawValueReference, ctx resolveContext) (Val
 
 		// No overrides allowed for instances
 		if raw.Template != "" || raw.Source != nil ||
-			len(raw.Transforms) > 0 || raw.Reset.Type != "" {
+			len(raw.Transforms) > 0 || raw.Reset.Type != "" || raw.Split != nil || raw.Initial != nil || raw.SpecialValues != nil {
 			return ValueConfig{}, ctx.error("cannot override instance value")
 		}
 
@@ -127,6 +137,25 @@ func (r *Resolver) resolveValue(raw *RawValueReference, ctx resolveContext) (Val
 			result.Reset = raw.Reset
 		}
 
+		if raw.Split != nil {
+			result.Split = resolveSplit(raw.Split)
+		}
+
+		if raw.Initial != nil {
+			result.Initial = *raw.Initial
+		}
+
+		if raw.SpecialValues != nil {
+			result.SpecialValues = resolveSpecialValues(raw.SpecialValues)
+		}
+
+		if err := validateSplit(result, ctx); err != nil {
+			return ValueConfig{}, err
+		}
+		if err := validateSpecialValues(result, ctx); err != nil {
+			return ValueConfig{}, err
+		}
+
 		return result, nil
 	}
 
@@ -146,10 +175,71 @@ func (r *Resolver) resolveValue(raw *RawValueReference, ctx resolveContext) (Val
 
 	result.Transforms = raw.Transforms
 	result.Reset = raw.Reset
+	result.Split = resolveSplit(raw.Split)
+	if raw.Initial != nil {
+		result.Initial = *raw.Initial
+	}
+	result.SpecialValues = resolveSpecialValues(raw.SpecialValues)
+
+	if err := validateSplit(result, ctx); err != nil {
+		return ValueConfig{}, err
+	}
+	if err := validateSpecialValues(result, ctx); err != nil {
+		return ValueConfig{}, err
+	}
 
 	return result, nil
 }
 
+// resolveSplit converts a raw split config to resolved form.
+func resolveSplit(raw *RawSplitConfig) *SplitConfig {
+	if raw == nil {
+		return nil
+	}
+	return &SplitConfig{Weight: raw.Weight}
+}
+
+// validateSplit validates the split of a value.
+// Values split a shared source, so the source must be an instance.
+func validateSplit(value ValueConfig, ctx resolveContext) error {
+	if value.Split == nil {
+		return nil
+	}
+	if value.Split.Weight <= 0 {
+		return ctx.error(fmt.Sprintf("split weight must be positive, got %d", value.Split.Weight))
+	}
+	if value.SourceRef == nil {
+		return ctx.error("split requires a source instance")
+	}
+	return nil
+}
+
+// resolveSpecialValues converts raw special value rules to resolved form.
+func resolveSpecialValues(raw []RawSpecialValueConfig) []SpecialValueConfig {
+	if raw == nil {
+		return nil
+	}
+	result := make([]SpecialValueConfig, len(raw))
+	for i, s := range raw {
+		result[i] = SpecialValueConfig{
+			Type:        SpecialValueType(s.Type),
+			Probability: s.Probability,
+			Every:       s.Every,
+		}
+	}
+	return result
+}
+
+// validateSpecialValues validates the special value rules of a value.
+func validateSpecialValues(value ValueConfig, ctx resolveContext) error {
+	for _, s := range value.SpecialValues {
+		if err := s.Validate(); err != nil {
+			return ctx.error(err.Error())
+		}
+	}
+	return nil
+}
+
 // validateValue validates a resolved value config
 func (r *Resolver) validateValue(value ValueConfig, ctx resolveContext) error {
 	// Source required
@@ -162,5 +252,9 @@ func (r *Resolver) validateValue(value ValueConfig, ctx resolveContext) error {
 		return ctx.error("clock required in source")
 	}
 
-	return nil
+	if err := validateSpecialValues(value, ctx); err != nil {
+		return err
+	}
+
+	return validateSplit(value, ctx)
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScaffoldScenarios lists the scenarios of ScaffoldProfile.
+var ScaffoldScenarios = []string{"web-service", "node-exporter", "kafka"}
+
+// ScaffoldProfile returns a starter configuration modeling a common
+// scenario, exported on a Prometheus endpoint.
+func ScaffoldProfile(scenario string) (*RawConfig, error) {
+	var raw *RawConfig
+	switch scenario {
+	case "web-service":
+		raw = scaffoldWebService()
+	case "node-exporter":
+		raw = scaffoldNodeExporter()
+	case "kafka":
+		raw = scaffoldKafka()
+	default:
+		return nil, fmt.Errorf("unknown scenario %q (must be one of: %s)", scenario, strings.Join(ScaffoldScenarios, ", "))
+	}
+
+	raw.Version = SchemaVersion
+	raw.Templates.Clocks = []RawClockReference{
+		{Name: "tick", Type: scaffoldString("periodic"), Interval: time.Second},
+	}
+	raw.Export = RawExportConfig{Prometheus: &RawPrometheusExportConfig{
+		Enabled: true, Port: DefaultPrometheusPort, Path: DefaultPrometheusPath,
+	}}
+	return raw, nil
+}
+
+// scaffoldWebService models an HTTP service with per-route traffic.
+func scaffoldWebService() *RawConfig {
+	return &RawConfig{
+		Iterators: []RawIterator{
+			{Name: "route", Type: "list", Values: []string{"/", "/api/users", "/api/orders"}},
+			{Name: "status", Type: "list", Values: []string{"200", "404", "500"}},
+		},
+		Metrics: []RawMetricConfig{
+			scaffoldCounter("http_requests_total", "http.server.requests", "Total HTTP requests", "",
+				scaffoldSource("rate", 0, 50), map[string]string{"route": "{route}", "status": "{status}"}),
+			scaffoldCounter("http_response_size_bytes_total", "http.server.response.size", "Total bytes sent in HTTP responses", "By",
+				scaffoldSource("rate", 0, 50000), map[string]string{"route": "{route}"}),
+			scaffoldGauge("http_requests_in_flight", "http.server.active_requests", "HTTP requests currently being served", "",
+				scaffoldSource("random_int", 0, 20), map[string]string{"route": "{route}"}),
+		},
+	}
+}
+
+// scaffoldNodeExporter models host metrics like the Prometheus node
+// exporter.
+func scaffoldNodeExporter() *RawConfig {
+	start, end := 0, 3
+	return &RawConfig{
+		Iterators: []RawIterator{
+			{Name: "cpu", Type: "range", Start: &start, End: &end},
+			{Name: "mode", Type: "list", Values: []string{"user", "system", "idle", "iowait"}},
+			{Name: "device", Type: "list", Values: []string{"sda", "sdb"}},
+		},
+		Metrics: []RawMetricConfig{
+			scaffoldCounter("node_cpu_seconds_total", "system.cpu.time", "Seconds the CPUs spent in each mode", "s",
+				scaffoldSource("rate", 0, 1), map[string]string{"cpu": "{cpu}", "mode": "{mode}"}),
+			scaffoldGauge("node_memory_MemAvailable_bytes", "system.memory.available", "Memory available in bytes", "By",
+				scaffoldSource("random_int", 2<<30, 6<<30), nil),
+			scaffoldCounter("node_disk_read_bytes_total", "system.disk.read", "Total bytes read from disk", "By",
+				scaffoldSource("rate", 0, 10<<20), map[string]string{"device": "{device}"}),
+			scaffoldCounter("node_network_receive_bytes_total", "system.network.receive", "Total bytes received on the network", "By",
+				scaffoldSource("rate", 0, 1<<20), map[string]string{"device": "eth0"}),
+		},
+	}
+}
+
+// scaffoldKafka models a Kafka broker with topics, partitions, and a
+// consumer group.
+func scaffoldKafka() *RawConfig {
+	start, end := 0, 2
+	return &RawConfig{
+		Iterators: []RawIterator{
+			{Name: "topic", Type: "list", Values: []string{"orders", "payments", "events"}},
+			{Name: "partition", Type: "range", Start: &start, End: &end},
+		},
+		Metrics: []RawMetricConfig{
+			scaffoldCounter("kafka_server_brokertopicmetrics_messagesin_total", "kafka.broker.messages.in", "Messages written to the topic", "",
+				scaffoldSource("rate", 10, 500), map[string]string{"topic": "{topic}"}),
+			scaffoldCounter("kafka_server_brokertopicmetrics_bytesin_total", "kafka.broker.bytes.in", "Bytes written to the topic", "By",
+				scaffoldSource("rate", 1000, 50000), map[string]string{"topic": "{topic}"}),
+			scaffoldGauge("kafka_consumergroup_lag", "kafka.consumer_group.lag", "Messages the consumer group is behind the partition", "",
+				scaffoldSource("random_int", 0, 1000), map[string]string{"topic": "{topic}", "partition": "{partition}", "consumergroup": "app"}),
+		},
+	}
+}
+
+// scaffoldCounter returns a counter accumulating its source.
+func scaffoldCounter(prometheus, otel, description, unit string, source *RawSourceReference, attributes map[string]string) RawMetricConfig {
+	m := scaffoldGauge(prometheus, otel, description, unit, source, attributes)
+	m.Type = string(MetricTypeCounter)
+	m.Value.Transforms = []TransformConfig{{Type: "accumulate"}}
+	return m
+}
+
+// scaffoldGauge returns a gauge reporting its source.
+func scaffoldGauge(prometheus, otel, description, unit string, source *RawSourceReference, attributes map[string]string) RawMetricConfig {
+	return RawMetricConfig{
+		Name:        RawMetricNameConfig{Prometheus: prometheus, OTEL: otel},
+		Type:        string(MetricTypeGauge),
+		Description: description,
+		Unit:        unit,
+		Value:       RawValueReference{Source: source},
+		Attributes:  attributes,
+	}
+}
+
+// scaffoldSource returns a source driven by the tick clock template.
+func scaffoldSource(typ string, minValue, maxValue int) *RawSourceReference {
+	return &RawSourceReference{
+		Type:  scaffoldString(typ),
+		Clock: &RawClockReference{Template: "tick"},
+		Min:   &minValue,
+		Max:   &maxValue,
+	}
+}
+
+// scaffoldString returns a pointer to s.
+func scaffoldString(s string) *string {
+	return &s
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Selector is a PromQL-style series selector. Metric names are matched as
+// the __name__ label.
+type Selector struct {
+	expr     string
+	matchers []labelMatcher
+}
+
+// String returns the selector as parsed.
+func (s *Selector) String() string {
+	return s.expr
+}
+
+// Match reports whether label values, including __name__, satisfy every
+// matcher of the selector.
+func (s *Selector) Match(values map[string]string) bool {
+	for _, m := range s.matchers {
+		if !m.match(values[m.name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// labelMatcher is one label condition of a series selector.
+type labelMatcher struct {
+	name  string
+	op    string // =, !=, =~, !~
+	value string
+	re    *regexp.Regexp // Anchored pattern for =~ and !~
+}
+
+func (m labelMatcher) match(value string) bool {
+	switch m.op {
+	case "=":
+		return value == m.value
+	case "!=":
+		return value != m.value
+	case "=~":
+		return m.re.MatchString(value)
+	default:
+		return !m.re.MatchString(value)
+	}
+}
+
+// ParseSelector parses a series selector like
+// `http_requests_total{method="GET",status=~"5.."}`. At least one matcher
+// must not match the empty string, as in PromQL.
+func ParseSelector(s string) (*Selector, error) {
+	p := &selectorParser{input: s, rest: strings.TrimSpace(s)}
+
+	var matchers []labelMatcher
+	if name := p.identifier(true); name != "" {
+		matchers = append(matchers, labelMatcher{name: "__name__", op: "=", value: name})
+	}
+
+	if p.consume("{") {
+		for !p.consume("}") {
+			m, err := p.matcher()
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, m)
+
+			if !p.consume(",") && !strings.HasPrefix(p.rest, "}") {
+				return nil, p.errorf("expected , or }")
+			}
+		}
+	}
+	if p.rest != "" {
+		return nil, p.errorf("unexpected %q", p.rest)
+	}
+
+	for _, m := range matchers {
+		if !m.match("") {
+			return &Selector{expr: s, matchers: matchers}, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid selector %q: must contain at least one matcher not matching the empty string", s)
+}
+
+// selectorParser consumes a selector from left to right.
+type selectorParser struct {
+	input string
+	rest  string
+}
+
+func (p *selectorParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid selector %q: %s", p.input, fmt.Sprintf(format, args...))
+}
+
+// consume skips token (after spaces) if rest starts with it.
+func (p *selectorParser) consume(token string) bool {
+	p.rest = strings.TrimLeft(p.rest, " \t")
+	if !strings.HasPrefix(p.rest, token) {
+		return false
+	}
+	p.rest = p.rest[len(token):]
+	return true
+}
+
+// identifier consumes a label name, or a metric name if colons are allowed.
+func (p *selectorParser) identifier(colons bool) string {
+	p.rest = strings.TrimLeft(p.rest, " \t")
+	end := 0
+	for end < len(p.rest) {
+		c := p.rest[end]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (colons && c == ':') || (end > 0 && c >= '0' && c <= '9') {
+			end++
+			continue
+		}
+		break
+	}
+	name := p.rest[:end]
+	p.rest = p.rest[end:]
+	return name
+}
+
+// matcher consumes `<label> <op> <quoted value>`.
+func (p *selectorParser) matcher() (labelMatcher, error) {
+	name := p.identifier(false)
+	if name == "" {
+		return labelMatcher{}, p.errorf("expected label name")
+	}
+
+	var op string
+	for _, candidate := range []string{"=~", "!~", "!=", "="} {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return labelMatcher{}, p.errorf("expected matcher operator after %q", name)
+	}
+
+	value, err := p.quoted()
+	if err != nil {
+		return labelMatcher{}, err
+	}
+
+	m := labelMatcher{name: name, op: op, value: value}
+	if op == "=~" || op == "!~" {
+		m.re, err = regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return labelMatcher{}, p.errorf("invalid pattern for %q: %v", name, err)
+		}
+	}
+	return m, nil
+}
+
+// quoted consumes a double-, single-, or backtick-quoted string.
+func (p *selectorParser) quoted() (string, error) {
+	p.rest = strings.TrimLeft(p.rest, " \t")
+	if p.rest == "" || !strings.ContainsRune("\"'`", rune(p.rest[0])) {
+		return "", p.errorf("expected quoted label value")
+	}
+	quote := p.rest[0]
+	rest := p.rest[1:]
+
+	if quote == '`' {
+		value, after, found := strings.Cut(rest, "`")
+		if !found {
+			return "", p.errorf("unterminated label value")
+		}
+		p.rest = after
+		return value, nil
+	}
+
+	var b strings.Builder
+	for {
+		if rest == "" {
+			return "", p.errorf("unterminated label value")
+		}
+		if rest[0] == quote {
+			p.rest = rest[1:]
+			return b.String(), nil
+		}
+		r, _, tail, err := strconv.UnquoteChar(rest, quote)
+		if err != nil {
+			return "", p.errorf("invalid escape in label value")
+		}
+		b.WriteRune(r)
+		rest = tail
+	}
+}
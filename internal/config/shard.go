@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseShard parses a shard given as index/count, e.g. 2/4.
+func ParseShard(s string) (RawShardConfig, error) {
+	index, count, found := strings.Cut(s, "/")
+	if !found {
+		return RawShardConfig{}, fmt.Errorf("invalid shard %q (must be index/count, e.g. 1/4)", s)
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return RawShardConfig{}, fmt.Errorf("invalid shard index %q: %w", index, err)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return RawShardConfig{}, fmt.Errorf("invalid shard count %q: %w", count, err)
+	}
+	return RawShardConfig{Index: i, Count: n}, nil
+}
+
+// shardMetrics returns the metrics owned by shard. Ownership depends only
+// on a metric's identity, so every instance of a sharded simulation assigns
+// each series to the same shard regardless of config order. Metrics sharing
+// a source instance stay together, keeping values split from one source on
+// one instance.
+func shardMetrics(metrics []MetricConfig, shard ShardConfig) []MetricConfig {
+	owned := make([]MetricConfig, 0, len(metrics)/shard.Count+1)
+	for _, m := range metrics {
+		h := fnv.New64a()
+		h.Write([]byte(shardKey(m)))
+		if int(h.Sum64()%uint64(shard.Count)) == shard.Index-1 {
+			owned = append(owned, m)
+		}
+	}
+
+	slog.Info("sharded metrics", "shard", shard.String(), "metrics", len(owned), "total", len(metrics))
+	return owned
+}
+
+// shardKey returns the identity deciding the shard of a metric.
+func shardKey(m MetricConfig) string {
+	if m.Value.SourceRef != nil {
+		return "source:" + *m.Value.SourceRef
+	}
+
+	keys := slices.Sorted(maps.Keys(m.Attributes))
+	var b strings.Builder
+	b.WriteString(m.PrometheusName)
+	for _, k := range keys {
+		b.WriteString("\x00")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(m.Attributes[k])
+	}
+	return b.String()
+}
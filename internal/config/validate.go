@@ -12,12 +12,48 @@ func Validate(raw *RawConfig) error {
 // validateRawSyntax performs basic syntactic validation on raw config
 func validateRawSyntax(raw *RawConfig) error {
 	// Validate at least one metric defined
-	if len(raw.Metrics) == 0 {
+	total := len(raw.Metrics)
+	for _, job := range raw.Jobs {
+		total += len(job.Metrics)
+	}
+	if total == 0 {
 		return fmt.Errorf("at least one metric must be defined")
 	}
 
-	// Validate metric names
-	for i, metric := range raw.Metrics {
+	if err := validateRawMetrics(raw.Metrics); err != nil {
+		return err
+	}
+
+	// Validate jobs
+	jobNames := make(map[string]bool, len(raw.Jobs))
+	for i, job := range raw.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("job at index %d: name cannot be empty", i)
+		}
+		if jobNames[job.Name] {
+			return fmt.Errorf("job %q: duplicate name", job.Name)
+		}
+		jobNames[job.Name] = true
+
+		if len(job.Metrics) == 0 {
+			return fmt.Errorf("job %q: at least one metric must be defined", job.Name)
+		}
+		for label := range job.Labels {
+			if !IsValidAttributeName(label) {
+				return fmt.Errorf("job %q: invalid label name: %q", job.Name, label)
+			}
+		}
+		if err := validateRawMetrics(job.Metrics); err != nil {
+			return fmt.Errorf("job %q: %w", job.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateRawMetrics validates metric names, types, and descriptions
+func validateRawMetrics(metrics []RawMetricConfig) error {
+	for i, metric := range metrics {
 		promName := metric.Name.GetPrometheusName()
 		otelName := metric.Name.GetOTELName()
 
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+)
+
+// lockedRNG serializes access to a random number generator shared by
+// concurrent requests.
+type lockedRNG struct {
+	mu  sync.Mutex
+	rng simulation.RNG
+}
+
+// newLockedRNG creates a generator with a stream of sim derived from key.
+func newLockedRNG(sim *simulation.Context, key string) *lockedRNG {
+	return &lockedRNG{rng: sim.NewDerivedRand(key)}
+}
+
+// IntN returns a value in [0, n).
+func (r *lockedRNG) IntN(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.IntN(n)
+}
+
+// delaySampler draws delays from a configured distribution.
+type delaySampler struct {
+	cfg config.DelayConfig
+	rng simulation.RNG
+}
+
+// newDelaySampler creates a sampler with a stream of sim derived from key.
+func newDelaySampler(cfg config.DelayConfig, sim *simulation.Context, key string) *delaySampler {
+	return &delaySampler{cfg: cfg, rng: newLockedRNG(sim, key)}
+}
+
+// sample returns the next delay. Safe for concurrent use.
+func (s *delaySampler) sample() time.Duration {
+	var d float64
+	switch s.cfg.Distribution {
+	case config.DelayFixed:
+		return s.cfg.Delay
+	case config.DelayUniform:
+		return time.Duration(uniform(s.rng, float64(s.cfg.Min), float64(s.cfg.Max)))
+	case config.DelayNormal:
+		// Box-Muller transform (u1 in (0, 1] keeps the logarithm finite)
+		u1 := 1 - uniform(s.rng, 0, 1)
+		u2 := uniform(s.rng, 0, 1)
+		z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+		d = float64(s.cfg.Mean) + z*float64(s.cfg.StdDev)
+	case config.DelayExponential:
+		d = -float64(s.cfg.Mean) * math.Log(1-uniform(s.rng, 0, 1))
+	}
+
+	hi := math.Inf(1)
+	if s.cfg.Max > 0 {
+		hi = float64(s.cfg.Max)
+	}
+	return time.Duration(clamp(d, float64(s.cfg.Min), hi))
+}
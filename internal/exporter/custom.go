@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/metric"
+)
+
+// CustomExporter is an exporter registered by external code and selected
+// with an export.custom block.
+type CustomExporter interface {
+	Start(ctx context.Context) error // Begins exporting without blocking
+	Stop(ctx context.Context) error
+	Describe() string
+}
+
+// CustomFactory creates a custom exporter from its configured options.
+// Metrics returns the currently exported metrics, which change on reload.
+type CustomFactory func(options map[string]any, metrics func() *metric.Registry) (CustomExporter, error)
+
+var (
+	customMu        sync.RWMutex
+	customFactories = make(map[string]CustomFactory)
+)
+
+// RegisterCustomExporter makes a custom exporter available by name.
+func RegisterCustomExporter(name string, factory CustomFactory) error {
+	if name == "" {
+		return fmt.Errorf("custom exporter name cannot be empty")
+	}
+
+	customMu.Lock()
+	defer customMu.Unlock()
+
+	if _, exists := customFactories[name]; exists {
+		return fmt.Errorf("custom exporter %q already registered", name)
+	}
+	customFactories[name] = factory
+	return nil
+}
+
+// NewCustomExporter creates the registered custom exporter selected by cfg.
+func NewCustomExporter(cfg *config.CustomExportConfig, metrics func() *metric.Registry) (CustomExporter, error) {
+	customMu.RLock()
+	factory, exists := customFactories[cfg.Name]
+	registered := slices.Sorted(maps.Keys(customFactories))
+	customMu.RUnlock()
+
+	if !exists {
+		if len(registered) == 0 {
+			return nil, fmt.Errorf("unknown custom exporter: %q (none registered)", cfg.Name)
+		}
+		return nil, fmt.Errorf("unknown custom exporter: %q (registered: %s)", cfg.Name, strings.Join(registered, ", "))
+	}
+
+	e, err := factory(cfg.Options, metrics)
+	if err != nil {
+		return nil, fmt.Errorf("custom exporter %q: %w", cfg.Name, err)
+	}
+	return e, nil
+}
@@ -0,0 +1,68 @@
+package exporter
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/generator"
+	"github.com/neox5/otelbox/internal/monitor"
+	"github.com/neox5/otelbox/internal/simulation"
+)
+
+// internalMetricName builds an otelbox internal metric name from parts.
+// The native format resolves to the convention of the calling exporter.
+func internalMetricName(cfg config.InternalMetricsConfig, native config.NamingFormat, parts ...string) string {
+	format := cfg.Format
+	if format == config.NamingFormatNative {
+		format = native
+	}
+
+	separator := "_"
+	if format == config.NamingFormatDot {
+		separator = "."
+	}
+
+	return strings.Join(append([]string{"otelbox"}, parts...), separator)
+}
+
+// internalGauge is an otelbox self-monitoring gauge shared by all exporters.
+type internalGauge struct {
+	name        string
+	description string
+	value       func() float64
+}
+
+// internalGauges returns the self-monitoring gauges of the generator,
+// named in the native format of the calling exporter.
+func internalGauges(cfg config.InternalMetricsConfig, native config.NamingFormat) []internalGauge {
+	name := func(parts ...string) string {
+		return internalMetricName(cfg, native, parts...)
+	}
+	return []internalGauge{
+		{name("active", "series"), "Number of series currently generated",
+			func() float64 { return float64(generator.ActiveSeries()) }},
+		{name("active", "clocks"), "Number of running clocks",
+			func() float64 { return float64(simulation.ActiveClocks()) }},
+		{name("goroutines"), "Number of goroutines",
+			func() float64 { return float64(runtime.NumGoroutine()) }},
+		{name("ramp", "factor"), "Fraction of configured source rates emitted by the startup ramp",
+			simulation.RampFactor},
+		{name("shed", "factor"), "Fraction of source updates generated under load shedding",
+			simulation.ShedFactor},
+
+		// Sampled by the resource monitor at its interval
+		{name("cpu", "utilization"), "Fraction of the available cores used by the process",
+			func() float64 { return monitor.Current().Utilization }},
+		{name("cpu", "saturation"), "CPU saturation: 0 normal, 1 high (above 80%), 2 saturated (above 95%)",
+			func() float64 { return float64(monitor.Current().Saturation.Level()) }},
+		{name("memory", "heap", "alloc", "bytes"), "Bytes of allocated heap objects",
+			func() float64 { return float64(monitor.Current().HeapAlloc) }},
+		{name("memory", "heap", "sys", "bytes"), "Bytes of heap memory obtained from the operating system",
+			func() float64 { return float64(monitor.Current().HeapSys) }},
+		{name("memory", "stack", "bytes"), "Bytes of stack memory in use",
+			func() float64 { return float64(monitor.Current().StackInuse) }},
+		{name("gc", "cpu", "fraction"), "Fraction of CPU time used by the garbage collector since start",
+			func() float64 { return monitor.Current().GCCPUFraction }},
+	}
+}
@@ -3,11 +3,13 @@ package exporter
 import (
 	"context"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/metric"
-	"github.com/neox5/simv/value"
+	"github.com/neox5/otelbox/internal/simulation"
 	"go.opentelemetry.io/otel/attribute"
 	otelmetric "go.opentelemetry.io/otel/metric"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
@@ -15,33 +17,55 @@ import (
 
 // OTELExporter pushes metrics to an OTEL collector.
 type OTELExporter struct {
-	config        *config.OTELExportConfig
-	meterProvider *sdkmetric.MeterProvider
-	meter         otelmetric.Meter
-	instruments   []instrument
+	config          *config.OTELExportConfig
+	internalMetrics config.InternalMetricsConfig
+	meterProvider   *sdkmetric.MeterProvider // nil for direct payloads
+	connection      otlpConnection
+	meter           otelmetric.Meter
+	direct          *directExporter // Builds requests from the registry (nil: sdk payload)
+
+	// Metric observation
+	mu           sync.Mutex
+	registration otelmetric.Registration
+
+	// Internal metric state
+	endpointChanges atomic.Int64
+
+	drained atomic.Bool // Final push sent and connection shut down
 }
 
 // instrument holds an OTEL observable instrument and its value reference.
 type instrument struct {
 	counter    otelmetric.Int64ObservableCounter
 	gauge      otelmetric.Int64ObservableGauge
-	value      *value.Value[int]
+	value      *simulation.ValueWrapper
+	guard      *simulation.Guard
+	sampler    *metric.Sampler
 	attributes []attribute.KeyValue
 }
 
-// NewOTELExporter creates a new OTEL exporter.
+// NewOTELExporter creates a new OTEL exporter. Chaos draws from the random
+// streams of sim.
 func NewOTELExporter(
 	cfg *config.OTELExportConfig,
 	metrics *metric.Registry,
+	sim *simulation.Context,
+	internalMetrics config.InternalMetricsConfig,
+	chaos config.ChaosConfig,
 ) (*OTELExporter, error) {
 	// Create resource
-	res, err := createOTELResource(cfg.Resource)
+	res, err := createOTELResource(cfg.Resource, cfg.Detectors)
 	if err != nil {
 		return nil, err
 	}
 
+	// Build requests without the SDK when configured
+	if cfg.Payload == config.OTELPayloadDirect {
+		return newDirectOTELExporter(cfg, res, metrics, sim, internalMetrics, chaos)
+	}
+
 	// Create meter provider
-	meterProvider, err := createMeterProvider(cfg, res)
+	meterProvider, connection, err := createMeterProvider(cfg, res, sim, chaos.OTLPFaults)
 	if err != nil {
 		return nil, err
 	}
@@ -51,9 +75,11 @@ func NewOTELExporter(
 
 	// Create exporter
 	e := &OTELExporter{
-		config:        cfg,
-		meterProvider: meterProvider,
-		meter:         meter,
+		config:          cfg,
+		internalMetrics: internalMetrics,
+		meterProvider:   meterProvider,
+		connection:      connection,
+		meter:           meter,
 	}
 
 	// Register instruments
@@ -61,9 +87,25 @@ func NewOTELExporter(
 		return nil, err
 	}
 
+	// Register internal metrics
+	if internalMetrics.Enabled {
+		if err := registerOTELInternalMetrics(e); err != nil {
+			return nil, err
+		}
+	}
+
 	return e, nil
 }
 
+// Update replaces the exported metrics without restarting the meter provider.
+func (e *OTELExporter) Update(metrics *metric.Registry) error {
+	if e.direct != nil {
+		e.direct.update(metrics)
+		return nil
+	}
+	return registerOTELInstruments(e, metrics)
+}
+
 // Start begins periodic metric export.
 // Blocks until context is cancelled, then shuts down gracefully.
 func (e *OTELExporter) Start(ctx context.Context) error {
@@ -73,13 +115,72 @@ func (e *OTELExporter) Start(ctx context.Context) error {
 		"push_interval", e.config.Interval.Push,
 	)
 
+	// Start background connection management
+	var wg sync.WaitGroup
+	if e.config.DNSRefresh > 0 {
+		wg.Go(func() { e.watchEndpointDNS(ctx) })
+	}
+	if e.config.Reconnect.Interval > 0 {
+		wg.Go(func() { e.reconnectPeriodically(ctx) })
+	}
+	if e.direct != nil {
+		wg.Go(func() { e.direct.run(ctx) })
+	}
+
 	// Wait for context cancellation
 	<-ctx.Done()
+	wg.Wait()
+	if e.drained.Load() {
+		return nil
+	}
 
 	// Shutdown meter provider
 	slog.Info("shutting down otel exporter")
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if e.direct != nil {
+		return e.direct.shutdown(shutdownCtx)
+	}
 	return e.meterProvider.Shutdown(shutdownCtx)
 }
+
+// Close shuts down an exporter that is not started, e.g. one prepared for
+// a reload that failed.
+func (e *OTELExporter) Close(ctx context.Context) error {
+	if e.direct != nil {
+		return e.direct.shutdown(ctx)
+	}
+	return e.meterProvider.Shutdown(ctx)
+}
+
+// Drain pushes the current values a final time and shuts down the
+// connection, reporting the data points the collector accepted. Stopping
+// the exporter afterwards skips its own final push.
+func (e *OTELExporter) Drain(ctx context.Context, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	before := e.connection.ExportedPoints()
+	e.drained.Store(true)
+
+	var err error
+	if e.direct != nil {
+		e.direct.drained.Store(true)
+		err = e.direct.shutdown(ctx)
+	} else {
+		err = e.meterProvider.Shutdown(ctx)
+	}
+
+	attrs := []any{
+		"endpoint", e.config.GetEndpoint(),
+		"points", e.connection.ExportedPoints() - before,
+		"duration", time.Since(start).Round(time.Millisecond),
+	}
+	if err != nil {
+		slog.Warn("otel final push failed", append(attrs, "error", err)...)
+		return
+	}
+	slog.Info("drained otel exporter", attrs...)
+}
@@ -0,0 +1,203 @@
+package exporter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// oauth2ExpiryMargin refreshes OAuth2 tokens this long before they expire.
+const oauth2ExpiryMargin = 10 * time.Second
+
+// authenticator supplies the Authorization header value for export requests.
+// It outlives reconnects so cached OAuth2 tokens are reused.
+type authenticator interface {
+	authorization(ctx context.Context) (string, error)
+}
+
+// newAuthenticator creates the authenticator for cfg (nil: no auth).
+func newAuthenticator(cfg *config.AuthConfig) authenticator {
+	switch {
+	case cfg == nil:
+		return nil
+	case cfg.Basic != nil:
+		return &basicAuth{cfg: *cfg.Basic}
+	case cfg.Bearer != nil:
+		return &bearerAuth{cfg: *cfg.Bearer}
+	case cfg.OAuth2 != nil:
+		return &oauth2Auth{cfg: *cfg.OAuth2, client: &http.Client{Timeout: 30 * time.Second}}
+	default:
+		return nil
+	}
+}
+
+// basicAuth sends HTTP basic credentials.
+type basicAuth struct {
+	cfg config.BasicAuthConfig
+}
+
+func (a *basicAuth) authorization(context.Context) (string, error) {
+	password, err := secret(a.cfg.Password, a.cfg.PasswordFile)
+	if err != nil {
+		return "", err
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(a.cfg.Username + ":" + password))
+	return "Basic " + credentials, nil
+}
+
+// bearerAuth sends a static or file-based bearer token.
+type bearerAuth struct {
+	cfg config.BearerAuthConfig
+}
+
+func (a *bearerAuth) authorization(context.Context) (string, error) {
+	token, err := secret(a.cfg.Token, a.cfg.TokenFile)
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + token, nil
+}
+
+// oauth2Auth fetches and caches tokens using the client credentials flow.
+type oauth2Auth struct {
+	cfg    config.OAuth2Config
+	client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time // Zero: token does not expire
+}
+
+func (a *oauth2Auth) authorization(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || (!a.expires.IsZero() && time.Now().After(a.expires)) {
+		if err := a.refresh(ctx); err != nil {
+			return "", err
+		}
+	}
+	return "Bearer " + a.token, nil
+}
+
+// refresh requests a new access token from the token endpoint.
+func (a *oauth2Auth) refresh(ctx context.Context) error {
+	clientSecret, err := secret(a.cfg.ClientSecret, a.cfg.ClientSecretFile)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(a.cfg.Scopes, " "))
+	}
+	for name, value := range a.cfg.EndpointParams {
+		form.Set(name, value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("oauth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.cfg.ClientID), url.QueryEscape(clientSecret))
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("oauth2 token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("oauth2 token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("oauth2 token request: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return fmt.Errorf("oauth2 token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return fmt.Errorf("oauth2 token response: missing access_token")
+	}
+
+	a.token = token.AccessToken
+	a.expires = time.Time{}
+	if token.ExpiresIn > 0 {
+		a.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - oauth2ExpiryMargin)
+	}
+	return nil
+}
+
+// secret returns the inline value or the trimmed contents of file.
+// Files are read on every call so rotated secrets are picked up.
+func secret(value, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// NewAuthTransport returns a transport sending the credentials of cfg with
+// every request over base. Returns base if cfg is nil.
+func NewAuthTransport(base http.RoundTripper, cfg *config.AuthConfig) http.RoundTripper {
+	auth := newAuthenticator(cfg)
+	if auth == nil {
+		return base
+	}
+	return &authTransport{base: base, auth: auth}
+}
+
+// authTransport adds the Authorization header to HTTP export requests.
+type authTransport struct {
+	base http.RoundTripper
+	auth authenticator
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	value, err := t.auth.authorization(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", value)
+	return t.base.RoundTrip(req)
+}
+
+// grpcAuth adds the authorization metadata to gRPC export calls.
+type grpcAuth struct {
+	auth authenticator
+}
+
+func (g grpcAuth) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	value, err := g.auth.authorization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": value}, nil
+}
+
+// RequireTransportSecurity allows credentials over the insecure connection.
+func (grpcAuth) RequireTransportSecurity() bool {
+	return false
+}
@@ -0,0 +1,234 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// otlpConnection is an OTLP push connection that can be re-established.
+type otlpConnection interface {
+	Reconnect(ctx context.Context) error
+	Reconnects() int64
+	ExportedPoints() int64
+	Pushes() int64
+	Requests() int64
+}
+
+// reconnectingExporter delegates to an OTLP exporter that can be replaced
+// at runtime, forcing a fresh connection to the collector.
+type reconnectingExporter struct {
+	factory        func() (sdkmetric.Exporter, error)
+	reconnectEvery uint64 // Reconnect after this many pushes (0 disables)
+
+	mu      sync.RWMutex
+	current sdkmetric.Exporter
+
+	pushes     atomic.Uint64
+	requests   atomic.Int64 // Export requests, more than pushes when split
+	reconnects atomic.Int64
+	exported   atomic.Int64 // Data points accepted by the collector
+}
+
+// newReconnectingExporter creates the initial exporter from factory.
+func newReconnectingExporter(
+	factory func() (sdkmetric.Exporter, error),
+	reconnectEvery int,
+) (*reconnectingExporter, error) {
+	current, err := factory()
+	if err != nil {
+		return nil, err
+	}
+	return &reconnectingExporter{
+		factory:        factory,
+		reconnectEvery: uint64(reconnectEvery),
+		current:        current,
+	}, nil
+}
+
+// Reconnect replaces the current exporter and shuts down the previous one.
+func (e *reconnectingExporter) Reconnect(ctx context.Context) error {
+	next, err := e.factory()
+	if err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+
+	e.mu.Lock()
+	previous := e.current
+	e.current = next
+	e.mu.Unlock()
+
+	e.reconnects.Add(1)
+	slog.Debug("otel exporter reconnected", "reconnects", e.reconnects.Load())
+
+	return previous.Shutdown(ctx)
+}
+
+// Reconnects returns the number of times the connection was re-established.
+func (e *reconnectingExporter) Reconnects() int64 {
+	return e.reconnects.Load()
+}
+
+// ExportedPoints returns the number of data points accepted by the collector.
+func (e *reconnectingExporter) ExportedPoints() int64 {
+	return e.exported.Load()
+}
+
+// Pushes returns the number of pushes, each sent as one or more requests.
+func (e *reconnectingExporter) Pushes() int64 {
+	return int64(e.pushes.Load())
+}
+
+// Requests returns the number of export requests sent.
+func (e *reconnectingExporter) Requests() int64 {
+	return e.requests.Load()
+}
+
+// Temporality delegates to the current exporter.
+func (e *reconnectingExporter) Temporality(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.current.Temporality(kind)
+}
+
+// Aggregation delegates to the current exporter.
+func (e *reconnectingExporter) Aggregation(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.current.Aggregation(kind)
+}
+
+// Export delegates one request to the current exporter.
+func (e *reconnectingExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.mu.RLock()
+	err := e.current.Export(ctx, rm)
+	e.mu.RUnlock()
+	e.requests.Add(1)
+	if err == nil {
+		e.exported.Add(int64(resourceDataPoints(rm)))
+	}
+	return err
+}
+
+// pushed counts a completed push.
+// Reconnects after every reconnectEvery pushes when configured.
+func (e *reconnectingExporter) pushed(ctx context.Context) {
+	pushes := e.pushes.Add(1)
+	if e.reconnectEvery > 0 && pushes%e.reconnectEvery == 0 {
+		if err := e.Reconnect(ctx); err != nil {
+			slog.Warn("otel reconnect failed", "error", err)
+		}
+	}
+}
+
+// pushExporter counts each export call as one push of the connection,
+// however many requests the push is split into below it.
+type pushExporter struct {
+	sdkmetric.Exporter
+	connection *reconnectingExporter
+}
+
+// Export forwards the push and counts it.
+func (e *pushExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	e.connection.pushed(ctx)
+	return err
+}
+
+// ForceFlush delegates to the current exporter.
+func (e *reconnectingExporter) ForceFlush(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.current.ForceFlush(ctx)
+}
+
+// Shutdown delegates to the current exporter.
+func (e *reconnectingExporter) Shutdown(ctx context.Context) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.current.Shutdown(ctx)
+}
+
+// reconnectPeriodically tears down and re-establishes the connection
+// at the configured interval.
+// Blocks until context is cancelled.
+func (e *OTELExporter) reconnectPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(e.config.Reconnect.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.connection.Reconnect(ctx); err != nil {
+				slog.Warn("otel reconnect failed", "error", err)
+			}
+		}
+	}
+}
+
+// watchEndpointDNS periodically re-resolves the collector host and
+// reconnects when the resolved address set changes.
+// Blocks until context is cancelled.
+func (e *OTELExporter) watchEndpointDNS(ctx context.Context) {
+	host := e.config.Host
+	if net.ParseIP(host) != nil {
+		slog.Debug("otel endpoint is an IP address, dns refresh disabled", "host", host)
+		return
+	}
+
+	previous, err := lookupHost(ctx, host)
+	if err != nil {
+		slog.Warn("failed to resolve otel endpoint", "host", host, "error", err)
+	}
+
+	ticker := time.NewTicker(e.config.DNSRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := lookupHost(ctx, host)
+		if err != nil {
+			slog.Warn("failed to resolve otel endpoint", "host", host, "error", err)
+			continue
+		}
+		if slices.Equal(previous, current) {
+			continue
+		}
+
+		slog.Info("otel endpoint address changed",
+			"host", host,
+			"previous", previous,
+			"current", current)
+		previous = current
+		e.endpointChanges.Add(1)
+
+		if err := e.connection.Reconnect(ctx); err != nil {
+			slog.Warn("otel reconnect failed", "error", err)
+		}
+	}
+}
+
+// lookupHost resolves host to a sorted address list.
+func lookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(addrs)
+	return addrs, nil
+}
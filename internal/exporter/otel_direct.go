@@ -0,0 +1,536 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// directExporter pushes OTLP requests built directly from the registry.
+// Data points and their attributes are prepared once per series, so a push
+// only reads values instead of running SDK callbacks and aggregation.
+type directExporter struct {
+	cfg      *config.OTELExportConfig
+	resource *resourcepb.Resource
+	factory  func() (otlpClient, error)
+	stamper  *sampleStamper // Data point timestamps (nil: wall clock)
+	created  time.Time      // Start time of sums with sdk start time semantics
+
+	clientMu sync.RWMutex
+	client   otlpClient
+
+	pushes     atomic.Uint64
+	requests   atomic.Int64 // Export requests, more than pushes when split
+	reconnects atomic.Int64
+	exported   atomic.Int64 // Data points accepted by the collector
+	drained    atomic.Bool  // Final push sent; periodic pushes stop
+
+	// Serializes pushes and updates; prepared data points are reused
+	mu        sync.Mutex
+	metrics   []*directMetric
+	internal  []otelInternalCounter
+	gauges    []internalGauge
+	epoch     time.Time // Start of the current reset window
+	nextReset time.Time
+}
+
+// directMetric holds an OTLP metric and the series filling its data points.
+type directMetric struct {
+	proto  *metricspb.Metric
+	sum    bool
+	series []*directSeries
+}
+
+// directSeries holds a prepared data point and its value reference.
+type directSeries struct {
+	value     *simulation.ValueWrapper
+	guard     *simulation.Guard
+	sampler   *metric.Sampler
+	point     metricspb.NumberDataPoint // Attributes rendered once
+	asInt     metricspb.NumberDataPoint_AsInt
+	firstSeen time.Time // First exported timestamp (zero: not yet exported)
+}
+
+// newDirectOTELExporter creates an OTEL exporter building requests directly
+// from the registry.
+func newDirectOTELExporter(
+	cfg *config.OTELExportConfig,
+	res *resource.Resource,
+	metrics *metric.Registry,
+	sim *simulation.Context,
+	internalMetrics config.InternalMetricsConfig,
+	chaos config.ChaosConfig,
+) (*OTELExporter, error) {
+	if len(chaos.OTLPFaults) > 0 {
+		return nil, fmt.Errorf("otlp_faults require the sdk payload")
+	}
+
+	// Shared across reconnects so cached credentials are reused
+	auth := newAuthenticator(cfg.Auth)
+
+	direct := &directExporter{
+		cfg:      cfg,
+		resource: &resourcepb.Resource{Attributes: resourceKeyValues(res)},
+		factory: func() (otlpClient, error) {
+			switch cfg.Transport {
+			case "grpc":
+				return newGRPCClient(cfg, auth)
+			case "http":
+				return newHTTPClient(cfg, auth), nil
+			default:
+				return nil, fmt.Errorf("unsupported transport: %s", cfg.Transport)
+			}
+		},
+		created: time.Now(),
+	}
+	if cfg.Timestamps != nil {
+		direct.stamper = newSampleStamper(*cfg.Timestamps, sim, "timestamps/otlp/"+cfg.Resource["service.name"])
+	}
+	if cfg.StartTime.ResetInterval > 0 {
+		direct.nextReset = time.Now().Add(cfg.StartTime.ResetInterval)
+	}
+
+	client, err := direct.factory()
+	if err != nil {
+		return nil, err
+	}
+	direct.client = client
+
+	e := &OTELExporter{
+		config:          cfg,
+		internalMetrics: internalMetrics,
+		connection:      direct,
+		direct:          direct,
+	}
+	if internalMetrics.Enabled {
+		direct.internal = e.internalCounters()
+		direct.gauges = internalGauges(internalMetrics, config.NamingFormatDot)
+	}
+	direct.update(metrics)
+
+	return e, nil
+}
+
+// update replaces the exported metrics. Series sharing a name are exported
+// as data points of one metric.
+func (d *directExporter) update(metrics *metric.Registry) {
+	var result []*directMetric
+	byName := make(map[string]*directMetric)
+	count := 0
+
+	for _, m := range metrics.Metrics() {
+		if !m.ExportedTo(config.ExportProtocolOTEL) {
+			continue
+		}
+
+		dm, exists := byName[m.OTELName]
+		if !exists {
+			dm = newDirectMetric(m.OTELName, m.Description, m.Unit, m.Type == metric.MetricTypeCounter)
+			byName[m.OTELName] = dm
+			result = append(result, dm)
+		}
+
+		s := &directSeries{
+			value:   m.Value,
+			guard:   m.Guard,
+			sampler: m.Sampler,
+		}
+		s.point.Attributes = stringKeyValues(m.Attributes)
+		s.point.Value = &s.asInt
+		dm.series = append(dm.series, s)
+		count++
+
+		slog.Debug("registered otel metric",
+			"name", m.OTELName,
+			"type", m.Type,
+			"payload", config.OTELPayloadDirect)
+	}
+
+	slog.Info("registered otel metrics", "count", count, "payload", config.OTELPayloadDirect)
+
+	d.mu.Lock()
+	d.metrics = result
+	d.mu.Unlock()
+}
+
+// newDirectMetric creates an OTLP metric without data points.
+// Counters are cumulative monotonic sums.
+func newDirectMetric(name, description, unit string, sum bool) *directMetric {
+	m := &metricspb.Metric{Name: name, Description: description, Unit: unit}
+	if sum {
+		m.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}}
+	} else {
+		m.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{}}
+	}
+	return &directMetric{proto: m, sum: sum}
+}
+
+// run pushes at the configured interval.
+// Blocks until context is cancelled.
+func (d *directExporter) run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval.Push)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if d.drained.Load() {
+				return
+			}
+			if err := d.push(ctx); err != nil {
+				slog.Warn("otel push failed", "error", err)
+			}
+		}
+	}
+}
+
+// shutdown pushes a final time and closes the connection.
+func (d *directExporter) shutdown(ctx context.Context) error {
+	err := d.push(ctx)
+
+	d.clientMu.Lock()
+	defer d.clientMu.Unlock()
+	if closeErr := d.client.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ExportedPoints returns the number of data points accepted by the collector.
+func (d *directExporter) ExportedPoints() int64 {
+	return d.exported.Load()
+}
+
+// push reads all series and sends them in one or more export requests.
+func (d *directExporter) push(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+
+	// Deliberate reset: move start time forward and forget first observations
+	if !d.nextReset.IsZero() && !now.Before(d.nextReset) {
+		d.epoch = now
+		d.nextReset = now.Add(d.cfg.StartTime.ResetInterval)
+		for _, m := range d.metrics {
+			for _, s := range m.series {
+				s.firstSeen = time.Time{}
+			}
+		}
+		slog.Debug("otel start time reset", "start_time", now)
+	}
+
+	metrics := d.collect(now)
+	slog.Debug("otel push", "metrics", len(metrics), "payload", config.OTELPayloadDirect)
+
+	// Requests are sent sequentially; their errors are joined
+	var errs []error
+	for _, batch := range splitDirectMetrics(metrics, d.cfg.MaxDataPoints) {
+		request := &collectorpb.ExportMetricsServiceRequest{
+			ResourceMetrics: []*metricspb.ResourceMetrics{{
+				Resource: d.resource,
+				ScopeMetrics: []*metricspb.ScopeMetrics{{
+					Scope:   &commonpb.InstrumentationScope{Name: "otelbox"},
+					Metrics: batch,
+				}},
+			}},
+		}
+		d.requests.Add(1)
+		if err := d.export(ctx, request); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, m := range batch {
+			d.exported.Add(int64(len(numberDataPoints(m))))
+		}
+	}
+
+	pushes := d.pushes.Add(1)
+	if reconnectEvery := uint64(d.cfg.Reconnect.Pushes); reconnectEvery > 0 && pushes%reconnectEvery == 0 {
+		if err := d.Reconnect(ctx); err != nil {
+			slog.Warn("otel reconnect failed", "error", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collect fills the prepared data points with current values and returns
+// the metrics holding at least one data point.
+// Must be called with d.mu held.
+func (d *directExporter) collect(now time.Time) []*metricspb.Metric {
+	stamped := now
+	if d.stamper != nil {
+		stamped = d.stamper.stamp(now)
+	}
+	shift := stamped.Sub(now) // Moves sdk start times with their timestamps
+
+	var result []*metricspb.Metric
+	for _, m := range d.metrics {
+		points := numberDataPoints(m.proto)[:0]
+		for _, s := range m.series {
+			// Omit sparse series from this push without consuming the value
+			if !s.sampler.Emit("otel") {
+				continue
+			}
+
+			// Omit throttled series likewise
+			if !simulation.AllowPoint() {
+				continue
+			}
+
+			// Integer data points cannot carry NaN or infinite samples
+			var val int64
+			var special bool
+			if !s.guard.Do("otel collect", func() {
+				if special = s.value.Special() != ""; !special {
+					val = int64(s.value.Read("otel")) // Resets this exporter's view for reset_on_read
+				}
+			}) || special {
+				continue
+			}
+
+			s.asInt.AsInt = val
+			s.point.TimeUnixNano = uint64(stamped.UnixNano())
+			if m.sum {
+				s.point.StartTimeUnixNano = uint64(d.startTime(s, stamped, shift).UnixNano())
+			}
+			points = append(points, &s.point)
+		}
+		setNumberDataPoints(m.proto, points)
+		if len(points) > 0 {
+			result = append(result, m.proto)
+		}
+	}
+
+	// Self-monitoring counters share the exporter start time
+	for _, c := range d.internal {
+		m := newDirectMetric(c.name, c.description, "", true)
+		setNumberDataPoints(m.proto, []*metricspb.NumberDataPoint{{
+			StartTimeUnixNano: uint64(d.created.Add(shift).UnixNano()),
+			TimeUnixNano:      uint64(stamped.UnixNano()),
+			Value:             &metricspb.NumberDataPoint_AsInt{AsInt: c.value()},
+		}})
+		result = append(result, m.proto)
+	}
+	for _, g := range d.gauges {
+		m := newDirectMetric(g.name, g.description, "", false)
+		setNumberDataPoints(m.proto, []*metricspb.NumberDataPoint{{
+			TimeUnixNano: uint64(stamped.UnixNano()),
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: g.value()},
+		}})
+		result = append(result, m.proto)
+	}
+
+	return result
+}
+
+// startTime returns the start timestamp of a sum data point at stamped.
+// Must be called with d.mu held.
+func (d *directExporter) startTime(s *directSeries, stamped time.Time, shift time.Duration) time.Time {
+	var start time.Time
+
+	switch d.cfg.StartTime.Mode {
+	case config.StartTimeModeProcess:
+		start = processStartTime
+	case config.StartTimeModeFixed:
+		start = d.cfg.StartTime.Time
+	case config.StartTimeModeSeries:
+		if s.firstSeen.IsZero() {
+			s.firstSeen = stamped
+		}
+		start = s.firstSeen
+	default:
+		start = d.created.Add(shift)
+	}
+
+	// Never report a start time before the last deliberate reset
+	if d.epoch.After(start) {
+		start = d.epoch
+	}
+
+	// Nor after the data point, which may be backdated
+	if start.After(stamped) {
+		start = stamped
+	}
+	return start
+}
+
+// numberDataPoints returns the data points of m.
+func numberDataPoints(m *metricspb.Metric) []*metricspb.NumberDataPoint {
+	switch data := m.Data.(type) {
+	case *metricspb.Metric_Sum:
+		return data.Sum.DataPoints
+	case *metricspb.Metric_Gauge:
+		return data.Gauge.DataPoints
+	}
+	return nil
+}
+
+// setNumberDataPoints replaces the data points of m.
+func setNumberDataPoints(m *metricspb.Metric, points []*metricspb.NumberDataPoint) {
+	switch data := m.Data.(type) {
+	case *metricspb.Metric_Sum:
+		data.Sum.DataPoints = points
+	case *metricspb.Metric_Gauge:
+		data.Gauge.DataPoints = points
+	}
+}
+
+// splitDirectMetrics groups metrics into batches of at most max data points
+// (0: one batch). Metrics spanning a boundary are split into copies
+// holding consecutive data points.
+func splitDirectMetrics(metrics []*metricspb.Metric, max int) [][]*metricspb.Metric {
+	if max <= 0 {
+		return [][]*metricspb.Metric{metrics}
+	}
+
+	var batches [][]*metricspb.Metric
+	var batch []*metricspb.Metric
+	size := 0
+	for _, m := range metrics {
+		points := numberDataPoints(m)
+		for len(points) > 0 {
+			n := min(max-size, len(points))
+			part := &metricspb.Metric{Name: m.Name, Description: m.Description, Unit: m.Unit}
+			switch data := m.Data.(type) {
+			case *metricspb.Metric_Sum:
+				part.Data = &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+					AggregationTemporality: data.Sum.AggregationTemporality,
+					IsMonotonic:            data.Sum.IsMonotonic,
+					DataPoints:             points[:n],
+				}}
+			case *metricspb.Metric_Gauge:
+				part.Data = &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points[:n]}}
+			}
+			batch = append(batch, part)
+			points = points[n:]
+
+			size += n
+			if size == max {
+				batches = append(batches, batch)
+				batch, size = nil, 0
+			}
+		}
+	}
+	if len(batch) > 0 || len(batches) == 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// export sends one request, retrying retryable failures with exponential
+// backoff per the configured retry policy.
+func (d *directExporter) export(ctx context.Context, request *collectorpb.ExportMetricsServiceRequest) error {
+	retry := d.cfg.Retry
+	backoff := retry.InitialInterval
+	deadline := time.Now().Add(retry.MaxElapsedTime)
+
+	for {
+		d.clientMu.RLock()
+		err := d.client.export(ctx, request)
+		d.clientMu.RUnlock()
+
+		if err == nil || !retry.Enabled || !isRetryable(err) {
+			return err
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("max retry time elapsed: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, retry.MaxInterval)
+	}
+}
+
+// Reconnect replaces the client and closes the previous one.
+func (d *directExporter) Reconnect(context.Context) error {
+	next, err := d.factory()
+	if err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+
+	d.clientMu.Lock()
+	previous := d.client
+	d.client = next
+	d.clientMu.Unlock()
+
+	d.reconnects.Add(1)
+	slog.Debug("otel exporter reconnected", "reconnects", d.reconnects.Load())
+
+	return previous.close()
+}
+
+// Reconnects returns the number of times the connection was re-established.
+func (d *directExporter) Reconnects() int64 {
+	return d.reconnects.Load()
+}
+
+// Pushes returns the number of pushes, each sent as one or more requests.
+func (d *directExporter) Pushes() int64 {
+	return int64(d.pushes.Load())
+}
+
+// Requests returns the number of export requests sent.
+func (d *directExporter) Requests() int64 {
+	return d.requests.Load()
+}
+
+// stringKeyValues converts attributes to sorted OTLP key values.
+func stringKeyValues(attributes map[string]string) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(attributes))
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		kvs = append(kvs, &commonpb.KeyValue{
+			Key:   key,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: attributes[key]}},
+		})
+	}
+	return kvs
+}
+
+// resourceKeyValues converts resource attributes to OTLP key values.
+// Slice-valued attributes are sent as their string form.
+func resourceKeyValues(res *resource.Resource) []*commonpb.KeyValue {
+	attrs := res.Attributes()
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		value := &commonpb.AnyValue{}
+		switch attr.Value.Type() {
+		case attribute.BOOL:
+			value.Value = &commonpb.AnyValue_BoolValue{BoolValue: attr.Value.AsBool()}
+		case attribute.INT64:
+			value.Value = &commonpb.AnyValue_IntValue{IntValue: attr.Value.AsInt64()}
+		case attribute.FLOAT64:
+			value.Value = &commonpb.AnyValue_DoubleValue{DoubleValue: attr.Value.AsFloat64()}
+		default:
+			value.Value = &commonpb.AnyValue_StringValue{StringValue: attr.Value.Emit()}
+		}
+		kvs = append(kvs, &commonpb.KeyValue{Key: string(attr.Key), Value: value})
+	}
+	return kvs
+}
@@ -0,0 +1,203 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers the gzip compressor
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// otlpClient sends prebuilt OTLP export requests to the collector.
+type otlpClient interface {
+	export(ctx context.Context, request *collectorpb.ExportMetricsServiceRequest) error
+	close() error
+}
+
+// retryableError marks a failed export the collector may accept later.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// isRetryable reports whether err marks a retryable export failure.
+func isRetryable(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable)
+}
+
+// grpcClient sends requests over an OTLP gRPC connection.
+type grpcClient struct {
+	conn     *grpc.ClientConn
+	service  collectorpb.MetricsServiceClient
+	headers  metadata.MD
+	timeout  time.Duration // Per-export request timeout
+	callOpts []grpc.CallOption
+}
+
+// newGRPCClient connects to the configured gRPC endpoint.
+func newGRPCClient(cfg *config.OTELExportConfig, auth authenticator) (*grpcClient, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+	}
+	if auth != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(grpcAuth{auth}))
+	}
+
+	conn, err := grpc.NewClient(cfg.GetEndpoint(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP gRPC client: %w", err)
+	}
+
+	c := &grpcClient{
+		conn:    conn,
+		service: collectorpb.NewMetricsServiceClient(conn),
+		headers: metadata.New(cfg.Headers),
+		timeout: cfg.Timeout,
+	}
+	if cfg.Compression == config.CompressionGzip {
+		c.callOpts = append(c.callOpts, grpc.UseCompressor("gzip"))
+	}
+	return c, nil
+}
+
+func (c *grpcClient) export(ctx context.Context, request *collectorpb.ExportMetricsServiceRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	if c.headers.Len() > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	}
+
+	response, err := c.service.Export(ctx, request, c.callOpts...)
+	if err != nil {
+		code := status.Code(err)
+		err = fmt.Errorf("failed to upload metrics: %w", err)
+		switch code {
+		case codes.Canceled, codes.DeadlineExceeded, codes.Aborted, codes.OutOfRange,
+			codes.Unavailable, codes.DataLoss, codes.ResourceExhausted:
+			return retryableError{err}
+		}
+		return err
+	}
+	return partialSuccess(response)
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+// httpClient sends requests as OTLP/HTTP protobuf.
+type httpClient struct {
+	client      *http.Client
+	url         string
+	headers     map[string]string
+	compression config.Compression
+}
+
+// newHTTPClient creates a client for the configured HTTP endpoint.
+func newHTTPClient(cfg *config.OTELExportConfig, auth authenticator) *httpClient {
+	var transport http.RoundTripper = http.DefaultTransport.(*http.Transport).Clone()
+	if auth != nil {
+		transport = &authTransport{base: transport, auth: auth}
+	}
+
+	return &httpClient{
+		client:      &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		url:         cfg.URL(),
+		headers:     cfg.Headers,
+		compression: cfg.Compression,
+	}
+}
+
+func (c *httpClient) export(ctx context.Context, request *collectorpb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode otlp: %w", err)
+	}
+	if c.compression == config.CompressionGzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return fmt.Errorf("failed to compress otlp: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress otlp: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to upload metrics: %w", err)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	if c.compression == config.CompressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return retryableError{fmt.Errorf("failed to upload metrics: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return retryableError{fmt.Errorf("failed to read otlp response: %w", err)}
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		return retryableError{fmt.Errorf("failed to upload metrics: %s", resp.Status)}
+	default:
+		return fmt.Errorf("failed to upload metrics: %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var response collectorpb.ExportMetricsServiceResponse
+	if err := proto.Unmarshal(data, &response); err != nil {
+		return nil // Responses are informational; the request was accepted
+	}
+	return partialSuccess(&response)
+}
+
+func (c *httpClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// partialSuccess reports data points the collector rejected.
+func partialSuccess(response *collectorpb.ExportMetricsServiceResponse) error {
+	partial := response.GetPartialSuccess()
+	if partial == nil || (partial.RejectedDataPoints == 0 && partial.ErrorMessage == "") {
+		return nil
+	}
+	return fmt.Errorf("otlp partial success: %d data points rejected: %s",
+		partial.RejectedDataPoints, partial.ErrorMessage)
+}
@@ -0,0 +1,119 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// faultExporter injects configured faults into a fraction of OTLP pushes.
+// At most one fault is injected per push.
+type faultExporter struct {
+	sdkmetric.Exporter
+	faults []config.OTLPFaultConfig
+	delays []*delaySampler // Per fault, set for delay faults
+	rng    simulation.RNG
+}
+
+// newFaultExporter wraps an exporter with fault injection drawing from the
+// streams of sim.
+func newFaultExporter(exporter sdkmetric.Exporter, faults []config.OTLPFaultConfig, sim *simulation.Context, key string) *faultExporter {
+	e := &faultExporter{
+		Exporter: exporter,
+		faults:   faults,
+		delays:   make([]*delaySampler, len(faults)),
+		rng:      newLockedRNG(sim, key),
+	}
+	// Each delay fault draws from its own stream
+	for i, fault := range faults {
+		if fault.Delay != nil {
+			e.delays[i] = newDelaySampler(*fault.Delay, sim, fmt.Sprintf("%s/%d/delay", key, i))
+		}
+	}
+	return e
+}
+
+// Export forwards the batch, possibly skipped, duplicated, delayed, or corrupted.
+func (e *faultExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	i, ok := e.pick()
+	if !ok {
+		return e.Exporter.Export(ctx, rm)
+	}
+	fault := e.faults[i]
+	slog.Debug("injecting otlp fault", "type", fault.Type)
+
+	switch fault.Type {
+	case config.OTLPFaultSkip:
+		return nil
+	case config.OTLPFaultDuplicate:
+		if err := e.Exporter.Export(ctx, rm); err != nil {
+			return err
+		}
+		return e.Exporter.Export(ctx, rm)
+	case config.OTLPFaultDelay:
+		timer := time.NewTimer(e.delays[i].sample())
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return e.Exporter.Export(ctx, rm)
+	case config.OTLPFaultCorrupt:
+		corruptMetrics(rm)
+		return e.Exporter.Export(ctx, rm)
+	default:
+		return e.Exporter.Export(ctx, rm)
+	}
+}
+
+// pick selects the fault to inject into a push, if any.
+func (e *faultExporter) pick() (int, bool) {
+	draw := uniform(e.rng, 0, 1)
+	for i, fault := range e.faults {
+		if draw < fault.Probability {
+			return i, true
+		}
+		draw -= fault.Probability
+	}
+	return 0, false
+}
+
+// corruptMetrics swaps start and end timestamps of all data points and
+// negates integer values, so counters decrease and intervals run backwards.
+func corruptMetrics(rm *metricdata.ResourceMetrics) {
+	for i := range rm.ScopeMetrics {
+		for j := range rm.ScopeMetrics[i].Metrics {
+			switch data := rm.ScopeMetrics[i].Metrics[j].Data.(type) {
+			case metricdata.Sum[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = dp.Time, dp.StartTime
+					dp.Value = -dp.Value
+				}
+			case metricdata.Gauge[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = dp.Time, dp.StartTime
+					dp.Value = -dp.Value
+				}
+			case metricdata.Histogram[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = dp.Time, dp.StartTime
+				}
+			case metricdata.ExponentialHistogram[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = dp.Time, dp.StartTime
+				}
+			}
+		}
+	}
+}
@@ -6,16 +6,24 @@ import (
 	"log/slog"
 	"sort"
 
+	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/monitor"
+	"github.com/neox5/otelbox/internal/simulation"
 	"go.opentelemetry.io/otel/attribute"
 	otelmetric "go.opentelemetry.io/otel/metric"
 )
 
 // registerOTELInstruments creates and registers instruments for all metrics.
+// Replaces the observation callback of a previous registration.
 func registerOTELInstruments(e *OTELExporter, metrics *metric.Registry) error {
 	var instruments []instrument
 
 	for _, m := range metrics.Metrics() {
+		if !m.ExportedTo(config.ExportProtocolOTEL) {
+			continue
+		}
+
 		// Convert attributes map to OTEL attributes
 		attrs := make([]attribute.KeyValue, 0, len(m.Attributes))
 		for key, val := range m.Attributes {
@@ -24,6 +32,8 @@ func registerOTELInstruments(e *OTELExporter, metrics *metric.Registry) error {
 
 		inst := instrument{
 			value:      m.Value,
+			guard:      m.Guard,
+			sampler:    m.Sampler,
 			attributes: attrs,
 		}
 
@@ -32,6 +42,7 @@ func registerOTELInstruments(e *OTELExporter, metrics *metric.Registry) error {
 			counter, err := e.meter.Int64ObservableCounter(
 				m.OTELName,
 				otelmetric.WithDescription(m.Description),
+				otelmetric.WithUnit(m.Unit),
 			)
 			if err != nil {
 				return fmt.Errorf("failed to create counter %q: %w", m.OTELName, err)
@@ -42,6 +53,7 @@ func registerOTELInstruments(e *OTELExporter, metrics *metric.Registry) error {
 			gauge, err := e.meter.Int64ObservableGauge(
 				m.OTELName,
 				otelmetric.WithDescription(m.Description),
+				otelmetric.WithUnit(m.Unit),
 			)
 			if err != nil {
 				return fmt.Errorf("failed to create gauge %q: %w", m.OTELName, err)
@@ -64,23 +76,35 @@ func registerOTELInstruments(e *OTELExporter, metrics *metric.Registry) error {
 			"attributes", fmt.Sprintf("[%s]", attrPairs))
 	}
 
-	e.instruments = instruments
-
 	slog.Info("registered otel metrics", "count", len(instruments))
 
-	// Register callback
-	if err := registerOTELCallback(e); err != nil {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Register the callback before dropping the previous one, so a failed
+	// update keeps observing the previous instruments
+	registration, err := registerOTELCallback(e.meter, instruments)
+	if err != nil {
 		return err
 	}
 
+	// Stop observing previous instruments
+	if e.registration != nil {
+		if err := e.registration.Unregister(); err != nil {
+			registration.Unregister()
+			return fmt.Errorf("failed to unregister callback: %w", err)
+		}
+	}
+	e.registration = registration
+
 	return nil
 }
 
-// registerOTELCallback registers the observation callback for all instruments.
-func registerOTELCallback(e *OTELExporter) error {
+// registerOTELCallback registers the observation callback for instruments.
+func registerOTELCallback(meter otelmetric.Meter, instruments []instrument) (otelmetric.Registration, error) {
 	// Collect all observables for callback registration
 	var observables []otelmetric.Observable
-	for _, inst := range e.instruments {
+	for _, inst := range instruments {
 		if inst.counter != nil {
 			observables = append(observables, inst.counter)
 		}
@@ -90,12 +114,31 @@ func registerOTELCallback(e *OTELExporter) error {
 	}
 
 	// Register callback with attributes
-	_, err := e.meter.RegisterCallback(
+	registration, err := meter.RegisterCallback(
 		func(ctx context.Context, observer otelmetric.Observer) error {
-			slog.Debug("otel push", "metrics", len(e.instruments))
+			slog.Debug("otel push", "metrics", len(instruments))
+
+			for _, inst := range instruments {
+				// Omit sparse series from this push without consuming the value
+				if !inst.sampler.Emit("otel") {
+					continue
+				}
+
+				// Omit throttled series likewise
+				if !simulation.AllowPoint() {
+					continue
+				}
 
-			for _, inst := range e.instruments {
-				val := int64(inst.value.Value()) // Triggers reset_on_read if configured
+				// Integer instruments cannot carry NaN or infinite samples
+				var val int64
+				var special bool
+				if !inst.guard.Do("otel collect", func() {
+					if special = inst.value.Special() != ""; !special {
+						val = int64(inst.value.Read("otel")) // Resets this exporter's view for reset_on_read
+					}
+				}) || special {
+					continue
+				}
 				if inst.counter != nil {
 					observer.ObserveInt64(inst.counter, val,
 						otelmetric.WithAttributes(inst.attributes...))
@@ -110,7 +153,80 @@ func registerOTELCallback(e *OTELExporter) error {
 		observables...,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to register callback: %w", err)
+		return nil, fmt.Errorf("failed to register callback: %w", err)
+	}
+
+	return registration, nil
+}
+
+// otelInternalCounter is an otelbox self-monitoring counter.
+type otelInternalCounter struct {
+	name        string
+	description string
+	value       func() int64
+}
+
+// internalCounters returns the self-monitoring counters of the exporter.
+func (e *OTELExporter) internalCounters() []otelInternalCounter {
+	name := func(parts ...string) string {
+		return internalMetricName(e.internalMetrics, config.NamingFormatDot, parts...)
+	}
+	return []otelInternalCounter{
+		{name("otlp", "endpoint", "changes"), "Number of times the resolved OTLP endpoint address changed", e.endpointChanges.Load},
+		{name("otlp", "reconnects"), "Number of times the OTLP connection was re-established", e.connection.Reconnects},
+		{name("otlp", "pushes"), "Number of OTLP pushes", e.connection.Pushes},
+		{name("otlp", "requests"), "Number of OTLP export requests, more than pushes when pushes are split by max_data_points", e.connection.Requests},
+		{name("panics"), "Number of panics recovered in series generation and export", simulation.RecoveredPanics},
+		{name("exporter", "restarts"), "Number of exporter restarts after failures", Restarts},
+		{name("throttled", "observations"), "Number of source updates dropped by the throttle", simulation.ThrottledObservations},
+		{name("throttled", "points"), "Number of data points dropped by the throttle", simulation.ThrottledPoints},
+		{name("generated", "values"), "Number of values generated by all sources", simulation.GeneratedValues},
+		{name("dropped", "observations"), "Number of source updates skipped because their series was disabled after a panic", simulation.DroppedObservations},
+		{name("shed", "observations"), "Number of source updates skipped by load shedding", simulation.ShedObservations},
+		{name("gc", "cycles"), "Number of completed garbage collection cycles, sampled by the resource monitor",
+			func() int64 { return int64(monitor.Current().NumGC) }},
+	}
+}
+
+// registerOTELInternalMetrics registers otelbox self-monitoring instruments.
+func registerOTELInternalMetrics(e *OTELExporter) error {
+	counters := e.internalCounters()
+	gauges := internalGauges(e.internalMetrics, config.NamingFormatDot)
+
+	instruments := make([]otelmetric.Int64ObservableCounter, len(counters))
+	gaugeInstruments := make([]otelmetric.Float64ObservableGauge, len(gauges))
+	var observables []otelmetric.Observable
+	for i, c := range counters {
+		counter, err := e.meter.Int64ObservableCounter(c.name, otelmetric.WithDescription(c.description))
+		if err != nil {
+			return fmt.Errorf("failed to create internal metric: %w", err)
+		}
+		instruments[i] = counter
+		observables = append(observables, counter)
+	}
+	for i, g := range gauges {
+		gauge, err := e.meter.Float64ObservableGauge(g.name, otelmetric.WithDescription(g.description))
+		if err != nil {
+			return fmt.Errorf("failed to create internal metric: %w", err)
+		}
+		gaugeInstruments[i] = gauge
+		observables = append(observables, gauge)
+	}
+
+	_, err := e.meter.RegisterCallback(
+		func(ctx context.Context, observer otelmetric.Observer) error {
+			for i, c := range counters {
+				observer.ObserveInt64(instruments[i], c.value())
+			}
+			for i, g := range gauges {
+				observer.ObserveFloat64(gaugeInstruments[i], g.value())
+			}
+			return nil
+		},
+		observables...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register internal metrics callback: %w", err)
 	}
 
 	return nil
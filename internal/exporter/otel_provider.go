@@ -3,34 +3,67 @@ package exporter
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc"
 )
 
 // createMeterProvider creates an OTEL meter provider with OTLP exporter.
+// The returned connection allows replacing the OTLP exporter at runtime.
 func createMeterProvider(
 	cfg *config.OTELExportConfig,
 	res *resource.Resource,
-) (*sdkmetric.MeterProvider, error) {
+	sim *simulation.Context,
+	faults []config.OTLPFaultConfig,
+) (*sdkmetric.MeterProvider, *reconnectingExporter, error) {
+	// Shared across reconnects so cached credentials are reused
+	auth := newAuthenticator(cfg.Auth)
+
 	// Create exporter based on transport type
-	var exporter sdkmetric.Exporter
-	var err error
+	connection, err := newReconnectingExporter(func() (sdkmetric.Exporter, error) {
+		switch cfg.Transport {
+		case "grpc":
+			return createGRPCExporter(cfg, auth)
+		case "http":
+			return createHTTPExporter(cfg, auth)
+		default:
+			return nil, fmt.Errorf("unsupported transport: %s", cfg.Transport)
+		}
+	}, cfg.Reconnect.Pushes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var exporter sdkmetric.Exporter = connection
 
-	switch cfg.Transport {
-	case "grpc":
-		exporter, err = createGRPCExporter(cfg)
-	case "http":
-		exporter, err = createHTTPExporter(cfg)
-	default:
-		return nil, fmt.Errorf("unsupported transport: %s", cfg.Transport)
+	// Split pushes into size-capped requests
+	if cfg.MaxDataPoints > 0 {
+		exporter = newSplitExporter(exporter, cfg.MaxDataPoints)
 	}
 
-	if err != nil {
-		return nil, err
+	// Count pushes above the split, so the requests of one push count once
+	exporter = &pushExporter{Exporter: exporter, connection: connection}
+
+	// Inject push faults below start time rewriting
+	if len(faults) > 0 {
+		exporter = newFaultExporter(exporter, faults, sim, "chaos/otlp_faults/"+cfg.GetEndpoint()+"/"+cfg.Resource["service.name"])
+	}
+
+	// Rewrite counter start timestamps unless SDK semantics are kept
+	if cfg.StartTime.Mode != config.StartTimeModeSDK || cfg.StartTime.ResetInterval > 0 {
+		exporter = newStartTimeExporter(exporter, cfg.StartTime)
+	}
+
+	// Offset data point timestamps before start times are rewritten
+	if cfg.Timestamps != nil {
+		exporter = newTimestampExporter(exporter, newSampleStamper(*cfg.Timestamps, sim, "timestamps/otlp/"+cfg.Resource["service.name"]))
 	}
 
 	// Create periodic reader with push interval
@@ -45,14 +78,26 @@ func createMeterProvider(
 		sdkmetric.WithReader(reader),
 	)
 
-	return meterProvider, nil
+	return meterProvider, connection, nil
 }
 
 // createGRPCExporter creates an OTLP gRPC exporter.
-func createGRPCExporter(cfg *config.OTELExportConfig) (sdkmetric.Exporter, error) {
+func createGRPCExporter(cfg *config.OTELExportConfig, auth authenticator) (sdkmetric.Exporter, error) {
 	opts := []otlpmetricgrpc.Option{
 		otlpmetricgrpc.WithEndpoint(cfg.GetEndpoint()),
-		otlpmetricgrpc.WithInsecure(), // TODO: Add TLS support later
+		otlpmetricgrpc.WithTemporalitySelector(temporalitySelector(cfg.Temporality)),
+		otlpmetricgrpc.WithAggregationSelector(aggregationSelector(cfg.Aggregation)),
+		otlpmetricgrpc.WithTimeout(cfg.Timeout),
+		otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetryConfig(retryConfig(cfg.Retry))),
+	}
+
+	// Without TLS credentials the exporter uses TLS with system roots
+	if !cfg.TLS {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+
+	if cfg.Compression == config.CompressionGzip {
+		opts = append(opts, otlpmetricgrpc.WithCompressor("gzip"))
 	}
 
 	// Add custom headers
@@ -60,6 +105,11 @@ func createGRPCExporter(cfg *config.OTELExportConfig) (sdkmetric.Exporter, error
 		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.Headers))
 	}
 
+	// Add per-call authorization
+	if auth != nil {
+		opts = append(opts, otlpmetricgrpc.WithDialOption(grpc.WithPerRPCCredentials(grpcAuth{auth})))
+	}
+
 	exporter, err := otlpmetricgrpc.New(context.Background(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP gRPC exporter: %w", err)
@@ -69,10 +119,22 @@ func createGRPCExporter(cfg *config.OTELExportConfig) (sdkmetric.Exporter, error
 }
 
 // createHTTPExporter creates an OTLP HTTP exporter.
-func createHTTPExporter(cfg *config.OTELExportConfig) (sdkmetric.Exporter, error) {
+func createHTTPExporter(cfg *config.OTELExportConfig, auth authenticator) (sdkmetric.Exporter, error) {
 	opts := []otlpmetrichttp.Option{
 		otlpmetrichttp.WithEndpoint(cfg.GetEndpoint()),
-		otlpmetrichttp.WithInsecure(), // TODO: Add TLS support later
+		otlpmetrichttp.WithURLPath(cfg.Path),
+		otlpmetrichttp.WithTemporalitySelector(temporalitySelector(cfg.Temporality)),
+		otlpmetrichttp.WithAggregationSelector(aggregationSelector(cfg.Aggregation)),
+		otlpmetrichttp.WithTimeout(cfg.Timeout),
+		otlpmetrichttp.WithRetry(retryConfig(cfg.Retry)),
+	}
+
+	if !cfg.TLS {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+
+	if cfg.Compression == config.CompressionGzip {
+		opts = append(opts, otlpmetrichttp.WithCompression(otlpmetrichttp.GzipCompression))
 	}
 
 	// Add custom headers
@@ -80,6 +142,18 @@ func createHTTPExporter(cfg *config.OTELExportConfig) (sdkmetric.Exporter, error
 		opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
 	}
 
+	// Add per-request authorization (the client replaces the SDK default,
+	// so the timeout is carried over)
+	if auth != nil {
+		opts = append(opts, otlpmetrichttp.WithHTTPClient(&http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &authTransport{
+				base: http.DefaultTransport.(*http.Transport).Clone(),
+				auth: auth,
+			},
+		}))
+	}
+
 	exporter, err := otlpmetrichttp.New(context.Background(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP HTTP exporter: %w", err)
@@ -87,3 +161,55 @@ func createHTTPExporter(cfg *config.OTELExportConfig) (sdkmetric.Exporter, error
 
 	return exporter, nil
 }
+
+// temporalitySelector maps instrument kinds to the configured temporality.
+// Counters are observable counters and gauges observable gauges.
+func temporalitySelector(cfg config.TemporalityConfig) sdkmetric.TemporalitySelector {
+	return func(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+		temporality := config.TemporalityCumulative
+		switch kind {
+		case sdkmetric.InstrumentKindObservableCounter:
+			temporality = cfg.Counter
+		case sdkmetric.InstrumentKindObservableGauge:
+			temporality = cfg.Gauge
+		}
+		if temporality == config.TemporalityDelta {
+			return metricdata.DeltaTemporality
+		}
+		return metricdata.CumulativeTemporality
+	}
+}
+
+// aggregationSelector maps instrument kinds to the configured aggregation.
+func aggregationSelector(cfg config.AggregationConfig) sdkmetric.AggregationSelector {
+	return func(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+		aggregation := config.AggregationDefault
+		switch kind {
+		case sdkmetric.InstrumentKindObservableCounter:
+			aggregation = cfg.Counter
+		case sdkmetric.InstrumentKindObservableGauge:
+			aggregation = cfg.Gauge
+		}
+
+		switch aggregation {
+		case config.AggregationHistogram:
+			return sdkmetric.DefaultAggregationSelector(sdkmetric.InstrumentKindHistogram)
+		case config.AggregationExponentialHistogram:
+			return sdkmetric.AggregationBase2ExponentialHistogram{MaxSize: 160, MaxScale: 20}
+		case config.AggregationDrop:
+			return sdkmetric.AggregationDrop{}
+		default:
+			return sdkmetric.DefaultAggregationSelector(kind)
+		}
+	}
+}
+
+// retryConfig converts the configured retry policy to the OTLP exporter form.
+func retryConfig(cfg config.RetryConfig) otlpmetrichttp.RetryConfig {
+	return otlpmetrichttp.RetryConfig{
+		Enabled:         cfg.Enabled,
+		InitialInterval: cfg.InitialInterval,
+		MaxInterval:     cfg.MaxInterval,
+		MaxElapsedTime:  cfg.MaxElapsedTime,
+	}
+}
@@ -2,26 +2,62 @@ package exporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 
+	"github.com/neox5/otelbox/internal/config"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
-// createOTELResource creates an OTEL resource from configuration attributes.
-func createOTELResource(resourceAttrs map[string]string) (*resource.Resource, error) {
+// createOTELResource creates an OTEL resource from detected and configured
+// attributes. Configured attributes override detected ones.
+func createOTELResource(resourceAttrs map[string]string, detectors []config.ResourceDetector) (*resource.Resource, error) {
 	attrs := make([]attribute.KeyValue, 0, len(resourceAttrs))
 	for k, v := range resourceAttrs {
 		attrs = append(attrs, attribute.String(k, v))
 	}
 
-	res, err := resource.New(
-		context.Background(),
-		resource.WithAttributes(attrs...),
-	)
-	if err != nil {
+	var opts []resource.Option
+	for _, d := range detectors {
+		switch d {
+		case config.ResourceDetectorHost:
+			opts = append(opts, resource.WithHost(), resource.WithHostID(), resource.WithOS())
+		case config.ResourceDetectorProcess:
+			opts = append(opts, resource.WithProcess())
+		case config.ResourceDetectorContainer:
+			opts = append(opts, resource.WithContainer())
+		case config.ResourceDetectorK8s:
+			opts = append(opts, resource.WithDetectors(k8sDetector{}))
+		}
+	}
+	// Later options override earlier ones
+	opts = append(opts, resource.WithAttributes(attrs...))
+
+	res, err := resource.New(context.Background(), opts...)
+	if errors.Is(err, resource.ErrPartialResource) {
+		// Detection failures are not fatal outside the detected environment
+		slog.Warn("resource detection incomplete", "error", err)
+	} else if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
 	return res, nil
 }
+
+// k8sDetector reads k8s resource attributes from environment variables
+// populated through the Kubernetes downward API.
+type k8sDetector struct{}
+
+// Detect returns the attributes of all set variables.
+func (k8sDetector) Detect(context.Context) (*resource.Resource, error) {
+	var attrs []attribute.KeyValue
+	for _, attr := range config.K8sEnvAttributes {
+		if value, ok := attr.Lookup(); ok {
+			attrs = append(attrs, attribute.String(attr.Attribute, value))
+		}
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
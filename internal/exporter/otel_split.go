@@ -0,0 +1,129 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// splitExporter sends each push as requests of at most limit data points.
+// Metrics larger than the cap are split across requests.
+type splitExporter struct {
+	sdkmetric.Exporter
+	limit int
+}
+
+// newSplitExporter wraps an exporter with a per-request data point cap.
+func newSplitExporter(exporter sdkmetric.Exporter, limit int) *splitExporter {
+	return &splitExporter{Exporter: exporter, limit: limit}
+}
+
+// Export forwards the push in capped requests. All requests are attempted;
+// their errors are joined.
+func (e *splitExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	batches := splitResourceMetrics(rm, e.limit)
+	if len(batches) <= 1 {
+		return e.Exporter.Export(ctx, rm)
+	}
+	slog.Debug("splitting otlp push", "requests", len(batches), "max_data_points", e.limit)
+
+	var errs []error
+	for _, batch := range batches {
+		if err := e.Exporter.Export(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resourceDataPoints returns the number of data points in rm.
+func resourceDataPoints(rm *metricdata.ResourceMetrics) int {
+	n := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			n += dataPointCount(m.Data)
+		}
+	}
+	return n
+}
+
+// splitResourceMetrics partitions rm into batches of at most limit data
+// points, preserving scope and metric order.
+func splitResourceMetrics(rm *metricdata.ResourceMetrics, limit int) []*metricdata.ResourceMetrics {
+	var batches []*metricdata.ResourceMetrics
+	var current *metricdata.ResourceMetrics
+	size := 0
+	scope := -1 // Scope index of the last ScopeMetrics in current
+
+	for i, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			n := dataPointCount(m.Data)
+			for lo := 0; ; {
+				// Metrics without data points never start a request
+				if current == nil || (size >= limit && n > 0) {
+					current = &metricdata.ResourceMetrics{Resource: rm.Resource}
+					batches = append(batches, current)
+					size = 0
+					scope = -1
+				}
+				if scope != i {
+					current.ScopeMetrics = append(current.ScopeMetrics, metricdata.ScopeMetrics{Scope: sm.Scope})
+					scope = i
+				}
+
+				hi := min(n, lo+limit-size)
+				part := m
+				part.Data = sliceDataPoints(m.Data, lo, hi)
+				last := &current.ScopeMetrics[len(current.ScopeMetrics)-1]
+				last.Metrics = append(last.Metrics, part)
+
+				size += hi - lo
+				lo = hi
+				if lo >= n {
+					break
+				}
+			}
+		}
+	}
+	return batches
+}
+
+// dataPointCount returns the number of data points in data. Unknown
+// aggregations count as a single unsplittable point.
+func dataPointCount(data metricdata.Aggregation) int {
+	switch data := data.(type) {
+	case metricdata.Sum[int64]:
+		return len(data.DataPoints)
+	case metricdata.Gauge[int64]:
+		return len(data.DataPoints)
+	case metricdata.Histogram[int64]:
+		return len(data.DataPoints)
+	case metricdata.ExponentialHistogram[int64]:
+		return len(data.DataPoints)
+	default:
+		return 1
+	}
+}
+
+// sliceDataPoints returns data restricted to data points [lo, hi).
+func sliceDataPoints(data metricdata.Aggregation, lo, hi int) metricdata.Aggregation {
+	switch data := data.(type) {
+	case metricdata.Sum[int64]:
+		data.DataPoints = data.DataPoints[lo:hi]
+		return data
+	case metricdata.Gauge[int64]:
+		data.DataPoints = data.DataPoints[lo:hi]
+		return data
+	case metricdata.Histogram[int64]:
+		data.DataPoints = data.DataPoints[lo:hi]
+		return data
+	case metricdata.ExponentialHistogram[int64]:
+		data.DataPoints = data.DataPoints[lo:hi]
+		return data
+	default:
+		return data
+	}
+}
@@ -0,0 +1,98 @@
+package exporter
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// splitMetric is a metric name with its data point count.
+type splitMetric struct {
+	name   string
+	points int
+}
+
+// splitInput builds resource metrics from scopes of metric name to data
+// point count.
+func splitInput(scopes ...[]splitMetric) *metricdata.ResourceMetrics {
+	rm := &metricdata.ResourceMetrics{}
+	for i, metrics := range scopes {
+		sm := metricdata.ScopeMetrics{Scope: instrumentation.Scope{Name: fmt.Sprintf("s%d", i)}}
+		for _, m := range metrics {
+			sm.Metrics = append(sm.Metrics, metricdata.Metrics{
+				Name: m.name,
+				Data: metricdata.Sum[int64]{DataPoints: make([]metricdata.DataPoint[int64], m.points)},
+			})
+		}
+		rm.ScopeMetrics = append(rm.ScopeMetrics, sm)
+	}
+	return rm
+}
+
+// describeBatches renders batches as "scope/metric:points" entries.
+func describeBatches(batches []*metricdata.ResourceMetrics) [][]string {
+	var got [][]string
+	for _, batch := range batches {
+		var entries []string
+		for _, sm := range batch.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				entries = append(entries, fmt.Sprintf("%s/%s:%d", sm.Scope.Name, m.Name, dataPointCount(m.Data)))
+			}
+		}
+		got = append(got, entries)
+	}
+	return got
+}
+
+func TestSplitResourceMetrics(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *metricdata.ResourceMetrics
+		limit int
+		want  [][]string
+	}{
+		{
+			name:  "within limit",
+			input: splitInput([]splitMetric{{"a", 2}, {"b", 1}}),
+			limit: 3,
+			want:  [][]string{{"s0/a:2", "s0/b:1"}},
+		},
+		{
+			name:  "metric larger than limit",
+			input: splitInput([]splitMetric{{"a", 5}}),
+			limit: 2,
+			want:  [][]string{{"s0/a:2"}, {"s0/a:2"}, {"s0/a:1"}},
+		},
+		{
+			name:  "boundary across scopes",
+			input: splitInput([]splitMetric{{"a", 2}}, []splitMetric{{"b", 3}}),
+			limit: 3,
+			want:  [][]string{{"s0/a:2", "s1/b:1"}, {"s1/b:2"}},
+		},
+		{
+			name:  "zero point metric",
+			input: splitInput([]splitMetric{{"a", 2}, {"empty", 0}, {"b", 1}}),
+			limit: 2,
+			want:  [][]string{{"s0/a:2", "s0/empty:0"}, {"s0/b:1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := splitResourceMetrics(tt.input, tt.limit)
+			for i, batch := range batches {
+				if n := resourceDataPoints(batch); n > tt.limit {
+					t.Errorf("batch %d has %d data points, limit %d", i, n, tt.limit)
+				}
+			}
+
+			got := describeBatches(batches)
+			if !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("batches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// processStartTime approximates the otelbox process start.
+var processStartTime = time.Now()
+
+// seriesKey identifies a single exported time series.
+type seriesKey struct {
+	name       string
+	attributes attribute.Distinct
+}
+
+// startTimeExporter rewrites cumulative counter start timestamps before export.
+type startTimeExporter struct {
+	sdkmetric.Exporter
+	cfg config.StartTimeConfig
+
+	mu        sync.Mutex
+	epoch     time.Time // Start of the current reset window
+	nextReset time.Time
+	firstSeen map[seriesKey]time.Time
+}
+
+// newStartTimeExporter wraps an exporter with configured start time semantics.
+func newStartTimeExporter(exporter sdkmetric.Exporter, cfg config.StartTimeConfig) *startTimeExporter {
+	e := &startTimeExporter{
+		Exporter:  exporter,
+		cfg:       cfg,
+		firstSeen: make(map[seriesKey]time.Time),
+	}
+	if cfg.ResetInterval > 0 {
+		e.nextReset = time.Now().Add(cfg.ResetInterval)
+	}
+	return e
+}
+
+// Export applies start time semantics to counter data points and forwards them.
+func (e *startTimeExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	e.mu.Lock()
+
+	// Deliberate reset: move start time forward and forget first observations
+	now := time.Now()
+	if !e.nextReset.IsZero() && !now.Before(e.nextReset) {
+		e.epoch = now
+		e.nextReset = now.Add(e.cfg.ResetInterval)
+		clear(e.firstSeen)
+		slog.Debug("otel start time reset", "start_time", now)
+	}
+
+	for i := range rm.ScopeMetrics {
+		for j := range rm.ScopeMetrics[i].Metrics {
+			m := &rm.ScopeMetrics[i].Metrics[j]
+			// Delta sums carry the previous export time as start time
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || sum.Temporality != metricdata.CumulativeTemporality {
+				continue
+			}
+			for k := range sum.DataPoints {
+				dp := &sum.DataPoints[k]
+				dp.StartTime = e.startTime(m.Name, dp)
+			}
+		}
+	}
+
+	e.mu.Unlock()
+
+	return e.Exporter.Export(ctx, rm)
+}
+
+// startTime returns the start timestamp for a data point.
+// Must be called with e.mu held.
+func (e *startTimeExporter) startTime(name string, dp *metricdata.DataPoint[int64]) time.Time {
+	var start time.Time
+
+	switch e.cfg.Mode {
+	case config.StartTimeModeProcess:
+		start = processStartTime
+	case config.StartTimeModeFixed:
+		start = e.cfg.Time
+	case config.StartTimeModeSeries:
+		key := seriesKey{name: name, attributes: dp.Attributes.Equivalent()}
+		first, exists := e.firstSeen[key]
+		if !exists {
+			first = dp.Time
+			e.firstSeen[key] = first
+		}
+		start = first
+	default:
+		start = dp.StartTime
+	}
+
+	// Never report a start time before the last deliberate reset
+	if e.epoch.After(start) {
+		start = e.epoch
+	}
+
+	// Nor after the data point, which may be backdated
+	if start.After(dp.Time) {
+		start = dp.Time
+	}
+	return start
+}
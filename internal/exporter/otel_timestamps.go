@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"context"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// timestampExporter moves data point timestamps away from the collection
+// time before export, emulating backdated or out-of-order pushes.
+type timestampExporter struct {
+	sdkmetric.Exporter
+	stamper *sampleStamper
+}
+
+// newTimestampExporter wraps an exporter with configured data point timestamps.
+func newTimestampExporter(exporter sdkmetric.Exporter, stamper *sampleStamper) *timestampExporter {
+	return &timestampExporter{Exporter: exporter, stamper: stamper}
+}
+
+// Export restamps all data points and forwards them. Start timestamps move
+// by the same amount, so intervals keep their length and stay ordered.
+func (e *timestampExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	for i := range rm.ScopeMetrics {
+		for j := range rm.ScopeMetrics[i].Metrics {
+			switch data := rm.ScopeMetrics[i].Metrics[j].Data.(type) {
+			case metricdata.Sum[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = e.restamp(dp.StartTime, dp.Time)
+				}
+			case metricdata.Gauge[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = e.restamp(dp.StartTime, dp.Time)
+				}
+			case metricdata.Histogram[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = e.restamp(dp.StartTime, dp.Time)
+				}
+			case metricdata.ExponentialHistogram[int64]:
+				for k := range data.DataPoints {
+					dp := &data.DataPoints[k]
+					dp.StartTime, dp.Time = e.restamp(dp.StartTime, dp.Time)
+				}
+			}
+		}
+	}
+	return e.Exporter.Export(ctx, rm)
+}
+
+// restamp shifts a data point interval to end at its new timestamp.
+func (e *timestampExporter) restamp(start, end time.Time) (time.Time, time.Time) {
+	stamped := e.stamper.stamp(end)
+	if start.IsZero() {
+		return start, stamped
+	}
+	return start.Add(stamped.Sub(end)), stamped
+}
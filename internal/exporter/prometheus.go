@@ -5,50 +5,111 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
+	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 // PrometheusExporter provides HTTP server for Prometheus metrics.
 type PrometheusExporter struct {
-	addr         string
-	path         string
-	server       *http.Server
-	promRegistry *prometheus.Registry
+	addr             string
+	path             string
+	socketActivation bool
+	socket           *os.File // Socket passed by systemd (nil: not activated)
+	server           *http.Server
+	promRegistry     *prometheus.Registry
+	collector        *collector
+	process          *processCollector // Emulated process metrics (nil if disabled)
+	drain            *drainWatcher
 }
 
-// NewPrometheusExporter creates a new Prometheus HTTP exporter.
+// NewPrometheusExporter creates a new Prometheus HTTP exporter. Chaos and
+// emulated processes draw from the random streams of sim.
 func NewPrometheusExporter(
-	port int,
-	path string,
+	cfg *config.PrometheusExportConfig,
 	metrics *metric.Registry,
-	internalMetricsEnabled bool,
+	sim *simulation.Context,
+	internalMetrics config.InternalMetricsConfig,
+	chaos config.ChaosConfig,
 ) *PrometheusExporter {
 	// Create registry
-	promRegistry := createPrometheusRegistry(metrics)
+	promRegistry, c := createPrometheusRegistry(metrics, cfg.ConstLabels)
+	if cfg.Timestamps != nil {
+		c.stamper = newSampleStamper(*cfg.Timestamps, sim, fmt.Sprintf("timestamps/prometheus/:%d", cfg.Port))
+	}
+	c.created = cfg.Metadata.Created
+
+	// Register other collectors apart from the generated series, so
+	// endpoints reading the series as their own consumer serve them too
+	others := prometheus.NewRegistry()
+	promRegistry.MustRegister(gathererCollector{gatherer: others})
+
+	// Register emulated process metrics
+	var process *processCollector
+	if cfg.ProcessMetrics.Enabled {
+		process = newProcessCollector(cfg.ProcessMetrics, cfg.ConstLabels, sim, metrics)
+		others.MustRegister(process)
+	}
+
+	// Register upstream amplification
+	for _, upstream := range cfg.Upstreams {
+		others.MustRegister(newUpstreamCollector(upstream, cfg.ConstLabels))
+		slog.Info("registered prometheus upstream",
+			"url", upstream.URL,
+			"replicas", upstream.Replicas)
+	}
+
+	// Register internal metrics, labeled with the const labels like all
+	// other exposed series
+	internal := prometheus.NewRegistry()
+	others.MustRegister(gathererCollector{gatherer: internal, constLabels: cfg.ConstLabels})
+	var scrapeIntervals *prometheus.HistogramVec
+	if internalMetrics.Enabled {
+		scrapeIntervals = registerPrometheusInternalMetrics(internal, internalMetrics)
+	}
 
 	// Setup HTTP server
-	addr := fmt.Sprintf(":%d", port)
-	server := createHTTPServer(addr, path, promRegistry, internalMetricsEnabled)
+	addr := cfg.Addr()
+	drain := newDrainWatcher()
+	server := createHTTPServer(addr, cfg, sim, chaos, promRegistry, others, internal, c, internalMetrics.Enabled, scrapeIntervals, drain)
 
 	return &PrometheusExporter{
-		addr:         addr,
-		path:         path,
-		promRegistry: promRegistry,
-		server:       server,
+		addr:             addr,
+		path:             cfg.Path,
+		socketActivation: cfg.SocketActivation,
+		promRegistry:     promRegistry,
+		collector:        c,
+		process:          process,
+		server:           server,
+		drain:            drain,
+	}
+}
+
+// Update replaces the exported metrics without restarting the HTTP server.
+func (e *PrometheusExporter) Update(metrics *metric.Registry) {
+	e.collector.update(metrics)
+	if e.process != nil {
+		e.process.update(metrics)
 	}
 }
 
 // Start begins serving HTTP requests.
 // Blocks until context is cancelled, then shuts down gracefully.
 func (e *PrometheusExporter) Start(ctx context.Context) error {
+	listener, err := e.listen()
+	if err != nil {
+		return err
+	}
+
 	errChan := make(chan error, 1)
 
 	go func() {
-		slog.Info("starting prometheus exporter", "addr", e.addr, "path", e.path)
-		if err := e.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		slog.Info("starting prometheus exporter", "addr", listener.Addr().String(), "path", e.path)
+		if err := e.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
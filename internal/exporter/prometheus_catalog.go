@@ -0,0 +1,133 @@
+package exporter
+
+import (
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// catalogPageSeries is the number of series listed per metric on the
+// landing page; the JSON catalog lists all series.
+const catalogPageSeries = 20
+
+// catalogSeries is a series of the metric catalog.
+type catalogSeries struct {
+	Labels map[string]string `json:"labels"`
+	Value  int               `json:"value"`
+}
+
+// catalogMetric is a metric of the catalog with all its series.
+type catalogMetric struct {
+	Name   string          `json:"name"`
+	Type   string          `json:"type"`
+	Help   string          `json:"help"`
+	Series []catalogSeries `json:"series"`
+}
+
+// metricCatalog lists the generated metrics in exposition order.
+type metricCatalog struct {
+	Path    string          `json:"path"`
+	Series  int             `json:"series"`
+	Metrics []catalogMetric `json:"metrics"`
+}
+
+// catalog returns the generated metrics with their current values. Values
+// are read without consuming reset_on_read deltas, so viewing the catalog
+// does not change what scrapes observe.
+func (c *collector) catalog() []catalogMetric {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var metrics []catalogMetric
+	index := make(map[string]int)
+	for i := range c.descriptors {
+		d := &c.descriptors[i]
+
+		var value int
+		if !d.guard.Do("catalog", func() { value = d.value.State() }) {
+			continue
+		}
+
+		j, exists := index[d.name]
+		if !exists {
+			typ := "gauge"
+			if d.valueType == prometheus.CounterValue {
+				typ = "counter"
+			}
+			j = len(metrics)
+			index[d.name] = j
+			metrics = append(metrics, catalogMetric{Name: d.name, Type: typ, Help: d.help})
+		}
+
+		labels := make(map[string]string, len(d.labels))
+		for _, pair := range d.labels {
+			labels[pair.GetName()] = pair.GetValue()
+		}
+		metrics[j].Series = append(metrics[j].Series, catalogSeries{Labels: labels, Value: value})
+	}
+	return metrics
+}
+
+// catalogHandler serves the metric catalog as an HTML landing page, or as
+// JSON if requested with ?format=json or Accept: application/json.
+func catalogHandler(c *collector, path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		catalog := metricCatalog{Path: path, Metrics: c.catalog()}
+		for _, m := range catalog.Metrics {
+			catalog.Series += len(m.Series)
+		}
+
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(catalog); err != nil {
+				slog.Debug("failed to write metric catalog", "error", err)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := landingPage.Execute(w, catalog); err != nil {
+			slog.Debug("failed to write landing page", "error", err)
+		}
+	})
+}
+
+// landingPage renders the metric catalog for browsers.
+var landingPage = template.Must(template.New("landing").Funcs(template.FuncMap{
+	"head": func(series []catalogSeries) []catalogSeries {
+		return series[:min(len(series), catalogPageSeries)]
+	},
+	"more": func(series []catalogSeries) int {
+		return max(len(series)-catalogPageSeries, 0)
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>otelbox</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; font-family: monospace; }
+.help { color: #555; }
+</style>
+</head>
+<body>
+<h1>otelbox</h1>
+<p>{{len .Metrics}} metrics, {{.Series}} series. Metrics: <a href="{{.Path}}">{{.Path}}</a>, catalog: <a href="?format=json">JSON</a></p>
+{{range .Metrics}}
+<h2 id="{{.Name}}">{{.Name}} <small>{{.Type}}</small></h2>
+{{if .Help}}<p class="help">{{.Help}}</p>{{end}}
+<table>
+<tr><th>Labels</th><th>Value</th></tr>
+{{range head .Series}}<tr><td>{{range $k, $v := .Labels}}{{$k}}="{{$v}}" {{end}}</td><td>{{.Value}}</td></tr>
+{{end}}</table>
+{{with more .Series}}<p>{{.}} more series in the <a href="?format=json">JSON catalog</a></p>{{end}}
+{{end}}
+</body>
+</html>
+`))
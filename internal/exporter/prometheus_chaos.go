@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"bytes"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/prometheus/common/expfmt"
+)
+
+// malformedLines are inserted into text expositions by malformed scrape errors.
+var malformedLines = []string{
+	`otelbox_malformed{label="unterminated 1`,
+	`otelbox malformed 1`,
+	`otelbox_malformed not_a_number`,
+	`otelbox_malformed{=""} 1`,
+}
+
+// scrapeDelayMiddleware sleeps before answering a scrape. Scrapers giving
+// up before the delay elapses get no response.
+func scrapeDelayMiddleware(next http.Handler, delays *delaySampler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay := delays.sample()
+		slog.Debug("delaying scrape", "delay", delay)
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			next.ServeHTTP(w, r)
+		case <-r.Context().Done():
+			slog.Debug("scrape cancelled during delay", "delay", delay)
+		}
+	})
+}
+
+// scrapeErrorMiddleware fails a configured fraction of scrapes.
+// At most one error is injected per scrape.
+func scrapeErrorMiddleware(next http.Handler, faults []config.ScrapeErrorConfig, rng simulation.RNG) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fault, ok := pickScrapeError(faults, rng)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		slog.Debug("injecting scrape error", "type", fault.Type)
+
+		switch fault.Type {
+		case config.ScrapeErrorStatus:
+			http.Error(w, http.StatusText(fault.Status), fault.Status)
+		case config.ScrapeErrorReset:
+			resetConnection(w)
+		case config.ScrapeErrorTruncate, config.ScrapeErrorMalformed:
+			// Render uncompressed so the body can be modified
+			r = r.Clone(r.Context())
+			r.Header.Del("Accept-Encoding")
+
+			rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			maps.Copy(w.Header(), rec.header)
+			body := rec.body.Bytes()
+
+			if fault.Type == config.ScrapeErrorTruncate {
+				// Announce the full length, send a prefix, then abort
+				w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+				w.WriteHeader(rec.status)
+				w.Write(body[:rng.IntN(max(len(body), 1))])
+				http.NewResponseController(w).Flush()
+				panic(http.ErrAbortHandler)
+			}
+
+			body = corruptExposition(body, rec.header.Get("Content-Type"), rng)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(rec.status)
+			w.Write(body)
+		}
+	})
+}
+
+// pickScrapeError selects the error to inject into a scrape, if any.
+func pickScrapeError(faults []config.ScrapeErrorConfig, rng simulation.RNG) (config.ScrapeErrorConfig, bool) {
+	draw := uniform(rng, 0, 1)
+	for _, fault := range faults {
+		if draw < fault.Probability {
+			return fault, true
+		}
+		draw -= fault.Probability
+	}
+	return config.ScrapeErrorConfig{}, false
+}
+
+// resetConnection closes the client connection with a TCP reset.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		tcp.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// corruptExposition inserts an invalid line into text formats and
+// overwrites bytes of protobuf bodies.
+func corruptExposition(body []byte, contentType string, rng simulation.RNG) []byte {
+	if len(body) == 0 {
+		return []byte(malformedLines[0] + "\n")
+	}
+
+	if strings.HasPrefix(contentType, expfmt.ProtoType) {
+		corrupted := bytes.Clone(body)
+		offset := rng.IntN(len(corrupted))
+		for i := offset; i < min(offset+8, len(corrupted)); i++ {
+			corrupted[i] = 0xff
+		}
+		return corrupted
+	}
+
+	lines := bytes.SplitAfter(body, []byte("\n"))
+	at := rng.IntN(len(lines))
+	line := []byte(malformedLines[rng.IntN(len(malformedLines))] + "\n")
+
+	corrupted := make([]byte, 0, len(body)+len(line))
+	for i, l := range lines {
+		if i == at {
+			corrupted = append(corrupted, line...)
+		}
+		corrupted = append(corrupted, l...)
+	}
+	return corrupted
+}
+
+// bufferedResponse captures a response for modification.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header         { return b.header }
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+func (b *bufferedResponse) WriteHeader(status int)      { b.status = status }
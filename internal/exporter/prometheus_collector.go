@@ -3,31 +3,154 @@ package exporter
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/metric"
-	"github.com/neox5/simv/value"
+	"github.com/neox5/otelbox/internal/simulation"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// seriesChunkSize is the number of series allocated at once per scrape.
+const seriesChunkSize = 1024
+
+// prometheusConsumer reads the series of the main scrape path. Other
+// endpoints of the exporter read as their own consumers, see consumerKey.
+const prometheusConsumer = "prometheus"
+
+// consumerKey returns the consumer reading the series served on path.
+func consumerKey(path string) string {
+	return prometheusConsumer + path
+}
+
+// targetConsumer returns the consumer reading the series of a scrape
+// target on the endpoint read by consumer.
+func targetConsumer(consumer string, target []*dto.LabelPair) string {
+	var b strings.Builder
+	b.WriteString(consumer)
+	for i, lp := range target {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(lp.GetName() + "=" + lp.GetValue())
+	}
+	return b.String()
+}
+
 // metricDescriptor holds metadata for a Prometheus metric.
 type metricDescriptor struct {
-	desc        *prometheus.Desc
-	valueType   prometheus.ValueType
-	value       *value.Value[int]
-	labelValues []string
+	name      string
+	help      string
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     *simulation.ValueWrapper
+	guard     *simulation.Guard
+	sampler   *metric.Sampler
+	labels    []*dto.LabelPair       // Rendered once, shared by every scrape
+	created   *timestamppb.Timestamp // Series creation, kept while its value carries over reloads
+}
+
+// seriesMetric is a series read by one scrape. Written metrics point to
+// its fields and its label pairs are shared, so series are allocated in
+// chunks per scrape instead of several objects per series.
+type seriesMetric struct {
+	descriptor *metricDescriptor
+	value      float64
+	created    *timestamppb.Timestamp // Counter creation, shared with the descriptor (nil: none)
+	timestamp  int64                  // Explicit sample timestamp in ms
+	stamped    bool
+	counter    dto.Counter
+	gauge      dto.Gauge
+}
+
+func (m *seriesMetric) Desc() *prometheus.Desc {
+	return m.descriptor.desc
+}
+
+// Write fills out like a constant metric with the same value and labels.
+func (m *seriesMetric) Write(out *dto.Metric) error {
+	out.Label = m.descriptor.labels
+	if m.descriptor.valueType == prometheus.CounterValue {
+		m.counter.Value = &m.value
+		m.counter.CreatedTimestamp = m.created
+		out.Counter = &m.counter
+	} else {
+		m.gauge.Value = &m.value
+		out.Gauge = &m.gauge
+	}
+	if m.stamped {
+		out.TimestampMs = &m.timestamp
+	}
+	return nil
 }
 
 // collector implements prometheus.Collector to read simv values on scrape.
 type collector struct {
+	mu          sync.RWMutex
 	descriptors []metricDescriptor
+	constLabels prometheus.Labels // Added to every series
+	stamper     *sampleStamper    // Explicit sample timestamps (nil: none)
+	created     bool              // Attach created timestamps to counters
 }
 
 // newCollector creates a collector from metric registry.
-func newCollector(metrics *metric.Registry) *collector {
+func newCollector(metrics *metric.Registry, constLabels map[string]string) *collector {
+	descriptors := buildDescriptors(metrics, constLabels)
+	stampCreated(descriptors, nil)
+
+	return &collector{
+		descriptors: descriptors,
+		constLabels: constLabels,
+	}
+}
+
+// update replaces the collected metrics.
+// Subsequent scrapes serve the new metric set from the same endpoint.
+func (c *collector) update(metrics *metric.Registry) {
+	descriptors := buildDescriptors(metrics, c.constLabels)
+
+	c.mu.Lock()
+	stampCreated(descriptors, c.descriptors)
+	c.descriptors = descriptors
+	c.mu.Unlock()
+}
+
+// stampCreated sets the creation time of descriptors. Series whose value
+// is carried over from previous keep their creation time; new series and
+// series with a fresh value are created now.
+func stampCreated(descriptors, previous []metricDescriptor) {
+	kept := make(map[*simulation.ValueWrapper]*timestamppb.Timestamp, len(previous))
+	for _, d := range previous {
+		kept[d.value] = d.created
+	}
+
+	now := timestamppb.Now() // Shared by every series created now
+	for i := range descriptors {
+		if created, exists := kept[descriptors[i].value]; exists {
+			descriptors[i].created = created
+		} else {
+			descriptors[i].created = now
+		}
+	}
+}
+
+// buildDescriptors creates Prometheus descriptors for all metrics.
+func buildDescriptors(metrics *metric.Registry, constLabels prometheus.Labels) []metricDescriptor {
 	var descriptors []metricDescriptor
 
 	for _, m := range metrics.Metrics() {
+		if !m.ExportedTo(config.ExportProtocolPrometheus) {
+			continue
+		}
+
 		var valueType prometheus.ValueType
 		switch m.Type {
 		case metric.MetricTypeCounter:
@@ -49,16 +172,28 @@ func newCollector(metrics *metric.Registry) *collector {
 			labelValues[i] = m.Attributes[name]
 		}
 
+		desc := prometheus.NewDesc(
+			m.PrometheusName,
+			m.Description,
+			labelNames,
+			constLabels,
+		)
+
+		// Validate labels once; series the client library rejects are never served
+		if _, err := prometheus.NewConstMetric(desc, valueType, 0, labelValues...); err != nil {
+			slog.Warn("skipping prometheus metric", "name", m.PrometheusName, "error", err)
+			continue
+		}
+
 		descriptors = append(descriptors, metricDescriptor{
-			desc: prometheus.NewDesc(
-				m.PrometheusName,
-				m.Description,
-				labelNames,
-				nil, // No constant labels
-			),
-			valueType:   valueType,
-			value:       m.Value,
-			labelValues: labelValues,
+			name:      m.PrometheusName,
+			help:      m.Description,
+			desc:      desc,
+			valueType: valueType,
+			value:     m.Value,
+			guard:     m.Guard,
+			sampler:   m.Sampler,
+			labels:    slices.Clip(prometheus.MakeLabelPairs(desc, labelValues)),
 		})
 
 		// Build label key=value pairs for logging
@@ -75,11 +210,14 @@ func newCollector(metrics *metric.Registry) *collector {
 
 	slog.Info("registered prometheus metrics", "count", len(descriptors))
 
-	return &collector{descriptors: descriptors}
+	return descriptors
 }
 
 // Describe sends metric descriptors to the channel.
 func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	for _, m := range c.descriptors {
 		ch <- m.desc
 	}
@@ -88,21 +226,83 @@ func (c *collector) Describe(ch chan<- *prometheus.Desc) {
 // Collect reads simv values and sends metrics to the channel.
 // This is called on each Prometheus scrape.
 func (c *collector) Collect(ch chan<- prometheus.Metric) {
-	for _, m := range c.descriptors {
-		// Read value from simv (may trigger reset for reset_on_read)
-		val := float64(m.value.Value())
-
-		// Create and send metric with current value and labels
-		metric, err := prometheus.NewConstMetric(
-			m.desc,
-			m.valueType,
-			val,
-			m.labelValues...,
-		)
-		if err != nil {
+	c.collect(ch, prometheusConsumer, nil)
+}
+
+// collect reads the series matching any of the selectors as consumer, so
+// reset_on_read deltas and sparse draws of one endpoint are independent
+// of the others. Series not selected are not read at all. Nil selectors
+// select every series.
+func (c *collector) collect(ch chan<- prometheus.Metric, consumer string, selectors []*config.Selector) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+
+	var chunk []seriesMetric
+	for i := range c.descriptors {
+		m := &c.descriptors[i]
+
+		if selectors != nil && !matchAny(selectors, m.name, m.labels) {
+			continue
+		}
+
+		// Omit sparse series from this scrape without consuming the value
+		if !m.sampler.Emit(consumer) {
+			continue
+		}
+
+		// Omit throttled series likewise
+		if !simulation.AllowPoint() {
+			continue
+		}
+
+		// Read value (resets this consumer's view for reset_on_read)
+		var val float64
+		var ok bool
+		if !m.guard.Do("prometheus collect", func() { val, ok = m.value.Sample(consumer) }) || !ok {
 			continue
 		}
 
-		ch <- metric
+		// Send the series from the current chunk
+		if len(chunk) == cap(chunk) {
+			chunk = make([]seriesMetric, 0, seriesChunkSize)
+		}
+		chunk = chunk[:len(chunk)+1]
+		series := &chunk[len(chunk)-1]
+		series.descriptor = m
+		series.value = val
+		if c.created {
+			series.created = m.created
+		}
+		if c.stamper != nil {
+			series.timestamp = c.stamper.stamp(now).UnixMilli()
+			series.stamped = true
+		}
+
+		ch <- series
 	}
 }
+
+// seriesView serves the series of a collector selected by selectors, read
+// as its own consumer. It describes nothing, as the selected series differ
+// per view, which keeps registering a view cheap.
+type seriesView struct {
+	collector *collector
+	consumer  string
+	selectors []*config.Selector
+}
+
+func (v seriesView) Describe(chan<- *prometheus.Desc) {}
+
+func (v seriesView) Collect(ch chan<- prometheus.Metric) {
+	v.collector.collect(ch, v.consumer, v.selectors)
+}
+
+// view returns a gatherer of the series selected by selectors, read as
+// consumer, together with the selected series of others.
+func (c *collector) view(consumer string, selectors []*config.Selector, others prometheus.Gatherer) prometheus.Gatherer {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(seriesView{collector: c, consumer: consumer, selectors: selectors})
+	return prometheus.Gatherers{registry, selectGatherer(others, selectors)}
+}
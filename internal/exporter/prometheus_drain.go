@@ -0,0 +1,51 @@
+package exporter
+
+import (
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// drainWatcher signals completed scrapes that started after a drain began,
+// which observe the final values.
+type drainWatcher struct {
+	since   atomic.Int64 // Drain start in Unix nanoseconds (0: not draining)
+	scraped chan struct{}
+}
+
+func newDrainWatcher() *drainWatcher {
+	return &drainWatcher{scraped: make(chan struct{}, 1)}
+}
+
+// middleware signals scrapes completed during the drain.
+func (w *drainWatcher) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		start := time.Now().UnixNano()
+		next.ServeHTTP(rw, r)
+		if since := w.since.Load(); since != 0 && start >= since {
+			select {
+			case w.scraped <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+// Drain keeps serving until a scrape started after generation stopped
+// completes, or the window elapses.
+func (e *PrometheusExporter) Drain(window time.Duration) {
+	start := time.Now()
+	e.drain.since.Store(start.UnixNano())
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	select {
+	case <-e.drain.scraped:
+		slog.Info("drained prometheus exporter", "addr", e.addr, "waited", time.Since(start).Round(time.Millisecond))
+	case <-timer.C:
+		slog.Warn("prometheus drain window elapsed without a scrape, final values not scraped",
+			"addr", e.addr, "window", window)
+	}
+}
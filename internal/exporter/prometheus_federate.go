@@ -0,0 +1,78 @@
+package exporter
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/protobuf/proto"
+)
+
+// federateHandler serves the series selected by the match[] parameters in
+// the format of the Prometheus /federate endpoint: a series is included if
+// it matches any selector, and every sample carries a timestamp. Generated
+// series are read as consumer, and only if selected, so federation does
+// not consume reset_on_read deltas of scrapes.
+func federateHandler(c *collector, others prometheus.Gatherer, consumer string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, fmt.Sprintf("error parsing form values: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		selectors := make([]*config.Selector, 0, len(r.Form["match[]"]))
+		for _, s := range r.Form["match[]"] {
+			selector, err := config.ParseSelector(s)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			selectors = append(selectors, selector)
+		}
+
+		families, err := c.view(consumer, selectors, others).Gather()
+		if err != nil {
+			slog.Warn("federation gather failed", "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		format := expfmt.Negotiate(r.Header)
+		w.Header().Set("Content-Type", string(format))
+		enc := expfmt.NewEncoder(w, format)
+
+		now := proto.Int64(time.Now().UnixMilli())
+		for _, family := range families {
+			for _, m := range family.Metric {
+				if m.TimestampMs == nil {
+					m.TimestampMs = now
+				}
+			}
+			if err := enc.Encode(family); err != nil {
+				slog.Debug("federation encode failed", "error", err)
+				return
+			}
+		}
+	})
+}
+
+// matchAny reports whether a series matches any of the selectors.
+func matchAny(selectors []*config.Selector, name string, labels []*dto.LabelPair) bool {
+	values := make(map[string]string, len(labels)+1)
+	values["__name__"] = name
+	for _, lp := range labels {
+		values[lp.GetName()] = lp.GetValue()
+	}
+
+	for _, selector := range selectors {
+		if selector.Match(values) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/virtual"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// resetConfig defines two counters adding 1 per tick, reset on read.
+const resetConfig = `
+metrics:
+  - name: a_total
+    type: counter
+    description: "A"
+    value:
+      source: {type: random_int, clock: {type: periodic, interval: 1s}, min: 1, max: 1}
+      transforms: [accumulate]
+      reset: on_read
+  - name: b_total
+    type: counter
+    description: "B"
+    value:
+      source: {type: random_int, clock: {type: periodic, interval: 1s}, min: 1, max: 1}
+      transforms: [accumulate]
+      reset: on_read
+`
+
+// startResetRun returns a collector over resetConfig in virtual time.
+func startResetRun(t *testing.T) (*virtual.Run, *collector) {
+	t.Helper()
+
+	raw, err := config.ParseBytes([]byte(resetConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Resolve(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := virtual.Start(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(run.Stop)
+
+	return run, newCollector(run.Metrics, nil)
+}
+
+// tick advances run by n ticks.
+func tick(t *testing.T, run *virtual.Run, n int) {
+	t.Helper()
+	for range n {
+		if _, err := run.Tick(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// gatherValues returns the counter values gathered from g by metric name.
+func gatherValues(t *testing.T, g prometheus.Gatherer) map[string]float64 {
+	t.Helper()
+	families, err := g.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return familyValues(families)
+}
+
+func familyValues(families []*dto.MetricFamily) map[string]float64 {
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.Metric {
+			values[family.GetName()] = m.GetCounter().GetValue()
+		}
+	}
+	return values
+}
+
+// federate requests the federation endpoint with match[] selectors.
+func federate(t *testing.T, handler http.Handler, match ...string) map[string]float64 {
+	t.Helper()
+	query := url.Values{"match[]": match}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/federate?"+query.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("federate status = %d: %s", rec.Code, rec.Body)
+	}
+
+	decoder := expfmt.NewDecoder(rec.Body, expfmt.ResponseFormat(rec.Header()))
+	var families []*dto.MetricFamily
+	for {
+		family := &dto.MetricFamily{}
+		if err := decoder.Decode(family); err != nil {
+			break
+		}
+		if !strings.HasPrefix(family.GetName(), "a_") && !strings.HasPrefix(family.GetName(), "b_") {
+			continue
+		}
+		for _, m := range family.Metric {
+			if m.TimestampMs == nil {
+				t.Errorf("federated %s has no timestamp", family.GetName())
+			}
+		}
+		families = append(families, family)
+	}
+	return familyValues(families)
+}
+
+func TestFederateReadsOnlySelectedSeriesAsOwnConsumer(t *testing.T) {
+	run, c := startResetRun(t)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+	handler := federateHandler(c, prometheus.NewRegistry(), consumerKey("/federate"))
+
+	tick(t, run, 3)
+
+	// Federation serves only the selected series, with the full delta
+	got := federate(t, handler, "a_total")
+	if len(got) != 1 || got["a_total"] != 3 {
+		t.Fatalf("federated = %v, want only a_total=3", got)
+	}
+
+	// A second federation read observes only the delta since its first
+	tick(t, run, 2)
+	if got := federate(t, handler, "a_total"); got["a_total"] != 2 {
+		t.Errorf("second federated a_total = %v, want 2", got["a_total"])
+	}
+
+	// The scrape consumer still sees the full deltas of both series,
+	// including the unselected one federation never read
+	scraped := gatherValues(t, registry)
+	if scraped["a_total"] != 5 || scraped["b_total"] != 5 {
+		t.Errorf("scraped = %v, want a_total=5 b_total=5", scraped)
+	}
+}
+
+func TestFederateRejectsInvalidSelector(t *testing.T) {
+	_, c := startResetRun(t)
+	handler := federateHandler(c, prometheus.NewRegistry(), consumerKey("/federate"))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/federate?match[]="+url.QueryEscape("{"), nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
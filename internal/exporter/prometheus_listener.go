@@ -0,0 +1,63 @@
+package exporter
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+)
+
+// systemdListenFD is the first file descriptor passed by systemd socket
+// activation (SD_LISTEN_FDS_START).
+const systemdListenFD = 3
+
+// systemdSocket returns the first socket passed by systemd socket
+// activation, or nil if the process was not socket-activated. The
+// activation variables are cleared so child processes do not inherit them.
+func systemdSocket() *os.File {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil
+	}
+	fds, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil
+	}
+
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	return os.NewFile(systemdListenFD, "systemd-socket")
+}
+
+// listen returns the listener of the exporter: the socket passed by
+// systemd if socket activation is enabled and present, otherwise a new
+// listener on the configured address. The systemd socket is kept so a
+// restarted exporter serves on it again.
+func (e *PrometheusExporter) listen() (net.Listener, error) {
+	if e.socketActivation && e.socket == nil {
+		e.socket = systemdSocket()
+		if e.socket == nil {
+			slog.Warn("no systemd socket passed, listening on configured address", "addr", e.addr)
+		}
+	}
+
+	if e.socket != nil {
+		// FileListener duplicates the descriptor; the socket stays open
+		l, err := net.FileListener(e.socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use systemd socket: %w", err)
+		}
+		return l, nil
+	}
+	return net.Listen("tcp", e.addr)
+}
+
+// Inherit takes over the systemd socket of prev, which this exporter
+// replaces. Prev must have stopped.
+func (e *PrometheusExporter) Inherit(prev *PrometheusExporter) {
+	if e.socketActivation && e.socket == nil {
+		e.socket = prev.socket
+	}
+}
@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// baseUnits are the unit suffixes announced by UNIT lines.
+var baseUnits = []string{"seconds", "bytes", "ratio", "meters", "grams", "celsius", "volts", "amperes", "joules", "hertz"}
+
+// metadataMiddleware rewrites HELP, TYPE, and UNIT lines of text and
+// OpenMetrics expositions. Protobuf responses pass through unchanged.
+func metadataMiddleware(next http.Handler, cfg config.MetadataConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := negotiatedFormat(r.Header)
+		if format == config.ExpositionProtobuf {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Render uncompressed so the body can be modified
+		r = r.Clone(r.Context())
+		r.Header.Del("Accept-Encoding")
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		maps.Copy(w.Header(), rec.header)
+
+		body := rec.body.Bytes()
+		if rec.status == http.StatusOK {
+			body = rewriteMetadata(body, cfg, format == config.ExpositionOpenMetrics)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(rec.status)
+		w.Write(body)
+	})
+}
+
+// exposedFamily is a metric family of a text exposition.
+type exposedFamily struct {
+	name    string
+	help    string // HELP line without newline ("" if absent)
+	typ     string // Declared type ("" if absent)
+	samples [][]byte
+}
+
+// rewriteMetadata re-renders an exposition family by family with the
+// configured metadata.
+func rewriteMetadata(body []byte, cfg config.MetadataConfig, openMetrics bool) []byte {
+	var families []*exposedFamily
+	var trailer [][]byte // # EOF and anything after it
+	current := func(name string) *exposedFamily {
+		if len(families) == 0 || families[len(families)-1].name != name {
+			families = append(families, &exposedFamily{name: name})
+		}
+		return families[len(families)-1]
+	}
+
+	for _, line := range bytes.SplitAfter(body, []byte("\n")) {
+		text := strings.TrimSuffix(string(line), "\n")
+		switch {
+		case len(trailer) > 0 || text == "# EOF":
+			trailer = append(trailer, line)
+		case strings.HasPrefix(text, "# HELP "):
+			name, _, _ := strings.Cut(strings.TrimPrefix(text, "# HELP "), " ")
+			current(name).help = text
+		case strings.HasPrefix(text, "# TYPE "):
+			name, typ, _ := strings.Cut(strings.TrimPrefix(text, "# TYPE "), " ")
+			current(name).typ = typ
+		case text == "":
+		case len(families) == 0:
+			families = append(families, &exposedFamily{})
+			fallthrough
+		default:
+			f := families[len(families)-1]
+			f.samples = append(f.samples, line)
+		}
+	}
+
+	malformed := make(map[config.MalformedMetadata]bool, len(cfg.Malformed))
+	for _, m := range cfg.Malformed {
+		malformed[m] = true
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(body))
+	for _, f := range families {
+		if f.help != "" && !cfg.OmitHelp {
+			help := f.help
+			if malformed[config.MalformedHelpEscape] {
+				help += ` \q`
+			}
+			out.WriteString(help + "\n")
+			if malformed[config.MalformedDuplicateHelp] {
+				out.WriteString("# HELP " + f.name + " Conflicting help of " + f.name + "\n")
+			}
+		}
+
+		typeLines := ""
+		if f.typ != "" && !cfg.OmitType {
+			typ := f.typ
+			if malformed[config.MalformedUnknownType] {
+				typ = "bogus"
+			}
+			typeLines = "# TYPE " + f.name + " " + typ + "\n"
+			if malformed[config.MalformedConflictingType] {
+				typeLines += "# TYPE " + f.name + " " + conflictingType(f.typ) + "\n"
+			}
+		}
+		if !malformed[config.MalformedTypeAfterSamples] || len(f.samples) == 0 {
+			out.WriteString(typeLines)
+			typeLines = ""
+		}
+
+		if openMetrics && f.name != "" {
+			if malformed[config.MalformedUnitMismatch] {
+				out.WriteString("# UNIT " + f.name + " widgets\n")
+			} else if unit := nameUnit(f.name); cfg.Unit && unit != "" {
+				out.WriteString("# UNIT " + f.name + " " + unit + "\n")
+			}
+		}
+
+		for i, sample := range f.samples {
+			out.Write(sample)
+			if i == 0 {
+				out.WriteString(typeLines)
+			}
+		}
+	}
+	for _, line := range trailer {
+		out.Write(line)
+	}
+
+	return out.Bytes()
+}
+
+// nameUnit returns the base unit a family name ends in, if any.
+func nameUnit(name string) string {
+	name = strings.TrimSuffix(name, "_total")
+	if i := slices.IndexFunc(baseUnits, func(unit string) bool { return strings.HasSuffix(name, "_"+unit) }); i >= 0 {
+		return baseUnits[i]
+	}
+	return ""
+}
+
+// conflictingType returns a type differing from typ.
+func conflictingType(typ string) string {
+	if typ == "gauge" {
+		return "counter"
+	}
+	return "gauge"
+}
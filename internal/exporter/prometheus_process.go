@@ -0,0 +1,383 @@
+package exporter
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// processGoVersion is reported by go_info for simulated processes
+	processGoVersion = "go1.23.4"
+
+	// processGCPauseWindow is the number of recent GC pauses kept for quantiles
+	processGCPauseWindow = 64
+
+	// processMaxGCPerAdvance bounds GC cycles simulated in a single advance
+	processMaxGCPerAdvance = 10000
+)
+
+// processGCQuantiles are the quantiles reported by go_gc_duration_seconds
+var processGCQuantiles = []float64{0, 0.25, 0.5, 0.75, 1}
+
+// processCollector exposes emulated process_ and go_ collector metrics for
+// simulated targets. Processes evolve on each scrape from elapsed wall time:
+// the heap grows with allocations and drops on garbage collection, memory
+// follows the heap, and CPU time, fds, goroutines, and threads drift within
+// per-process bounds.
+type processCollector struct {
+	mu           sync.Mutex
+	sim          *simulation.Context
+	targetLabels []string
+	processes    map[string]*simulatedProcess
+	targets      []string // Process keys in exposition order
+
+	cpuSeconds    *prometheus.Desc
+	openFDs       *prometheus.Desc
+	maxFDs        *prometheus.Desc
+	residentBytes *prometheus.Desc
+	virtualBytes  *prometheus.Desc
+	startTime     *prometheus.Desc
+	goroutines    *prometheus.Desc
+	threads       *prometheus.Desc
+	info          *prometheus.Desc
+	gcDuration    *prometheus.Desc
+	allocBytes    *prometheus.Desc
+	allocTotal    *prometheus.Desc
+	heapAlloc     *prometheus.Desc
+	sysBytes      *prometheus.Desc
+	nextGC        *prometheus.Desc
+	lastGC        *prometheus.Desc
+}
+
+// newProcessCollector creates a process collector for the targets found in
+// the metric registry. Processes draw from the random streams of sim.
+func newProcessCollector(cfg config.ProcessMetricsConfig, constLabels map[string]string, sim *simulation.Context, metrics *metric.Registry) *processCollector {
+	labels := cfg.TargetLabels
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, labels, constLabels)
+	}
+
+	c := &processCollector{
+		sim:          sim,
+		targetLabels: labels,
+		processes:    make(map[string]*simulatedProcess),
+
+		cpuSeconds:    desc("process_cpu_seconds_total", "Total user and system CPU time spent in seconds."),
+		openFDs:       desc("process_open_fds", "Number of open file descriptors."),
+		maxFDs:        desc("process_max_fds", "Maximum number of open file descriptors."),
+		residentBytes: desc("process_resident_memory_bytes", "Resident memory size in bytes."),
+		virtualBytes:  desc("process_virtual_memory_bytes", "Virtual memory size in bytes."),
+		startTime:     desc("process_start_time_seconds", "Start time of the process since unix epoch in seconds."),
+		goroutines:    desc("go_goroutines", "Number of goroutines that currently exist."),
+		threads:       desc("go_threads", "Number of OS threads created."),
+		info: prometheus.NewDesc("go_info", "Information about the Go environment.",
+			append(slices.Clone(labels), "version"), constLabels),
+		gcDuration: desc("go_gc_duration_seconds", "A summary of the wall-time pause (stop-the-world) duration in garbage collection cycles."),
+		allocBytes: desc("go_memstats_alloc_bytes", "Number of heap bytes allocated and currently in use."),
+		allocTotal: desc("go_memstats_alloc_bytes_total", "Total number of heap bytes allocated, even if freed."),
+		heapAlloc:  desc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and currently in use."),
+		sysBytes:   desc("go_memstats_sys_bytes", "Number of bytes obtained from system."),
+		nextGC:     desc("go_memstats_next_gc_bytes", "Number of heap bytes when next garbage collection will take place."),
+		lastGC:     desc("go_memstats_last_gc_time_seconds", "Number of seconds since 1970 of last garbage collection."),
+	}
+	c.update(metrics)
+
+	return c
+}
+
+// update sets the simulated targets from the metric registry.
+// Processes of targets that remain keep their state.
+func (c *processCollector) update(metrics *metric.Registry) {
+	targets := make(map[string][]string)
+	if len(c.targetLabels) == 0 {
+		targets[""] = nil
+	} else {
+		for _, m := range metrics.Metrics() {
+			values := make([]string, len(c.targetLabels))
+			complete := true
+			for i, label := range c.targetLabels {
+				values[i], complete = m.Attributes[label]
+				if !complete {
+					break
+				}
+			}
+			if complete {
+				targets[strings.Join(values, "\xff")] = values
+			}
+		}
+		if len(targets) == 0 {
+			slog.Warn("no metric carries all process_metrics target labels, no process metrics exposed",
+				"target_labels", c.targetLabels)
+		}
+	}
+
+	keys := make([]string, 0, len(targets))
+	for key := range targets {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	processes := make(map[string]*simulatedProcess, len(keys))
+	for _, key := range keys {
+		if p, exists := c.processes[key]; exists {
+			processes[key] = p
+			continue
+		}
+		processes[key] = newSimulatedProcess(c.sim, targets[key], now)
+	}
+	c.processes = processes
+	c.targets = keys
+
+	slog.Info("registered simulated processes", "count", len(keys))
+}
+
+// Describe sends process metric descriptors to the channel.
+func (c *processCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.cpuSeconds, c.openFDs, c.maxFDs, c.residentBytes, c.virtualBytes,
+		c.startTime, c.goroutines, c.threads, c.info, c.gcDuration,
+		c.allocBytes, c.allocTotal, c.heapAlloc, c.sysBytes, c.nextGC, c.lastGC,
+	} {
+		ch <- desc
+	}
+}
+
+// Collect advances all simulated processes to now and sends their metrics.
+func (c *processCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range c.targets {
+		p := c.processes[key]
+		p.advance(now)
+
+		gauge := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, p.labels...)
+		}
+		counter := func(desc *prometheus.Desc, value float64) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, p.labels...)
+		}
+
+		counter(c.cpuSeconds, p.cpuSeconds)
+		gauge(c.openFDs, math.Round(p.fds))
+		gauge(c.maxFDs, p.maxFDs)
+		gauge(c.residentBytes, math.Round(p.residentBytes()))
+		gauge(c.virtualBytes, math.Round(p.virtualBase+p.sysBytes()))
+		gauge(c.startTime, float64(p.start.Unix()))
+		gauge(c.goroutines, math.Round(p.goroutines))
+		gauge(c.threads, p.threads)
+		ch <- prometheus.MustNewConstMetric(c.info, prometheus.GaugeValue, 1,
+			append(slices.Clone(p.labels), processGoVersion)...)
+		ch <- prometheus.MustNewConstSummary(c.gcDuration, p.gcCount, p.gcPauseSum,
+			p.gcPauseQuantiles(), p.labels...)
+		gauge(c.allocBytes, math.Round(p.heapBytes))
+		counter(c.allocTotal, math.Round(p.allocTotal))
+		gauge(c.heapAlloc, math.Round(p.heapBytes))
+		gauge(c.sysBytes, math.Round(p.sysBytes()))
+		gauge(c.nextGC, math.Round(p.nextGC))
+		gauge(c.lastGC, float64(p.lastGC.UnixNano())/1e9)
+	}
+}
+
+// simulatedProcess holds the evolving state of one emulated Go process.
+// Its parameters derive from the seed and target labels, so the same target
+// looks alike across runs.
+type simulatedProcess struct {
+	labels []string
+	rng    simulation.RNG
+	start  time.Time
+	last   time.Time
+
+	// CPU
+	cpuSeconds  float64
+	utilization float64 // Mean CPU cores in use
+
+	// File descriptors
+	fds     float64
+	fdsBase float64
+	maxFDs  float64
+
+	// Scheduling
+	goroutines     float64
+	goroutinesBase float64
+	threads        float64
+
+	// Memory
+	liveBytes     float64 // Heap retained after the last GC
+	liveBase      float64
+	heapBytes     float64
+	nextGC        float64
+	allocRate     float64 // Bytes allocated per second
+	allocTotal    float64
+	runtimeBytes  float64 // Non-heap memory obtained from the system
+	residentRatio float64 // Share of system memory resident
+	virtualBase   float64 // Reserved address space beyond system memory
+
+	// Garbage collection
+	gcCount    uint64
+	gcPauseSum float64
+	gcPauses   []float64 // Recent pauses, oldest first
+	lastGC     time.Time
+}
+
+// newSimulatedProcess creates a process that has been running for a while
+// before now.
+func newSimulatedProcess(sim *simulation.Context, labels []string, now time.Time) *simulatedProcess {
+	rng := sim.NewDerivedRand("process:" + strings.Join(labels, "\xff"))
+	p := &simulatedProcess{labels: labels, rng: rng}
+
+	uptime := time.Duration(uniform(rng, 0.5, 72) * float64(time.Hour))
+	p.start = now.Add(-uptime).Truncate(10 * time.Millisecond)
+	p.last = now
+	p.lastGC = now
+
+	p.utilization = uniform(rng, 0.02, 0.4)
+	p.cpuSeconds = uptime.Seconds() * p.utilization
+
+	p.fdsBase = math.Round(uniform(rng, 15, 200))
+	p.fds = p.fdsBase
+	p.maxFDs = 1048576
+	if rng.IntN(3) == 0 {
+		p.maxFDs = 65536
+	}
+
+	p.goroutinesBase = math.Round(uniform(rng, 20, 400))
+	p.goroutines = p.goroutinesBase
+	p.threads = math.Round(uniform(rng, 8, 24))
+
+	p.liveBase = uniform(rng, 8, 200) * (1 << 20)
+	p.liveBytes = p.liveBase
+	p.nextGC = 2 * p.liveBytes
+	p.heapBytes = uniform(rng, p.liveBytes, p.nextGC)
+	p.allocRate = p.liveBase * uniform(rng, 0.05, 0.5)
+	p.runtimeBytes = uniform(rng, 8, 24) * (1 << 20)
+	p.residentRatio = uniform(rng, 0.8, 0.95)
+	p.virtualBase = uniform(rng, 0.8, 2.5) * (1 << 30)
+
+	// History since start, with pauses sampled from the current distribution
+	p.allocTotal = uptime.Seconds()*p.allocRate + p.heapBytes
+	p.gcCount = uint64(uptime.Seconds() * p.allocRate / p.liveBytes)
+	for range min(p.gcCount, processGCPauseWindow) {
+		p.recordPause(p.samplePause())
+	}
+	if len(p.gcPauses) > 0 {
+		mean := p.gcPauseSum / float64(len(p.gcPauses))
+		p.gcPauseSum = mean * float64(p.gcCount)
+	}
+
+	return p
+}
+
+// advance evolves the process to now.
+func (p *simulatedProcess) advance(now time.Time) {
+	dt := now.Sub(p.last).Seconds()
+	if dt <= 0 {
+		return
+	}
+	p.last = now
+
+	p.cpuSeconds += dt * p.utilization * uniform(p.rng, 0.6, 1.4)
+
+	// Bounded random walks scaled by elapsed time
+	walk := math.Sqrt(dt)
+	p.fds = clamp(p.fds+walk*uniform(p.rng, -1.5, 1.5), p.fdsBase*0.5, p.fdsBase*2)
+	p.goroutines = clamp(p.goroutines+walk*uniform(p.rng, -3, 3), p.goroutinesBase*0.5, p.goroutinesBase*2)
+
+	// Go rarely releases threads; spawn more as goroutines peak
+	if wanted := math.Round(6 + p.goroutines/40); wanted > p.threads {
+		p.threads = wanted
+	}
+
+	// Allocate and collect
+	allocated := dt * p.allocRate * uniform(p.rng, 0.5, 1.5)
+	p.allocTotal += allocated
+	p.heapBytes += allocated
+
+	for i := 0; p.heapBytes >= p.nextGC && i < processMaxGCPerAdvance; i++ {
+		overshoot := p.heapBytes - p.nextGC
+		p.liveBytes = clamp(p.liveBytes*uniform(p.rng, 0.95, 1.05), p.liveBase*0.8, p.liveBase*1.5)
+		p.heapBytes = p.liveBytes + overshoot
+		p.nextGC = 2 * p.liveBytes
+		p.gcCount++
+		p.recordPause(p.samplePause())
+		p.lastGC = now
+	}
+	if p.heapBytes >= p.nextGC {
+		p.heapBytes = p.liveBytes
+	}
+}
+
+// samplePause draws a GC pause, mostly tens of microseconds with rare
+// millisecond outliers.
+func (p *simulatedProcess) samplePause() float64 {
+	pause := uniform(p.rng, 20e-6, 300e-6)
+	if p.rng.IntN(50) == 0 {
+		pause += uniform(p.rng, 1e-3, 5e-3)
+	}
+	return pause
+}
+
+// recordPause adds a GC pause to the summary.
+func (p *simulatedProcess) recordPause(pause float64) {
+	p.gcPauseSum += pause
+	p.gcPauses = append(p.gcPauses, pause)
+	if len(p.gcPauses) > processGCPauseWindow {
+		p.gcPauses = p.gcPauses[1:]
+	}
+}
+
+// gcPauseQuantiles computes the reported quantiles over recent pauses.
+func (p *simulatedProcess) gcPauseQuantiles() map[float64]float64 {
+	quantiles := make(map[float64]float64, len(processGCQuantiles))
+	if len(p.gcPauses) == 0 {
+		for _, q := range processGCQuantiles {
+			quantiles[q] = 0
+		}
+		return quantiles
+	}
+
+	sorted := slices.Clone(p.gcPauses)
+	slices.Sort(sorted)
+	for _, q := range processGCQuantiles {
+		quantiles[q] = sorted[int(q*float64(len(sorted)-1))]
+	}
+	return quantiles
+}
+
+// sysBytes returns memory obtained from the system: the heap up to the
+// next GC target plus goroutine stacks and runtime overhead.
+func (p *simulatedProcess) sysBytes() float64 {
+	return p.nextGC*1.1 + p.goroutines*8192 + p.runtimeBytes
+}
+
+// residentBytes returns the resident share of system memory.
+func (p *simulatedProcess) residentBytes() float64 {
+	return p.sysBytes() * p.residentRatio
+}
+
+// uniform draws a float in [lo, hi) from rng.
+func uniform(rng simulation.RNG, lo, hi float64) float64 {
+	const resolution = 1 << 30
+	return lo + (hi-lo)*float64(rng.IntN(resolution))/resolution
+}
+
+// clamp bounds v to [lo, hi].
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
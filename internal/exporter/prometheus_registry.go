@@ -1,17 +1,169 @@
 package exporter
 
 import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/monitor"
+	"github.com/neox5/otelbox/internal/simulation"
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
 )
 
 // createPrometheusRegistry creates and populates a Prometheus registry.
-func createPrometheusRegistry(metrics *metric.Registry) *prometheus.Registry {
+// The returned collector allows replacing the metric set at runtime.
+func createPrometheusRegistry(metrics *metric.Registry, constLabels map[string]string) (*prometheus.Registry, *collector) {
 	promRegistry := prometheus.NewRegistry()
 
 	// Create and register collector
-	c := newCollector(metrics)
+	c := newCollector(metrics, constLabels)
 	promRegistry.MustRegister(c)
 
+	return promRegistry, c
+}
+
+// NewPrometheusGatherer returns a gatherer for metrics without serving HTTP.
+// Gathered output matches what the Prometheus exporter serves.
+func NewPrometheusGatherer(metrics *metric.Registry, constLabels map[string]string) prometheus.Gatherer {
+	promRegistry, _ := createPrometheusRegistry(metrics, constLabels)
 	return promRegistry
 }
+
+// gathererCollector re-exposes the series of a gatherer. Collectors other
+// than the generated series are registered in a registry of their own and
+// served through it, so endpoints reading the generated series as another
+// consumer can serve them too.
+type gathererCollector struct {
+	gatherer    prometheus.Gatherer
+	constLabels map[string]string // Added to series without a label of the same name
+}
+
+// Describe sends nothing: the gathered series are unchecked.
+func (c gathererCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect gathers the series and sends them with the const labels.
+func (c gathererCollector) Collect(ch chan<- prometheus.Metric) {
+	families, err := c.gatherer.Gather()
+	if err != nil {
+		slog.Debug("prometheus gather failed", "error", err)
+	}
+	for _, family := range families {
+		desc := prometheus.NewDesc(family.GetName(), family.GetHelp(), nil, nil)
+		for _, m := range family.Metric {
+			ch <- &upstreamMetric{desc: desc, metric: m, labels: withConstLabels(m.Label, c.constLabels)}
+		}
+	}
+}
+
+// withConstLabels returns labels extended by the const labels they do not
+// already have, sorted by name.
+func withConstLabels(labels []*dto.LabelPair, constLabels map[string]string) []*dto.LabelPair {
+	if len(constLabels) == 0 {
+		return labels
+	}
+	result := slices.Clone(labels)
+	for name, value := range constLabels {
+		if !slices.ContainsFunc(labels, func(l *dto.LabelPair) bool { return l.GetName() == name }) {
+			result = append(result, &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)})
+		}
+	}
+	slices.SortFunc(result, func(a, b *dto.LabelPair) int { return strings.Compare(a.GetName(), b.GetName()) })
+	return result
+}
+
+// scrapeIntervalBuckets cover common scrape intervals in seconds
+var scrapeIntervalBuckets = []float64{0.5, 1, 2, 5, 10, 15, 20, 30, 45, 60, 120, 300}
+
+// registerPrometheusInternalMetrics registers otelbox self-monitoring metrics.
+// Returns the scrape inter-arrival histogram observed by the HTTP server.
+func registerPrometheusInternalMetrics(promRegistry *prometheus.Registry, cfg config.InternalMetricsConfig) *prometheus.HistogramVec {
+	scrapeIntervals := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    internalMetricName(cfg, config.NamingFormatUnderscore, "scrape", "interval", "seconds"),
+			Help:    "Time between consecutive scrapes of an endpoint",
+			Buckets: scrapeIntervalBuckets,
+		},
+		[]string{"path"},
+	)
+	promRegistry.MustRegister(scrapeIntervals)
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "panics", "total"),
+			Help: "Number of panics recovered in series generation and export",
+		},
+		func() float64 { return float64(simulation.RecoveredPanics()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "exporter", "restarts", "total"),
+			Help: "Number of exporter restarts after failures",
+		},
+		func() float64 { return float64(Restarts()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "throttled", "observations", "total"),
+			Help: "Number of source updates dropped by the throttle",
+		},
+		func() float64 { return float64(simulation.ThrottledObservations()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "throttled", "points", "total"),
+			Help: "Number of data points dropped by the throttle",
+		},
+		func() float64 { return float64(simulation.ThrottledPoints()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "generated", "values", "total"),
+			Help: "Number of values generated by all sources",
+		},
+		func() float64 { return float64(simulation.GeneratedValues()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "dropped", "observations", "total"),
+			Help: "Number of source updates skipped because their series was disabled after a panic",
+		},
+		func() float64 { return float64(simulation.DroppedObservations()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "shed", "observations", "total"),
+			Help: "Number of source updates skipped by load shedding",
+		},
+		func() float64 { return float64(simulation.ShedObservations()) },
+	))
+
+	promRegistry.MustRegister(prometheus.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: internalMetricName(cfg, config.NamingFormatUnderscore, "gc", "cycles", "total"),
+			Help: "Number of completed garbage collection cycles, sampled by the resource monitor",
+		},
+		func() float64 { return float64(monitor.Current().NumGC) },
+	))
+
+	for _, g := range internalGauges(cfg, config.NamingFormatUnderscore) {
+		promRegistry.MustRegister(prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name: g.name,
+				Help: g.description,
+			},
+			g.value,
+		))
+	}
+
+	return scrapeIntervals
+}
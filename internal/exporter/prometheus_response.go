@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/prometheus/common/expfmt"
+)
+
+// paddingLine is repeated to pad text expositions to their minimum size.
+const paddingLine = "# otelbox padding ................................................\n"
+
+// responseMiddleware pads, truncates, and compresses scrape responses as
+// configured. The body is rendered uncompressed and compressed afterwards,
+// so the size limits apply to the exposition itself.
+func responseMiddleware(next http.Handler, cfg config.ResponseConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		compress := acceptsGzip(r.Header)
+		switch cfg.Compression {
+		case config.ResponseCompressionGzip:
+			compress = true
+		case config.ResponseCompressionNone:
+			compress = false
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Del("Accept-Encoding")
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		maps.Copy(w.Header(), rec.header)
+
+		body := rec.body.Bytes()
+		if rec.status == http.StatusOK {
+			if int(cfg.MinSize) > len(body) && expfmt.ResponseFormat(rec.header).FormatType() == expfmt.TypeTextPlain {
+				body = padExposition(body, int(cfg.MinSize))
+			}
+			if cfg.MaxSize > 0 && len(body) > int(cfg.MaxSize) {
+				body = body[:cfg.MaxSize]
+			}
+		}
+
+		if compress {
+			var buf bytes.Buffer
+			zw := gzip.NewWriter(&buf)
+			zw.Write(body)
+			zw.Close()
+			body = buf.Bytes()
+			w.Header().Set("Content-Encoding", "gzip")
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(rec.status)
+		w.Write(body)
+	})
+}
+
+// padExposition appends comment lines to a text exposition until it is
+// size bytes long. The last line is shortened to hit the size exactly.
+func padExposition(body []byte, size int) []byte {
+	padded := make([]byte, len(body), size)
+	copy(padded, body)
+	for len(padded) < size {
+		line := paddingLine
+		if rest := size - len(padded); rest < len(line) {
+			// Keep the line a comment: "#" plus filler, ending in a newline
+			line = strings.Repeat(".", rest-1) + "\n"
+			if rest > 1 {
+				line = "#" + line[1:]
+			}
+		}
+		padded = append(padded, line...)
+	}
+	return padded
+}
+
+// acceptsGzip reports whether the request accepts gzip-encoded responses.
+func acceptsGzip(header http.Header) bool {
+	for entry := range strings.SplitSeq(header.Get("Accept-Encoding"), ",") {
+		encoding, params, _ := strings.Cut(entry, ";")
+		if strings.TrimSpace(encoding) != "gzip" {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
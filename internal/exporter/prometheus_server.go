@@ -1,47 +1,87 @@
 package exporter
 
 import (
+	"crypto/subtle"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 )
 
 // createHTTPServer creates an HTTP server for Prometheus metrics.
 func createHTTPServer(
 	addr string,
-	path string,
+	cfg *config.PrometheusExportConfig,
+	sim *simulation.Context,
+	chaos config.ChaosConfig,
 	promRegistry *prometheus.Registry,
+	others *prometheus.Registry,
+	internal prometheus.Registerer,
+	metrics *collector,
 	internalMetricsEnabled bool,
+	scrapeIntervals *prometheus.HistogramVec,
+	drain *drainWatcher,
 ) *http.Server {
 	mux := http.NewServeMux()
 
-	// Create base handler
-	baseHandler := promhttp.HandlerFor(
-		promRegistry,
-		promhttp.HandlerOpts{
-			EnableOpenMetrics: true,
-		},
-	)
-
-	// Conditionally wrap with instrumentation
-	var handler http.Handler
 	if internalMetricsEnabled {
-		handler = promhttp.InstrumentMetricHandler(promRegistry, baseHandler)
 		slog.Info("enabled prometheus internal metrics",
 			"metrics", []string{
 				"promhttp_metric_handler_requests_total",
 				"promhttp_metric_handler_requests_in_flight",
 			})
-	} else {
-		handler = baseHandler
 	}
 
-	// Wrap with debug logging
-	handler = loggingMiddleware(handler)
+	targets := func(target []*dto.LabelPair) prometheus.Gatherer {
+		return metrics.view(targetConsumer(prometheusConsumer, target), nil, others)
+	}
+	mux.Handle(cfg.Path, scrapeHandler(cfg.Path, addr, promRegistry, targets, cfg, sim, chaos, internal, internalMetricsEnabled, scrapeIntervals, drain))
+
+	// Serve subsets of the series on additional paths, keyed apart from the
+	// main path so their chaos draws differ. Each path reads the series as
+	// its own consumer and reads only the series it selects.
+	for _, p := range cfg.Paths {
+		pathChaos := chaos
+		if p.Chaos != nil {
+			pathChaos = *p.Chaos
+		}
+		consumer, selectors := consumerKey(p.Path), parseSelectors(p.Match)
+		gatherer := metrics.view(consumer, selectors, others)
+		targets := func(target []*dto.LabelPair) prometheus.Gatherer {
+			return metrics.view(targetConsumer(consumer, target), selectors, others)
+		}
+		mux.Handle(p.Path, scrapeHandler(p.Path, addr+p.Path, gatherer, targets, cfg, sim, pathChaos, internal, internalMetricsEnabled, scrapeIntervals, drain))
+		slog.Info("enabled prometheus scrape path", "path", p.Path, "match", p.Match)
+	}
 
-	mux.Handle(path, handler)
+	// Serve selected series like the Prometheus /federate endpoint
+	if cfg.Federation.Enabled {
+		federate := federateHandler(metrics, others, consumerKey(cfg.Federation.Path))
+		if cfg.Auth != nil {
+			federate = authMiddleware(federate, cfg.Auth)
+		}
+		mux.Handle(cfg.Federation.Path, loggingMiddleware(federate))
+		slog.Info("enabled prometheus federation", "path", cfg.Federation.Path)
+	}
+
+	// List the exposed metrics for browsers and discovery tooling
+	rootServed := cfg.Path == "/" || slices.ContainsFunc(cfg.Paths, func(p config.ScrapePathConfig) bool { return p.Path == "/" })
+	if cfg.LandingPage && !rootServed {
+		catalog := catalogHandler(metrics, cfg.Path)
+		if cfg.Auth != nil {
+			catalog = authMiddleware(catalog, cfg.Auth)
+		}
+		mux.Handle("GET /{$}", loggingMiddleware(catalog))
+	}
 
 	return &http.Server{
 		Addr:    addr,
@@ -49,6 +89,123 @@ func createHTTPServer(
 	}
 }
 
+// scrapeHandler builds the handler of a scrape path serving gatherer with
+// the given chaos. Scrapes of a target configured by target_params are
+// served from the gatherer targets returns for it. Random draws of chaos
+// are keyed by key.
+func scrapeHandler(
+	path string,
+	key string,
+	gatherer prometheus.Gatherer,
+	targets func(target []*dto.LabelPair) prometheus.Gatherer,
+	cfg *config.PrometheusExportConfig,
+	sim *simulation.Context,
+	chaos config.ChaosConfig,
+	instrumentation prometheus.Registerer,
+	internalMetricsEnabled bool,
+	scrapeIntervals *prometheus.HistogramVec,
+	drain *drainWatcher,
+) http.Handler {
+	// Create base handler, labeling series per target when configured
+	opts := promhttp.HandlerOpts{
+		EnableOpenMetrics:                   true,
+		EnableOpenMetricsTextCreatedSamples: cfg.Metadata.Created,
+	}
+	violations := newViolationInjector(chaos.ProtocolViolations, sim, "chaos/protocol_violations/"+key)
+	var baseHandler http.Handler
+	if len(cfg.TargetParams) > 0 {
+		baseHandler = targetHandler(gatherer, targets, cfg.TargetParams, opts, violations)
+	} else {
+		baseHandler = promhttp.HandlerFor(violations.wrap(gatherer), opts)
+	}
+
+	// Conditionally wrap with instrumentation
+	var handler http.Handler
+	if internalMetricsEnabled {
+		handler = promhttp.InstrumentMetricHandler(instrumentation, baseHandler)
+	} else {
+		handler = baseHandler
+	}
+
+	// Rewrite metadata of text expositions
+	if cfg.Metadata.Rewrites() {
+		handler = metadataMiddleware(handler, cfg.Metadata)
+	}
+
+	// Restrict negotiable exposition formats
+	if restricted(cfg.Exposition) {
+		handler = expositionMiddleware(handler, cfg.Exposition)
+	}
+
+	// Control compression and size of responses
+	if cfg.Response.Modifies() {
+		handler = responseMiddleware(handler, cfg.Response)
+	}
+
+	// Inject scrape failures and latency
+	if len(chaos.ScrapeErrors) > 0 {
+		handler = scrapeErrorMiddleware(handler, chaos.ScrapeErrors, newLockedRNG(sim, "chaos/scrape_errors/"+key))
+	}
+	if chaos.ScrapeDelay != nil {
+		handler = scrapeDelayMiddleware(handler, newDelaySampler(*chaos.ScrapeDelay, sim, "chaos/scrape_delay/"+key))
+	}
+
+	// Track time between scrapes
+	if scrapeIntervals != nil {
+		handler = scrapeIntervalMiddleware(handler, scrapeIntervals.WithLabelValues(path))
+	}
+
+	// Signal final scrapes while draining
+	handler = drain.middleware(handler)
+
+	// Reject unauthenticated scrapes before they count as scrapes
+	if cfg.Auth != nil {
+		handler = authMiddleware(handler, cfg.Auth)
+	}
+
+	// Wrap with debug logging
+	return loggingMiddleware(handler)
+}
+
+// parseSelectors parses series selectors validated with the configuration.
+// Returns nil without selectors.
+func parseSelectors(match []string) []*config.Selector {
+	if len(match) == 0 {
+		return nil
+	}
+	selectors := make([]*config.Selector, len(match))
+	for i, s := range match {
+		selectors[i], _ = config.ParseSelector(s)
+	}
+	return selectors
+}
+
+// selectGatherer returns the series of gatherer matching any of the
+// selectors, or gatherer itself with nil selectors.
+func selectGatherer(gatherer prometheus.Gatherer, selectors []*config.Selector) prometheus.Gatherer {
+	if selectors == nil {
+		return gatherer
+	}
+
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := gatherer.Gather()
+		selected := families[:0]
+		for _, family := range families {
+			metrics := family.Metric[:0]
+			for _, m := range family.Metric {
+				if matchAny(selectors, family.GetName(), m.Label) {
+					metrics = append(metrics, m)
+				}
+			}
+			if len(metrics) > 0 {
+				family.Metric = metrics
+				selected = append(selected, family)
+			}
+		}
+		return selected, err
+	})
+}
+
 // loggingMiddleware logs scrape requests when debug logging is enabled
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -56,3 +213,166 @@ func loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// scrapeIntervalMiddleware observes the time since the previous scrape of
+// an endpoint. Misconfigured intervals and competing scrapers show up as
+// unexpected buckets.
+func scrapeIntervalMiddleware(next http.Handler, observer prometheus.Observer) http.Handler {
+	var mu sync.Mutex
+	var last time.Time
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+
+		mu.Lock()
+		if !last.IsZero() {
+			observer.Observe(now.Sub(last).Seconds())
+		}
+		last = now
+		mu.Unlock()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// expositionAccept maps exposition formats to the Accept value selecting them.
+var expositionAccept = map[config.ExpositionFormat]string{
+	config.ExpositionText:        string(expfmt.NewFormat(expfmt.TypeTextPlain)),
+	config.ExpositionOpenMetrics: string(expfmt.NewFormat(expfmt.TypeOpenMetrics)),
+	config.ExpositionProtobuf:    string(expfmt.NewFormat(expfmt.TypeProtoDelim)),
+}
+
+// restricted reports whether negotiation differs from the promhttp default.
+func restricted(cfg config.ExpositionConfig) bool {
+	return cfg.Force != "" ||
+		(len(cfg.Formats) > 0 && len(cfg.Formats) < len(config.DefaultExpositionFormats))
+}
+
+// expositionMiddleware rewrites the Accept header so promhttp negotiates
+// only the configured formats. Disallowed formats fall back to the first
+// configured format; a forced format ignores the request entirely.
+func expositionMiddleware(next http.Handler, cfg config.ExpositionConfig) http.Handler {
+	allowed := make(map[config.ExpositionFormat]bool, len(cfg.Formats))
+	for _, f := range cfg.Formats {
+		allowed[f] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.Clone(r.Context())
+
+		if cfg.Force != "" {
+			r.Header.Set("Accept", expositionAccept[cfg.Force])
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Drop disallowed media types, keeping their order and weights
+		var kept []string
+		for entry := range strings.SplitSeq(r.Header.Get("Accept"), ",") {
+			if f, known := mediaTypeFormat(entry); !known || allowed[f] {
+				kept = append(kept, entry)
+			}
+		}
+		r.Header.Set("Accept", strings.Join(kept, ","))
+
+		// Fall back when negotiation would still pick a disallowed format
+		if !allowed[negotiatedFormat(r.Header)] {
+			r.Header.Set("Accept", expositionAccept[cfg.Formats[0]])
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// mediaTypeFormat returns the exposition format an Accept entry selects.
+func mediaTypeFormat(entry string) (config.ExpositionFormat, bool) {
+	mediaType, _, _ := strings.Cut(entry, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "text/plain":
+		return config.ExpositionText, true
+	case expfmt.OpenMetricsType:
+		return config.ExpositionOpenMetrics, true
+	case expfmt.ProtoType:
+		return config.ExpositionProtobuf, true
+	default:
+		return "", false
+	}
+}
+
+// negotiatedFormat returns the format promhttp serves for header.
+func negotiatedFormat(header http.Header) config.ExpositionFormat {
+	switch expfmt.NegotiateIncludingOpenMetrics(header).FormatType() {
+	case expfmt.TypeOpenMetrics:
+		return config.ExpositionOpenMetrics
+	case expfmt.TypeProtoDelim, expfmt.TypeProtoText, expfmt.TypeProtoCompact:
+		return config.ExpositionProtobuf
+	default:
+		return config.ExpositionText
+	}
+}
+
+// authMiddleware requires basic or bearer credentials on every request.
+// Secret files are re-read per request so rotated credentials apply
+// immediately.
+func authMiddleware(next http.Handler, auth *config.AuthConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var ok bool
+		var challenge string
+		var err error
+
+		switch {
+		case auth.Basic != nil:
+			challenge = `Basic realm="otelbox"`
+			ok, err = checkBasicAuth(r, auth.Basic)
+		case auth.Bearer != nil:
+			challenge = "Bearer"
+			ok, err = checkBearerAuth(r, auth.Bearer)
+		}
+
+		if err != nil {
+			slog.Warn("prometheus auth failed", "error", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			slog.Debug("prometheus scrape unauthorized", "remote", r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", challenge)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkBasicAuth reports whether the request carries the configured
+// username and password.
+func checkBasicAuth(r *http.Request, cfg *config.BasicAuthConfig) (bool, error) {
+	username, password, present := r.BasicAuth()
+	if !present {
+		return false, nil
+	}
+	expected, err := secret(cfg.Password, cfg.PasswordFile)
+	if err != nil {
+		return false, err
+	}
+	return secureEqual(username, cfg.Username) && secureEqual(password, expected), nil
+}
+
+// checkBearerAuth reports whether the request carries the configured token.
+func checkBearerAuth(r *http.Request, cfg *config.BearerAuthConfig) (bool, error) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return false, nil
+	}
+	expected, err := secret(cfg.Token, cfg.TokenFile)
+	if err != nil {
+		return false, err
+	}
+	return secureEqual(token, expected), nil
+}
+
+// secureEqual compares credentials in constant time.
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,65 @@
+package exporter
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// targetHandler serves each scrape with the configured query parameters
+// added as labels to every series, so one endpoint backs many targets.
+// Each target is served from its own gatherer, so reset_on_read deltas
+// and sparse draws of one target do not change what another observes.
+// Scrapes without any target parameter are served unchanged. Violations
+// are injected after labeling so they survive label sorting.
+func targetHandler(gatherer prometheus.Gatherer, targets func([]*dto.LabelPair) prometheus.Gatherer, params []string, opts promhttp.HandlerOpts, violations *violationInjector) http.Handler {
+	base := promhttp.HandlerFor(violations.wrap(gatherer), opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		labels := targetLabels(r, params)
+		if len(labels) == 0 {
+			base.ServeHTTP(w, r)
+			return
+		}
+		slog.Debug("prometheus target scrape", "labels", labels)
+
+		promhttp.HandlerFor(violations.wrap(labeledGatherer(targets(labels), labels)), opts).ServeHTTP(w, r)
+	})
+}
+
+// targetLabels returns the label pairs of the target parameters present in
+// the request query.
+func targetLabels(r *http.Request, params []string) []*dto.LabelPair {
+	query := r.URL.Query()
+
+	var labels []*dto.LabelPair
+	for _, param := range params {
+		if value := query.Get(param); value != "" {
+			labels = append(labels, &dto.LabelPair{Name: proto.String(param), Value: proto.String(value)})
+		}
+	}
+	return labels
+}
+
+// labeledGatherer adds labels to every gathered series, keeping label pairs
+// sorted by name as the exposition formats expect.
+func labeledGatherer(gatherer prometheus.Gatherer, labels []*dto.LabelPair) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := gatherer.Gather()
+		for _, family := range families {
+			for _, m := range family.Metric {
+				m.Label = append(m.Label, labels...)
+				slices.SortFunc(m.Label, func(a, b *dto.LabelPair) int {
+					return strings.Compare(a.GetName(), b.GetName())
+				})
+			}
+		}
+		return families, err
+	})
+}
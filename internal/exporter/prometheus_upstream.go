@@ -0,0 +1,169 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// upstreamCollector scrapes a real metrics endpoint and re-exposes its
+// series once per replica. Replicas differ only in the replica label, so a
+// single upstream appears as a fleet of identical targets. Scrapes are
+// cached for the configured interval; failed scrapes re-expose the last
+// successful result.
+type upstreamCollector struct {
+	cfg         config.UpstreamConfig
+	constLabels map[string]string
+	client      *http.Client
+
+	mu        sync.Mutex
+	fetched   time.Time
+	families  []*dto.MetricFamily
+	descs     map[string]*prometheus.Desc // Family name -> descriptor
+	lastError error
+}
+
+// newUpstreamCollector creates a collector for the upstream endpoint.
+func newUpstreamCollector(cfg config.UpstreamConfig, constLabels map[string]string) *upstreamCollector {
+	return &upstreamCollector{
+		cfg:         cfg,
+		constLabels: constLabels,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		descs:       make(map[string]*prometheus.Desc),
+	}
+}
+
+// Describe sends nothing: upstream series are unknown until scraped, which
+// makes this an unchecked collector.
+func (c *upstreamCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect re-exposes the upstream series, scraping the upstream first if
+// the cached result is older than the interval.
+func (c *upstreamCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	if time.Since(c.fetched) >= c.cfg.Interval {
+		c.refresh()
+	}
+	families := c.families
+	descs := c.descs
+	c.mu.Unlock()
+
+	for _, family := range families {
+		desc := descs[family.GetName()]
+		for _, m := range family.Metric {
+			for replica := range c.cfg.Replicas {
+				ch <- &upstreamMetric{desc: desc, metric: m, labels: c.labels(m, replica)}
+			}
+		}
+	}
+}
+
+// refresh scrapes the upstream and replaces the cached families on success.
+// Callers must hold c.mu.
+func (c *upstreamCollector) refresh() {
+	c.fetched = time.Now()
+
+	families, err := c.scrape()
+	if err != nil {
+		if c.lastError == nil {
+			slog.Warn("upstream scrape failed, serving last result", "url", c.cfg.URL, "error", err)
+		}
+		c.lastError = err
+		return
+	}
+	if c.lastError != nil {
+		slog.Info("upstream scrape recovered", "url", c.cfg.URL)
+		c.lastError = nil
+	}
+
+	c.families = families
+	c.descs = make(map[string]*prometheus.Desc, len(families))
+	for _, family := range families {
+		c.descs[family.GetName()] = prometheus.NewDesc(family.GetName(), family.GetHelp(), nil, nil)
+	}
+}
+
+// scrape fetches and parses the upstream exposition in text format.
+func (c *upstreamCollector) scrape() ([]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.cfg.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	parsed, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+
+	families := make([]*dto.MetricFamily, 0, len(parsed))
+	for _, name := range slices.Sorted(maps.Keys(parsed)) {
+		families = append(families, parsed[name])
+	}
+	return families, nil
+}
+
+// labels returns the sorted label pairs of a replica of m. Configured and
+// constant labels replace upstream labels of the same name.
+func (c *upstreamCollector) labels(m *dto.Metric, replica int) []*dto.LabelPair {
+	values := make(map[string]string, len(m.Label)+len(c.cfg.Labels)+len(c.constLabels)+1)
+	for _, lp := range m.Label {
+		values[lp.GetName()] = lp.GetValue()
+	}
+	maps.Copy(values, c.cfg.Labels)
+	maps.Copy(values, c.constLabels)
+	if c.cfg.Replicas > 1 {
+		values[c.cfg.ReplicaLabel] = strconv.Itoa(replica + 1)
+	}
+
+	pairs := make([]*dto.LabelPair, 0, len(values))
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		pairs = append(pairs, &dto.LabelPair{Name: proto.String(name), Value: proto.String(values[name])})
+	}
+	return pairs
+}
+
+// upstreamMetric is a scraped series re-exposed with replica labels.
+type upstreamMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+	labels []*dto.LabelPair
+}
+
+func (m *upstreamMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+// Write copies the upstream sample into out with the replica labels.
+func (m *upstreamMetric) Write(out *dto.Metric) error {
+	proto.Merge(out, m.metric)
+	out.Label = m.labels
+	return nil
+}
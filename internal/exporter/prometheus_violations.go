@@ -0,0 +1,120 @@
+package exporter
+
+import (
+	"log/slog"
+	"math"
+	"slices"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// violationInjector corrupts gathered series with protocol violations.
+// Each configured violation is drawn independently per scrape and hits one
+// randomly chosen eligible series.
+type violationInjector struct {
+	violations []config.ProtocolViolationConfig
+	rng        *lockedRNG
+}
+
+// newViolationInjector creates an injector with a stream of sim derived
+// from key. Returns nil if no violations are configured.
+func newViolationInjector(violations []config.ProtocolViolationConfig, sim *simulation.Context, key string) *violationInjector {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &violationInjector{violations: violations, rng: newLockedRNG(sim, key)}
+}
+
+// wrap returns a gatherer injecting violations into the output of
+// gatherer. A nil injector returns gatherer unchanged.
+func (v *violationInjector) wrap(gatherer prometheus.Gatherer) prometheus.Gatherer {
+	if v == nil {
+		return gatherer
+	}
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := gatherer.Gather()
+		for _, violation := range v.violations {
+			if uniform(v.rng, 0, 1) < violation.Probability {
+				v.inject(violation.Type, families)
+			}
+		}
+		return families, err
+	})
+}
+
+// inject applies a violation to one eligible series of families.
+func (v *violationInjector) inject(typ config.ProtocolViolationType, families []*dto.MetricFamily) {
+	type series struct {
+		family *dto.MetricFamily
+		metric *dto.Metric
+	}
+	var eligible []series
+	for _, family := range families {
+		for _, m := range family.Metric {
+			if violationApplies(typ, family, m) {
+				eligible = append(eligible, series{family, m})
+			}
+		}
+	}
+	if len(eligible) == 0 {
+		return
+	}
+
+	s := eligible[v.rng.IntN(len(eligible))]
+	slog.Debug("injecting protocol violation", "type", typ, "metric", s.family.GetName())
+
+	// Label pairs may be shared with descriptors: replace, never modify
+	switch typ {
+	case config.ViolationDuplicateSeries:
+		s.family.Metric = append(s.family.Metric, proto.Clone(s.metric).(*dto.Metric))
+	case config.ViolationLabelOrder:
+		s.metric.Label = slices.Clone(s.metric.Label)
+		slices.Reverse(s.metric.Label)
+	case config.ViolationNaN:
+		setSampleValue(s.metric, math.NaN())
+	case config.ViolationInf:
+		setSampleValue(s.metric, math.Inf(1))
+	case config.ViolationInvalidUTF8:
+		s.metric.Label = slices.Clone(s.metric.Label)
+		i := v.rng.IntN(len(s.metric.Label))
+		s.metric.Label[i] = &dto.LabelPair{
+			Name:  s.metric.Label[i].Name,
+			Value: proto.String(s.metric.Label[i].GetValue() + "\xff"),
+		}
+	case config.ViolationCounterDecrease:
+		value := s.metric.Counter.GetValue()
+		setSampleValue(s.metric, math.Floor(value/2)-1)
+	}
+}
+
+// violationApplies reports whether a series is eligible for a violation.
+func violationApplies(typ config.ProtocolViolationType, family *dto.MetricFamily, m *dto.Metric) bool {
+	switch typ {
+	case config.ViolationLabelOrder:
+		return len(m.Label) > 1
+	case config.ViolationInvalidUTF8:
+		return len(m.Label) > 0
+	case config.ViolationNaN, config.ViolationInf:
+		return m.Counter != nil || m.Gauge != nil || m.Untyped != nil
+	case config.ViolationCounterDecrease:
+		return family.GetType() == dto.MetricType_COUNTER && m.Counter != nil
+	default:
+		return true
+	}
+}
+
+// setSampleValue replaces the value of a counter, gauge, or untyped sample.
+func setSampleValue(m *dto.Metric, value float64) {
+	switch {
+	case m.Counter != nil:
+		m.Counter.Value = proto.Float64(value)
+	case m.Gauge != nil:
+		m.Gauge.Value = proto.Float64(value)
+	case m.Untyped != nil:
+		m.Untyped.Value = proto.Float64(value)
+	}
+}
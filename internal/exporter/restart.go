@@ -0,0 +1,67 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// restarts counts exporter restarts after failures.
+var restarts atomic.Int64
+
+// Restarts returns the number of exporter restarts after failures.
+func Restarts() int64 {
+	return restarts.Load()
+}
+
+// WithRestart wraps the run function of an exporter to restart it after
+// failures as configured. Without restart configuration run is returned
+// as is and a failure ends the run.
+func WithRestart(name string, restart *config.RestartConfig, run func(ctx context.Context) error) func(ctx context.Context) error {
+	if restart == nil {
+		return run
+	}
+
+	return func(ctx context.Context) error {
+		backoff := restart.InitialBackoff
+		failures := 0
+		for {
+			start := time.Now()
+			err := run(ctx)
+			if err == nil || ctx.Err() != nil {
+				return err
+			}
+
+			// A run that outlasted the longest backoff was healthy
+			if time.Since(start) > restart.MaxBackoff {
+				backoff = restart.InitialBackoff
+				failures = 0
+			}
+			failures++
+			if restart.MaxRestarts > 0 && failures > restart.MaxRestarts {
+				return fmt.Errorf("giving up after %d restarts: %w", restart.MaxRestarts, err)
+			}
+
+			slog.Warn("exporter failed, restarting",
+				"exporter", name,
+				"error", err,
+				"backoff", backoff,
+				"attempt", failures,
+			)
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+
+			restarts.Add(1)
+			backoff = min(backoff*2, restart.MaxBackoff)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package exporter
+
+import (
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+)
+
+// sampleStamper assigns explicit sample timestamps offset from wall clock.
+type sampleStamper struct {
+	cfg config.TimestampConfig
+	rng *lockedRNG
+}
+
+// newSampleStamper creates a stamper with a jitter stream of sim derived
+// from key.
+func newSampleStamper(cfg config.TimestampConfig, sim *simulation.Context, key string) *sampleStamper {
+	return &sampleStamper{cfg: cfg, rng: newLockedRNG(sim, key)}
+}
+
+// stamp returns the timestamp of a sample taken at now. Safe for
+// concurrent use.
+func (s *sampleStamper) stamp(now time.Time) time.Time {
+	lag := s.cfg.Offset
+	if s.cfg.Jitter > 0 {
+		lag += time.Duration(s.rng.IntN(int(s.cfg.Jitter) + 1))
+	}
+	return now.Add(-lag)
+}
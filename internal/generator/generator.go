@@ -3,8 +3,12 @@ package generator
 import (
 	"fmt"
 	"log/slog"
+	"reflect"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/otelbox/internal/simulation"
@@ -12,56 +16,130 @@ import (
 	"github.com/neox5/simv/source"
 )
 
+// activeSeries counts the series of running generators.
+var activeSeries atomic.Int64
+
+// ActiveSeries returns the number of series currently generated.
+func ActiveSeries() int64 {
+	return activeSeries.Load()
+}
+
 // Generator manages simv components and value generation.
 type Generator struct {
-	// Lifecycle management - unique objects only
-	clocks  []clock.Clock
-	sources []source.Publisher[int]
-	values  []*simulation.ValueWrapper
+	mu       sync.Mutex
+	running  bool
+	sim      *simulation.Context
+	newClock ClockFactory
 
 	// Instance sharing - named references
-	clockInstances  map[string]clock.Clock
-	sourceInstances map[string]source.Publisher[int]
-	valueInstances  map[string]*simulation.ValueWrapper
+	clockInstances  map[string]sharedClock
+	sourceInstances map[string]sharedSource
+	splitInstances  map[string]sharedSplit // Splits by source instance name
+
+	// Metric ownership - components per metric key
+	entries map[string]*metricEntry
 
 	// Metric indexing - fast lookup by metric index
 	metricValues []*simulation.ValueWrapper
 }
 
+// sharedClock is a clock instance referenced by name.
+type sharedClock struct {
+	clock  clock.Clock
+	config config.ClockConfig
+}
+
+// sharedSource is a source instance referenced by name.
+type sharedSource struct {
+	source source.Publisher[int]
+	clock  clock.Clock
+	config config.SourceConfig
+}
+
+// metricEntry holds the components generating a single metric.
+type metricEntry struct {
+	config config.MetricConfig
+	split  splitPosition
+	clock  clock.Clock // Clock driving the value's source (owned or shared)
+	value  *simulation.ValueWrapper
+}
+
+// ClockFactory creates the clock driving a source.
+type ClockFactory func(cfg config.ClockConfig) (clock.Clock, error)
+
 // New creates a generator from metric configurations.
 // Creates separate clock/source/value instances for each metric.
 // Reuses instances when referenced by name via *Ref fields.
-func New(metrics []config.MetricConfig) (*Generator, error) {
-	g := &Generator{
-		clockInstances:  make(map[string]clock.Clock),
-		sourceInstances: make(map[string]source.Publisher[int]),
-		valueInstances:  make(map[string]*simulation.ValueWrapper),
-		metricValues:    make([]*simulation.ValueWrapper, len(metrics)),
+// Components are created with the random state of sim.
+func New(sim *simulation.Context, metrics []config.MetricConfig) (*Generator, error) {
+	return NewWithClockFactory(sim, metrics, sim.CreateClock)
+}
+
+// NewWithClockFactory creates a generator whose clocks are created by
+// factory instead of from the clock type, e.g. to drive generation in
+// virtual time.
+func NewWithClockFactory(sim *simulation.Context, metrics []config.MetricConfig, factory ClockFactory) (*Generator, error) {
+	g := newGenerator(len(metrics))
+	g.sim = sim
+	g.newClock = factory
+	if err := g.build(metrics, nil); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// newGenerator creates an empty generator.
+func newGenerator(metricCount int) *Generator {
+	return &Generator{
+		clockInstances:  make(map[string]sharedClock),
+		sourceInstances: make(map[string]sharedSource),
+		splitInstances:  make(map[string]sharedSplit),
+		entries:         make(map[string]*metricEntry),
+		metricValues:    make([]*simulation.ValueWrapper, metricCount),
 	}
+}
+
+// build creates components for all metrics.
+// Metrics and instances identical to those in prev are carried over.
+func (g *Generator) build(metrics []config.MetricConfig, prev *Generator) error {
+	keys := metricKeys(metrics)
+	splits := splitPositions(metrics)
 
 	for i, metric := range metrics {
-		// Get or create clock
-		clk, err := g.getOrCreateClock(metric.Value.Source)
-		if err != nil {
-			return nil, fmt.Errorf("metric %d (%s): failed to create clock: %w",
-				i, metric.PrometheusName, err)
+		key := keys[i]
+
+		// Carry over unchanged metric with its current state
+		if prev != nil {
+			if entry, exists := prev.entries[key]; exists && reflect.DeepEqual(entry.config, metric) && reflect.DeepEqual(entry.split, splits[i]) {
+				g.adoptInstances(metric.Value, prev)
+				g.adoptSplit(metric.Value, prev)
+				g.entries[key] = entry
+				g.metricValues[i] = entry.value
+				continue
+			}
 		}
 
-		// Get or create source
-		src, err := g.getOrCreateSource(metric.Value, clk)
+		// Get or create source (creates its clock when needed)
+		src, clk, err := g.getOrCreateSource(metric.Value, key, prev)
 		if err != nil {
-			return nil, fmt.Errorf("metric %d (%s): failed to create source: %w",
+			return fmt.Errorf("metric %d (%s): failed to create source: %w",
 				i, metric.PrometheusName, err)
 		}
 
+		// Values splitting a source instance receive their share of it
+		if metric.Value.Split != nil {
+			src = g.getOrCreateSplit(*metric.Value.SourceRef, src, splits[i], prev)
+		}
+
 		// Get or create value
-		val, err := g.getOrCreateValue(metric.Value, src)
+		val, err := g.getOrCreateValue(metric.Value, src, key)
 		if err != nil {
-			return nil, fmt.Errorf("metric %d (%s): failed to create value: %w",
+			return fmt.Errorf("metric %d (%s): failed to create value: %w",
 				i, metric.PrometheusName, err)
 		}
 
 		// Store for metric lookup (allows duplicates)
+		g.entries[key] = &metricEntry{config: metric, split: splits[i], clock: clk, value: val}
 		g.metricValues[i] = val
 
 		// Log metric creation with structured attributes
@@ -85,32 +163,86 @@ func New(metrics []config.MetricConfig) (*Generator, error) {
 		slog.Debug("created metric", logAttrs...)
 	}
 
-	return g, nil
+	return nil
+}
+
+// metricKeys returns the identities of all metrics.
+// Duplicates get a numeric suffix to keep their random streams distinct.
+func metricKeys(metrics []config.MetricConfig) []string {
+	keys := make([]string, len(metrics))
+	seenKeys := make(map[string]int)
+
+	for i, metric := range metrics {
+		key := metricKey(metric)
+		if n := seenKeys[key]; n > 0 {
+			seenKeys[key]++
+			key = fmt.Sprintf("%s#%d", key, n)
+		} else {
+			seenKeys[key] = 1
+		}
+		keys[i] = key
+	}
+
+	return keys
+}
+
+// metricKey returns a stable identity for a metric from its name and attributes.
+func metricKey(metric config.MetricConfig) string {
+	attrKeys := make([]string, 0, len(metric.Attributes))
+	for k := range metric.Attributes {
+		attrKeys = append(attrKeys, k)
+	}
+	sort.Strings(attrKeys)
+
+	attrPairs := make([]string, len(attrKeys))
+	for i, k := range attrKeys {
+		attrPairs[i] = fmt.Sprintf("%s=%s", k, metric.Attributes[k])
+	}
+	return fmt.Sprintf("metric:%s{%s}", metric.PrometheusName, strings.Join(attrPairs, ","))
+}
+
+// adoptInstances registers the shared instances used by a carried-over metric.
+func (g *Generator) adoptInstances(valueCfg config.ValueConfig, prev *Generator) {
+	if valueCfg.SourceRef != nil {
+		if shared, exists := prev.sourceInstances[*valueCfg.SourceRef]; exists {
+			g.sourceInstances[*valueCfg.SourceRef] = shared
+		}
+	}
+	if valueCfg.Source.ClockRef != nil {
+		if shared, exists := prev.clockInstances[*valueCfg.Source.ClockRef]; exists {
+			g.clockInstances[*valueCfg.Source.ClockRef] = shared
+		}
+	}
 }
 
 // getOrCreateClock returns cached clock if ClockRef is set, otherwise creates new.
-// Adds unique clocks to lifecycle management.
-func (g *Generator) getOrCreateClock(sourceCfg config.SourceConfig) (clock.Clock, error) {
+// Shared clocks with unchanged configuration are carried over from prev.
+func (g *Generator) getOrCreateClock(sourceCfg config.SourceConfig, prev *Generator) (clock.Clock, error) {
 	// Check if clock is shared instance
 	if sourceCfg.ClockRef != nil {
 		instanceName := *sourceCfg.ClockRef
 
 		// Return cached clock if already created
-		if clk, exists := g.clockInstances[instanceName]; exists {
-			return clk, nil
+		if shared, exists := g.clockInstances[instanceName]; exists {
+			return shared.clock, nil
+		}
+
+		// Carry over unchanged clock from previous generation
+		if prev != nil {
+			if shared, exists := prev.clockInstances[instanceName]; exists && reflect.DeepEqual(shared.config, sourceCfg.Clock) {
+				g.clockInstances[instanceName] = shared
+				return shared.clock, nil
+			}
 		}
 
 		// Create new clock
-		clk, err := simulation.CreateClock(sourceCfg.Clock)
+		clk, err := g.newClock(sourceCfg.Clock)
 		if err != nil {
 			return nil, fmt.Errorf("clock instance %q: %w", instanceName, err)
 		}
 
 		// Cache for sharing
-		g.clockInstances[instanceName] = clk
-
-		// Add to lifecycle management
-		g.clocks = append(g.clocks, clk)
+		g.clockInstances[instanceName] = sharedClock{clock: clk, config: sourceCfg.Clock}
 
 		// Log clock creation
 		slog.Debug("created clock",
@@ -123,14 +255,11 @@ func (g *Generator) getOrCreateClock(sourceCfg config.SourceConfig) (clock.Clock
 	}
 
 	// Unique clock - create new without caching
-	clk, err := simulation.CreateClock(sourceCfg.Clock)
+	clk, err := g.newClock(sourceCfg.Clock)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add to lifecycle management
-	g.clocks = append(g.clocks, clk)
-
 	// Log clock creation
 	slog.Debug("created clock",
 		"name", "<inline>",
@@ -142,28 +271,42 @@ func (g *Generator) getOrCreateClock(sourceCfg config.SourceConfig) (clock.Clock
 }
 
 // getOrCreateSource returns cached source if SourceRef is set, otherwise creates new.
-// Adds unique sources to lifecycle management.
-func (g *Generator) getOrCreateSource(valueCfg config.ValueConfig, clk clock.Clock) (source.Publisher[int], error) {
+// Returns the source together with the clock driving it.
+// Shared sources derive their random stream from the instance name, unique
+// sources from the owning metric key.
+func (g *Generator) getOrCreateSource(valueCfg config.ValueConfig, metricKey string, prev *Generator) (source.Publisher[int], clock.Clock, error) {
 	// Check if source is shared instance
 	if valueCfg.SourceRef != nil {
 		instanceName := *valueCfg.SourceRef
 
 		// Return cached source if already created
-		if src, exists := g.sourceInstances[instanceName]; exists {
-			return src, nil
+		if shared, exists := g.sourceInstances[instanceName]; exists {
+			return shared.source, shared.clock, nil
+		}
+
+		// Carry over unchanged source from previous generation
+		if prev != nil {
+			if shared, exists := prev.sourceInstances[instanceName]; exists && reflect.DeepEqual(shared.config, valueCfg.Source) {
+				g.sourceInstances[instanceName] = shared
+				g.adoptInstances(valueCfg, prev)
+				return shared.source, shared.clock, nil
+			}
+		}
+
+		// Get or create clock
+		clk, err := g.getOrCreateClock(valueCfg.Source, prev)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create clock: %w", err)
 		}
 
 		// Create new source
-		src, err := simulation.CreateSource(valueCfg.Source, clk)
+		src, err := g.sim.CreateSource(valueCfg.Source, clk, "source:"+instanceName)
 		if err != nil {
-			return nil, fmt.Errorf("source instance %q: %w", instanceName, err)
+			return nil, nil, fmt.Errorf("source instance %q: %w", instanceName, err)
 		}
 
 		// Cache for sharing
-		g.sourceInstances[instanceName] = src
-
-		// Add to lifecycle management
-		g.sources = append(g.sources, src)
+		g.sourceInstances[instanceName] = sharedSource{source: src, clock: clk, config: valueCfg.Source}
 
 		// Log source creation
 		clockName := "<inline>"
@@ -178,17 +321,20 @@ func (g *Generator) getOrCreateSource(valueCfg config.ValueConfig, clk clock.Clo
 				"min", valueCfg.Source.Min,
 				"max", valueCfg.Source.Max))
 
-		return src, nil
+		return src, clk, nil
 	}
 
-	// Unique source - create new without caching
-	src, err := simulation.CreateSource(valueCfg.Source, clk)
+	// Get or create clock
+	clk, err := g.getOrCreateClock(valueCfg.Source, prev)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to create clock: %w", err)
 	}
 
-	// Add to lifecycle management
-	g.sources = append(g.sources, src)
+	// Unique source - create new without caching
+	src, err := g.sim.CreateSource(valueCfg.Source, clk, metricKey)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Log source creation
 	clockName := "<inline>"
@@ -203,24 +349,20 @@ func (g *Generator) getOrCreateSource(valueCfg config.ValueConfig, clk clock.Clo
 			"min", valueCfg.Source.Min,
 			"max", valueCfg.Source.Max))
 
-	return src, nil
+	return src, clk, nil
 }
 
-// getOrCreateValue creates or returns cached value.
-// Values are always added to lifecycle management.
-func (g *Generator) getOrCreateValue(valueCfg config.ValueConfig, src source.Publisher[int]) (*simulation.ValueWrapper, error) {
+// getOrCreateValue creates a value for a metric.
+func (g *Generator) getOrCreateValue(valueCfg config.ValueConfig, src source.Publisher[int], metricKey string) (*simulation.ValueWrapper, error) {
 	// Note: Value instance sharing not yet implemented in config
 	// This structure supports future value instance sharing
 
 	// Create value
-	val, err := simulation.CreateValue(valueCfg, src)
+	val, err := g.sim.CreateValue(valueCfg, src, metricKey)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add to lifecycle management
-	g.values = append(g.values, val)
-
 	// Log value creation
 	sourceName := "<inline>"
 	if valueCfg.SourceRef != nil {
@@ -258,29 +400,282 @@ func (g *Generator) getOrCreateValue(valueCfg config.ValueConfig, src source.Pub
 	return val, nil
 }
 
+// clockSet returns all unique clocks driving metric values.
+func (g *Generator) clockSet() map[clock.Clock]bool {
+	clocks := make(map[clock.Clock]bool, len(g.entries))
+	for _, entry := range g.entries {
+		clocks[entry.clock] = true
+	}
+	return clocks
+}
+
 // Start begins value generation by starting all unique clocks.
 func (g *Generator) Start() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	// Start each unique clock exactly once
-	for _, clk := range g.clocks {
+	for clk := range g.clockSet() {
 		clk.Start()
 	}
+	if !g.running {
+		activeSeries.Add(int64(len(g.metricValues)))
+	}
+	g.running = true
 }
 
 // Stop halts value generation and releases resources.
 func (g *Generator) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	// Stop unique clocks
-	for _, clk := range g.clocks {
+	for clk := range g.clockSet() {
 		clk.Stop()
 	}
 
 	// Stop unique values
-	for _, val := range g.values {
-		val.Stop()
+	for _, entry := range g.entries {
+		entry.value.Stop()
+	}
+	if g.running {
+		activeSeries.Add(-int64(len(g.metricValues)))
+	}
+	g.running = false
+}
+
+// Reload is a prepared replacement of the generated metrics. The metrics
+// of the new configuration read their values from it before it is
+// committed, so a failure after preparing leaves the running generation
+// untouched.
+type Reload struct {
+	g         *Generator
+	next      *Generator
+	oldClocks map[clock.Clock]bool
+}
+
+// PrepareReload builds the components for metrics without changing the
+// running generation. Unchanged metrics keep their components and current
+// values; changed and new metrics get fresh components. The generator must
+// not change until the reload is committed or aborted.
+func (g *Generator) PrepareReload(metrics []config.MetricConfig) (*Reload, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	r := &Reload{g: g, next: newGenerator(len(metrics)), oldClocks: g.clockSet()}
+	r.next.sim = g.sim
+	r.next.newClock = g.newClock
+	if err := r.next.build(metrics, g.reusable(metrics)); err != nil {
+		r.abort()
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetValue returns the value at the specified metric index of the new
+// configuration.
+func (r *Reload) GetValue(index int) *simulation.ValueWrapper {
+	if index < 0 || index >= len(r.next.metricValues) {
+		return nil
+	}
+	return r.next.metricValues[index]
+}
+
+// Abort releases the components created for the reload: new clocks stop
+// and new values subscribed to kept sources are detached.
+func (r *Reload) Abort() {
+	r.g.mu.Lock()
+	defer r.g.mu.Unlock()
+	r.abort()
+}
+
+// abort releases the components created for the reload. Must be called
+// with the generator lock held.
+func (r *Reload) abort() {
+	current := make(map[*metricEntry]bool, len(r.g.entries))
+	for _, entry := range r.g.entries {
+		current[entry] = true
+	}
+
+	// Include clocks of instances whose metric failed to build
+	clocks := r.next.clockSet()
+	for _, shared := range r.next.clockInstances {
+		clocks[shared.clock] = true
+	}
+	for _, shared := range r.next.sourceInstances {
+		clocks[shared.clock] = true
+	}
+
+	stopped := make(map[clock.Clock]bool)
+	for clk := range clocks {
+		if !r.oldClocks[clk] {
+			clk.Stop()
+			stopped[clk] = true
+		}
+	}
+	for _, entry := range r.next.entries {
+		if current[entry] {
+			continue
+		}
+		if stopped[entry.clock] {
+			entry.value.Stop()
+		} else {
+			entry.value.Detach()
+		}
 	}
 }
 
+// Commit replaces the generated metrics with the prepared ones.
+// Components no longer referenced stop. Metric indexes follow the new
+// configuration order.
+func (r *Reload) Commit() {
+	g, next := r.g, r.next
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	newClocks := next.clockSet()
+
+	// Stop clocks no longer referenced
+	stopped := make(map[clock.Clock]bool)
+	for clk := range r.oldClocks {
+		if !newClocks[clk] {
+			clk.Stop()
+			stopped[clk] = true
+		}
+	}
+
+	// Stop values of removed or changed metrics
+	previous := make(map[*metricEntry]bool, len(g.entries))
+	for _, entry := range g.entries {
+		previous[entry] = true
+	}
+	kept := make(map[*metricEntry]bool, len(next.entries))
+	added := 0
+	for _, entry := range next.entries {
+		kept[entry] = true
+		if !previous[entry] {
+			added++
+		}
+	}
+
+	removed, detached := 0, 0
+	for _, entry := range g.entries {
+		if kept[entry] {
+			continue
+		}
+		removed++
+		if stopped[entry.clock] {
+			entry.value.Stop()
+			continue
+		}
+		// Clock still drives other metrics: value keeps draining its source
+		entry.value.Detach()
+		detached++
+	}
+
+	// Start new clocks
+	if g.running {
+		for clk := range newClocks {
+			if !r.oldClocks[clk] {
+				clk.Start()
+			}
+		}
+	}
+
+	g.clockInstances = next.clockInstances
+	g.sourceInstances = next.sourceInstances
+	g.splitInstances = next.splitInstances
+	if g.running {
+		activeSeries.Add(int64(len(next.metricValues) - len(g.metricValues)))
+	}
+	g.entries = next.entries
+	g.metricValues = next.metricValues
+
+	slog.Info("generator reloaded",
+		"metrics", len(next.metricValues),
+		"added", added,
+		"removed", removed,
+		"detached", detached)
+}
+
+// reusable returns the components of g that can be carried over to a
+// generation built from metrics. Clocks cannot drop subscribers, so a clock
+// is only reused when every source it drives is kept.
+func (g *Generator) reusable(metrics []config.MetricConfig) *Generator {
+	unchanged := make(map[string]bool)
+	keptSources := make(map[string]bool)
+	splits := splitPositions(metrics)
+	for i, key := range metricKeys(metrics) {
+		entry, exists := g.entries[key]
+		if !exists || !reflect.DeepEqual(entry.config, metrics[i]) || !reflect.DeepEqual(entry.split, splits[i]) {
+			continue
+		}
+		unchanged[key] = true
+		if entry.config.Value.SourceRef != nil {
+			keptSources[*entry.config.Value.SourceRef] = true
+		}
+	}
+
+	// Clocks driving sources that are dropped
+	tainted := make(map[clock.Clock]bool)
+	for key, entry := range g.entries {
+		if unchanged[key] {
+			continue
+		}
+		// Kept shared source stays subscribed, only the value detaches
+		if ref := entry.config.Value.SourceRef; ref != nil && keptSources[*ref] {
+			continue
+		}
+		tainted[entry.clock] = true
+	}
+
+	r := newGenerator(0)
+	for key := range unchanged {
+		if entry := g.entries[key]; !tainted[entry.clock] {
+			r.entries[key] = entry
+		}
+	}
+	for name, shared := range g.clockInstances {
+		if !tainted[shared.clock] {
+			r.clockInstances[name] = shared
+		}
+	}
+	for name, shared := range g.sourceInstances {
+		if !tainted[shared.clock] {
+			r.sourceInstances[name] = shared
+			if split, exists := g.splitInstances[name]; exists {
+				r.splitInstances[name] = split
+			}
+		}
+	}
+
+	return r
+}
+
+// Settle waits until every value has processed all ticks issued by its
+// clock. Returns an error if values are still behind after timeout.
+func (g *Generator) Settle(timeout time.Duration) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for key, entry := range g.entries {
+		for entry.value.Updates() < entry.clock.Stats().TickCount {
+			if time.Now().After(deadline) {
+				return fmt.Errorf("value %s did not settle within %s", key, timeout)
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}
+	return nil
+}
+
 // GetValue returns the value at the specified metric index.
 func (g *Generator) GetValue(index int) *simulation.ValueWrapper {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	if index < 0 || index >= len(g.metricValues) {
 		return nil
 	}
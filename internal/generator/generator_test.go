@@ -0,0 +1,160 @@
+package generator
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/neox5/simv/clock"
+)
+
+// sharedSourceConfig defines a counter adding 1 per tick from a shared
+// source instance.
+const sharedSourceConfig = `
+instances:
+  sources:
+    - name: events
+      type: random_int
+      clock: {type: periodic, interval: 1s}
+      min: 1
+      max: 1
+metrics:
+  - name: a_total
+    type: counter
+    description: "A"
+    value:
+      source: {instance: events}
+      transforms: [accumulate]
+`
+
+// reloadedConfig adds a second counter reading the shared source.
+const reloadedConfig = sharedSourceConfig + `
+  - name: b_total
+    type: counter
+    description: "B"
+    value:
+      source: {instance: events}
+      transforms: [accumulate]
+`
+
+func resolveMetrics(t *testing.T, data string) []config.MetricConfig {
+	t.Helper()
+	raw, err := config.ParseBytes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Resolve(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Metrics
+}
+
+// randomConfig defines a gauge drawing a random value per tick.
+const randomConfig = `
+metrics:
+  - name: level
+    type: gauge
+    description: "Level"
+    value:
+      source: {type: random_int, clock: {type: periodic, interval: 1s}, min: 0, max: 1000000}
+`
+
+// startManual starts a generator over metrics whose clocks tick manually,
+// in a simulation context of its own with seed.
+func startManual(t *testing.T, seed uint64, metrics []config.MetricConfig) (*Generator, func()) {
+	t.Helper()
+	sim, err := simulation.NewContext(&config.SettingsConfig{Seed: &seed}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var clocks []*simulation.ManualClock
+	g, err := NewWithClockFactory(sim, metrics, func(cfg config.ClockConfig) (clock.Clock, error) {
+		clk := simulation.NewManualClock(cfg.Interval)
+		clocks = append(clocks, clk)
+		return clk, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Start()
+	t.Cleanup(g.Stop)
+
+	return g, func() { simulation.TickManual(clocks...) }
+}
+
+func TestAbortedReloadKeepsRunningGeneration(t *testing.T) {
+	g, tick := startManual(t, 1, resolveMetrics(t, sharedSourceConfig))
+	a := g.GetValue(0)
+	tick()
+
+	reload, err := g.PrepareReload(resolveMetrics(t, reloadedConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reload.GetValue(0) != a {
+		t.Fatal("prepared reload did not carry over the unchanged value")
+	}
+	b := reload.GetValue(1)
+	reload.Abort()
+	tick()
+
+	if g.GetValue(0) != a || g.GetValue(1) != nil {
+		t.Fatal("aborted reload changed the running generation")
+	}
+	if got := a.State(); got != 2 {
+		t.Errorf("a = %d, want 2", got)
+	}
+	// The value subscribed to the kept source no longer updates
+	if got := b.State(); got != 0 {
+		t.Errorf("aborted b = %d, want 0", got)
+	}
+}
+
+func TestCommittedReloadKeepsUnchangedValues(t *testing.T) {
+	g, tick := startManual(t, 1, resolveMetrics(t, sharedSourceConfig))
+	a := g.GetValue(0)
+	tick()
+
+	reload, err := g.PrepareReload(resolveMetrics(t, reloadedConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reload.Commit()
+	tick()
+
+	if g.GetValue(0) != a {
+		t.Fatal("committed reload replaced the unchanged value")
+	}
+	if got := a.State(); got != 2 {
+		t.Errorf("a = %d, want 2", got)
+	}
+	if got := g.GetValue(1).State(); got != 1 {
+		t.Errorf("b = %d, want 1", got)
+	}
+}
+
+func TestGeneratorsWithSeparateContexts(t *testing.T) {
+	metrics := resolveMetrics(t, randomConfig)
+	first, tickFirst := startManual(t, 1, metrics)
+	same, tickSame := startManual(t, 1, metrics)
+	other, tickOther := startManual(t, 2, metrics)
+
+	// Ticking one generator does not advance the streams of another
+	tickFirst()
+	tickFirst()
+	tickSame()
+	tickOther()
+	tickSame()
+	tickOther()
+
+	want := first.GetValue(0).State()
+	if got := same.GetValue(0).State(); got != want {
+		t.Errorf("same seed = %d, want %d", got, want)
+	}
+	if got := other.GetValue(0).State(); got == want {
+		t.Errorf("other seed = %d, want a different value", got)
+	}
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/neox5/simv/source"
+)
+
+// sharedSplit is the split of a source instance across the values
+// referencing it with a split weight.
+type sharedSplit struct {
+	split   *simulation.SplitSource
+	source  source.Publisher[int] // Split source instance
+	weights []int
+}
+
+// splitPosition locates a value within the split of its source instance.
+// The zero value marks a value receiving every update in full.
+type splitPosition struct {
+	Weights []int // Weights of all values splitting the instance, in metric order
+	Index   int
+}
+
+// splitPositions returns the split position of every metric. Values
+// splitting the same source instance form one split, in metric order.
+func splitPositions(metrics []config.MetricConfig) []splitPosition {
+	weights := make(map[string][]int)
+	indexes := make([]int, len(metrics))
+	for i, metric := range metrics {
+		if metric.Value.Split == nil {
+			continue
+		}
+		ref := *metric.Value.SourceRef
+		indexes[i] = len(weights[ref])
+		weights[ref] = append(weights[ref], metric.Value.Split.Weight)
+	}
+
+	positions := make([]splitPosition, len(metrics))
+	for i, metric := range metrics {
+		if metric.Value.Split == nil {
+			continue
+		}
+		positions[i] = splitPosition{Weights: weights[*metric.Value.SourceRef], Index: indexes[i]}
+	}
+	return positions
+}
+
+// adoptSplit registers the split used by a carried-over metric.
+func (g *Generator) adoptSplit(valueCfg config.ValueConfig, prev *Generator) {
+	if valueCfg.Split == nil {
+		return
+	}
+	if shared, exists := prev.splitInstances[*valueCfg.SourceRef]; exists {
+		g.splitInstances[*valueCfg.SourceRef] = shared
+	}
+}
+
+// getOrCreateSplit returns the part at pos of the split of source instance
+// ref. A split with unchanged weights over the same source is carried over
+// from prev.
+func (g *Generator) getOrCreateSplit(ref string, src source.Publisher[int], pos splitPosition, prev *Generator) source.Publisher[int] {
+	if shared, exists := g.splitInstances[ref]; exists {
+		return shared.split.Part(pos.Index)
+	}
+
+	if prev != nil {
+		if shared, exists := prev.splitInstances[ref]; exists && shared.source == src && slices.Equal(shared.weights, pos.Weights) {
+			g.splitInstances[ref] = shared
+			return shared.split.Part(pos.Index)
+		}
+	}
+
+	split := simulation.NewSplitSource(src, pos.Weights)
+	g.splitInstances[ref] = sharedSplit{split: split, source: src, weights: pos.Weights}
+
+	slog.Debug("created split",
+		"source", "instance:"+ref,
+		"weights", pos.Weights)
+
+	return split.Part(pos.Index)
+}
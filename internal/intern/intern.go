@@ -0,0 +1,43 @@
+// Package intern deduplicates strings that repeat across many series.
+// Expanded metrics share their names and most attribute keys and values;
+// interning them once lets descriptors, Prometheus Descs and OTEL attribute
+// sets reference a single copy instead of one per series.
+package intern
+
+import "sync"
+
+// Pool hands out one canonical copy per distinct string. A Pool is safe for
+// concurrent use and keeps its strings until the Pool itself is dropped.
+type Pool struct {
+	strings sync.Map // string -> string
+}
+
+// New creates an empty pool.
+func New() *Pool {
+	return &Pool{}
+}
+
+// String returns the canonical copy of s.
+func (p *Pool) String(s string) string {
+	if s == "" {
+		return s
+	}
+	if v, ok := p.strings.Load(s); ok {
+		return v.(string)
+	}
+	v, _ := p.strings.LoadOrStore(s, s)
+	return v.(string)
+}
+
+// Attributes returns a copy of attrs with interned keys and values.
+// A nil map stays nil.
+func (p *Pool) Attributes(attrs map[string]string) map[string]string {
+	if attrs == nil {
+		return nil
+	}
+	result := make(map[string]string, len(attrs))
+	for key, value := range attrs {
+		result[p.String(key)] = p.String(value)
+	}
+	return result
+}
@@ -0,0 +1,147 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// New creates the handler configured by cfg. Records are written to w
+// unless cfg selects an output. The returned closer releases a log file
+// and is a no-op otherwise.
+func New(cfg config.LoggingConfig, w io.Writer) (slog.Handler, io.Closer, error) {
+	var closer io.Closer = nopCloser{}
+	switch cfg.Output {
+	case "":
+	case config.LogOutputStdout:
+		w = os.Stdout
+	case config.LogOutputStderr:
+		w = os.Stderr
+	default:
+		file, err := openRotatingFile(cfg.Output, int64(cfg.MaxSize), cfg.MaxFiles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log output: %w", err)
+		}
+		w, closer = file, file
+	}
+
+	// Module levels may lower the level below the default
+	minLevel := cfg.Level.Slog()
+	for _, level := range cfg.Modules {
+		minLevel = min(minLevel, level.Slog())
+	}
+
+	opts := &slog.HandlerOptions{Level: debugOverride{minLevel}}
+	var handler slog.Handler
+	if cfg.Format == config.LogFormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	if len(cfg.Modules) > 0 {
+		levels := make(map[string]slog.Level, len(cfg.Modules))
+		for module, level := range cfg.Modules {
+			levels[module] = level.Slog()
+		}
+		handler = &ModuleHandler{inner: handler, level: cfg.Level.Slog(), levels: levels, modules: &sync.Map{}}
+	}
+
+	return handler, closer, nil
+}
+
+// debugAll switches every module to debug logging, overriding configured
+// levels until toggled back.
+var debugAll atomic.Bool
+
+// ToggleDebug switches between configured levels and debug logging for
+// all modules. Returns whether debug logging is now on.
+func ToggleDebug() bool {
+	for {
+		on := debugAll.Load()
+		if debugAll.CompareAndSwap(on, !on) {
+			return !on
+		}
+	}
+}
+
+// debugOverride is the level of a handler, lowered to debug while debug
+// logging is toggled on.
+type debugOverride struct {
+	level slog.Level
+}
+
+func (d debugOverride) Level() slog.Level {
+	if debugAll.Load() {
+		return slog.LevelDebug
+	}
+	return d.level
+}
+
+// nopCloser is the closer of outputs otelbox does not own.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// ModuleHandler applies log levels per module, the otelbox package logging
+// a record. Records of modules without a level use the default level.
+type ModuleHandler struct {
+	inner   slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+	modules *sync.Map // Program counter -> module, shared by derived handlers
+}
+
+// Enabled reports whether records at level may pass for any module.
+func (h *ModuleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle forwards records at or above the level of their module.
+func (h *ModuleHandler) Handle(ctx context.Context, r slog.Record) error {
+	level, ok := h.levels[h.module(r.PC)]
+	if !ok {
+		level = h.level
+	}
+	if r.Level < level && !debugAll.Load() {
+		return nil
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler sharing module levels.
+func (h *ModuleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ModuleHandler{inner: h.inner.WithAttrs(attrs), level: h.level, levels: h.levels, modules: h.modules}
+}
+
+// WithGroup returns a handler sharing module levels.
+func (h *ModuleHandler) WithGroup(name string) slog.Handler {
+	return &ModuleHandler{inner: h.inner.WithGroup(name), level: h.level, levels: h.levels, modules: h.modules}
+}
+
+// module returns the package name of the function at pc, e.g. exporter
+// for github.com/neox5/otelbox/internal/exporter.(*OTELExporter).run.
+func (h *ModuleHandler) module(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if module, ok := h.modules.Load(pc); ok {
+		return module.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[:i]
+	}
+	h.modules.Store(pc, name)
+	return name
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// SamplingHandler drops repeated debug records to bound log volume on hot
+// paths. Each message is sampled independently; info and above always pass.
+type SamplingHandler struct {
+	inner slog.Handler
+	cfg   config.LoggingConfig
+	state *samplingState // Shared by derived handlers
+}
+
+// samplingState tracks occurrences per message.
+type samplingState struct {
+	mu       sync.Mutex
+	messages map[string]*messageState
+}
+
+// messageState holds sampling counters for a single message.
+type messageState struct {
+	count       uint64
+	windowStart time.Time
+	inWindow    int
+	suppressed  int
+}
+
+// NewSamplingHandler wraps inner with debug log sampling.
+func NewSamplingHandler(inner slog.Handler, cfg config.LoggingConfig) *SamplingHandler {
+	return &SamplingHandler{
+		inner: inner,
+		cfg:   cfg,
+		state: &samplingState{messages: make(map[string]*messageState)},
+	}
+}
+
+// Enabled reports whether the inner handler handles records at level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle forwards sampled records to the inner handler.
+// Forwarded records carry the number of records dropped since the last one.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level > slog.LevelDebug {
+		return h.inner.Handle(ctx, r)
+	}
+
+	allowed, suppressed := h.state.allow(r.Message, r.Time, h.cfg)
+	if !allowed {
+		return nil
+	}
+	if suppressed > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Int("suppressed", suppressed))
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler sharing sampling state.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{inner: h.inner.WithAttrs(attrs), cfg: h.cfg, state: h.state}
+}
+
+// WithGroup returns a handler sharing sampling state.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{inner: h.inner.WithGroup(name), cfg: h.cfg, state: h.state}
+}
+
+// allow applies every-Nth sampling, then the per-second limit.
+// Returns whether the record passes and how many were dropped before it.
+func (s *samplingState) allow(msg string, now time.Time, cfg config.LoggingConfig) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, exists := s.messages[msg]
+	if !exists {
+		m = &messageState{windowStart: now}
+		s.messages[msg] = m
+	}
+
+	// Log every Nth occurrence
+	m.count++
+	if cfg.SampleEvery > 1 && (m.count-1)%uint64(cfg.SampleEvery) != 0 {
+		m.suppressed++
+		return false, 0
+	}
+
+	// Log at most N per second
+	if cfg.MaxPerSecond > 0 {
+		if now.Sub(m.windowStart) >= time.Second {
+			m.windowStart = now
+			m.inWindow = 0
+		}
+		if m.inWindow >= cfg.MaxPerSecond {
+			m.suppressed++
+			return false, 0
+		}
+		m.inWindow++
+	}
+
+	suppressed := m.suppressed
+	m.suppressed = 0
+	return true, suppressed
+}
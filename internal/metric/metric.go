@@ -1,6 +1,11 @@
 package metric
 
-import "github.com/neox5/simv/value"
+import (
+	"slices"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+)
 
 // MetricType defines the semantic type of a metric.
 type MetricType string
@@ -16,6 +21,16 @@ type Descriptor struct {
 	OTELName       string
 	Type           MetricType
 	Description    string
+	Unit           string // OTEL unit (empty if unknown)
 	Attributes     map[string]string
-	Value          *value.Value[int]
+	Value          *simulation.ValueWrapper // Read with a consumer name to isolate reset_on_read
+	Guard          *simulation.Guard
+	Sampler        *Sampler                // Omits sparse series from some reads (nil: always emitted)
+	ExportTo       []config.ExportProtocol // Protocols exporting the metric (empty: all)
+	Job            string                  // Owning job (empty for top-level metrics)
+}
+
+// ExportedTo reports whether the metric is exported by protocol.
+func (d Descriptor) ExportedTo(protocol config.ExportProtocol) bool {
+	return len(d.ExportTo) == 0 || slices.Contains(d.ExportTo, protocol)
 }
@@ -0,0 +1,31 @@
+package metric
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// payloadAlphabet holds characters used for generated payload values
+const payloadAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// payloadAttributes generates large attribute values for a metric.
+// Values are deterministic per metric name and label index, and do not
+// consume streams from the simulation seed registry.
+func payloadAttributes(name string, cfg config.PayloadConfig) map[string]string {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	nameHash := h.Sum64()
+
+	attrs := make(map[string]string, cfg.Labels)
+	for i := range cfg.Labels {
+		rng := rand.New(rand.NewPCG(nameHash, uint64(i)))
+		buf := make([]byte, cfg.Size)
+		for j := range buf {
+			buf[j] = payloadAlphabet[rng.IntN(len(payloadAlphabet))]
+		}
+		attrs[cfg.Key(i)] = string(buf)
+	}
+	return attrs
+}
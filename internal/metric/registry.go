@@ -2,9 +2,11 @@ package metric
 
 import (
 	"fmt"
+	"maps"
 
 	"github.com/neox5/otelbox/internal/config"
-	"github.com/neox5/otelbox/internal/generator"
+	"github.com/neox5/otelbox/internal/intern"
+	"github.com/neox5/otelbox/internal/simulation"
 )
 
 // Registry holds protocol-agnostic metric definitions.
@@ -12,10 +14,26 @@ type Registry struct {
 	metrics []Descriptor
 }
 
-// New creates a registry from configuration.
-func New(cfg *config.Config, gen *generator.Generator) (*Registry, error) {
+// Values looks up the values of configured metrics by index, like a
+// generator or a prepared generator reload.
+type Values interface {
+	GetValue(index int) *simulation.ValueWrapper
+}
+
+// New creates a registry from configuration. Sparse series draw from the
+// random streams of sim.
+func New(cfg *config.Config, sim *simulation.Context, gen Values) (*Registry, error) {
 	var metrics []Descriptor
 
+	// Payload values depend only on name and payload config; series of
+	// one expanded metric share them instead of generating their own
+	type payloadKey struct {
+		name string
+		cfg  config.PayloadConfig
+	}
+	payloads := make(map[payloadKey]map[string]string)
+	keys := intern.New() // Payload keys repeat across metric names
+
 	for i, metricCfg := range cfg.Metrics {
 		val := gen.GetValue(i)
 		if val == nil {
@@ -23,13 +41,32 @@ func New(cfg *config.Config, gen *generator.Generator) (*Registry, error) {
 				i, metricCfg.PrometheusName)
 		}
 
+		// Merge generated payload attributes
+		attributes := metricCfg.Attributes
+		if metricCfg.Payload.Enabled() {
+			attributes = make(map[string]string, len(metricCfg.Attributes)+metricCfg.Payload.Labels)
+			maps.Copy(attributes, metricCfg.Attributes)
+			key := payloadKey{metricCfg.PrometheusName, metricCfg.Payload}
+			payload, ok := payloads[key]
+			if !ok {
+				payload = keys.Attributes(payloadAttributes(metricCfg.PrometheusName, metricCfg.Payload))
+				payloads[key] = payload
+			}
+			maps.Copy(attributes, payload)
+		}
+
 		metrics = append(metrics, Descriptor{
 			PrometheusName: metricCfg.PrometheusName,
 			OTELName:       metricCfg.OTELName,
 			Type:           MetricType(metricCfg.Type),
 			Description:    metricCfg.Description,
-			Attributes:     metricCfg.Attributes,
-			Value:          val.Value,
+			Unit:           metricCfg.Unit,
+			Attributes:     attributes,
+			Value:          val,
+			Guard:          val.Guard,
+			Sampler:        newSampler(metricCfg, sim),
+			ExportTo:       metricCfg.ExportTo,
+			Job:            metricCfg.Job,
 		})
 	}
 
@@ -40,3 +77,15 @@ func New(cfg *config.Config, gen *generator.Generator) (*Registry, error) {
 func (r *Registry) Metrics() []Descriptor {
 	return r.metrics
 }
+
+// Filter returns a registry holding the metrics for which keep is true.
+// Descriptors share their values with the original registry.
+func (r *Registry) Filter(keep func(Descriptor) bool) *Registry {
+	var metrics []Descriptor
+	for _, m := range r.metrics {
+		if keep(m) {
+			metrics = append(metrics, m)
+		}
+	}
+	return &Registry{metrics: metrics}
+}
@@ -0,0 +1,73 @@
+package metric
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+)
+
+// samplerResolution is the granularity of emit probabilities
+const samplerResolution = 1 << 30
+
+// Sampler decides on each read whether a sparse series is emitted.
+// A nil sampler emits on every read.
+type Sampler struct {
+	key       string
+	threshold int
+	sim       *simulation.Context
+
+	mu   sync.Mutex
+	rngs map[string]simulation.RNG // By consumer
+}
+
+// newSampler creates a sampler for a metric, or nil if the metric is
+// emitted on every read. Draws come from streams derived from the series
+// identity so sparse output is reproducible with a fixed seed.
+func newSampler(cfg config.MetricConfig, sim *simulation.Context) *Sampler {
+	if !cfg.Sparse() {
+		return nil
+	}
+	return &Sampler{
+		key:       seriesKey("emit", cfg),
+		threshold: int(cfg.EmitProbability * samplerResolution),
+		sim:       sim,
+		rngs:      make(map[string]simulation.RNG),
+	}
+}
+
+// Emit reports whether the series is emitted on the current read of
+// consumer. Each consumer draws from its own stream, so reads of one
+// consumer do not change which reads of another emit the series.
+func (s *Sampler) Emit(consumer string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rng, exists := s.rngs[consumer]
+	if !exists {
+		rng = s.sim.NewDerivedRand(s.key + "/" + consumer)
+		s.rngs[consumer] = rng
+	}
+	return rng.IntN(samplerResolution) < s.threshold
+}
+
+// seriesKey returns a stable identity for a series from name and attributes.
+func seriesKey(prefix string, cfg config.MetricConfig) string {
+	attrKeys := make([]string, 0, len(cfg.Attributes))
+	for k := range cfg.Attributes {
+		attrKeys = append(attrKeys, k)
+	}
+	sort.Strings(attrKeys)
+
+	attrPairs := make([]string, len(attrKeys))
+	for i, k := range attrKeys {
+		attrPairs[i] = fmt.Sprintf("%s=%s", k, cfg.Attributes[k])
+	}
+	return fmt.Sprintf("%s:%s{%s}", prefix, cfg.PrometheusName, strings.Join(attrPairs, ","))
+}
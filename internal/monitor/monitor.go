@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/shirou/gopsutil/v4/process"
@@ -18,6 +19,7 @@ type Monitor struct {
 	logger   *slog.Logger
 	wg       sync.WaitGroup
 	proc     *process.Process
+	onSample func(Snapshot)
 }
 
 // New creates a new monitor with specified collection interval.
@@ -35,6 +37,12 @@ func New(interval time.Duration, logger *slog.Logger) *Monitor {
 	}
 }
 
+// OnSample registers fn to be called with each sample.
+// Must be called before Run.
+func (m *Monitor) OnSample(fn func(Snapshot)) {
+	m.onSample = fn
+}
+
 // Run starts the monitoring loop in a background goroutine.
 // Blocks until context is cancelled.
 func (m *Monitor) Run(ctx context.Context) {
@@ -62,35 +70,60 @@ func (m *Monitor) Wait() {
 	m.wg.Wait()
 }
 
-// collect reads current metrics and logs resource usage.
-func (m *Monitor) collect() {
-	// ---- CPU ----
-	processCPU, err := m.proc.CPUPercent()
-	if err != nil {
-		m.logger.Warn("failed to get CPU percent", "error", err)
-		processCPU = 0
-	}
+// Saturation classifies CPU utilization.
+type Saturation string
 
-	cores := runtime.GOMAXPROCS(-1)
-	maxCPU := float64(cores * 100)
+const (
+	SaturationNormal    Saturation = "normal"
+	SaturationHigh      Saturation = "high"      // Above 80% of available cores
+	SaturationSaturated Saturation = "saturated" // Above 95% of available cores
+)
 
-	utilization := 0.0
-	if maxCPU > 0 {
-		utilization = processCPU / maxCPU
+// Level returns the saturation as a number for metrics: 0 normal, 1 high,
+// 2 saturated.
+func (s Saturation) Level() int {
+	switch s {
+	case SaturationHigh:
+		return 1
+	case SaturationSaturated:
+		return 2
+	default:
+		return 0
 	}
+}
 
-	// ---- Runtime / Memory ----
-	var ms runtime.MemStats
-	runtime.ReadMemStats(&ms)
+// Snapshot is one sample of the resource monitor.
+type Snapshot struct {
+	CPUPercent    float64 // Process CPU since the previous sample, 100 per core
+	Utilization   float64 // Fraction of the available cores
+	Cores         int     // Available cores (GOMAXPROCS)
+	Goroutines    int
+	HeapAlloc     uint64 // Bytes
+	HeapSys       uint64 // Bytes
+	StackInuse    uint64 // Bytes
+	NumGC         uint32
+	GCCPUFraction float64
+	Saturation    Saturation
+}
 
-	goroutines := runtime.NumGoroutine()
+// current holds the latest sample of the running monitor.
+var current atomic.Pointer[Snapshot]
 
-	// ---- Saturation ----
-	saturation := "normal"
-	if utilization > 0.95 {
-		saturation = "saturated"
-	} else if utilization > 0.80 {
-		saturation = "high"
+// Current returns the latest sample of the running monitor, or the zero
+// snapshot before the first sample.
+func Current() Snapshot {
+	if s := current.Load(); s != nil {
+		return *s
+	}
+	return Snapshot{Saturation: SaturationNormal}
+}
+
+// collect samples resource usage, publishes it, and logs it.
+func (m *Monitor) collect() {
+	s := m.sample()
+	current.Store(&s)
+	if m.onSample != nil {
+		m.onSample(s)
 	}
 
 	// ---- Helpers ----
@@ -106,31 +139,75 @@ func (m *Monitor) collect() {
 		context.Background(),
 		slog.LevelInfo,
 		"resource",
-		slog.String("cpu", fmt.Sprintf("%.4f%%", processCPU)),
-		slog.String("util", fmt.Sprintf("%.4f%%", utilization*100)),
-		slog.Int("cores", cores),
-		slog.Int("gor", goroutines),
+		slog.String("cpu", fmt.Sprintf("%.4f%%", s.CPUPercent)),
+		slog.String("util", fmt.Sprintf("%.4f%%", s.Utilization*100)),
+		slog.Int("cores", s.Cores),
+		slog.Int("gor", s.Goroutines),
 		slog.String(
 			"mem",
 			fmt.Sprintf(
 				"alloc:%.2fMB sys:%.2fMB stack:%.0fKB",
-				mb(ms.HeapAlloc),
-				mb(ms.HeapSys),
-				kb(ms.StackInuse),
+				mb(s.HeapAlloc),
+				mb(s.HeapSys),
+				kb(s.StackInuse),
 			),
 		),
-		slog.Uint64("gc", uint64(ms.NumGC)),
-		slog.String("gc_cpu", fmt.Sprintf("%.3f", ms.GCCPUFraction)),
-		slog.String("sat", saturation),
+		slog.Uint64("gc", uint64(s.NumGC)),
+		slog.String("gc_cpu", fmt.Sprintf("%.3f", s.GCCPUFraction)),
+		slog.String("sat", string(s.Saturation)),
 	)
 
 	// ---- Saturation warning (unchanged semantics, compact keys) ----
-	if saturation == "saturated" {
+	if s.Saturation == SaturationSaturated {
 		m.logger.Warn(
 			"cpu saturation detected",
-			"cpu", processCPU,
-			"util_pct", utilization*100,
+			"cpu", s.CPUPercent,
+			"util_pct", s.Utilization*100,
 			"action", "reduce load or increase GOMAXPROCS",
 		)
 	}
 }
+
+// sample reads current resource usage.
+func (m *Monitor) sample() Snapshot {
+	// ---- CPU ----
+	// Averaged since the previous sample, so saturation reflects current load
+	processCPU, err := m.proc.Percent(0)
+	if err != nil {
+		m.logger.Warn("failed to get CPU percent", "error", err)
+		processCPU = 0
+	}
+
+	cores := runtime.GOMAXPROCS(-1)
+	maxCPU := float64(cores * 100)
+
+	utilization := 0.0
+	if maxCPU > 0 {
+		utilization = processCPU / maxCPU
+	}
+
+	// ---- Runtime / Memory ----
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	// ---- Saturation ----
+	saturation := SaturationNormal
+	if utilization > 0.95 {
+		saturation = SaturationSaturated
+	} else if utilization > 0.80 {
+		saturation = SaturationHigh
+	}
+
+	return Snapshot{
+		CPUPercent:    processCPU,
+		Utilization:   utilization,
+		Cores:         cores,
+		Goroutines:    runtime.NumGoroutine(),
+		HeapAlloc:     ms.HeapAlloc,
+		HeapSys:       ms.HeapSys,
+		StackInuse:    ms.StackInuse,
+		NumGC:         ms.NumGC,
+		GCCPUFraction: ms.GCCPUFraction,
+		Saturation:    saturation,
+	}
+}
@@ -0,0 +1,320 @@
+// Package receiver implements a minimal OTLP metrics receiver. It accepts
+// exports over gRPC and HTTP, from otelbox itself or any other client, and
+// summarizes what it received so exports can be verified end to end
+// without running a collector.
+package receiver
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip" // Accept gzip-compressed gRPC exports
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxBodySize bounds OTLP/HTTP request bodies after decompression.
+const maxBodySize = 64 << 20
+
+// Receiver accepts OTLP metric exports and keeps a summary of them.
+type Receiver struct {
+	collectorpb.UnimplementedMetricsServiceServer
+
+	grpcAddr string
+	httpAddr string
+
+	mu      sync.Mutex
+	summary summary
+}
+
+// summary aggregates all exports received since start or the last reset.
+type summary struct {
+	since      time.Time
+	requests   map[string]int64 // By transport
+	rejected   int64
+	dataPoints int64
+	services   map[string]bool
+	metrics    map[string]*metricSummary
+}
+
+// metricSummary aggregates the data points of one metric name.
+type metricSummary struct {
+	typ        string
+	unit       string
+	dataPoints int64
+	series     map[string]bool // Attribute sets
+	last       time.Time       // Latest data point timestamp
+}
+
+// New creates a receiver listening on the configured ports.
+func New(cfg config.ReceiverConfig) *Receiver {
+	r := &Receiver{
+		grpcAddr: fmt.Sprintf(":%d", cfg.GRPCPort),
+		httpAddr: fmt.Sprintf(":%d", cfg.HTTPPort),
+	}
+	r.reset()
+	return r
+}
+
+// reset clears the summary.
+func (r *Receiver) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary = summary{
+		since:    time.Now(),
+		requests: make(map[string]int64),
+		services: make(map[string]bool),
+		metrics:  make(map[string]*metricSummary),
+	}
+}
+
+// Run serves OTLP over gRPC and HTTP until ctx is cancelled.
+func (r *Receiver) Run(ctx context.Context) error {
+	grpcListener, err := net.Listen("tcp", r.grpcAddr)
+	if err != nil {
+		return err
+	}
+	grpcServer := grpc.NewServer()
+	collectorpb.RegisterMetricsServiceServer(grpcServer, r)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/metrics", r.handleExport)
+	mux.HandleFunc("GET /summary", r.handleSummary)
+	mux.HandleFunc("DELETE /summary", func(w http.ResponseWriter, _ *http.Request) {
+		r.reset()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	httpServer := &http.Server{Addr: r.httpAddr, Handler: mux}
+
+	errChan := make(chan error, 2)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			errChan <- err
+		}
+	}()
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+	slog.Info("starting otlp receiver", "grpc", r.grpcAddr, "http", r.httpAddr)
+
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		slog.Info("shutting down otlp receiver")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	grpcServer.GracefulStop()
+	if shutdownErr := httpServer.Shutdown(shutdownCtx); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// Export implements the OTLP gRPC metrics service.
+func (r *Receiver) Export(_ context.Context, req *collectorpb.ExportMetricsServiceRequest) (*collectorpb.ExportMetricsServiceResponse, error) {
+	r.record("grpc", req)
+	return &collectorpb.ExportMetricsServiceResponse{}, nil
+}
+
+// handleExport accepts OTLP/HTTP exports in protobuf or JSON encoding.
+func (r *Receiver) handleExport(w http.ResponseWriter, req *http.Request) {
+	body := io.Reader(req.Body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(req.Body)
+		if err != nil {
+			r.reject(w, http.StatusBadRequest, err)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxBodySize))
+	if err != nil {
+		r.reject(w, http.StatusBadRequest, err)
+		return
+	}
+
+	export := &collectorpb.ExportMetricsServiceRequest{}
+	marshal := proto.Marshal
+	switch contentType, _, _ := strings.Cut(req.Header.Get("Content-Type"), ";"); strings.TrimSpace(contentType) {
+	case "application/x-protobuf":
+		err = proto.Unmarshal(data, export)
+	case "application/json":
+		err = protojson.Unmarshal(data, export)
+		marshal = protojson.Marshal
+	default:
+		r.reject(w, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q", req.Header.Get("Content-Type")))
+		return
+	}
+	if err != nil {
+		r.reject(w, http.StatusBadRequest, err)
+		return
+	}
+
+	r.record("http", export)
+
+	// Answer in the encoding of the request
+	response, _ := marshal(&collectorpb.ExportMetricsServiceResponse{})
+	w.Header().Set("Content-Type", req.Header.Get("Content-Type"))
+	w.Write(response)
+}
+
+// reject answers a malformed export and counts it.
+func (r *Receiver) reject(w http.ResponseWriter, status int, err error) {
+	r.mu.Lock()
+	r.summary.rejected++
+	r.mu.Unlock()
+	slog.Debug("otlp receiver rejected export", "error", err)
+	http.Error(w, err.Error(), status)
+}
+
+// record adds an export request to the summary.
+func (r *Receiver) record(transport string, req *collectorpb.ExportMetricsServiceRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.summary.requests[transport]++
+	for _, rm := range req.GetResourceMetrics() {
+		for _, attr := range rm.GetResource().GetAttributes() {
+			if attr.GetKey() == "service.name" {
+				r.summary.services[attr.GetValue().GetStringValue()] = true
+			}
+		}
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				r.recordMetric(m)
+			}
+		}
+	}
+}
+
+// recordMetric adds the data points of a metric to the summary.
+func (r *Receiver) recordMetric(m *metricspb.Metric) {
+	s, exists := r.summary.metrics[m.GetName()]
+	if !exists {
+		s = &metricSummary{series: make(map[string]bool)}
+		r.summary.metrics[m.GetName()] = s
+	}
+	s.unit = m.GetUnit()
+
+	add := func(attrs []*commonpb.KeyValue, timeUnixNano uint64) {
+		s.dataPoints++
+		r.summary.dataPoints++
+		s.series[attributeKey(attrs)] = true
+		if t := time.Unix(0, int64(timeUnixNano)); t.After(s.last) {
+			s.last = t
+		}
+	}
+
+	switch data := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		s.typ = "gauge"
+		for _, dp := range data.Gauge.GetDataPoints() {
+			add(dp.GetAttributes(), dp.GetTimeUnixNano())
+		}
+	case *metricspb.Metric_Sum:
+		s.typ = "sum"
+		for _, dp := range data.Sum.GetDataPoints() {
+			add(dp.GetAttributes(), dp.GetTimeUnixNano())
+		}
+	case *metricspb.Metric_Histogram:
+		s.typ = "histogram"
+		for _, dp := range data.Histogram.GetDataPoints() {
+			add(dp.GetAttributes(), dp.GetTimeUnixNano())
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		s.typ = "exponential_histogram"
+		for _, dp := range data.ExponentialHistogram.GetDataPoints() {
+			add(dp.GetAttributes(), dp.GetTimeUnixNano())
+		}
+	case *metricspb.Metric_Summary:
+		s.typ = "summary"
+		for _, dp := range data.Summary.GetDataPoints() {
+			add(dp.GetAttributes(), dp.GetTimeUnixNano())
+		}
+	}
+}
+
+// attributeKey identifies an attribute set independent of order.
+func attributeKey(attrs []*commonpb.KeyValue) string {
+	pairs := make([]string, len(attrs))
+	for i, attr := range attrs {
+		pairs[i] = attr.GetKey() + "=" + attr.GetValue().String()
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, "\x00")
+}
+
+// summaryResponse is the JSON form of the summary.
+type summaryResponse struct {
+	Since      time.Time          `json:"since"`
+	Requests   map[string]int64   `json:"requests"`
+	Rejected   int64              `json:"rejected"`
+	DataPoints int64              `json:"data_points"`
+	Series     int                `json:"series"`
+	Services   []string           `json:"services"`
+	Metrics    []metricSummaryRow `json:"metrics"`
+}
+
+// metricSummaryRow is the JSON form of a metric summary.
+type metricSummaryRow struct {
+	Name       string    `json:"name"`
+	Type       string    `json:"type"`
+	Unit       string    `json:"unit,omitempty"`
+	Series     int       `json:"series"`
+	DataPoints int64     `json:"data_points"`
+	Last       time.Time `json:"last"`
+}
+
+// handleSummary serves the summary as JSON, metrics sorted by name.
+func (r *Receiver) handleSummary(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	response := summaryResponse{
+		Since:      r.summary.since,
+		Requests:   maps.Clone(r.summary.requests),
+		Rejected:   r.summary.rejected,
+		DataPoints: r.summary.dataPoints,
+		Services:   slices.Sorted(maps.Keys(r.summary.services)),
+		Metrics:    make([]metricSummaryRow, 0, len(r.summary.metrics)),
+	}
+	for _, name := range slices.Sorted(maps.Keys(r.summary.metrics)) {
+		m := r.summary.metrics[name]
+		response.Series += len(m.series)
+		response.Metrics = append(response.Metrics, metricSummaryRow{
+			Name:       name,
+			Type:       m.typ,
+			Unit:       m.unit,
+			Series:     len(m.series),
+			DataPoints: m.dataPoints,
+			Last:       m.last,
+		})
+	}
+	r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(response); err != nil {
+		slog.Debug("failed to write receiver summary", "error", err)
+	}
+}
@@ -0,0 +1,195 @@
+// Package scrape verifies a running Prometheus endpoint against a
+// configuration: every configured series must be exposed with the
+// configured type.
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/exporter"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// Target is an endpoint and the series it is expected to expose.
+type Target struct {
+	URL         string
+	Auth        *config.AuthConfig // Scrape credentials (nil: none)
+	ConstLabels map[string]string  // Labels added to every series
+	CheckTypes  bool               // False if the endpoint omits TYPE metadata
+	Metrics     []config.MetricConfig
+}
+
+// NewTarget returns the Prometheus endpoint of cfg exposing the metrics of
+// job, or the top-level endpoint if job is empty. The URL points to the
+// configured host, or localhost if the endpoint listens on all interfaces.
+func NewTarget(cfg *config.Config, job string) (Target, error) {
+	export := cfg.Export
+	if job != "" {
+		i := slices.IndexFunc(cfg.Jobs, func(j config.JobConfig) bool { return j.Name == job })
+		if i < 0 {
+			return Target{}, fmt.Errorf("unknown job %q", job)
+		}
+		if cfg.Jobs[i].Dedicated() {
+			export = *cfg.Jobs[i].Export
+		}
+	}
+
+	prom := export.Prometheus
+	if prom == nil || !prom.Enabled {
+		return Target{}, fmt.Errorf("prometheus export not enabled")
+	}
+
+	// Metrics of jobs without dedicated export share the top-level endpoint
+	dedicated := make(map[string]bool)
+	for _, j := range cfg.Jobs {
+		dedicated[j.Name] = j.Dedicated()
+	}
+	var metrics []config.MetricConfig
+	for _, m := range cfg.Metrics {
+		inScope := m.Job == job || (job == "" && !dedicated[m.Job])
+		if inScope && m.ExportsTo(config.ExportProtocolPrometheus) {
+			metrics = append(metrics, m)
+		}
+	}
+
+	host := prom.Host
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+
+	return Target{
+		URL:         fmt.Sprintf("http://%s%s", net.JoinHostPort(host, strconv.Itoa(prom.Port)), prom.Path),
+		Auth:        prom.Auth,
+		ConstLabels: prom.ConstLabels,
+		CheckTypes:  !prom.Metadata.OmitType,
+		Metrics:     metrics,
+	}, nil
+}
+
+// Fetch scrapes url in text format and returns the parsed metric families.
+func Fetch(ctx context.Context, url string, auth *config.AuthConfig) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	client := &http.Client{Transport: exporter.NewAuthTransport(http.DefaultTransport, auth)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	return families, nil
+}
+
+// Report is the outcome of a verification.
+type Report struct {
+	Expected int      // Configured series
+	Found    int      // Configured series present in the scrape
+	Absent   int      // Sparse series absent from the scrape (not a problem)
+	Problems []string // Missing series and type mismatches
+}
+
+// OK reports whether the verification found no problems.
+func (r Report) OK() bool {
+	return len(r.Problems) == 0
+}
+
+// Verify checks that families contain every series of target with the
+// configured type. A series matches a scraped sample of the same name
+// having all its configured labels; further labels, like payload or target
+// labels, are ignored. Series that may be omitted from a scrape, because
+// they are sparse or have missing samples, are not required.
+func Verify(families map[string]*dto.MetricFamily, target Target) Report {
+	var report Report
+	mismatched := make(map[string]bool)
+
+	for _, m := range target.Metrics {
+		report.Expected++
+
+		labels := make(map[string]string, len(m.Attributes)+len(target.ConstLabels))
+		maps.Copy(labels, m.Attributes)
+		maps.Copy(labels, target.ConstLabels)
+
+		family := families[m.PrometheusName]
+		if family == nil || !slices.ContainsFunc(family.Metric, func(s *dto.Metric) bool { return hasLabels(s, labels) }) {
+			if optional(m) {
+				report.Absent++
+			} else {
+				report.Problems = append(report.Problems, "missing series "+formatSeries(m.PrometheusName, labels))
+			}
+			continue
+		}
+		report.Found++
+
+		if want := familyType(m.Type); target.CheckTypes && family.GetType() != want && !mismatched[m.PrometheusName] {
+			mismatched[m.PrometheusName] = true
+			report.Problems = append(report.Problems, fmt.Sprintf("type mismatch for %s: expected %s, got %s",
+				m.PrometheusName, strings.ToLower(want.String()), strings.ToLower(family.GetType().String())))
+		}
+	}
+
+	return report
+}
+
+// optional reports whether a series may be absent from a scrape.
+func optional(m config.MetricConfig) bool {
+	if m.Sparse() {
+		return true
+	}
+	return slices.ContainsFunc(m.Value.SpecialValues, func(s config.SpecialValueConfig) bool {
+		return s.Type == config.SpecialMissing
+	})
+}
+
+// hasLabels reports whether sample has all labels with the given values.
+func hasLabels(sample *dto.Metric, labels map[string]string) bool {
+	found := 0
+	for _, lp := range sample.Label {
+		if value, ok := labels[lp.GetName()]; ok {
+			if value != lp.GetValue() {
+				return false
+			}
+			found++
+		}
+	}
+	return found == len(labels)
+}
+
+// familyType returns the exposition type of a metric type.
+func familyType(t config.MetricType) dto.MetricType {
+	if t == config.MetricTypeCounter {
+		return dto.MetricType_COUNTER
+	}
+	return dto.MetricType_GAUGE
+}
+
+// formatSeries formats a series in exposition syntax.
+func formatSeries(name string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, labels[key]))
+	}
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
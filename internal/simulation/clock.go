@@ -8,11 +8,10 @@ import (
 )
 
 // CreateClock creates a clock from configuration.
-func CreateClock(cfg config.ClockConfig) (clock.Clock, error) {
-	switch cfg.Type {
-	case "periodic":
-		return clock.NewPeriodicClock(cfg.Interval), nil
-	default:
+func (c *Context) CreateClock(cfg config.ClockConfig) (clock.Clock, error) {
+	t, err := lookupType(clockTypes, cfg.Type, func(t clockType) TypeInfo { return t.TypeInfo })
+	if err != nil {
 		return nil, fmt.Errorf("unknown clock type: %s", cfg.Type)
 	}
+	return t.create(c, cfg)
 }
@@ -0,0 +1,55 @@
+package simulation
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// Context holds the seed, random number generator, and generation mode of
+// one simulation. Clocks, sources, and values created from different
+// contexts are independent, so several simulations can run in a process.
+type Context struct {
+	seed       uint64
+	rng        config.RNGConfig
+	sequence   []uint64 // Values of the sequence generator
+	generation config.GenerationMode
+
+	latentEpochs sync.Map // Correlation group -> first tick time
+}
+
+// NewContext creates the context of a simulation from settings, logging
+// the selected seed and generator to logger.
+func NewContext(settings *config.SettingsConfig, logger *slog.Logger) (*Context, error) {
+	c := &Context{
+		// Without settings.seed, use the seed random iterators were expanded with
+		seed:       config.EffectiveSeed(settings.Seed),
+		rng:        settings.RNG,
+		generation: settings.Generation,
+	}
+	if c.rng.Type == "" {
+		c.rng.Type = config.RNGTypePCG
+	}
+
+	if c.rng.Type == config.RNGTypeSequence {
+		values, err := loadSequence(c.rng.File)
+		if err != nil {
+			return nil, err
+		}
+		c.sequence = values
+	}
+
+	logger.Info("seed initialized", "master", c.seed, "explicit", settings.Seed != nil)
+	if c.rng.Type == config.RNGTypeCrypto {
+		logger.Warn("crypto rng ignores seed, generated values are not reproducible")
+	}
+	logger.Info("rng initialized", "type", c.rng.Type)
+
+	return c, nil
+}
+
+// Seed returns the master seed all random streams derive from.
+func (c *Context) Seed() uint64 {
+	return c.seed
+}
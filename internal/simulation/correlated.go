@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/neox5/simv/clock"
+)
+
+// NewCorrelatedSource creates a source of integers in [min, max] that moves
+// with the other sources of group. Each tick mixes the group's latent
+// signal with individual noise from rng, so values of two members have
+// correlation close to correlation. Marginally, values are uniform like
+// random_int. Groups are shared by the sources of the simulation context.
+func (c *Context) NewCorrelatedSource(clk clock.Clock, interval time.Duration, min, max int, group string, correlation float64, rng RNG, guard *Guard) (*TickSource, error) {
+	if group == "" {
+		return nil, fmt.Errorf("correlated source requires a group")
+	}
+	if correlation < 0 || correlation > 1 {
+		return nil, fmt.Errorf("correlation must be in [0, 1], got %g", correlation)
+	}
+	if max < min {
+		return nil, fmt.Errorf("correlated source max %d is below min %d", max, min)
+	}
+
+	latentWeight := math.Sqrt(correlation)
+	noiseWeight := math.Sqrt(1 - correlation)
+
+	index := c.latentIndex(clk, interval, group)
+	next := func() int {
+		x := latentWeight*c.latentSignal(group, index()) + noiseWeight*normal(rng)
+		u := 0.5 * (1 + math.Erf(x/math.Sqrt2)) // Standard normal CDF
+		return min + int(math.Min(u*float64(max-min+1), float64(max-min)))
+	}
+	return NewTickSource(clk, next, guard), nil
+}
+
+// latentIndex returns a function reporting the latent signal index of the
+// current tick. Manual clocks count their own ticks, keeping virtual time
+// replay deterministic. Lazy clocks use the nominal time of the tick being
+// caught up on. Members index the latent signal by ticks elapsed since the
+// first tick of the group, so members created later, e.g. by a reload, stay
+// aligned with the group.
+func (c *Context) latentIndex(clk clock.Clock, interval time.Duration, group string) func() uint64 {
+	if _, ok := clk.(*ManualClock); ok {
+		var ticks uint64
+		return func() uint64 {
+			ticks++
+			return ticks - 1
+		}
+	}
+	if lazy, ok := clk.(*LazyClock); ok {
+		tick := lazy.Due() // Ticks due before the source was created
+		return func() uint64 {
+			tick++
+			at := lazy.Started().Add(time.Duration(tick) * lazy.Interval())
+			epoch, _ := c.latentEpochs.LoadOrStore(group, at)
+			return uint64(math.Round(float64(at.Sub(epoch.(time.Time))) / float64(interval)))
+		}
+	}
+	return func() uint64 {
+		now := time.Now()
+		epoch, _ := c.latentEpochs.LoadOrStore(group, now)
+		return uint64(math.Round(float64(now.Sub(epoch.(time.Time))) / float64(interval)))
+	}
+}
+
+// latentSignal returns the standard normal latent value of group at index.
+// It depends only on the seed, group, and index, so all members observe
+// the same value.
+func (c *Context) latentSignal(group string, index uint64) float64 {
+	h := fnv.New64a()
+	h.Write([]byte("latent:" + group))
+
+	return rand.New(rand.NewPCG(c.seed^h.Sum64(), index)).NormFloat64()
+}
+
+// normal draws a standard normal value from rng (Box-Muller).
+func normal(rng RNG) float64 {
+	const resolution = 1 << 53
+	u1 := (float64(rng.IntN(resolution)) + 0.5) / resolution
+	u2 := float64(rng.IntN(resolution)) / resolution
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"log/slog"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/simv/transform"
+)
+
+// recoveredPanics counts panics contained by guards.
+var recoveredPanics atomic.Int64
+
+// disableOnPanic stops a series after its first panic.
+var disableOnPanic atomic.Bool
+
+// ConfigureRecovery applies panic handling settings to all guards.
+func ConfigureRecovery(cfg config.PanicConfig) {
+	disableOnPanic.Store(cfg.DisableSeries)
+}
+
+// RecoveredPanics returns the number of panics contained so far.
+func RecoveredPanics() int64 {
+	return recoveredPanics.Load()
+}
+
+// Guard contains panics raised while generating or reading a series,
+// so a single faulty series cannot terminate the process.
+type Guard struct {
+	series   string
+	disabled atomic.Bool
+}
+
+// NewGuard creates a guard for the identified series.
+func NewGuard(series string) *Guard {
+	return &Guard{series: series}
+}
+
+// Do runs fn and recovers a panic raised by it.
+// Returns false if fn panicked or the series is disabled.
+func (g *Guard) Do(stage string, fn func()) (ok bool) {
+	if g.disabled.Load() {
+		return false
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			recoveredPanics.Add(1)
+			disable := disableOnPanic.Load()
+			if disable {
+				g.disabled.Store(true)
+			}
+
+			slog.Error("recovered panic",
+				"series", g.series,
+				"stage", stage,
+				"panic", r,
+				"disabled", disable,
+				"stack", string(debug.Stack()))
+			ok = false
+		}
+	}()
+
+	fn()
+	return true
+}
+
+// Disabled reports whether the series was disabled after a panic.
+func (g *Guard) Disabled() bool {
+	return g.disabled.Load()
+}
+
+// guardedTransform keeps the previous state when a transform panics.
+type guardedTransform struct {
+	inner transform.Transformation[int]
+	guard *Guard
+}
+
+// Apply runs the wrapped transform under the guard.
+func (t guardedTransform) Apply(incoming int, state transform.State[int]) int {
+	result := state.GetState()
+	t.guard.Do("transform:"+t.inner.Name(), func() {
+		result = t.inner.Apply(incoming, state)
+	})
+	return result
+}
+
+// Name returns the wrapped transform identifier.
+func (t guardedTransform) Name() string {
+	return t.inner.Name()
+}
@@ -0,0 +1,125 @@
+package simulation
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/simv/clock"
+)
+
+// nominalClock is a clock whose ticks stand for their nominal interval
+// rather than the wall time between them, keeping generation
+// deterministic when ticks are processed late or out of wall time.
+type nominalClock interface {
+	clock.Clock
+	Interval() time.Duration
+}
+
+// LazyClock counts ticks from elapsed time without ticking in the
+// background. Sources driven by it generate the ticks due since their
+// previous update when a value reading them is read, so series cost
+// nothing between reads and series never read are never generated.
+type LazyClock struct {
+	interval time.Duration
+
+	mu      sync.Mutex
+	started time.Time
+	frozen  uint64 // Ticks due when stopped
+	onStop  []func()
+	stopped bool
+
+	running atomic.Bool
+}
+
+// NewLazyClock creates a lazy clock ticking every interval once started.
+func NewLazyClock(interval time.Duration) *LazyClock {
+	return &LazyClock{interval: interval}
+}
+
+// Subscribe returns a channel that never ticks: lazy clocks only drive
+// sources reading them on demand.
+func (c *LazyClock) Subscribe() <-chan struct{} {
+	return make(chan struct{})
+}
+
+// addTask registers stopped to run once the clock stops. Ticks are not
+// run: sources catch up on read instead.
+func (c *LazyClock) addTask(_, stopped func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onStop = append(c.onStop, stopped)
+}
+
+// Start begins counting ticks.
+func (c *LazyClock) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running.Load() || c.stopped {
+		return
+	}
+	c.started = time.Now()
+	c.running.Store(true)
+	activeClocks.Add(1)
+}
+
+// Stop stops counting ticks. Safe to call multiple times.
+func (c *LazyClock) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+	c.frozen = c.dueLocked()
+	c.stopped = true
+	if c.running.Swap(false) {
+		activeClocks.Add(-1)
+	}
+
+	for _, stopped := range c.onStop {
+		stopped()
+	}
+}
+
+// Interval returns the nominal tick interval.
+func (c *LazyClock) Interval() time.Duration {
+	return c.interval
+}
+
+// Started returns the time the clock started, or the zero time.
+func (c *LazyClock) Started() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.started
+}
+
+// Due returns the number of ticks elapsed since the clock started.
+func (c *LazyClock) Due() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.dueLocked()
+}
+
+// dueLocked returns the ticks due. Must be called with c.mu held.
+func (c *LazyClock) dueLocked() uint64 {
+	if c.stopped {
+		return c.frozen
+	}
+	if c.started.IsZero() {
+		return 0
+	}
+	return uint64(time.Since(c.started) / c.interval)
+}
+
+// Stats returns current clock metrics. The tick count is the number of
+// ticks due, whether or not sources caught up on them.
+func (c *LazyClock) Stats() clock.ClockStats {
+	return clock.ClockStats{
+		TickCount: c.Due(),
+		IsRunning: c.running.Load(),
+		Interval:  c.interval,
+	}
+}
@@ -0,0 +1,119 @@
+package simulation
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/simv/clock"
+)
+
+// ManualClock ticks only when Tick is called.
+// Used for deterministic replay in virtual time.
+type ManualClock struct {
+	interval time.Duration
+
+	mu          sync.Mutex
+	tasks       []func()
+	onStop      []func()
+	subscribers []chan struct{}
+	stopped     bool
+
+	tickCount atomic.Uint64
+	running   atomic.Bool
+}
+
+// NewManualClock creates a manual clock with a nominal interval.
+func NewManualClock(interval time.Duration) *ManualClock {
+	return &ManualClock{interval: interval}
+}
+
+// Subscribe returns a channel receiving each tick.
+// Unlike the periodic clock, every subscriber receives every tick.
+func (c *ManualClock) Subscribe() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan struct{})
+	c.subscribers = append(c.subscribers, ch)
+	return ch
+}
+
+// addTask runs tick on every tick and stopped once the clock stops.
+func (c *ManualClock) addTask(tick, stopped func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tasks = append(c.tasks, tick)
+	c.onStop = append(c.onStop, stopped)
+}
+
+// Start marks the clock as running.
+func (c *ManualClock) Start() {
+	if !c.running.Swap(true) {
+		activeClocks.Add(1)
+	}
+}
+
+// Stop closes all subscriber channels.
+func (c *ManualClock) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+	c.stopped = true
+	if c.running.Swap(false) {
+		activeClocks.Add(-1)
+	}
+
+	for _, stopped := range c.onStop {
+		stopped()
+	}
+	for _, ch := range c.subscribers {
+		close(ch)
+	}
+}
+
+// Tick delivers one tick to every subscriber.
+// Blocks until each subscriber has received it.
+func (c *ManualClock) Tick() {
+	TickManual(c)
+}
+
+// TickManual ticks clocks at once: the sources they drive are updated as
+// one batch on the worker pool. Blocks until every subscriber has received
+// the tick.
+func TickManual(clocks ...*ManualClock) {
+	var tasks []func()
+	for _, c := range clocks {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.stopped {
+			continue
+		}
+		c.tickCount.Add(1)
+
+		for _, ch := range c.subscribers {
+			ch <- struct{}{}
+		}
+		tasks = append(tasks, c.tasks...)
+	}
+	runBatch(tasks)
+}
+
+// Interval returns the nominal tick interval.
+func (c *ManualClock) Interval() time.Duration {
+	return c.interval
+}
+
+// Stats returns current clock metrics.
+func (c *ManualClock) Stats() clock.ClockStats {
+	return clock.ClockStats{
+		TickCount: c.tickCount.Load(),
+		IsRunning: c.running.Load(),
+		Interval:  c.interval,
+	}
+}
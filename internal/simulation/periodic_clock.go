@@ -0,0 +1,203 @@
+package simulation
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/simv/clock"
+)
+
+// batcher is a clock running tasks on every tick, so sources driven by it
+// need no goroutine of their own.
+type batcher interface {
+	clock.Clock
+
+	// addTask runs tick on every tick and stopped once the clock stops.
+	addTask(tick, stopped func())
+}
+
+// tickGroups holds the running periodic clocks by interval.
+var tickGroups = struct {
+	mu     sync.Mutex
+	groups map[time.Duration]*tickGroup
+}{groups: make(map[time.Duration]*tickGroup)}
+
+// tickGroup ticks all running periodic clocks of one interval from a
+// single ticker and runs their tasks as one batch on the worker pool.
+type tickGroup struct {
+	interval time.Duration
+	stop     chan struct{}
+
+	mu     sync.Mutex // Held during a tick
+	clocks []*PeriodicClock
+	tasks  []func() // Reused batch buffer
+}
+
+// PeriodicClock ticks at a fixed interval. Clocks with the same interval
+// share a ticker: they tick in phase, and the sources they drive are
+// updated in batches on a shared worker pool instead of by goroutines per
+// source. Every subscriber receives every tick.
+type PeriodicClock struct {
+	interval time.Duration
+	stop     chan struct{}
+
+	mu          sync.Mutex
+	tasks       []func()
+	stopped     []func()
+	subscribers []chan struct{}
+
+	stopOnce  sync.Once
+	tickCount atomic.Uint64
+	running   atomic.Bool
+}
+
+// NewPeriodicClock creates a clock ticking every interval once started.
+func NewPeriodicClock(interval time.Duration) *PeriodicClock {
+	return &PeriodicClock{
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving each tick.
+func (c *PeriodicClock) Subscribe() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan struct{})
+	c.subscribers = append(c.subscribers, ch)
+	return ch
+}
+
+// addTask runs tick on every tick and stopped once the clock stops.
+func (c *PeriodicClock) addTask(tick, stopped func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tasks = append(c.tasks, tick)
+	c.stopped = append(c.stopped, stopped)
+}
+
+// Start begins ticking in phase with running clocks of the same interval.
+func (c *PeriodicClock) Start() {
+	if !c.running.CompareAndSwap(false, true) {
+		return
+	}
+	activeClocks.Add(1)
+
+	tickGroups.mu.Lock()
+	defer tickGroups.mu.Unlock()
+
+	g, exists := tickGroups.groups[c.interval]
+	if !exists {
+		g = &tickGroup{interval: c.interval, stop: make(chan struct{})}
+		tickGroups.groups[c.interval] = g
+		go g.run()
+	}
+
+	g.mu.Lock()
+	g.clocks = append(g.clocks, c)
+	g.mu.Unlock()
+}
+
+// Stop stops ticking, waiting for a tick in progress, and closes all
+// subscriber channels. Safe to call multiple times.
+func (c *PeriodicClock) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+
+		if c.running.Swap(false) {
+			activeClocks.Add(-1)
+			tickGroups.mu.Lock()
+			if g := tickGroups.groups[c.interval]; g != nil && g.remove(c) {
+				close(g.stop)
+				delete(tickGroups.groups, c.interval)
+			}
+			tickGroups.mu.Unlock()
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, stopped := range c.stopped {
+			stopped()
+		}
+		for _, ch := range c.subscribers {
+			close(ch)
+		}
+	})
+}
+
+// Stats returns current clock metrics.
+func (c *PeriodicClock) Stats() clock.ClockStats {
+	return clock.ClockStats{
+		TickCount: c.tickCount.Load(),
+		IsRunning: c.running.Load(),
+		Interval:  c.interval,
+	}
+}
+
+// appendTasks counts a tick and appends its work to tasks.
+func (c *PeriodicClock) appendTasks(tasks []func()) []func() {
+	c.tickCount.Add(1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tasks = append(tasks, c.tasks...)
+	for _, ch := range c.subscribers {
+		tasks = append(tasks, func() {
+			select {
+			case ch <- struct{}{}:
+			case <-c.stop:
+			}
+		})
+	}
+	return tasks
+}
+
+// run ticks the group until its last clock stops. Ticks are skipped while
+// a tick takes longer than the interval.
+func (g *tickGroup) run() {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			g.tick()
+		case <-g.stop:
+			return
+		}
+	}
+}
+
+// tick runs the tasks of all clocks in the group.
+func (g *tickGroup) tick() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	tasks := g.tasks[:0]
+	for _, c := range g.clocks {
+		tasks = c.appendTasks(tasks)
+	}
+	runBatch(tasks)
+
+	clear(tasks) // Release references of stopped clocks
+	g.tasks = tasks[:0]
+}
+
+// remove drops c from the group after a tick in progress and reports
+// whether the group is empty.
+func (g *tickGroup) remove(c *PeriodicClock) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i, member := range g.clocks {
+		if member == c {
+			g.clocks = append(g.clocks[:i], g.clocks[i+1:]...)
+			break
+		}
+	}
+	return len(g.clocks) == 0
+}
@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minShardSize is the fewest tasks handed to a worker at once. Smaller
+// batches run on the ticking goroutine, where handoff would cost more
+// than the tasks.
+const minShardSize = 256
+
+// shardsPerWorker splits batches finer than the worker count, so workers
+// finishing early pick up remaining shards.
+const shardsPerWorker = 4
+
+// pool runs task shards on a fixed set of worker goroutines shared by all
+// clocks, bounding goroutines regardless of the series count.
+var pool struct {
+	once    sync.Once
+	workers int
+	shards  chan shard
+}
+
+// shard is a slice of a batch run by one worker.
+type shard struct {
+	tasks []func()
+	wg    *sync.WaitGroup
+}
+
+// runBatch runs all tasks of a tick and returns when every task is done.
+// Tasks must not depend on each other: shards run in parallel.
+func runBatch(tasks []func()) {
+	pool.once.Do(func() {
+		pool.workers = runtime.GOMAXPROCS(0)
+		pool.shards = make(chan shard)
+		for range pool.workers {
+			go worker()
+		}
+	})
+
+	n := min(pool.workers*shardsPerWorker, (len(tasks)+minShardSize-1)/minShardSize)
+	if n <= 1 {
+		for _, task := range tasks {
+			task()
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	size := (len(tasks) + n - 1) / n
+	for start := 0; start < len(tasks); start += size {
+		wg.Add(1)
+		pool.shards <- shard{tasks: tasks[start:min(start+size, len(tasks))], wg: &wg}
+	}
+	wg.Wait()
+}
+
+// worker runs shards until the process exits.
+func worker() {
+	for s := range pool.shards {
+		for _, task := range s.tasks {
+			task()
+		}
+		s.wg.Done()
+	}
+}
@@ -0,0 +1,75 @@
+package simulation
+
+import (
+	"slices"
+
+	"github.com/neox5/simv/transform"
+)
+
+// Quantize snaps values to the nearest of a fixed set of levels.
+// Ties resolve to the lower level.
+type Quantize struct {
+	levels []int
+}
+
+// NewQuantize creates a transform that snaps values to the given levels.
+func NewQuantize(levels []int) *Quantize {
+	sorted := slices.Clone(levels)
+	slices.Sort(sorted)
+	return &Quantize{levels: slices.Compact(sorted)}
+}
+
+// Apply returns the level nearest to the incoming value.
+func (q *Quantize) Apply(incoming int, state transform.State[int]) int {
+	i, found := slices.BinarySearch(q.levels, incoming)
+	if found {
+		return incoming
+	}
+	if i == 0 {
+		return q.levels[0]
+	}
+	if i == len(q.levels) {
+		return q.levels[len(q.levels)-1]
+	}
+
+	lower, upper := q.levels[i-1], q.levels[i]
+	if upper-incoming < incoming-lower {
+		return upper
+	}
+	return lower
+}
+
+// Name returns the transform identifier.
+func (q *Quantize) Name() string {
+	return "Quantize"
+}
+
+// Bucketize snaps values to the nearest multiple of a fixed step.
+// Ties resolve toward zero.
+type Bucketize struct {
+	step int
+}
+
+// NewBucketize creates a transform that snaps values to multiples of step.
+func NewBucketize(step int) *Bucketize {
+	return &Bucketize{step: step}
+}
+
+// Apply returns the multiple of step nearest to the incoming value.
+func (b *Bucketize) Apply(incoming int, state transform.State[int]) int {
+	lower := incoming / b.step * b.step
+	remainder := incoming - lower
+	switch {
+	case remainder*2 > b.step:
+		return lower + b.step
+	case remainder*2 < -b.step:
+		return lower - b.step
+	default:
+		return lower
+	}
+}
+
+// Name returns the transform identifier.
+func (b *Bucketize) Name() string {
+	return "Bucketize"
+}
@@ -0,0 +1,13 @@
+package simulation
+
+import (
+	"github.com/neox5/simv/clock"
+)
+
+// NewRandomIntSource creates a source of uniformly distributed integers in
+// [min, max] using rng. Unlike the simv source, the random stream is
+// injected, so it can be derived from a stable source identity.
+// Panics during generation are contained by guard.
+func NewRandomIntSource(clk clock.Clock, min, max int, rng RNG, guard *Guard) *TickSource {
+	return NewTickSource(clk, func() int { return min + rng.IntN(max-min+1) }, guard)
+}
@@ -0,0 +1,88 @@
+package simulation
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/simv/clock"
+)
+
+// rampConfig holds the rate ramp selected at startup.
+var (
+	rampConfig  config.RampConfig
+	rampStarted time.Time // Start of the process-wide ramp reported by RampFactor
+)
+
+// ConfigureRamp applies the rate ramp to rate sources.
+// Must be called before creating sources.
+func ConfigureRamp(cfg config.RampConfig) {
+	rampConfig = cfg
+	rampStarted = time.Now()
+}
+
+// NewRateSource creates a source emitting the increment accrued since its
+// previous tick at a per-second rate drawn from [min, max] on each tick.
+// Increments follow elapsed wall time rather than tick count, so an
+// accumulated counter tracks the rate regardless of clock jitter.
+// Fractions carry over to later ticks. Manual and lazy clocks advance by
+// their nominal interval, keeping virtual time replay and lazy generation
+// deterministic. With a
+// configured ramp, rates are scaled up to their full values over the
+// source's first ramp duration.
+func NewRateSource(clk clock.Clock, min, max int, rng RNG, guard *Guard) (*TickSource, error) {
+	if min < 0 {
+		return nil, fmt.Errorf("rate source min must be >= 0, got %d", min)
+	}
+	if max < min {
+		return nil, fmt.Errorf("rate source max %d is below min %d", max, min)
+	}
+
+	elapsed := wallElapsed()
+	if nominal, ok := clk.(nominalClock); ok {
+		elapsed = func() time.Duration { return nominal.Interval() }
+	}
+
+	ramp := newRamp(rampConfig)
+
+	var carry float64
+	next := func() int {
+		d := elapsed()
+		rate := min + rng.IntN(max-min+1)
+		carry += float64(rate) * ramp(d) * d.Seconds()
+
+		increment := int(carry)
+		carry -= float64(increment)
+		return increment
+	}
+	return NewTickSource(clk, next, guard), nil
+}
+
+// wallElapsed returns a function reporting the wall time since its
+// previous call, or since creation on the first call.
+func wallElapsed() func() time.Duration {
+	last := time.Now()
+	return func() time.Duration {
+		now := time.Now()
+		d := now.Sub(last)
+		last = now
+		return d
+	}
+}
+
+// newRamp returns a function advancing the age of a source by d and
+// reporting the fraction of its rate to emit at that age.
+func newRamp(cfg config.RampConfig) func(d time.Duration) float64 {
+	if !cfg.Enabled() {
+		return func(time.Duration) float64 { return 1 }
+	}
+
+	var age time.Duration
+	return func(d time.Duration) float64 {
+		age += d
+		if age >= cfg.Duration {
+			return 1
+		}
+		return cfg.Start + (1-cfg.Start)*float64(age)/float64(cfg.Duration)
+	}
+}
@@ -0,0 +1,86 @@
+package simulation
+
+import (
+	"sync"
+
+	"github.com/neox5/simv/transform"
+)
+
+// readCursors isolates reset_on_read between consumers of a value.
+// Each consumer holds its own state, fed every source update through the
+// value transforms, so a read resets only the reading consumer's view.
+// Called by the value on every update, under the value lock.
+type readCursors struct {
+	transforms []transform.Transformation[int]
+	resetValue int
+	initial    int // Added to the first read of each consumer
+
+	mu      sync.Mutex
+	state   int // Value state without resets
+	cursors map[string]*int
+}
+
+// newReadCursors creates cursors replaying transforms, resetting to resetValue.
+// Consumers start from initial.
+func newReadCursors(transforms []transform.Transformation[int], resetValue, initial int) *readCursors {
+	return &readCursors{
+		transforms: transforms,
+		resetValue: resetValue,
+		initial:    initial,
+		cursors:    make(map[string]*int),
+	}
+}
+
+// read returns the consumer's value and resets it. A consumer's first read
+// observes everything since the value started.
+func (c *readCursors) read(consumer string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cursor, exists := c.cursors[consumer]
+	if !exists {
+		state := c.initial + c.state
+		cursor = &state
+		c.cursors[consumer] = cursor
+	}
+
+	val := *cursor
+	*cursor = c.resetValue
+	return val
+}
+
+// OnInput applies a source update to every consumer's state.
+func (c *readCursors) OnInput(input int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, cursor := range c.cursors {
+		transformed := input
+		for _, t := range c.transforms {
+			transformed = t.Apply(transformed, cursorState(*cursor))
+		}
+		*cursor = transformed
+	}
+}
+
+// AfterUpdate records the state of the value itself.
+func (c *readCursors) AfterUpdate(finalState int) {
+	c.mu.Lock()
+	c.state = finalState
+	c.mu.Unlock()
+}
+
+// release drops the state of all consumers.
+func (c *readCursors) release() {
+	c.mu.Lock()
+	clear(c.cursors)
+	c.mu.Unlock()
+}
+
+// cursorState exposes a consumer's state to transforms.
+type cursorState int
+
+// GetState returns the consumer's state.
+func (s cursorState) GetState() int {
+	return int(s)
+}
@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/neox5/simv/transform"
+)
+
+// newResetValue returns an accumulating value reset on read to resetValue.
+func newResetValue(resetValue, initial int) *ValueWrapper {
+	w := &ValueWrapper{
+		Guard:      NewGuard("test"),
+		initial:    initial,
+		transforms: []transform.Transformation[int]{transform.NewAccumulate[int]()},
+	}
+	w.cursors = newReadCursors(w.transforms, resetValue, initial)
+	return w
+}
+
+func TestReadCursorsIsolateConsumers(t *testing.T) {
+	w := newResetValue(0, 0)
+
+	w.update(5)
+	if got := w.Read("prometheus"); got != 5 {
+		t.Fatalf("prometheus first read = %d, want 5", got)
+	}
+
+	w.update(3)
+	if got := w.Read("prometheus"); got != 3 {
+		t.Errorf("prometheus second read = %d, want delta 3", got)
+	}
+
+	// A consumer's first read observes everything since the value started
+	if got := w.Read("otel"); got != 8 {
+		t.Errorf("otel first read = %d, want 8", got)
+	}
+
+	// Reads reset only the reading consumer
+	w.update(2)
+	if got := w.Read("otel"); got != 2 {
+		t.Errorf("otel second read = %d, want 2", got)
+	}
+	if got := w.Read("prometheus"); got != 2 {
+		t.Errorf("prometheus third read = %d, want 2", got)
+	}
+
+	// The value itself is never reset
+	if got := w.State(); got != 10 {
+		t.Errorf("state = %d, want 10", got)
+	}
+}
+
+func TestReadCursorsResetValueAndInitial(t *testing.T) {
+	w := newResetValue(100, 1000)
+
+	w.update(5)
+	if got := w.Read("prometheus"); got != 1005 {
+		t.Fatalf("first read = %d, want initial plus update 1005", got)
+	}
+
+	w.update(5)
+	if got := w.Read("prometheus"); got != 105 {
+		t.Errorf("second read = %d, want reset value plus update 105", got)
+	}
+	if got := w.Read("prometheus"); got != 100 {
+		t.Errorf("read without update = %d, want reset value 100", got)
+	}
+}
+
+func TestDetachReleasesConsumers(t *testing.T) {
+	w := newResetValue(0, 0)
+
+	w.update(5)
+	w.Read("prometheus")
+	w.Read("otel")
+
+	w.Detach()
+	if n := len(w.cursors.cursors); n != 0 {
+		t.Errorf("cursors after detach = %d, want 0", n)
+	}
+
+	// Updates after detaching are discarded
+	w.update(7)
+	if got := w.State(); got != 5 {
+		t.Errorf("state after detach = %d, want 5", got)
+	}
+	if n := len(w.cursors.cursors); n != 0 {
+		t.Errorf("cursors after update = %d, want 0", n)
+	}
+}
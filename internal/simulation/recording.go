@@ -0,0 +1,139 @@
+package simulation
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/simv/clock"
+	"github.com/neox5/simv/source"
+)
+
+// recordedUpdate is a single source update in a recording.
+type recordedUpdate struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"` // Source key
+	Value  int       `json:"value"`
+}
+
+// activeRecorder records sources created while set.
+var activeRecorder atomic.Pointer[recorder]
+
+// activePlayer replays sources created while set.
+var activePlayer atomic.Pointer[player]
+
+// recorder writes the updates of all recorded sources as JSON lines.
+type recorder struct {
+	mu  sync.Mutex
+	out *bufio.Writer
+	enc *json.Encoder
+	err error // First write error
+}
+
+// StartRecording records every update of sources created from now on to
+// w, one JSON object per line.
+func StartRecording(w io.Writer) {
+	out := bufio.NewWriter(w)
+	activeRecorder.Store(&recorder{out: out, enc: json.NewEncoder(out)})
+}
+
+// StopRecording stops recording and flushes the recording. Returns the
+// first write error.
+func StopRecording() error {
+	r := activeRecorder.Swap(nil)
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	return r.out.Flush()
+}
+
+// record writes every update of src under key.
+func (r *recorder) record(key string, src source.Publisher[int]) {
+	write := func(value int) {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = r.enc.Encode(recordedUpdate{Time: time.Now(), Source: key, Value: value})
+		}
+		r.mu.Unlock()
+	}
+
+	if fp, ok := src.(funcPublisher); ok {
+		fp.subscribeFunc(write)
+		return
+	}
+	updates := src.Subscribe()
+	go func() {
+		for value := range updates {
+			write(value)
+		}
+	}()
+}
+
+// player holds the remaining recorded updates per source key.
+type player struct {
+	mu      sync.Mutex
+	updates map[string][]int
+}
+
+// LoadReplay replaces sources created from now on by the updates recorded
+// in r. A replayed source emits its next recorded update on every tick of
+// its clock, regardless of its type and random stream.
+func LoadReplay(r io.Reader) error {
+	p := &player{updates: make(map[string][]int)}
+
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var u recordedUpdate
+		if err := dec.Decode(&u); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("recording update %d: %w", line, err)
+		}
+		p.updates[u.Source] = append(p.updates[u.Source], u.Value)
+	}
+
+	activePlayer.Store(p)
+	return nil
+}
+
+// source creates a source replaying the updates recorded under key.
+// Sources recreated under the same key, e.g. by a reload, continue where
+// the previous one stopped.
+func (p *player) source(key string, clk clock.Clock) *TickSource {
+	p.mu.Lock()
+	_, recorded := p.updates[key]
+	p.mu.Unlock()
+	if !recorded {
+		slog.Warn("no recorded updates for source", "source", key)
+	}
+
+	var exhausted sync.Once
+	next := func() (int, bool) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		updates := p.updates[key]
+		if len(updates) == 0 {
+			if recorded {
+				exhausted.Do(func() { slog.Warn("recording exhausted", "source", key) })
+			}
+			return 0, false
+		}
+		p.updates[key] = updates[1:]
+		return updates[0], true
+	}
+	return newTickSource(clk, next, NewGuard(key))
+}
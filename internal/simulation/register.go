@@ -0,0 +1,60 @@
+package simulation
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/simv/clock"
+	"github.com/neox5/simv/source"
+	"github.com/neox5/simv/transform"
+)
+
+// typesMu guards source and transform registrations against concurrent
+// registration by external code.
+var typesMu sync.RWMutex
+
+// RegisterSourceType adds a source type referenced by name in config.
+// Built-in and previously registered names cannot be replaced. Sources are
+// created with the context of the simulation using them.
+func RegisterSourceType(
+	info TypeInfo,
+	create func(sim *Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error),
+) error {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	if err := checkTypeName(info.Name, sourceTypes, func(t sourceType) TypeInfo { return t.TypeInfo }); err != nil {
+		return fmt.Errorf("source %w", err)
+	}
+	sourceTypes = append(sourceTypes, sourceType{TypeInfo: info, create: create})
+	return nil
+}
+
+// RegisterTransformType adds a transform type referenced by name in config.
+// Built-in and previously registered names cannot be replaced.
+func RegisterTransformType(
+	info TypeInfo,
+	create func(cfg config.TransformConfig) (transform.Transformation[int], error),
+) error {
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	if err := checkTypeName(info.Name, transformTypes, func(t transformType) TypeInfo { return t.TypeInfo }); err != nil {
+		return fmt.Errorf("transform %w", err)
+	}
+	transformTypes = append(transformTypes, transformType{TypeInfo: info, create: create})
+	return nil
+}
+
+// checkTypeName rejects empty and already registered names.
+func checkTypeName[T any](name string, types []T, info func(T) TypeInfo) error {
+	if name == "" {
+		return fmt.Errorf("type name cannot be empty")
+	}
+	if slices.ContainsFunc(types, func(t T) bool { return info(t).Name == name }) {
+		return fmt.Errorf("type %q already registered", name)
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package simulation
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/simv/clock"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// dumpSeries holds the recorded samples of one series in file order.
+type dumpSeries struct {
+	name   string
+	labels map[string]string
+	values []float64
+}
+
+// dump holds all series of a recorded file in first-seen order.
+type dump struct {
+	series []*dumpSeries
+}
+
+// dumps caches parsed files, shared by all replay sources reading them.
+var dumps = struct {
+	mu    sync.Mutex
+	files map[string]*dump
+}{files: make(map[string]*dump)}
+
+// NewReplaySource creates a source emitting the samples of a recorded
+// series, one per tick. After the last sample it holds the last value, or
+// restarts at the first with cfg.Loop.
+func NewReplaySource(clk clock.Clock, cfg *config.ReplayConfig, guard *Guard) (*TickSource, error) {
+	if cfg == nil || cfg.File == "" || cfg.Metric == "" {
+		return nil, errors.New("replay source requires replay.file and replay.metric")
+	}
+
+	d, err := loadDump(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	values := d.find(cfg.Metric, cfg.Labels)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("replay: no samples for %s%v in %s", cfg.Metric, cfg.Labels, cfg.File)
+	}
+
+	i := 0
+	next := func() int {
+		v := values[i]
+		switch {
+		case i+1 < len(values):
+			i++
+		case cfg.Loop:
+			i = 0
+		}
+		return int(math.Round(v * cfg.Scale))
+	}
+	return NewTickSource(clk, next, guard), nil
+}
+
+// find returns the samples of the first series named name whose labels
+// include labels.
+func (d *dump) find(name string, labels map[string]string) []float64 {
+	for _, s := range d.series {
+		if s.name == name && hasLabels(s.labels, labels) {
+			return s.values
+		}
+	}
+	return nil
+}
+
+// hasLabels reports whether want is a subset of have.
+func hasLabels(have, want map[string]string) bool {
+	for k, v := range want {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// loadDump parses path once. OTLP JSON lines are recognized by a leading
+// '{'; anything else is read as Prometheus text exposition.
+func loadDump(path string) (*dump, error) {
+	dumps.mu.Lock()
+	defer dumps.mu.Unlock()
+
+	if d, exists := dumps.files[path]; exists {
+		return d, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+
+	d := &dump{}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		err = d.parseOTLP(data)
+	} else {
+		err = d.parseExposition(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay: %s: %w", path, err)
+	}
+
+	dumps.files[path] = d
+	return d, nil
+}
+
+// add appends a sample to its series.
+func (d *dump) add(index map[string]*dumpSeries, name string, labels map[string]string, value float64) {
+	key := name + "\x00" + labelKey(labels)
+	s, exists := index[key]
+	if !exists {
+		s = &dumpSeries{name: name, labels: labels}
+		index[key] = s
+		d.series = append(d.series, s)
+	}
+	s.values = append(s.values, value)
+}
+
+// labelKey returns a canonical form of labels.
+func labelKey(labels map[string]string) string {
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// parseExposition reads samples from one or more concatenated Prometheus
+// text expositions. Comments, timestamps, and blank lines are ignored.
+func (d *dump) parseExposition(data []byte) error {
+	index := make(map[string]*dumpSeries)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, labels, value, err := parseSample(text)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		d.add(index, name, labels, value)
+	}
+	return scanner.Err()
+}
+
+// parseSample parses an exposition sample line: name{labels} value [timestamp].
+func parseSample(line string) (string, map[string]string, float64, error) {
+	end := strings.IndexAny(line, "{ \t")
+	if end <= 0 {
+		return "", nil, 0, fmt.Errorf("invalid sample %q", line)
+	}
+	name, rest := line[:end], line[end:]
+
+	labels := make(map[string]string)
+	if strings.HasPrefix(rest, "{") {
+		rest = rest[1:]
+		for {
+			rest = strings.TrimLeft(rest, " \t,")
+			if strings.HasPrefix(rest, "}") {
+				rest = rest[1:]
+				break
+			}
+			eq := strings.Index(rest, "=\"")
+			if eq <= 0 {
+				return "", nil, 0, fmt.Errorf("invalid labels in %q", line)
+			}
+			label := strings.TrimSpace(rest[:eq])
+			value, n, err := unquoteLabel(rest[eq+2:])
+			if err != nil {
+				return "", nil, 0, fmt.Errorf("label %s in %q: %w", label, line, err)
+			}
+			labels[label] = value
+			rest = rest[eq+2+n:]
+		}
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, fmt.Errorf("missing value in %q", line)
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, fmt.Errorf("invalid value in %q", line)
+	}
+	return name, labels, value, nil
+}
+
+// unquoteLabel reads an escaped label value up to its closing quote.
+// Returns the value and the number of bytes consumed including the quote.
+func unquoteLabel(s string) (string, int, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), i + 1, nil
+		case '\\':
+			i++
+			if i == len(s) {
+				return "", 0, errors.New("unterminated escape")
+			}
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, errors.New("unterminated value")
+}
+
+// parseOTLP reads sum and gauge data points from OTLP JSON lines, one
+// export request per line.
+func (d *dump) parseOTLP(data []byte) error {
+	index := make(map[string]*dumpSeries)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 64<<20)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var request collectorpb.ExportMetricsServiceRequest
+		if err := protojson.Unmarshal(scanner.Bytes(), &request); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		for _, rm := range request.ResourceMetrics {
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					var points []*metricspb.NumberDataPoint
+					switch data := m.Data.(type) {
+					case *metricspb.Metric_Sum:
+						points = data.Sum.DataPoints
+					case *metricspb.Metric_Gauge:
+						points = data.Gauge.DataPoints
+					}
+					for _, dp := range points {
+						value := dp.GetAsDouble()
+						if v, ok := dp.Value.(*metricspb.NumberDataPoint_AsInt); ok {
+							value = float64(v.AsInt)
+						}
+						d.add(index, m.Name, attributeLabels(dp.Attributes), value)
+					}
+				}
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// attributeLabels converts OTLP attributes to string labels.
+func attributeLabels(attributes []*commonpb.KeyValue) map[string]string {
+	labels := make(map[string]string, len(attributes))
+	for _, kv := range attributes {
+		switch v := kv.Value.GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			labels[kv.Key] = v.StringValue
+		case *commonpb.AnyValue_IntValue:
+			labels[kv.Key] = strconv.FormatInt(v.IntValue, 10)
+		case *commonpb.AnyValue_BoolValue:
+			labels[kv.Key] = strconv.FormatBool(v.BoolValue)
+		case *commonpb.AnyValue_DoubleValue:
+			labels[kv.Key] = strconv.FormatFloat(v.DoubleValue, 'g', -1, 64)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,103 @@
+package simulation
+
+import (
+	"bufio"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// RNG generates random integers for sources.
+type RNG interface {
+	// IntN returns a value in [0, n).
+	IntN(n int) int
+}
+
+// NewDerivedRand returns a random number generator seeded from the master
+// seed and a stable key. The stream does not depend on creation order, so
+// adding or reordering metrics leaves other series intact.
+func (c *Context) NewDerivedRand(key string) RNG {
+	master := c.seed
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	stream := h.Sum64()
+
+	switch c.rng.Type {
+	case config.RNGTypeChaCha8:
+		var chachaSeed [32]byte
+		binary.LittleEndian.PutUint64(chachaSeed[0:], master)
+		binary.LittleEndian.PutUint64(chachaSeed[8:], stream)
+		return rand.New(rand.NewChaCha8(chachaSeed))
+	case config.RNGTypeCrypto:
+		return rand.New(cryptoSource{})
+	case config.RNGTypeSequence:
+		// Start each stream at a stable offset
+		offset := rand.New(rand.NewPCG(master, stream)).IntN(len(c.sequence))
+		return &sequenceRNG{values: c.sequence, next: offset}
+	default:
+		return rand.New(rand.NewPCG(master, stream))
+	}
+}
+
+// cryptoSource reads from the operating system's secure random source.
+type cryptoSource struct{}
+
+// Uint64 returns a cryptographically secure random value.
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	crand.Read(b[:])
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// sequenceRNG replays a fixed sequence of values, wrapping at the end.
+type sequenceRNG struct {
+	values []uint64
+	next   int
+}
+
+// IntN returns the next sequence value reduced modulo n.
+func (s *sequenceRNG) IntN(n int) int {
+	v := s.values[s.next]
+	s.next = (s.next + 1) % len(s.values)
+	return int(v % uint64(n))
+}
+
+// loadSequence reads newline-delimited non-negative integers.
+// Blank lines and lines starting with # are ignored.
+func loadSequence(path string) ([]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rng sequence: %w", err)
+	}
+	defer f.Close()
+
+	var values []uint64
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		v, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rng sequence value %q", path, line, text)
+		}
+		values = append(values, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rng sequence: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("rng sequence %s contains no values", path)
+	}
+
+	return values, nil
+}
@@ -0,0 +1,97 @@
+package simulation
+
+import (
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// sheddingConfig holds the load shedding selected at startup.
+var sheddingConfig config.LoadSheddingConfig
+
+// shed is the fraction of source updates currently skipped, stored as
+// float64 bits (0: no shedding).
+var shed atomic.Uint64
+
+// shedObservations counts source updates skipped by load shedding.
+var shedObservations atomic.Int64
+
+// sheddingMu serializes adjustments.
+var sheddingMu sync.Mutex
+
+// ConfigureShedding applies load shedding to all sources.
+// Must be called before creating sources.
+func ConfigureShedding(cfg config.LoadSheddingConfig) {
+	sheddingConfig = cfg
+	shed.Store(0)
+
+	if cfg.Enabled {
+		slog.Info("load shedding configured",
+			"step", cfg.Step,
+			"min_factor", cfg.MinFactor,
+			"recover_below", cfg.RecoverBelow)
+	}
+}
+
+// AdjustShedding updates the shed fraction from a resource monitor sample:
+// saturation sheds one more step, utilization below the recovery threshold
+// restores one step, and anything in between holds the current rate.
+func AdjustShedding(utilization float64, saturated bool) {
+	if !sheddingConfig.Enabled {
+		return
+	}
+
+	sheddingMu.Lock()
+	defer sheddingMu.Unlock()
+
+	factor := ShedFactor()
+	next := factor
+	switch {
+	case saturated:
+		next = max(sheddingConfig.MinFactor, factor-sheddingConfig.Step)
+	case utilization < sheddingConfig.RecoverBelow:
+		next = min(1, factor+sheddingConfig.Step)
+	}
+	if next == factor {
+		return
+	}
+	shed.Store(math.Float64bits(1 - next))
+
+	if next < factor {
+		slog.Warn("shedding load", "factor", next, "utilization", utilization)
+	} else {
+		slog.Info("restoring load", "factor", next, "utilization", utilization)
+	}
+}
+
+// ShedFactor returns the fraction of source updates currently generated.
+func ShedFactor() float64 {
+	return 1 - math.Float64frombits(shed.Load())
+}
+
+// ShedObservations returns the number of source updates skipped by load
+// shedding so far.
+func ShedObservations() int64 {
+	return shedObservations.Load()
+}
+
+// allowShed reports whether a source generates its current tick. Each
+// source keeps the factor's share of its ticks, spread evenly by carrying
+// the fraction across ticks in carry.
+func allowShed(carry *float64) bool {
+	factor := ShedFactor()
+	if factor >= 1 {
+		return true
+	}
+
+	*carry += factor
+	if *carry >= 1 {
+		*carry--
+		return true
+	}
+	shedObservations.Add(1)
+	return false
+}
@@ -9,11 +9,27 @@ import (
 )
 
 // CreateSource creates a source from configuration.
-func CreateSource(cfg config.SourceConfig, clk clock.Clock) (source.Publisher[int], error) {
-	switch cfg.Type {
-	case "random_int":
-		return source.NewRandomIntSource(clk, cfg.Min, cfg.Max), nil
-	default:
+// The key identifies the source across runs and selects its random stream.
+// While a replay is loaded, the source replays its recorded updates instead;
+// while recording, its updates are recorded.
+func (c *Context) CreateSource(cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error) {
+	typesMu.RLock()
+	t, err := lookupType(sourceTypes, cfg.Type, func(t sourceType) TypeInfo { return t.TypeInfo })
+	typesMu.RUnlock()
+	if err != nil {
 		return nil, fmt.Errorf("unknown source type: %s", cfg.Type)
 	}
+
+	if p := activePlayer.Load(); p != nil {
+		return p.source(key, clk), nil
+	}
+
+	src, err := t.create(c, cfg, clk, key)
+	if err != nil {
+		return nil, err
+	}
+	if r := activeRecorder.Load(); r != nil {
+		r.record(key, src)
+	}
+	return src, nil
 }
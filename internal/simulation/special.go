@@ -0,0 +1,83 @@
+package simulation
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// specialValues decides which updates of a value produce special samples.
+// Decisions depend only on the seed, the series, and the update index, so
+// every consumer observes the same special samples, and virtual time runs
+// are reproducible.
+type specialValues struct {
+	rules  []config.SpecialValueConfig
+	master uint64
+	stream uint64
+}
+
+// newSpecialValues creates the decisions for series, or nil without rules.
+func newSpecialValues(rules []config.SpecialValueConfig, master uint64, series string) *specialValues {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte("special:" + series))
+
+	return &specialValues{rules: rules, master: master, stream: h.Sum64()}
+}
+
+// at returns the special type of the sample after update, or "" for a
+// regular sample. The first matching rule wins. Samples before the first
+// update are regular.
+func (s *specialValues) at(update uint64) config.SpecialValueType {
+	if s == nil || update == 0 {
+		return ""
+	}
+
+	rng := rand.New(rand.NewPCG(s.master^s.stream, update))
+
+	for _, rule := range s.rules {
+		if rule.Every > 0 {
+			if update%uint64(rule.Every) == 0 {
+				return rule.Type
+			}
+			continue
+		}
+		if rng.Float64() < rule.Probability {
+			return rule.Type
+		}
+	}
+	return ""
+}
+
+// Special returns the special type of the current sample, or "" if the
+// sample is a regular number.
+func (w *ValueWrapper) Special() config.SpecialValueType {
+	if w.specials == nil {
+		return ""
+	}
+	w.sync()
+	return w.specials.at(w.Updates())
+}
+
+// Sample returns the value observed by consumer as a float, or false if
+// the current sample is missing. Special samples leave reset_on_read
+// state unconsumed, so the next regular read observes the full delta.
+func (w *ValueWrapper) Sample(consumer string) (float64, bool) {
+	switch w.Special() {
+	case config.SpecialNaN:
+		return math.NaN(), true
+	case config.SpecialInf:
+		return math.Inf(1), true
+	case config.SpecialNegInf:
+		return math.Inf(-1), true
+	case config.SpecialMissing:
+		return 0, false
+	default:
+		return float64(w.Read(consumer)), true
+	}
+}
@@ -0,0 +1,176 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/neox5/simv/source"
+)
+
+// SplitSource distributes each update of a source across parts in
+// proportion to their weights. The parts of every update sum to the update,
+// so series fed by the parts stay consistent with the total. Fractional
+// shares carry over to later updates.
+type SplitSource struct {
+	source  source.Publisher[int]
+	weights []int
+	total   int
+	carry   []float64 // Owed fraction per part, in (-1, 1), summing to zero
+
+	mu    sync.Mutex
+	funcs [][]func(int) // Synchronous subscribers per part
+	parts [][]chan int  // Subscribers per part
+
+	generationCount atomic.Uint64
+}
+
+// NewSplitSource creates a split of src into one part per weight.
+// Subscribes to src immediately; updates before a part is subscribed are
+// dropped for that part.
+func NewSplitSource(src source.Publisher[int], weights []int) *SplitSource {
+	s := &SplitSource{
+		source:  src,
+		weights: weights,
+		carry:   make([]float64, len(weights)),
+		funcs:   make([][]func(int), len(weights)),
+		parts:   make([][]chan int, len(weights)),
+	}
+	for _, w := range weights {
+		s.total += w
+	}
+	if fp, ok := src.(funcPublisher); ok {
+		fp.subscribeFunc(s.update)
+	} else {
+		go s.run(src.Subscribe())
+	}
+	return s
+}
+
+// Part returns the publisher of the part at index.
+func (s *SplitSource) Part(index int) source.Publisher[int] {
+	return splitPart{split: s, index: index}
+}
+
+// run splits every update received from a channel.
+func (s *SplitSource) run(updates <-chan int) {
+	for update := range updates {
+		s.update(update)
+	}
+
+	s.mu.Lock()
+	for _, subs := range s.parts {
+		for _, ch := range subs {
+			close(ch)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// update splits an update and fans the shares out to part subscribers.
+func (s *SplitSource) update(update int) {
+	shares := s.allocate(update)
+	s.generationCount.Add(1)
+
+	s.mu.Lock()
+	funcs, parts := s.funcs, s.parts
+	s.mu.Unlock()
+
+	for i, subs := range funcs {
+		for _, fn := range subs {
+			fn(shares[i])
+		}
+	}
+	for i, subs := range parts {
+		for _, ch := range subs {
+			ch <- shares[i]
+		}
+	}
+}
+
+// allocate divides update by weight. Each part gets the whole units of its
+// exact share plus owed fractions; the units left over go to the parts owed
+// the most, ties to the lower index.
+func (s *SplitSource) allocate(update int) []int {
+	shares := make([]int, len(s.weights))
+	left := update
+	for i, w := range s.weights {
+		exact := s.carry[i] + float64(update)*float64(w)/float64(s.total)
+		shares[i] = int(math.Floor(exact))
+		s.carry[i] = exact - float64(shares[i])
+		left -= shares[i]
+	}
+
+	order := make([]int, len(s.weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return s.carry[order[a]] > s.carry[order[b]] })
+	for _, i := range order[:max(0, min(left, len(order)))] {
+		shares[i]++
+		s.carry[i]--
+	}
+
+	return shares
+}
+
+// subscribe adds a subscriber to the part at index.
+func (s *SplitSource) subscribe(index int) <-chan int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan int)
+	parts := make([][]chan int, len(s.parts))
+	copy(parts, s.parts)
+	parts[index] = append(parts[index][:len(parts[index]):len(parts[index])], ch)
+	s.parts = parts
+	return ch
+}
+
+// subscribeFunc calls fn with the share of the part at index.
+func (s *SplitSource) subscribeFunc(index int, fn func(int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	funcs := make([][]func(int), len(s.funcs))
+	copy(funcs, s.funcs)
+	funcs[index] = append(funcs[index][:len(funcs[index]):len(funcs[index])], fn)
+	s.funcs = funcs
+}
+
+// splitPart publishes one part of a split.
+type splitPart struct {
+	split *SplitSource
+	index int
+}
+
+// Subscribe returns a channel receiving the part's share of each update.
+func (p splitPart) Subscribe() <-chan int {
+	return p.split.subscribe(p.index)
+}
+
+// subscribeFunc calls fn with the part's share of each update.
+func (p splitPart) subscribeFunc(fn func(int)) {
+	p.split.subscribeFunc(p.index, fn)
+}
+
+// catchUp generates the updates of a lazily generated source, updating
+// all parts.
+func (p splitPart) catchUp() {
+	if lazy, ok := p.split.source.(lazyPublisher); ok {
+		lazy.catchUp()
+	}
+}
+
+// Stats returns the split's update count and the part's subscribers.
+func (p splitPart) Stats() source.SourceStats {
+	p.split.mu.Lock()
+	subCount := len(p.split.funcs[p.index]) + len(p.split.parts[p.index])
+	p.split.mu.Unlock()
+
+	return source.SourceStats{
+		GenerationCount: p.split.generationCount.Load(),
+		SubscriberCount: subCount,
+	}
+}
@@ -0,0 +1,44 @@
+package simulation
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Generation statistics across all sources and clocks, reported by the
+// exporters' internal metrics.
+var (
+	generatedValues     atomic.Int64
+	droppedObservations atomic.Int64
+	activeClocks        atomic.Int64
+)
+
+// GeneratedValues returns the number of values generated by all sources so far.
+func GeneratedValues() int64 {
+	return generatedValues.Load()
+}
+
+// DroppedObservations returns the number of source updates skipped because
+// their series was disabled after a panic.
+func DroppedObservations() int64 {
+	return droppedObservations.Load()
+}
+
+// ActiveClocks returns the number of running clocks.
+func ActiveClocks() int64 {
+	return activeClocks.Load()
+}
+
+// RampFactor returns the fraction of the configured source rates emitted
+// by the startup ramp at the current time: 1 without a ramp or once it is
+// complete.
+func RampFactor() float64 {
+	if !rampConfig.Enabled() {
+		return 1
+	}
+	age := time.Since(rampStarted)
+	if age >= rampConfig.Duration {
+		return 1
+	}
+	return rampConfig.Start + (1-rampConfig.Start)*float64(age)/float64(rampConfig.Duration)
+}
@@ -0,0 +1,122 @@
+package simulation
+
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// observationLimit and pointLimit hold the throttle selected at startup
+// (nil: unlimited).
+var (
+	observationLimit *tokenBucket
+	pointLimit       *tokenBucket
+)
+
+// throttledObservations and throttledPoints count drops by the throttle.
+var (
+	throttledObservations atomic.Int64
+	throttledPoints       atomic.Int64
+)
+
+// ConfigureThrottle applies the throttle to all sources and exporters.
+// Must be called before creating sources.
+func ConfigureThrottle(cfg config.ThrottleConfig) {
+	backpressure := cfg.Policy == config.ThrottlePolicyBackpressure
+	observationLimit = newTokenBucket(cfg.MaxObservationsPerSecond, backpressure)
+	pointLimit = newTokenBucket(cfg.MaxPointsPerSecond, backpressure)
+
+	if cfg.Enabled() {
+		slog.Info("throttle configured",
+			"max_observations_per_second", cfg.MaxObservationsPerSecond,
+			"max_points_per_second", cfg.MaxPointsPerSecond,
+			"policy", cfg.Policy)
+	}
+}
+
+// AllowObservation reports whether a source may generate a value now.
+// Blocks until it may with the backpressure policy.
+func AllowObservation() bool {
+	if !observationLimit.take() {
+		throttledObservations.Add(1)
+		return false
+	}
+	return true
+}
+
+// AllowPoint reports whether an exporter may emit a data point now.
+// Blocks until it may with the backpressure policy.
+func AllowPoint() bool {
+	if !pointLimit.take() {
+		throttledPoints.Add(1)
+		return false
+	}
+	return true
+}
+
+// ThrottledObservations returns the number of source updates dropped so far.
+func ThrottledObservations() int64 {
+	return throttledObservations.Load()
+}
+
+// ThrottledPoints returns the number of data points dropped so far.
+func ThrottledPoints() int64 {
+	return throttledPoints.Load()
+}
+
+// tokenBucket limits events to a rate per second, allowing bursts of up
+// to one second's worth. A nil bucket allows every event.
+type tokenBucket struct {
+	mu           sync.Mutex
+	rate         float64
+	tokens       float64
+	last         time.Time
+	backpressure bool // Wait for a token instead of refusing
+}
+
+// newTokenBucket creates a full bucket, or nil for a rate of 0.
+func newTokenBucket(rate int, backpressure bool) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:         float64(rate),
+		tokens:       float64(rate),
+		last:         time.Now(),
+		backpressure: backpressure,
+	}
+}
+
+// take consumes a token. Without a token available it refuses, or with
+// backpressure reserves the next one and waits until it is due.
+func (b *tokenBucket) take() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		b.mu.Unlock()
+		return true
+	}
+	if !b.backpressure {
+		b.mu.Unlock()
+		return false
+	}
+
+	// Reserve the token; waiters queue up behind a negative balance
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	time.Sleep(wait)
+	return true
+}
@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/neox5/simv/clock"
+	"github.com/neox5/simv/source"
+)
+
+// TickSource emits a generated integer on each clock tick.
+type TickSource struct {
+	clock clock.Clock
+	next  func() (int, bool)
+	guard *Guard
+
+	initOnce        sync.Once
+	catchMu         sync.Mutex // Serializes lazy catch-up
+	caught          uint64     // Lazy clock ticks generated
+	mu              sync.Mutex
+	funcs           []func(int) // Subscribers called on the ticking goroutine
+	subscribers     []chan int
+	generationCount atomic.Uint64
+	shedCarry       float64 // Fraction of a tick kept by load shedding
+}
+
+// funcPublisher is a source calling subscribers synchronously on each
+// update, so a subscriber needs no goroutine of its own.
+type funcPublisher interface {
+	source.Publisher[int]
+
+	// subscribeFunc calls fn with each update.
+	subscribeFunc(fn func(int))
+}
+
+// lazyPublisher is a source generating updates on demand.
+type lazyPublisher interface {
+	// catchUp generates the updates due since the previous call.
+	catchUp()
+}
+
+// NewTickSource creates a source calling next on every tick.
+// Panics during generation are contained by guard.
+func NewTickSource(clk clock.Clock, next func() int, guard *Guard) *TickSource {
+	return newTickSource(clk, func() (int, bool) { return next(), true }, guard)
+}
+
+// newTickSource creates a source calling next on every tick. Ticks for
+// which next reports no value are skipped.
+func newTickSource(clk clock.Clock, next func() (int, bool), guard *Guard) *TickSource {
+	return &TickSource{
+		clock: clk,
+		next:  next,
+		guard: guard,
+	}
+}
+
+// Subscribe returns a channel receiving each generated value.
+// The first subscription starts generation.
+func (s *TickSource) Subscribe() <-chan int {
+	s.start()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan int)
+	s.subscribers = append(s.subscribers, ch)
+	return ch
+}
+
+// subscribeFunc calls fn with each generated value.
+// The first subscription starts generation.
+func (s *TickSource) subscribeFunc(fn func(int)) {
+	s.start()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.funcs = append(s.funcs, fn)
+}
+
+// start begins generation. Batching clocks run ticks on their worker
+// pool; ticks of other clocks are received by a goroutine. On a lazy
+// clock, generation starts from the ticks already due.
+func (s *TickSource) start() {
+	s.initOnce.Do(func() {
+		if lazy, ok := s.clock.(*LazyClock); ok {
+			s.caught = lazy.Due()
+		}
+		if b, ok := s.clock.(batcher); ok {
+			b.addTask(s.tick, s.close)
+			return
+		}
+
+		ticks := s.clock.Subscribe()
+		go func() {
+			for range ticks {
+				s.tick()
+			}
+			s.close()
+		}()
+	})
+}
+
+// tick generates a value and fans it out to subscribers, unless load
+// shedding or the throttle drops the tick.
+func (s *TickSource) tick() {
+	if !allowShed(&s.shedCarry) {
+		return // Skip shed tick
+	}
+	if !AllowObservation() {
+		return // Skip throttled tick
+	}
+	s.generate()
+}
+
+// generate generates a value and fans it out to subscribers.
+func (s *TickSource) generate() {
+	if s.guard.Disabled() {
+		droppedObservations.Add(1)
+		return
+	}
+
+	var value int
+	var ok bool
+	if !s.guard.Do("source", func() { value, ok = s.next() }) || !ok {
+		return // Skip tick
+	}
+	s.generationCount.Add(1)
+	generatedValues.Add(1)
+
+	s.mu.Lock()
+	funcs, subs := s.funcs, s.subscribers
+	s.mu.Unlock()
+
+	for _, fn := range funcs {
+		fn(value)
+	}
+	for _, subChan := range subs {
+		subChan <- value
+	}
+}
+
+// catchUp generates the ticks of a lazy clock due since the previous
+// catch-up, one by one, so its cost grows with the ticks since the last
+// read. Load shedding and the throttle bound background generation and do
+// not apply: dropping caught-up ticks would lose them for good. Sources of
+// other clocks are updated by their clock.
+func (s *TickSource) catchUp() {
+	lazy, ok := s.clock.(*LazyClock)
+	if !ok {
+		return
+	}
+
+	s.catchMu.Lock()
+	defer s.catchMu.Unlock()
+
+	for due := lazy.Due(); s.caught < due; s.caught++ {
+		s.generate()
+	}
+}
+
+// close closes subscriber channels once the clock stops.
+func (s *TickSource) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subChan := range s.subscribers {
+		close(subChan)
+	}
+	s.subscribers = nil
+}
+
+// Stats returns current source metrics.
+func (s *TickSource) Stats() source.SourceStats {
+	s.mu.Lock()
+	subCount := len(s.funcs) + len(s.subscribers)
+	s.mu.Unlock()
+
+	return source.SourceStats{
+		GenerationCount: s.generationCount.Load(),
+		SubscriberCount: subCount,
+	}
+}
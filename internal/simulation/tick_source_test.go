@@ -0,0 +1,31 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+func TestCatchUpIgnoresThrottle(t *testing.T) {
+	ConfigureThrottle(config.ThrottleConfig{MaxObservationsPerSecond: 1, Policy: config.ThrottlePolicyDrop})
+	t.Cleanup(func() { ConfigureThrottle(config.ThrottleConfig{}) })
+
+	clk := NewLazyClock(time.Millisecond)
+	src := NewTickSource(clk, func() int { return 1 }, NewGuard("test"))
+
+	var sum int
+	src.subscribeFunc(func(v int) { sum += v })
+
+	// Ten ticks are due on the next read
+	clk.Start()
+	clk.mu.Lock()
+	clk.started = clk.started.Add(-10 * time.Millisecond)
+	clk.mu.Unlock()
+	clk.Stop()
+
+	src.catchUp()
+	if sum != 10 {
+		t.Errorf("caught up %d ticks, want all 10 despite the throttle", sum)
+	}
+}
@@ -0,0 +1,217 @@
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/simv/clock"
+	"github.com/neox5/simv/source"
+	"github.com/neox5/simv/transform"
+)
+
+// TypeInfo describes a supported component type and its options.
+type TypeInfo struct {
+	Name        string
+	Description string
+	Options     []OptionInfo
+}
+
+// OptionInfo describes a configuration option of a component type.
+type OptionInfo struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// clockType registers a clock type with its factory.
+type clockType struct {
+	TypeInfo
+	create func(sim *Context, cfg config.ClockConfig) (clock.Clock, error)
+}
+
+// sourceType registers a source type with its factory.
+type sourceType struct {
+	TypeInfo
+	create func(sim *Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error)
+}
+
+// transformType registers a transform type with its factory.
+type transformType struct {
+	TypeInfo
+	create func(cfg config.TransformConfig) (transform.Transformation[int], error)
+}
+
+// resetType registers a reset type with the function applying it to a value.
+type resetType struct {
+	TypeInfo
+	apply func(w *ValueWrapper, cfg config.ResetConfig)
+}
+
+// clockTypes lists all supported clock types.
+var clockTypes = []clockType{
+	{
+		TypeInfo: TypeInfo{
+			Name:        "periodic",
+			Description: "Ticks at a fixed interval",
+			Options: []OptionInfo{
+				{Name: "interval", Type: "duration", Required: true, Description: "Time between ticks"},
+			},
+		},
+		create: func(sim *Context, cfg config.ClockConfig) (clock.Clock, error) {
+			if sim.generation == config.GenerationLazy {
+				return NewLazyClock(cfg.Interval), nil
+			}
+			return NewPeriodicClock(cfg.Interval), nil
+		},
+	},
+}
+
+// sourceTypes lists all supported source types.
+var sourceTypes = []sourceType{
+	{
+		TypeInfo: TypeInfo{
+			Name:        "random_int",
+			Description: "Emits a uniformly distributed integer on every clock tick",
+			Options: []OptionInfo{
+				{Name: "clock", Type: "clock reference", Required: true, Description: "Clock driving the source"},
+				{Name: "min", Type: "int", Required: true, Description: "Lowest value (inclusive)"},
+				{Name: "max", Type: "int", Required: true, Description: "Highest value (inclusive)"},
+			},
+		},
+		create: func(sim *Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error) {
+			return NewRandomIntSource(clk, cfg.Min, cfg.Max, sim.NewDerivedRand(key), NewGuard(key)), nil
+		},
+	},
+	{
+		TypeInfo: TypeInfo{
+			Name:        "rate",
+			Description: "Emits the increment accrued since the previous tick at a per-second rate",
+			Options: []OptionInfo{
+				{Name: "clock", Type: "clock reference", Required: true, Description: "Clock driving the source"},
+				{Name: "min", Type: "int", Required: true, Description: "Lowest rate per second (inclusive, >= 0)"},
+				{Name: "max", Type: "int", Required: true, Description: "Highest rate per second (inclusive)"},
+			},
+		},
+		create: func(sim *Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error) {
+			return NewRateSource(clk, cfg.Min, cfg.Max, sim.NewDerivedRand(key), NewGuard(key))
+		},
+	},
+	{
+		TypeInfo: TypeInfo{
+			Name:        "correlated",
+			Description: "Emits an integer moving with a group's shared latent signal plus individual noise",
+			Options: []OptionInfo{
+				{Name: "clock", Type: "clock reference", Required: true, Description: "Clock driving the source"},
+				{Name: "min", Type: "int", Required: true, Description: "Lowest value (inclusive)"},
+				{Name: "max", Type: "int", Required: true, Description: "Highest value (inclusive)"},
+				{Name: "group", Type: "string", Required: true, Description: "Group sharing the latent signal"},
+				{Name: "correlation", Type: "float", Description: "Correlation between members, in [0, 1] (default: 0)"},
+			},
+		},
+		create: func(sim *Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error) {
+			return sim.NewCorrelatedSource(clk, cfg.Clock.Interval, cfg.Min, cfg.Max, cfg.Group, cfg.Correlation, sim.NewDerivedRand(key), NewGuard(key))
+		},
+	},
+	{
+		TypeInfo: TypeInfo{
+			Name:        "replay",
+			Description: "Emits the samples of a series recorded in a Prometheus exposition or OTLP JSON file",
+			Options: []OptionInfo{
+				{Name: "clock", Type: "clock reference", Required: true, Description: "Clock driving the source"},
+				{Name: "replay.file", Type: "string", Required: true, Description: "Prometheus text exposition or OTLP JSON lines file"},
+				{Name: "replay.metric", Type: "string", Required: true, Description: "Name of the recorded metric"},
+				{Name: "replay.labels", Type: "map", Description: "Labels the recorded series must have (default: first series)"},
+				{Name: "replay.scale", Type: "float", Description: "Factor applied to recorded values (default: 1)"},
+				{Name: "replay.loop", Type: "bool", Description: "Restart after the last sample instead of holding it (default: false)"},
+			},
+		},
+		create: func(sim *Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error) {
+			return NewReplaySource(clk, cfg.Replay, NewGuard(key))
+		},
+	},
+}
+
+// transformTypes lists all supported transform types.
+var transformTypes = []transformType{
+	{
+		TypeInfo: TypeInfo{
+			Name:        "accumulate",
+			Description: "Adds each update to the running total",
+		},
+		create: func(cfg config.TransformConfig) (transform.Transformation[int], error) {
+			return transform.NewAccumulate[int](), nil
+		},
+	},
+	{
+		TypeInfo: TypeInfo{
+			Name:        "quantize",
+			Description: "Snaps values to discrete levels or multiples of a step",
+			Options: []OptionInfo{
+				{Name: "levels", Type: "[]int", Description: "Allowed output levels (exclusive with step)"},
+				{Name: "step", Type: "int", Description: "Snap to multiples of step (exclusive with levels)"},
+			},
+		},
+		create: buildQuantize,
+	},
+}
+
+// resetTypes lists all supported reset types.
+var resetTypes = []resetType{
+	{
+		TypeInfo: TypeInfo{
+			Name:        "on_read",
+			Description: "Resets the value after each read",
+			Options: []OptionInfo{
+				{Name: "value", Type: "int", Description: "Value after reset (default: 0)"},
+			},
+		},
+		apply: func(w *ValueWrapper, cfg config.ResetConfig) {
+			w.cursors = newReadCursors(w.transforms, cfg.Value, w.initial)
+		},
+	},
+}
+
+// ClockTypes describes all supported clock types.
+func ClockTypes() []TypeInfo {
+	return typeInfos(clockTypes, func(t clockType) TypeInfo { return t.TypeInfo })
+}
+
+// SourceTypes describes all supported source types.
+func SourceTypes() []TypeInfo {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	return typeInfos(sourceTypes, func(t sourceType) TypeInfo { return t.TypeInfo })
+}
+
+// TransformTypes describes all supported transform types.
+func TransformTypes() []TypeInfo {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	return typeInfos(transformTypes, func(t transformType) TypeInfo { return t.TypeInfo })
+}
+
+// ResetTypes describes all supported reset types.
+func ResetTypes() []TypeInfo {
+	return typeInfos(resetTypes, func(t resetType) TypeInfo { return t.TypeInfo })
+}
+
+// typeInfos extracts type descriptions from registrations.
+func typeInfos[T any](types []T, info func(T) TypeInfo) []TypeInfo {
+	result := make([]TypeInfo, len(types))
+	for i, t := range types {
+		result[i] = info(t)
+	}
+	return result
+}
+
+// lookupType finds the registration for name.
+func lookupType[T any](types []T, name string, info func(T) TypeInfo) (T, error) {
+	for _, t := range types {
+		if info(t).Name == name {
+			return t, nil
+		}
+	}
+	var zero T
+	return zero, fmt.Errorf("unknown type: %q", name)
+}
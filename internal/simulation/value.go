@@ -2,30 +2,144 @@ package simulation
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/neox5/otelbox/internal/config"
 	"github.com/neox5/simv/source"
 	"github.com/neox5/simv/transform"
-	"github.com/neox5/simv/value"
 )
 
-// ValueWrapper wraps simv Value for easier management
+// ValueWrapper holds the state of a series. Updates are applied on the
+// goroutine delivering them, normally a clock worker, so a value costs no
+// goroutine of its own.
 type ValueWrapper struct {
-	*value.Value[int]
+	Guard *Guard // Contains panics in transforms and value reads
+
+	mu          sync.Mutex
+	state       valueState
+	updateCount atomic.Uint64
+	done        chan struct{} // Closed when a channel subscription ends (nil: synchronous)
+	catchUp     func()        // Generates updates due from a lazy source (nil: continuous)
+
+	transforms []transform.Transformation[int]
+	initial    int            // Offset added to reads until the first reset
+	cursors    *readCursors   // Per-consumer reset_on_read state (nil: reads do not reset)
+	specials   *specialValues // Updates producing special samples (nil: none)
+	detached   bool           // Updates are discarded
+}
+
+// valueState exposes the state of a value to its transforms.
+type valueState struct {
+	current int
+}
+
+// GetState returns the current state.
+func (s *valueState) GetState() int {
+	return s.current
+}
+
+// Read returns the value observed by consumer. With reset_on_read, the read
+// resets only the consumer's view: each consumer observes the full delta
+// since its own previous read.
+func (w *ValueWrapper) Read(consumer string) int {
+	w.sync()
+	if w.cursors == nil {
+		return w.State()
+	}
+	return w.cursors.read(consumer)
+}
+
+// State returns the current value without resetting it.
+func (w *ValueWrapper) State() int {
+	w.sync()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.initial + w.state.current
+}
+
+// Updates returns the number of updates applied so far.
+func (w *ValueWrapper) Updates() uint64 {
+	return w.updateCount.Load()
+}
+
+// Updated reports whether the value received an update yet, bringing a
+// lazily generated value up to date first.
+func (w *ValueWrapper) Updated() bool {
+	w.sync()
+	return w.Updates() > 0
+}
+
+// sync brings a lazily generated value up to date.
+func (w *ValueWrapper) sync() {
+	if w.catchUp != nil {
+		w.catchUp()
+	}
+}
+
+// Stop waits until a channel subscription has delivered its last update.
+// Synchronously updated values stop with their clock.
+func (w *ValueWrapper) Stop() {
+	if w.done != nil {
+		<-w.done
+	}
+}
+
+// Detach discards further updates and drops the per-consumer state.
+// Used for values of removed metrics whose source stays subscribed, since
+// subscriptions cannot be removed.
+func (w *ValueWrapper) Detach() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.detached = true
+	if w.cursors != nil {
+		w.cursors.release()
+	}
+}
+
+// update applies a source update through the transforms.
+func (w *ValueWrapper) update(input int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.detached {
+		return
+	}
+
+	if w.cursors != nil {
+		w.cursors.OnInput(input)
+	}
+
+	transformed := input
+	for _, t := range w.transforms {
+		transformed = t.Apply(transformed, &w.state)
+	}
+	w.state.current = transformed
+	w.updateCount.Add(1)
+
+	if w.cursors != nil {
+		w.cursors.AfterUpdate(transformed)
+	}
 }
 
 // CreateValue creates a value from configuration.
 // The value is started and ready to receive updates.
-func CreateValue(
+// The series identifies the value in panic reports.
+func (c *Context) CreateValue(
 	cfg config.ValueConfig,
 	src source.Publisher[int],
+	series string,
 ) (*ValueWrapper, error) {
 	if src == nil {
 		return nil, fmt.Errorf("source required for value")
 	}
 
-	// Create value
-	val := value.New(src)
+	w := &ValueWrapper{
+		Guard:    NewGuard(series),
+		initial:  cfg.Initial,
+		specials: newSpecialValues(cfg.SpecialValues, c.seed, series),
+	}
 
 	// Add transforms
 	if len(cfg.Transforms) > 0 {
@@ -34,19 +148,39 @@ func CreateValue(
 			return nil, err
 		}
 		for _, t := range transforms {
-			val.AddTransform(t)
+			w.transforms = append(w.transforms, guardedTransform{inner: t, guard: w.Guard})
 		}
 	}
 
 	// Apply reset behavior
-	if cfg.Reset.Type == "on_read" {
-		val.EnableResetOnRead(cfg.Reset.Value)
+	if cfg.Reset.Type != "" {
+		t, err := lookupType(resetTypes, cfg.Reset.Type, func(t resetType) TypeInfo { return t.TypeInfo })
+		if err != nil {
+			return nil, fmt.Errorf("unknown reset type: %q", cfg.Reset.Type)
+		}
+		t.apply(w, cfg.Reset)
+	}
+
+	// Lazily generated sources catch up on read
+	if lazy, ok := src.(lazyPublisher); ok && c.generation == config.GenerationLazy {
+		w.catchUp = lazy.catchUp
 	}
 
-	// Start the value (begins receiving updates)
-	val.Start()
+	// Start receiving updates
+	if fp, ok := src.(funcPublisher); ok {
+		fp.subscribeFunc(w.update)
+	} else {
+		updates := src.Subscribe()
+		w.done = make(chan struct{})
+		go func() {
+			defer close(w.done)
+			for update := range updates {
+				w.update(update)
+			}
+		}()
+	}
 
-	return &ValueWrapper{Value: val}, nil
+	return w, nil
 }
 
 // buildTransforms creates transform instances from configuration.
@@ -54,15 +188,37 @@ func buildTransforms(transformCfgs []config.TransformConfig) ([]transform.Transf
 	var transforms []transform.Transformation[int]
 
 	for _, tfCfg := range transformCfgs {
-		switch tfCfg.Type {
-		case "accumulate":
-			transforms = append(transforms, transform.NewAccumulate[int]())
-		case "":
+		if tfCfg.Type == "" {
 			return nil, fmt.Errorf("transform type cannot be empty")
-		default:
+		}
+		typesMu.RLock()
+		tt, err := lookupType(transformTypes, tfCfg.Type, func(t transformType) TypeInfo { return t.TypeInfo })
+		typesMu.RUnlock()
+		if err != nil {
 			return nil, fmt.Errorf("unknown transform type: %q", tfCfg.Type)
 		}
+		t, err := tt.create(tfCfg)
+		if err != nil {
+			return nil, err
+		}
+		transforms = append(transforms, t)
 	}
 
 	return transforms, nil
 }
+
+// buildQuantize creates a quantize transform from levels or step configuration.
+func buildQuantize(cfg config.TransformConfig) (transform.Transformation[int], error) {
+	switch {
+	case len(cfg.Levels) > 0 && cfg.Step != 0:
+		return nil, fmt.Errorf("quantize transform: levels and step are mutually exclusive")
+	case len(cfg.Levels) > 0:
+		return NewQuantize(cfg.Levels), nil
+	case cfg.Step > 0:
+		return NewBucketize(cfg.Step), nil
+	case cfg.Step < 0:
+		return nil, fmt.Errorf("quantize transform: step must be positive: %d", cfg.Step)
+	default:
+		return nil, fmt.Errorf("quantize transform: levels or step required")
+	}
+}
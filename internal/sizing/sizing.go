@@ -0,0 +1,243 @@
+// Package sizing estimates the memory and CPU a resolved configuration
+// needs and compares the estimate with the resources of the host. Costs
+// per series are calibrated with the bench command and are deliberately
+// rough; the estimate replaces trial-and-error sizing, not measurement.
+package sizing
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/shirou/gopsutil/v4/mem"
+)
+
+// Memory per series in bytes, excluding attribute strings.
+const (
+	generatorBytesPerSeries  = 900 // Clock, source, value and descriptor state
+	prometheusBytesPerSeries = 350 // Descriptor, rendered labels and scrape chunk
+	otelSDKBytesPerSeries    = 700 // Instrument, attribute set and aggregator
+	otelDirectBytesPerSeries = 250 // Prepared data point
+	attributeBytesPerEntry   = 48  // Key and value headers of an interned attribute
+	labelCopies              = 2   // Attribute entries held by the registry and an exporter
+)
+
+// CPU time per series update or export, calibrated with the bench command.
+const (
+	updateCost     = 500 * time.Nanosecond
+	scrapeCost     = 1 * time.Microsecond
+	otelSDKCost    = 2 * time.Microsecond
+	otelDirectCost = 500 * time.Nanosecond
+)
+
+// AssumedScrapeInterval is the scrape interval assumed for Prometheus
+// exporters, which do not know how often they are scraped.
+const AssumedScrapeInterval = 15 * time.Second
+
+// Component is the estimated cost of one part of otelbox.
+type Component struct {
+	Name   string
+	Series int
+	Memory uint64  // Bytes
+	CPU    float64 // Cores
+}
+
+// Estimate is the estimated steady-state cost of a configuration.
+type Estimate struct {
+	Components []Component
+}
+
+// Memory returns the estimated memory of all components.
+func (e Estimate) Memory() uint64 {
+	var total uint64
+	for _, c := range e.Components {
+		total += c.Memory
+	}
+	return total
+}
+
+// CPU returns the estimated cores of all components.
+func (e Estimate) CPU() float64 {
+	var total float64
+	for _, c := range e.Components {
+		total += c.CPU
+	}
+	return total
+}
+
+// Resources are the memory and CPUs available to otelbox.
+type Resources struct {
+	Memory uint64 // Bytes (0: unknown)
+	CPUs   int
+}
+
+// Detect returns the resources available to the process. Memory is the
+// cgroup limit when set, otherwise the total memory of the host. CPUs
+// follow GOMAXPROCS, which respects cgroup CPU limits.
+func Detect() Resources {
+	r := Resources{CPUs: runtime.GOMAXPROCS(0)}
+	if limit, ok := cgroupMemoryLimit(); ok {
+		r.Memory = limit
+	} else if vm, err := mem.VirtualMemory(); err == nil {
+		r.Memory = vm.Total
+	}
+	return r
+}
+
+// cgroupMemoryLimit reads the cgroup v2 or v1 memory limit.
+func cgroupMemoryLimit() (uint64, bool) {
+	for _, path := range []string{
+		"/sys/fs/cgroup/memory.max",
+		"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, false
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		// cgroup v1 reports an unset limit as a huge page-aligned value
+		if err != nil || limit >= 1<<62 {
+			return 0, false
+		}
+		return limit, true
+	}
+	return 0, false
+}
+
+// Check returns an error if the estimate exceeds the resources.
+func Check(e Estimate, r Resources) error {
+	if r.Memory > 0 && e.Memory() > r.Memory {
+		return fmt.Errorf("estimated memory %s exceeds available %s", FormatBytes(e.Memory()), FormatBytes(r.Memory))
+	}
+	if r.CPUs > 0 && e.CPU() > float64(r.CPUs) {
+		return fmt.Errorf("estimated CPU %.2f cores exceeds available %d", e.CPU(), r.CPUs)
+	}
+	return nil
+}
+
+// EstimateConfig estimates the cost of generating and exporting the
+// metrics of cfg.
+func EstimateConfig(cfg *config.Config) Estimate {
+	var e Estimate
+
+	// Generation updates every series once per clock tick
+	// Attribute strings are interned and count once across all series
+	generator := Component{Name: "generator", Series: len(cfg.Metrics)}
+	strings := make(map[string]bool)
+	for _, m := range cfg.Metrics {
+		generator.Memory += generatorBytesPerSeries + attributeBytes(m, strings)
+		generator.CPU += perSecond(updateCost, m.Value.Source.Clock.Interval)
+	}
+	e.Components = append(e.Components, generator)
+
+	// Metrics of jobs with dedicated export are served only there
+	dedicated := make(map[string]bool)
+	for _, job := range cfg.Jobs {
+		if job.Dedicated() {
+			dedicated[job.Name] = true
+		}
+	}
+	e.Components = append(e.Components, exportComponents("", cfg.Export, cfg.Metrics, func(m config.MetricConfig) bool {
+		return !dedicated[m.Job]
+	})...)
+	for _, job := range cfg.Jobs {
+		if !job.Dedicated() {
+			continue
+		}
+		e.Components = append(e.Components, exportComponents("job "+job.Name+" ", *job.Export, cfg.Metrics, func(m config.MetricConfig) bool {
+			return m.Job == job.Name
+		})...)
+	}
+
+	return e
+}
+
+// exportComponents estimates the exporters of one export configuration.
+func exportComponents(prefix string, export config.ExportConfig, metrics []config.MetricConfig, served func(config.MetricConfig) bool) []Component {
+	var components []Component
+
+	if export.Prometheus != nil && export.Prometheus.Enabled {
+		c := Component{Name: prefix + "prometheus"}
+		for _, m := range metrics {
+			if served(m) && m.ExportsTo(config.ExportProtocolPrometheus) {
+				c.Series++
+				c.Memory += prometheusBytesPerSeries
+			}
+		}
+		c.CPU = float64(c.Series) * perSecond(scrapeCost, AssumedScrapeInterval)
+		components = append(components, c)
+	}
+
+	if export.OTEL != nil && export.OTEL.Enabled {
+		c := Component{Name: prefix + "otel"}
+		bytesPerSeries, cost := uint64(otelSDKBytesPerSeries), otelSDKCost
+		if export.OTEL.Payload == config.OTELPayloadDirect {
+			bytesPerSeries, cost = otelDirectBytesPerSeries, otelDirectCost
+		}
+		for _, m := range metrics {
+			if served(m) && m.ExportsTo(config.ExportProtocolOTEL) {
+				c.Series++
+				c.Memory += bytesPerSeries
+			}
+		}
+		c.CPU = float64(c.Series) * perSecond(cost, export.OTEL.Interval.Push)
+		components = append(components, c)
+	}
+
+	return components
+}
+
+// attributeBytes returns the size of the attributes of a series. Strings
+// not yet in seen are added and counted; payload attributes are generated
+// on top of the configured ones and shared by series of the same name.
+func attributeBytes(m config.MetricConfig, seen map[string]bool) uint64 {
+	n := uint64(len(m.Attributes)+m.Payload.Labels) * attributeBytesPerEntry * labelCopies
+	for key, value := range m.Attributes {
+		n += stringBytes(key, seen) + stringBytes(value, seen)
+	}
+	if m.Payload.Enabled() && !seen["\x00payload:"+m.PrometheusName] {
+		seen["\x00payload:"+m.PrometheusName] = true
+		n += uint64(m.Payload.Labels) * uint64(len(m.Payload.Prefix)+4+int(m.Payload.Size))
+	}
+	return n
+}
+
+// stringBytes returns the size of s the first time it is seen.
+func stringBytes(s string, seen map[string]bool) uint64 {
+	if seen[s] {
+		return 0
+	}
+	seen[s] = true
+	return uint64(len(s))
+}
+
+// perSecond returns the cores spent doing work of cost once per interval.
+// Intervals that are not set count as one second.
+func perSecond(cost, interval time.Duration) float64 {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return float64(cost) / float64(interval)
+}
+
+// FormatBytes formats a size in binary units with one decimal.
+func FormatBytes(b uint64) string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1fGiB", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(b)/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(b)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", b)
+	}
+}
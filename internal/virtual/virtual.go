@@ -0,0 +1,129 @@
+// Package virtual runs a configuration in virtual time. Clocks tick on
+// demand instead of with wall time, so any span of simulated time is
+// generated as fast as values settle.
+package virtual
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/generator"
+	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/neox5/simv/clock"
+)
+
+// settleTimeout bounds how long a tick may take to propagate to values
+const settleTimeout = 5 * time.Second
+
+// manualClock is a clock advanced in virtual time.
+type manualClock struct {
+	clock    *simulation.ManualClock
+	interval time.Duration
+	next     time.Duration // Virtual time of the next tick
+}
+
+// Run is a generator advanced in virtual time.
+// Each tick advances virtual time by the shortest clock interval; every
+// clock fires whenever its own interval has elapsed.
+type Run struct {
+	Metrics *metric.Registry
+
+	gen    *generator.Generator
+	clocks []*manualClock
+	step   time.Duration
+	now    time.Duration
+}
+
+// Start creates and starts the generator of cfg with manually advanced
+// clocks in a simulation context of its own.
+func Start(cfg *config.Config) (*Run, error) {
+	sim, err := Initialize(cfg.Settings)
+	if err != nil {
+		return nil, err
+	}
+	return New(sim, cfg)
+}
+
+// Initialize creates the simulation context with the seed and rng of
+// settings and applies their process-wide panic and ramp settings.
+func Initialize(settings config.SettingsConfig) (*simulation.Context, error) {
+	sim, err := simulation.NewContext(&settings, slog.Default())
+	if err != nil {
+		return nil, err
+	}
+	simulation.ConfigureRecovery(settings.Panics)
+	simulation.ConfigureRamp(settings.Ramp)
+	return sim, nil
+}
+
+// New creates and starts the generator of cfg with manually advanced
+// clocks, using the seed and rng of sim.
+func New(sim *simulation.Context, cfg *config.Config) (*Run, error) {
+	// Replace every clock with a manually advanced one
+	var clocks []*manualClock
+	factory := func(c config.ClockConfig) (clock.Clock, error) {
+		if c.Interval <= 0 {
+			return nil, fmt.Errorf("invalid clock interval: %s", c.Interval)
+		}
+		clk := simulation.NewManualClock(c.Interval)
+		clocks = append(clocks, &manualClock{clock: clk, interval: c.Interval, next: c.Interval})
+		return clk, nil
+	}
+
+	gen, err := generator.NewWithClockFactory(sim, cfg.Metrics, factory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create generator: %w", err)
+	}
+	metrics, err := metric.New(cfg, sim, gen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics: %w", err)
+	}
+
+	r := &Run{Metrics: metrics, gen: gen, clocks: clocks}
+	for _, c := range clocks {
+		if r.step == 0 || c.interval < r.step {
+			r.step = c.interval
+		}
+	}
+
+	gen.Start()
+	return r, nil
+}
+
+// Step returns the virtual time each tick advances (0 without clocks).
+func (r *Run) Step() time.Duration {
+	return r.step
+}
+
+// Tick advances virtual time by one step and waits until all values
+// reflect the fired clocks. Returns the virtual time elapsed since start.
+func (r *Run) Tick() (time.Duration, error) {
+	r.now += r.step
+
+	// Tick all due clocks as one batch, as often as each is due
+	for {
+		var due []*simulation.ManualClock
+		for _, c := range r.clocks {
+			if c.next <= r.now {
+				due = append(due, c.clock)
+				c.next += c.interval
+			}
+		}
+		if len(due) == 0 {
+			break
+		}
+		simulation.TickManual(due...)
+	}
+	if err := r.gen.Settle(settleTimeout); err != nil {
+		return r.now, err
+	}
+	return r.now, nil
+}
+
+// Stop stops the generator.
+func (r *Run) Stop() {
+	r.gen.Stop()
+}
@@ -0,0 +1,167 @@
+package otelbox
+
+import (
+	"errors"
+	"time"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// Builder assembles a configuration in code instead of YAML.
+//
+//	cfg, err := otelbox.NewConfig().
+//		Metric(otelbox.NewMetric("http_requests_total").Counter().
+//			Description("Total HTTP requests").
+//			Attribute("method", "GET").
+//			Source(otelbox.RandomInt(0, 10).Every(time.Second)).
+//			Accumulate()).
+//		Export("prometheus://:9090/metrics").
+//		Build(otelbox.WithSeed(42))
+type Builder struct {
+	raw  config.RawConfig
+	errs []error
+}
+
+// NewConfig starts an empty configuration.
+func NewConfig() *Builder {
+	return &Builder{}
+}
+
+// Metric adds a metric definition. Later changes to m do not affect the
+// builder.
+func (b *Builder) Metric(m *MetricBuilder) *Builder {
+	b.raw.Metrics = append(b.raw.Metrics, m.raw.DeepCopy())
+	return b
+}
+
+// Export sets the exporter from a target URL, as with the --target flag:
+// otlp://host:port, otlp+http://host:port, or prometheus://:port/path.
+// A later call replaces the exporter. Without Export, the default
+// Prometheus endpoint is served.
+func (b *Builder) Export(target string) *Builder {
+	if err := config.ApplyTarget(&b.raw, target); err != nil {
+		b.errs = append(b.errs, err)
+	}
+	return b
+}
+
+// Build validates and resolves the configuration.
+func (b *Builder) Build(opts ...Option) (*Config, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return nil, err
+	}
+
+	raw := b.raw
+	raw.Metrics = make([]config.RawMetricConfig, len(b.raw.Metrics))
+	for i, m := range b.raw.Metrics {
+		raw.Metrics[i] = m.DeepCopy()
+	}
+
+	if err := config.Validate(&raw); err != nil {
+		return nil, err
+	}
+	return resolve(&raw, opts)
+}
+
+// MetricBuilder assembles a metric definition.
+type MetricBuilder struct {
+	raw config.RawMetricConfig
+}
+
+// NewMetric starts a metric definition. The name is used for both
+// Prometheus and OTEL export.
+func NewMetric(name string) *MetricBuilder {
+	return &MetricBuilder{raw: config.RawMetricConfig{
+		Name: config.RawMetricNameConfig{Simple: name},
+	}}
+}
+
+// Counter makes the metric a counter.
+func (m *MetricBuilder) Counter() *MetricBuilder {
+	m.raw.Type = string(Counter)
+	return m
+}
+
+// Gauge makes the metric a gauge.
+func (m *MetricBuilder) Gauge() *MetricBuilder {
+	m.raw.Type = string(Gauge)
+	return m
+}
+
+// Description sets the metric help text.
+func (m *MetricBuilder) Description(description string) *MetricBuilder {
+	m.raw.Description = description
+	return m
+}
+
+// Attribute adds an attribute (Prometheus label) to the series.
+func (m *MetricBuilder) Attribute(name, value string) *MetricBuilder {
+	if m.raw.Attributes == nil {
+		m.raw.Attributes = make(map[string]string)
+	}
+	m.raw.Attributes[name] = value
+	return m
+}
+
+// Source sets the source feeding the metric value.
+func (m *MetricBuilder) Source(s *SourceBuilder) *MetricBuilder {
+	source := s.raw.DeepCopy()
+	m.raw.Value.Source = &source
+	return m
+}
+
+// Accumulate adds each source update to a running total.
+func (m *MetricBuilder) Accumulate() *MetricBuilder {
+	m.raw.Value.Transforms = append(m.raw.Value.Transforms, config.TransformConfig{Type: "accumulate"})
+	return m
+}
+
+// Quantize snaps values to the given levels.
+func (m *MetricBuilder) Quantize(levels ...int) *MetricBuilder {
+	m.raw.Value.Transforms = append(m.raw.Value.Transforms, config.TransformConfig{Type: "quantize", Levels: levels})
+	return m
+}
+
+// Transform applies a registered custom transform with options.
+func (m *MetricBuilder) Transform(transformType string, options map[string]any) *MetricBuilder {
+	m.raw.Value.Transforms = append(m.raw.Value.Transforms, config.TransformConfig{Type: transformType, Options: options})
+	return m
+}
+
+// ResetOnRead resets the value to value after each export read.
+func (m *MetricBuilder) ResetOnRead(value int) *MetricBuilder {
+	m.raw.Value.Reset = config.ResetConfig{Type: "on_read", Value: value}
+	return m
+}
+
+// SourceBuilder assembles a source definition.
+type SourceBuilder struct {
+	raw config.RawSourceReference
+}
+
+// RandomInt starts a source emitting uniformly distributed integers in
+// [min, max]. A clock must be set with Every.
+func RandomInt(min, max int) *SourceBuilder {
+	sourceType := "random_int"
+	return &SourceBuilder{raw: config.RawSourceReference{
+		Type: &sourceType,
+		Min:  &min,
+		Max:  &max,
+	}}
+}
+
+// CustomSource starts a source of a registered custom type with options.
+// A clock must be set with Every.
+func CustomSource(sourceType string, options map[string]any) *SourceBuilder {
+	return &SourceBuilder{raw: config.RawSourceReference{
+		Type:    &sourceType,
+		Options: options,
+	}}
+}
+
+// Every drives the source with a periodic clock.
+func (s *SourceBuilder) Every(interval time.Duration) *SourceBuilder {
+	clockType := "periodic"
+	s.raw.Clock = &config.RawClockReference{Type: &clockType, Interval: interval}
+	return s
+}
@@ -0,0 +1,72 @@
+package otelbox
+
+import (
+	"fmt"
+
+	"github.com/neox5/otelbox/internal/config"
+)
+
+// Config is a resolved otelbox configuration.
+type Config struct {
+	cfg *config.Config
+}
+
+// Option adjusts configuration before expansion.
+type Option func(*config.RawConfig)
+
+// WithSeed sets the simulation seed, overriding settings.seed.
+func WithSeed(seed uint64) Option {
+	return func(raw *config.RawConfig) {
+		raw.Settings.Seed = &seed
+	}
+}
+
+// WithMaxSeries sets the expansion series limit (0: unlimited),
+// overriding settings.max_series.
+func WithMaxSeries(n int) Option {
+	return func(raw *config.RawConfig) {
+		raw.Settings.MaxSeries = &n
+	}
+}
+
+// LoadConfig reads, merges, and resolves configuration files.
+// Paths may be files or directories, as with the --config flag.
+func LoadConfig(paths []string, opts ...Option) (*Config, error) {
+	raw, err := config.ParseFiles(paths, config.ParseOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return resolve(raw, opts)
+}
+
+// ParseConfig resolves configuration from YAML data.
+func ParseConfig(data []byte, opts ...Option) (*Config, error) {
+	raw, err := config.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return resolve(raw, opts)
+}
+
+// resolve applies options, then expands and resolves raw configuration.
+func resolve(raw *config.RawConfig, opts []Option) (*Config, error) {
+	for _, opt := range opts {
+		opt(raw)
+	}
+
+	if err := config.Expand(raw); err != nil {
+		return nil, fmt.Errorf("failed to expand config: %w", err)
+	}
+
+	cfg, err := config.Resolve(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve config: %w", err)
+	}
+
+	return &Config{cfg: cfg}, nil
+}
+
+// Explain returns the resolved configuration as YAML.
+func (c *Config) Explain() ([]byte, error) {
+	return config.Explain(c.cfg, nil)
+}
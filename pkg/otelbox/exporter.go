@@ -0,0 +1,49 @@
+package otelbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/neox5/otelbox/internal/exporter"
+	"github.com/neox5/otelbox/internal/metric"
+)
+
+// Exporter is a custom exporter selected by name in an export.custom
+// block:
+//
+//	export:
+//	  custom:
+//	    name: my-exporter
+//	    options:
+//	      url: http://localhost:8080
+//
+// Like the built-in exporters, it replaces prometheus and otel export.
+type Exporter interface {
+	Start(ctx context.Context) error // Begins exporting without blocking
+	Stop(ctx context.Context) error
+	Describe() string
+}
+
+// ExporterFactory creates a custom exporter from the options of its
+// export.custom block. The reader reads the simulated series.
+type ExporterFactory func(options map[string]any, reader *Reader) (Exporter, error)
+
+// RegisterExporter makes a custom exporter available by name. Register
+// exporters before loading configuration that references them, typically
+// from an init function.
+func RegisterExporter(name string, factory ExporterFactory) error {
+	return exporter.RegisterCustomExporter(name, func(options map[string]any, metrics func() *metric.Registry) (exporter.CustomExporter, error) {
+		return factory(options, &Reader{metrics: metrics})
+	})
+}
+
+// Reader reads the series exported by a custom exporter.
+type Reader struct {
+	metrics func() *metric.Registry
+}
+
+// Read returns the current data points. Reads behave like exporter reads:
+// sparse series may be omitted and reset_on_read values are reset.
+func (r *Reader) Read() []DataPoint {
+	return readPoints(r.metrics(), "custom", time.Now())
+}
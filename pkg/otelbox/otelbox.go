@@ -0,0 +1,125 @@
+// Package otelbox embeds the otelbox metric simulator in Go programs, so
+// test harnesses can run simulations in-process instead of shelling out to
+// the binary.
+//
+// A Simulation is built from a Config and runs the same generator and
+// exporters as `otelbox serve`:
+//
+//	cfg, err := otelbox.LoadConfig([]string{"otelbox.yaml"}, otelbox.WithSeed(42))
+//	if err != nil {
+//		return err
+//	}
+//	sim, err := otelbox.New(cfg)
+//	if err != nil {
+//		return err
+//	}
+//	go sim.Run(ctx)
+//
+// Each simulation has its own seed, random number generator, and
+// generation mode, so several simulations can run in one process, e.g. one
+// per test. Without settings.seed, they share the seed drawn for the
+// process. Throttling, load shedding, ramp, and panic handling settings
+// apply process-wide, set by the latest New. Logs go to slog.Default;
+// settings.logging is applied by the otelbox binary only and never changes
+// the default logger of the host program.
+package otelbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+
+	"github.com/neox5/otelbox/internal/app"
+	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
+)
+
+// Simulation is a running or runnable otelbox instance.
+type Simulation struct {
+	mu    sync.RWMutex // Guards app.Metrics across reloads
+	app   *app.App
+	sinks []sinkReader
+}
+
+// New creates a simulation from configuration.
+// Sources and exporters start with Run.
+func New(cfg *Config) (*Simulation, error) {
+	a, err := app.New(cfg.cfg, slog.Default())
+	if err != nil {
+		return nil, fmt.Errorf("initialization failed: %w", err)
+	}
+	return &Simulation{app: a}, nil
+}
+
+// Run starts the generator and exporters and blocks until ctx is cancelled
+// or a component fails, then stops all components.
+func (s *Simulation) Run(ctx context.Context) error {
+	lifecycle := s.app.Lifecycle()
+	for _, r := range s.sinks {
+		lifecycle.Add(app.Component{
+			Name:      r.name,
+			DependsOn: []string{app.ComponentGenerator},
+			Run:       func(ctx context.Context) error { return s.runSink(ctx, r) },
+		})
+	}
+	return lifecycle.Run(ctx)
+}
+
+// Reload applies a new configuration to the running simulation.
+// As with SIGHUP, unchanged metrics keep their values and changed
+// exporters are replaced; settings, chaos, and custom exporter changes are
+// ignored.
+func (s *Simulation) Reload(cfg *Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.app.Reload(cfg.cfg)
+}
+
+// MetricType is the semantic type of a metric.
+type MetricType string
+
+const (
+	Counter MetricType = MetricType(metric.MetricTypeCounter)
+	Gauge   MetricType = MetricType(metric.MetricTypeGauge)
+)
+
+// Metric is a simulated series.
+type Metric struct {
+	PrometheusName string
+	OTELName       string
+	Type           MetricType
+	Description    string
+	Attributes     map[string]string
+	Job            string // Owning job (empty for top-level metrics)
+
+	value *simulation.ValueWrapper
+}
+
+// Value returns the current value of the series. Unlike exporter reads,
+// it does not reset values configured with reset_on_read.
+func (m Metric) Value() int {
+	return m.value.State()
+}
+
+// Metrics returns the simulated series.
+func (s *Simulation) Metrics() []Metric {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	descriptors := s.app.Metrics.Metrics()
+	metrics := make([]Metric, len(descriptors))
+	for i, d := range descriptors {
+		metrics[i] = Metric{
+			PrometheusName: d.PrometheusName,
+			OTELName:       d.OTELName,
+			Type:           MetricType(d.Type),
+			Description:    d.Description,
+			Attributes:     maps.Clone(d.Attributes),
+			Job:            d.Job,
+			value:          d.Value,
+		}
+	}
+	return metrics
+}
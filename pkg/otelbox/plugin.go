@@ -0,0 +1,81 @@
+package otelbox
+
+import (
+	"github.com/neox5/otelbox/internal/config"
+	"github.com/neox5/otelbox/internal/simulation"
+	"github.com/neox5/simv/clock"
+	"github.com/neox5/simv/source"
+	"github.com/neox5/simv/transform"
+)
+
+// Rand is the random stream of a custom source. It is derived from the
+// seed and the source identity, so seeded runs stay reproducible.
+type Rand interface {
+	IntN(n int) int
+}
+
+// SourceFactory creates the generator of a custom source from the options
+// of its source definition:
+//
+//	source:
+//	  type: my-source
+//	  clock:
+//	    instance: tick
+//	  options:
+//	    mean: 50
+//
+// The returned function is called on every clock tick.
+type SourceFactory func(options map[string]any, rng Rand) (func() int, error)
+
+// RegisterSource makes a custom source type available by name. Register
+// sources before loading configuration that references them, typically
+// from an init function.
+func RegisterSource(name, description string, factory SourceFactory) error {
+	return simulation.RegisterSourceType(
+		simulation.TypeInfo{Name: name, Description: description},
+		func(sim *simulation.Context, cfg config.SourceConfig, clk clock.Clock, key string) (source.Publisher[int], error) {
+			next, err := factory(cfg.Options, sim.NewDerivedRand(key))
+			if err != nil {
+				return nil, err
+			}
+			return simulation.NewTickSource(clk, next, simulation.NewGuard(key)), nil
+		},
+	)
+}
+
+// TransformFunc computes the next value from an incoming update and the
+// current value.
+type TransformFunc func(incoming, current int) int
+
+// TransformFactory creates a custom transform from the options of its
+// transform definition.
+type TransformFactory func(options map[string]any) (TransformFunc, error)
+
+// RegisterTransform makes a custom transform type available by name.
+// Register transforms before loading configuration that references them.
+func RegisterTransform(name, description string, factory TransformFactory) error {
+	return simulation.RegisterTransformType(
+		simulation.TypeInfo{Name: name, Description: description},
+		func(cfg config.TransformConfig) (transform.Transformation[int], error) {
+			fn, err := factory(cfg.Options)
+			if err != nil {
+				return nil, err
+			}
+			return &customTransform{name: name, fn: fn}, nil
+		},
+	)
+}
+
+// customTransform adapts a TransformFunc to a simv transformation.
+type customTransform struct {
+	name string
+	fn   TransformFunc
+}
+
+func (t *customTransform) Apply(incoming int, state transform.State[int]) int {
+	return t.fn(incoming, state.GetState())
+}
+
+func (t *customTransform) Name() string {
+	return t.name
+}
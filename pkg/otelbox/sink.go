@@ -0,0 +1,163 @@
+package otelbox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/neox5/otelbox/internal/metric"
+	"github.com/neox5/otelbox/internal/simulation"
+)
+
+// DataPoint is a series value captured by a sink.
+type DataPoint struct {
+	Time       time.Time
+	Name       string // Prometheus name
+	Type       MetricType
+	Attributes map[string]string
+	Job        string
+	Value      int
+}
+
+// Sink receives the data points of every read of a simulation, like a
+// collector receiving pushes. A sink is fed by a reader of its own, not by
+// the exporters: it sees what an exporter pushing at the sink's interval
+// would emit, not the samples of actual scrapes or pushes.
+type Sink interface {
+	Write(points []DataPoint)
+}
+
+// sinkReader reads all series into a sink at a fixed interval.
+type sinkReader struct {
+	name     string // Component and read consumer name
+	sink     Sink
+	interval time.Duration
+}
+
+// AddSink registers a sink read every interval while the simulation runs.
+// The sink samples the series on its own schedule, independent of export.
+// Reads behave like exporter reads: sparse series may be omitted and
+// reset_on_read values are reset. Each sink observes reset_on_read deltas
+// and sparse draws independently of exporters and other sinks. Must be
+// called before Run. Returns error if interval is not positive.
+func (s *Simulation) AddSink(sink Sink, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("sink interval must be positive: %s", interval)
+	}
+	name := fmt.Sprintf("sink/%d", len(s.sinks))
+	s.sinks = append(s.sinks, sinkReader{name: name, sink: sink, interval: interval})
+	return nil
+}
+
+// runSink feeds a sink until ctx is cancelled.
+func (s *Simulation) runSink(ctx context.Context, r sinkReader) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			r.sink.Write(s.read(r.name, now))
+		}
+	}
+}
+
+// read collects the data points of all series emitted on this read.
+func (s *Simulation) read(consumer string, now time.Time) []DataPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return readPoints(s.app.Metrics, consumer, now)
+}
+
+// readPoints reads all series of metrics like an exporter: sparse series
+// may be omitted and reset_on_read values are reset for consumer.
+func readPoints(metrics *metric.Registry, consumer string, now time.Time) []DataPoint {
+	var points []DataPoint
+	for _, d := range metrics.Metrics() {
+		if !d.Sampler.Emit(consumer) || !simulation.AllowPoint() {
+			continue
+		}
+		// Special samples are omitted: data points carry integers
+		var val int
+		var special bool
+		if !d.Guard.Do("sink read", func() {
+			if special = d.Value.Special() != ""; !special {
+				val = d.Value.Read(consumer)
+			}
+		}) || special {
+			continue
+		}
+		points = append(points, DataPoint{
+			Time:       now,
+			Name:       d.PrometheusName,
+			Type:       MetricType(d.Type),
+			Attributes: maps.Clone(d.Attributes),
+			Job:        d.Job,
+			Value:      val,
+		})
+	}
+	return points
+}
+
+// MemorySink keeps every written data point in memory, for assertions in
+// tests. It is safe for concurrent use.
+type MemorySink struct {
+	mu     sync.Mutex
+	points []DataPoint
+}
+
+// NewMemorySink creates an empty in-memory sink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Write appends points.
+func (m *MemorySink) Write(points []DataPoint) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.points = append(m.points, points...)
+}
+
+// Points returns all captured data points in write order.
+func (m *MemorySink) Points() []DataPoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.points)
+}
+
+// Series returns the captured data points of a metric whose attributes
+// include attrs, in write order.
+func (m *MemorySink) Series(name string, attrs map[string]string) []DataPoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var points []DataPoint
+	for _, p := range m.points {
+		if p.Name == name && hasAttributes(p.Attributes, attrs) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// Reset discards all captured data points.
+func (m *MemorySink) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.points = nil
+}
+
+// hasAttributes reports whether attrs is a subset of have.
+func hasAttributes(have, attrs map[string]string) bool {
+	for k, v := range attrs {
+		if have[k] != v {
+			return false
+		}
+	}
+	return true
+}